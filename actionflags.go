@@ -0,0 +1,197 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ActionParamFlag describes one of an action's params as a command
+// line flag, such as those accepted by "juju run".
+type ActionParamFlag struct {
+	// Name holds the name of the param. The params of nested objects
+	// are named by joining the names of the params leading to them
+	// with dots, as in "backup.compression".
+	Name string
+
+	// Type holds the JSON-Schema type of the param's value: "string",
+	// "integer", "number", "boolean", "array" or "object", or "" if
+	// the schema does not constrain it.
+	Type string
+
+	// Default holds the default value of the param, or nil if it has
+	// none.
+	Default interface{}
+
+	// Required reports whether the param must be given.
+	Required bool
+
+	// Description holds the description of the param, if any.
+	Description string
+
+	// Enum holds the values the param may take, or nil if any value
+	// of its type is allowed.
+	Enum []interface{}
+}
+
+// ParamFlags returns the action's params as a list of flags sorted by
+// name. Params holding objects with declared properties are flattened
+// into a flag per property, so that every flag takes a single value.
+func (spec *ActionSpec) ParamFlags() []ActionParamFlag {
+	var flags []ActionParamFlag
+	appendParamFlags(&flags, "", spec.Params, true)
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags
+}
+
+// appendParamFlags appends to flags a flag for each of the properties
+// of the object described by schema, whose own flag is named prefix
+// and which is required if required is true.
+func appendParamFlags(flags *[]ActionParamFlag, prefix string, schema map[string]interface{}, required bool) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	requiredNames, _ := schema["required"].([]interface{})
+	for name, value := range properties {
+		property, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		flagName := name
+		if prefix != "" {
+			flagName = prefix + "." + name
+		}
+		propertyRequired := required && containsValue(requiredNames, name)
+		flagType := schemaType(property)
+		if _, ok := property["properties"].(map[string]interface{}); ok && flagType == "object" {
+			appendParamFlags(flags, flagName, property, propertyRequired)
+			continue
+		}
+		description, _ := property["description"].(string)
+		enum, _ := property["enum"].([]interface{})
+		*flags = append(*flags, ActionParamFlag{
+			Name:        flagName,
+			Type:        flagType,
+			Default:     property["default"],
+			Required:    propertyRequired,
+			Description: description,
+			Enum:        enum,
+		})
+	}
+}
+
+// schemaType returns the type declared by the given schema. Of a list
+// of types, the first that is not "null" is returned.
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// containsValue reports whether values contains v.
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseParamFlags parses the values given on the command line for the
+// action's param flags, keyed by flag name as returned by ParamFlags,
+// and returns the params map they describe after validating it with
+// ValidateParams. Values are converted to the types of their params;
+// arrays, objects and params of unknown type are parsed as YAML. Names
+// not matching any flag are only accepted if the action allows
+// additional properties.
+func (spec *ActionSpec) ParseParamFlags(values map[string]string) (map[string]interface{}, error) {
+	flags := make(map[string]ActionParamFlag)
+	for _, flag := range spec.ParamFlags() {
+		flags[flag.Name] = flag
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make(map[string]interface{})
+	for _, name := range names {
+		flag, ok := flags[name]
+		if !ok {
+			if spec.Params["additionalProperties"] == false {
+				return nil, errors.NotValidf("param %q", name)
+			}
+			flag = ActionParamFlag{Name: name}
+		}
+		value, err := parseParamFlag(flag, values[name])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := setParam(params, strings.Split(name, "."), value); err != nil {
+			return nil, errors.Annotatef(err, "cannot set param %q", name)
+		}
+	}
+	if err := spec.ValidateParams(params); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return params, nil
+}
+
+// parseParamFlag converts the string given for flag to its type.
+func parseParamFlag(flag ActionParamFlag, s string) (interface{}, error) {
+	var (
+		value interface{}
+		err   error
+	)
+	switch flag.Type {
+	case "string":
+		return s, nil
+	case "integer":
+		value, err = strconv.Atoi(s)
+	case "number":
+		value, err = strconv.ParseFloat(s, 64)
+	case "boolean":
+		value, err = strconv.ParseBool(s)
+	default:
+		if err = yaml.Unmarshal([]byte(s), &value); err == nil {
+			value, err = cleanse(value)
+		}
+	}
+	if err != nil {
+		return nil, errors.NotValidf("value %q for param %q", s, flag.Name)
+	}
+	return value, nil
+}
+
+// setParam sets the value of the param found by following path from
+// params, creating the objects leading to it as needed.
+func setParam(params map[string]interface{}, path []string, value interface{}) error {
+	for _, key := range path[:len(path)-1] {
+		next, ok := params[key]
+		if !ok {
+			next = make(map[string]interface{})
+			params[key] = next
+		}
+		if params, ok = next.(map[string]interface{}); !ok {
+			return errors.Errorf("%q is not an object", key)
+		}
+	}
+	params[path[len(path)-1]] = value
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type ActionFlagsSuite struct{}
+
+var _ = gc.Suite(&ActionFlagsSuite{})
+
+const actionFlagsYAML = `
+backup:
+  description: Back up the database.
+  params:
+    outfile:
+      type: string
+      description: The file to write the backup to.
+    level:
+      type: integer
+      default: 3
+      enum: [1, 3, 9]
+    ratio:
+      type: number
+    verbose:
+      type: [boolean, "null"]
+    tables:
+      type: array
+      items:
+        type: string
+    target:
+      type: object
+      properties:
+        host:
+          type: string
+        port:
+          type: integer
+      required: [host]
+    extra:
+      description: Anything else.
+  required: [outfile, target]
+  additional-properties: false
+`
+
+func (s *ActionFlagsSuite) readSpec(c *gc.C) charm.ActionSpec {
+	actions, err := charm.ReadActionsYaml("dummy", strings.NewReader(actionFlagsYAML))
+	c.Assert(err, jc.ErrorIsNil)
+	return actions.ActionSpecs["backup"]
+}
+
+func (s *ActionFlagsSuite) TestParamFlags(c *gc.C) {
+	spec := s.readSpec(c)
+	c.Assert(spec.ParamFlags(), jc.DeepEquals, []charm.ActionParamFlag{{
+		Name:        "extra",
+		Description: "Anything else.",
+	}, {
+		Name:    "level",
+		Type:    "integer",
+		Default: 3,
+		Enum:    []interface{}{1, 3, 9},
+	}, {
+		Name:        "outfile",
+		Type:        "string",
+		Required:    true,
+		Description: "The file to write the backup to.",
+	}, {
+		Name: "ratio",
+		Type: "number",
+	}, {
+		Name: "tables",
+		Type: "array",
+	}, {
+		Name:     "target.host",
+		Type:     "string",
+		Required: true,
+	}, {
+		Name: "target.port",
+		Type: "integer",
+	}, {
+		Name: "verbose",
+		Type: "boolean",
+	}})
+}
+
+func (s *ActionFlagsSuite) TestParseParamFlags(c *gc.C) {
+	spec := s.readSpec(c)
+	params, err := spec.ParseParamFlags(map[string]string{
+		"outfile":     "/tmp/backup",
+		"level":       "9",
+		"ratio":       "0.5",
+		"verbose":     "true",
+		"tables":      "[users, orders]",
+		"target.host": "10.0.0.1",
+		"target.port": "5432",
+		"extra":       "{a: 1}",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params, jc.DeepEquals, map[string]interface{}{
+		"outfile": "/tmp/backup",
+		"level":   9,
+		"ratio":   0.5,
+		"verbose": true,
+		"tables":  []interface{}{"users", "orders"},
+		"target": map[string]interface{}{
+			"host": "10.0.0.1",
+			"port": 5432,
+		},
+		"extra": map[string]interface{}{"a": 1},
+	})
+}
+
+func (s *ActionFlagsSuite) TestParseParamFlagsErrors(c *gc.C) {
+	spec := s.readSpec(c)
+	for i, test := range []struct {
+		values map[string]string
+		err    string
+	}{{
+		values: map[string]string{"outfile": "x", "target.host": "h", "level": "high"},
+		err:    `value "high" for param "level" not valid`,
+	}, {
+		values: map[string]string{"outfile": "x", "target.host": "h", "verbose": "maybe"},
+		err:    `value "maybe" for param "verbose" not valid`,
+	}, {
+		values: map[string]string{"outfile": "x", "target.host": "h", "unknown": "1"},
+		err:    `param "unknown" not valid`,
+	}, {
+		values: map[string]string{"outfile": "x", "target.host": "h", "level": "2"},
+		err:    `validation failed: .*level.*`,
+	}, {
+		values: map[string]string{"outfile": "x"},
+		err:    `validation failed: .*target.*`,
+	}} {
+		c.Logf("test %d: %v", i, test.values)
+		_, err := spec.ParseParamFlags(test.values)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+
+	_, err := spec.ParseParamFlags(map[string]string{"level": "high"})
+	c.Assert(err, jc.ErrorIs, errors.NotValid)
+}
+
+func (s *ActionFlagsSuite) TestParseParamFlagsAdditionalProperties(c *gc.C) {
+	spec := charm.ActionSpec{
+		Params: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		},
+	}
+	params, err := spec.ParseParamFlags(map[string]string{
+		"name":    "value",
+		"opts.on": "yes",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params, jc.DeepEquals, map[string]interface{}{
+		"name": "value",
+		"opts": map[string]interface{}{"on": true},
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"path"
 	"regexp"
 	"strings"
 
@@ -17,11 +18,15 @@ import (
 
 var prohibitedSchemaKeys = map[string]bool{"$ref": true, "$schema": true}
 
-var actionNameRule = regexp.MustCompile("^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$")
+// refsProhibitedSchemaKeys holds the keys rejected within the params and
+// results of actions that set schema-refs, which may use references.
+var refsProhibitedSchemaKeys = map[string]bool{"$schema": true}
+
+var actionNameRule = lazyRegexp("^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$")
 
 // Export `actionNameRule` variable to different contexts.
 func GetActionNameRule() *regexp.Regexp {
-	return actionNameRule
+	return actionNameRule()
 }
 
 // Actions defines the available actions for the charm. Additional params
@@ -37,12 +42,61 @@ func NewActions() *Actions {
 
 // ActionSpec is a definition of the parameters and traits of an Action.
 // The Params map is expected to conform to JSON-Schema Draft 4 as defined at
-// http://json-schema.org/draft-04/schema# (see http://json-schema.org/latest/json-schema-core.html)
+// http://json-schema.org/draft-04/schema# (see http://json-schema.org/latest/json-schema-core.html).
+// References ("$ref") are only allowed if the action sets schema-refs
+// in actions.yaml; they may then refer to schemas in its "definitions".
 type ActionSpec struct {
 	Description    string
 	Parallel       bool
 	ExecutionGroup string
 	Params         map[string]interface{}
+
+	// Results holds the JSON-Schema describing the results of the
+	// action, as declared in the results section of actions.yaml, or
+	// nil if the action does not declare its results. Like Params, it
+	// may use references if the action sets schema-refs.
+	Results map[string]interface{} `bson:",omitempty" yaml:",omitempty"`
+
+	// AdditionalProperties holds whether the action accepts params
+	// not declared in Params, as declared by the additional-properties
+	// or additionalProperties key in actions.yaml, or nil if the action
+	// does not declare it as a bool.
+	AdditionalProperties *bool `bson:",omitempty" yaml:",omitempty"`
+
+	// Execution holds how the action is run, as declared by the
+	// execution block in actions.yaml, or nil if the action runs the
+	// script of the same name in the charm's actions directory.
+	Execution *ActionExecution `bson:",omitempty" yaml:",omitempty"`
+}
+
+// ActionExecution describes how an action is run. Exactly one of Script
+// and Command is set.
+type ActionExecution struct {
+	// Script holds the path, relative to the root of the charm, of the
+	// executable implementing the action.
+	Script string `bson:",omitempty" yaml:",omitempty"`
+
+	// Command holds a command line embedded in actions.yaml, which is
+	// run with juju-exec rather than from a file in the charm.
+	Command string `bson:",omitempty" yaml:",omitempty"`
+}
+
+// IsCommand reports whether the action runs an embedded command rather
+// than a script.
+func (e *ActionExecution) IsCommand() bool {
+	return e != nil && e.Command != ""
+}
+
+// Commands returns the embedded commands of the actions, keyed by
+// action name, so that juju-exec may restrict itself to running them.
+func (a *Actions) Commands() map[string]string {
+	commands := make(map[string]string)
+	for name, spec := range a.ActionSpecs {
+		if spec.Execution.IsCommand() {
+			commands[name] = spec.Execution.Command
+		}
+	}
+	return commands
 }
 
 // ValidateParams validates the passed params map against the given ActionSpec
@@ -50,18 +104,34 @@ type ActionSpec struct {
 // Usage:
 //   err := ch.Actions().ActionSpecs["snapshot"].ValidateParams(someMap)
 func (spec *ActionSpec) ValidateParams(params map[string]interface{}) error {
+	return validateDocument(spec.Params, params)
+}
+
+// ValidateResults validates the results produced by running the action
+// against the results schema of the ActionSpec, and returns any error
+// encountered. Actions which do not declare their results accept any
+// results.
+func (spec *ActionSpec) ValidateResults(results map[string]interface{}) error {
+	if spec.Results == nil {
+		return nil
+	}
+	return validateDocument(spec.Results, results)
+}
+
+// validateDocument validates doc against the given JSON-Schema.
+func validateDocument(schemaDoc, doc map[string]interface{}) error {
 	// Load the schema from the Charm.
-	specLoader := gjs.NewGoLoader(spec.Params)
+	specLoader := gjs.NewGoLoader(schemaDoc)
 	schema, err := gjs.NewSchema(specLoader)
 	if err != nil {
 		return err
 	}
 
-	// Load the params as a document to validate.
+	// Load the document to validate.
 	// If an empty map was passed, we need an empty map to validate against.
 	p := map[string]interface{}{}
-	if len(params) > 0 {
-		p = params
+	if len(doc) > 0 {
+		p = doc
 	}
 	docLoader := gjs.NewGoLoader(p)
 	results, err := schema.Validate(docLoader)
@@ -83,11 +153,16 @@ func (spec *ActionSpec) ValidateParams(params map[string]interface{}) error {
 // InsertDefaults inserts the schema's default values in target using
 // github.com/juju/gojsonschema.  If a nil target is received, an empty map
 // will be created as the target.  The target is then mutated to include the
-// defaults.
+// defaults.  Defaults declared by the schemas that properties refer to
+// with "$ref" are inserted too.
 //
 // The returned map will be the transformed or created target map.
 func (spec *ActionSpec) InsertDefaults(target map[string]interface{}) (map[string]interface{}, error) {
-	specLoader := gjs.NewGoLoader(spec.Params)
+	params, err := resolveSchemaRefs(spec.Params, spec.Params, nil)
+	if err != nil {
+		return target, err
+	}
+	specLoader := gjs.NewGoLoader(params)
 	schema, err := gjs.NewSchema(specLoader)
 	if err != nil {
 		return target, err
@@ -96,6 +171,79 @@ func (spec *ActionSpec) InsertDefaults(target map[string]interface{}) (map[strin
 	return schema.InsertDefaults(target)
 }
 
+// resolveSchemaRefs returns a copy of the given schema in which the
+// schemas referred to with "$ref", which must point into root, replace
+// the references. References to a schema being resolved, as by
+// recursive definitions, are left in place.
+func resolveSchemaRefs(schema interface{}, root map[string]interface{}, resolving []string) (interface{}, error) {
+	switch schema := schema.(type) {
+	case map[string]interface{}:
+		if schema == nil {
+			return schema, nil
+		}
+		if ref, ok := schema["$ref"].(string); ok {
+			for _, r := range resolving {
+				if r == ref {
+					return schema, nil
+				}
+			}
+			target, err := lookupSchemaRef(root, ref)
+			if err != nil {
+				return nil, err
+			}
+			return resolveSchemaRefs(target, root, append(resolving, ref))
+		}
+		result := make(map[string]interface{}, len(schema))
+		for key, value := range schema {
+			if key == "definitions" {
+				result[key] = value
+				continue
+			}
+			resolved, err := resolveSchemaRefs(value, root, resolving)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = resolved
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(schema))
+		for i, value := range schema {
+			resolved, err := resolveSchemaRefs(value, root, resolving)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	}
+	return schema, nil
+}
+
+// lookupSchemaRef returns the schema within root that the given "$ref"
+// value points to, such as "#/definitions/mode".
+func lookupSchemaRef(root map[string]interface{}, ref string) (interface{}, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, errors.NotSupportedf("reference %q outside of the schema", ref)
+	}
+	var target interface{} = root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := target.(map[string]interface{})
+		if !ok {
+			return nil, errors.NotFoundf("schema reference %q", ref)
+		}
+		if target, ok = m[token]; !ok {
+			return nil, errors.NotFoundf("schema reference %q", ref)
+		}
+	}
+	return target, nil
+}
+
 // ReadActionsYaml builds an Actions spec from a charm's actions.yaml.
 func ReadActionsYaml(charmName string, r io.Reader) (*Actions, error) {
 	data, err := ioutil.ReadAll(r)
@@ -113,7 +261,7 @@ func ReadActionsYaml(charmName string, r io.Reader) (*Actions, error) {
 	}
 
 	for name, actionSpec := range unmarshaledActions {
-		if valid := actionNameRule.MatchString(name); !valid {
+		if valid := actionNameRule().MatchString(name); !valid {
 			return nil, fmt.Errorf("bad action name %s", name)
 		}
 		if reserved, reason := reservedName(charmName, name); reserved {
@@ -126,12 +274,29 @@ func ReadActionsYaml(charmName string, r io.Reader) (*Actions, error) {
 		desc := "No description"
 		parallel := false
 		executionGroup := ""
+		var (
+			additionalProperties *bool
+			execution            *ActionExecution
+		)
+		// The params and results are draft-04 schemas, which may only
+		// use references if the action opts in with schema-refs.
+		prohibited := prohibitedSchemaKeys
+		if value, ok := actionSpec["schema-refs"]; ok {
+			refs, ok := value.(bool)
+			if !ok {
+				return nil, errors.Errorf("value for schema key %q must be a bool", "schema-refs")
+			}
+			if refs {
+				prohibited = refsProhibitedSchemaKeys
+			}
+		}
 		thisActionSchema := map[string]interface{}{
 			"description": desc,
 			"type":        "object",
 			"title":       name,
 			"properties":  map[string]interface{}{},
 		}
+		var resultsSchema map[string]interface{}
 
 		for key, value := range actionSpec {
 			switch key {
@@ -168,10 +333,40 @@ func ReadActionsYaml(charmName string, r io.Reader) (*Actions, error) {
 					return nil, errors.Errorf("value for schema key %q must be a string", key)
 				}
 				executionGroup = typed
+			case "additional-properties":
+				typed, ok := value.(bool)
+				if !ok {
+					return nil, errors.Errorf("value for schema key %q must be a bool", key)
+				}
+				if _, ok := actionSpec["additionalProperties"]; ok {
+					return nil, errors.Errorf("action %s declares both additional-properties and additionalProperties", name)
+				}
+				thisActionSchema["additionalProperties"] = typed
+				additionalProperties = &typed
+			case "additionalProperties":
+				// The JSON schema keyword may also give a schema
+				// for the additional params, which is kept as is.
+				if typed, ok := value.(bool); ok {
+					thisActionSchema[key] = typed
+					additionalProperties = &typed
+					break
+				}
+				typed, err := cleanseSchema(value, prohibited)
+				if err != nil {
+					return nil, err
+				}
+				thisActionSchema[key] = typed
+			case "schema-refs":
+				// Already handled above.
+			case "execution":
+				var err error
+				if execution, err = parseActionExecution(value); err != nil {
+					return nil, errors.Annotatef(err, "invalid execution for action %s", name)
+				}
 			case "params":
 				// Clean any map[interface{}]interface{}s out so they don't
 				// cause problems with BSON serialization later.
-				cleansedParams, err := cleanse(value)
+				cleansedParams, err := cleanseSchema(value, prohibited)
 				if err != nil {
 					return nil, err
 				}
@@ -182,9 +377,22 @@ func ReadActionsYaml(charmName string, r io.Reader) (*Actions, error) {
 					return nil, errors.New("params failed to parse as a map")
 				}
 				thisActionSchema["properties"] = typed
+			case "results":
+				cleansedResults, err := cleanseSchema(value, prohibited)
+				if err != nil {
+					return nil, err
+				}
+				typed, ok := cleansedResults.(map[string]interface{})
+				if !ok {
+					return nil, errors.New("results failed to parse as a map")
+				}
+				resultsSchema = map[string]interface{}{
+					"type":       "object",
+					"properties": typed,
+				}
 			default:
 				// In case this has nested maps, we must clean them out.
-				typed, err := cleanse(value)
+				typed, err := cleanseSchema(value, prohibited)
 				if err != nil {
 					return nil, err
 				}
@@ -193,38 +401,96 @@ func ReadActionsYaml(charmName string, r io.Reader) (*Actions, error) {
 		}
 
 		// Make sure the new Params doc conforms to JSON-Schema
-		// Draft 4 (http://json-schema.org/latest/json-schema-core.html)
-		schemaLoader := gjs.NewGoLoader(thisActionSchema)
-		_, err := gjs.NewSchema(schemaLoader)
+		// Draft 4 (http://json-schema.org/latest/json-schema-core.html).
+		_, err := gjs.NewSchema(gjs.NewGoLoader(thisActionSchema))
 		if err != nil {
 			return nil, errors.Annotatef(err, "invalid params schema for action schema %s", name)
 		}
 
+		// The results schema may refer to the same definitions as
+		// the params.
+		if resultsSchema != nil {
+			if definitions, ok := thisActionSchema["definitions"]; ok {
+				resultsSchema["definitions"] = definitions
+			}
+			if _, err := gjs.NewSchema(gjs.NewGoLoader(resultsSchema)); err != nil {
+				return nil, errors.Annotatef(err, "invalid results schema for action schema %s", name)
+			}
+		}
+
 		// Now assign the resulting schema to the final entry for the result.
 		result.ActionSpecs[name] = ActionSpec{
 			Description:    desc,
 			Parallel:       parallel,
 			ExecutionGroup: executionGroup,
 			Params:         thisActionSchema,
+			Results:        resultsSchema,
+
+			AdditionalProperties: additionalProperties,
+			Execution:            execution,
 		}
 	}
 	return result, nil
 }
 
+// parseActionExecution parses the execution block of an action, which
+// holds either the path of a script or an embedded command.
+func parseActionExecution(value interface{}) (*ActionExecution, error) {
+	fields, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("expected a map")
+	}
+	var execution ActionExecution
+	for key, value := range fields {
+		typed, ok := value.(string)
+		if !ok {
+			return nil, errors.Errorf("value for key %q must be a string", key)
+		}
+		switch key {
+		case "script":
+			execution.Script = typed
+		case "command":
+			execution.Command = typed
+		default:
+			return nil, errors.Errorf("unknown key %q", key)
+		}
+	}
+	switch {
+	case execution.Script != "" && execution.Command != "":
+		return nil, errors.New("script and command are mutually exclusive")
+	case execution.Script != "":
+		clean := path.Clean(execution.Script)
+		if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return nil, errors.Errorf("script %q must be within the charm", execution.Script)
+		}
+	case strings.TrimSpace(execution.Command) == "":
+		return nil, errors.New("expected a script or a command")
+	case strings.ContainsAny(execution.Command, "\n\r"):
+		return nil, errors.New("command must be a single line")
+	}
+	return &execution, nil
+}
+
 // cleanse rejects schemas containing references or maps keyed with non-
 // strings, and coerces acceptable maps to contain only maps with string keys.
 func cleanse(input interface{}) (interface{}, error) {
+	return cleanseSchema(input, prohibitedSchemaKeys)
+}
+
+// cleanseSchema is like cleanse, but rejects the given schema keys
+// rather than all references.
+func cleanseSchema(input interface{}, prohibited map[string]bool) (interface{}, error) {
 	switch typedInput := input.(type) {
 	// In this case, recurse in.
 	case map[string]interface{}:
 		newMap := make(map[string]interface{})
 		for key, value := range typedInput {
 
-			if prohibitedSchemaKeys[key] {
+			if prohibited[key] {
 				return nil, fmt.Errorf("schema key %q not compatible with this version of juju", key)
 			}
 
-			newValue, err := cleanse(value)
+			newValue, err := cleanseSchema(value, prohibited)
 			if err != nil {
 				return nil, err
 			}
@@ -242,13 +508,13 @@ func cleanse(input interface{}) (interface{}, error) {
 			}
 			newMap[typedKey] = value
 		}
-		return cleanse(newMap)
+		return cleanseSchema(newMap, prohibited)
 
 	// Recurse
 	case []interface{}:
 		newSlice := make([]interface{}, 0)
 		for _, sliceValue := range typedInput {
-			newSliceValue, err := cleanse(sliceValue)
+			newSliceValue, err := cleanseSchema(sliceValue, prohibited)
 			if err != nil {
 				return nil, errors.New("map keyed with non-string value")
 			}
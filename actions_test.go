@@ -1024,3 +1024,350 @@ func getSchemaForAction(c *gc.C, wholeSchema string) ActionSpec {
 	// Same action name for all tests, "act".
 	return loadedActions.ActionSpecs["act"]
 }
+
+func (s *ActionsSuite) TestSchemaRefsActionsYaml(c *gc.C) {
+	spec := getSchemaForAction(c, `
+act:
+  schema-refs: true
+  description: Take a snapshot.
+  params:
+    mode:
+      $ref: "#/definitions/mode"
+    name:
+      type: string
+      pattern: "^[a-z]+$"
+    target:
+      oneOf:
+        - type: integer
+        - type: string
+          enum: [all]
+    count:
+      type: integer
+      default: 1
+  definitions:
+    mode:
+      type: string
+      enum: [full, incremental]
+  required: [mode]
+`)
+	_, ok := spec.Params["schema-refs"]
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(spec.Params["definitions"], gc.NotNil)
+
+	err := spec.ValidateParams(map[string]interface{}{"mode": "full", "name": "db", "target": "all"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = spec.ValidateParams(map[string]interface{}{"mode": "full", "target": 3})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = spec.ValidateParams(map[string]interface{}{"mode": "partial"})
+	c.Assert(err, gc.ErrorMatches, `validation failed: \(root\)\.mode : must match one of the enum values \["full","incremental"\], given "partial"`)
+	err = spec.ValidateParams(map[string]interface{}{"mode": "full", "target": "some"})
+	c.Assert(err, gc.ErrorMatches, `validation failed: .*target.*`)
+	err = spec.ValidateParams(map[string]interface{}{"mode": "full", "name": "DB"})
+	c.Assert(err, gc.ErrorMatches, `validation failed: \(root\)\.name : does not match pattern .*`)
+	err = spec.ValidateParams(nil)
+	c.Assert(err, gc.ErrorMatches, `validation failed: \(root\) : "mode" property is missing and required, given {}`)
+
+	params, err := spec.InsertDefaults(map[string]interface{}{"mode": "full"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params, jc.DeepEquals, map[string]interface{}{"mode": "full", "count": float64(1)})
+}
+
+func (s *ActionsSuite) TestSchemaRefsInsertDefaults(c *gc.C) {
+	spec := getSchemaForAction(c, `
+act:
+  schema-refs: true
+  params:
+    mode:
+      $ref: "#/definitions/mode"
+    target:
+      $ref: "#/definitions/target"
+    tree:
+      $ref: "#/definitions/tree"
+  definitions:
+    mode:
+      type: string
+      enum: [full, incremental]
+      default: incremental
+    target:
+      type: object
+      properties:
+        host: {type: string, default: localhost}
+        port: {type: integer, default: 5432}
+    tree:
+      type: object
+      properties:
+        name: {type: string, default: root}
+        child: {$ref: "#/definitions/tree"}
+`)
+	params, err := spec.InsertDefaults(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params, jc.DeepEquals, map[string]interface{}{
+		"mode":   "incremental",
+		"target": map[string]interface{}{"host": "localhost", "port": float64(5432)},
+		"tree":   map[string]interface{}{"name": "root"},
+	})
+
+	params, err = spec.InsertDefaults(map[string]interface{}{
+		"mode":   "full",
+		"target": map[string]interface{}{"port": 3306},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params, jc.DeepEquals, map[string]interface{}{
+		"mode":   "full",
+		"target": map[string]interface{}{"host": "localhost", "port": 3306},
+		"tree":   map[string]interface{}{"name": "root"},
+	})
+	err = spec.ValidateParams(params)
+	c.Assert(err, jc.ErrorIsNil)
+
+	spec.Params["properties"].(map[string]interface{})["mode"] = map[string]interface{}{"$ref": "#/definitions/unknown"}
+	_, err = spec.InsertDefaults(nil)
+	c.Assert(err, gc.ErrorMatches, `schema reference "#/definitions/unknown" not found`)
+}
+
+func (s *ActionsSuite) TestSchemaRefsActionsYamlErrors(c *gc.C) {
+	for i, t := range []struct {
+		yaml string
+		err  string
+	}{{
+		yaml: `
+act:
+  schema-refs: "yes"
+  params:
+    outfile: {type: string}
+`,
+		err: `value for schema key "schema-refs" must be a bool`,
+	}, {
+		yaml: `
+act:
+  schema-refs: true
+  params:
+    outfile:
+      $schema: http://json-schema.org/draft-04/schema#
+`,
+		err: `schema key "\$schema" not compatible with this version of juju`,
+	}, {
+		// References remain rejected unless the action opts in.
+		yaml: `
+act:
+  params:
+    outfile: {$ref: "#/definitions/file"}
+`,
+		err: `schema key "\$ref" not compatible with this version of juju`,
+	}, {
+		yaml: `
+act:
+  schema-refs: false
+  params:
+    outfile: {$ref: "#/definitions/file"}
+`,
+		err: `schema key "\$ref" not compatible with this version of juju`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := ReadActionsYaml("somecharm", bytes.NewReader([]byte(t.yaml)))
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
+func (s *ActionsSuite) TestActionResults(c *gc.C) {
+	spec := getSchemaForAction(c, `
+act:
+  description: Take a snapshot.
+  params:
+    outfile: {type: string}
+  results:
+    path:
+      type: string
+    size:
+      type: integer
+      minimum: 0
+`)
+	c.Assert(spec.Results, jc.DeepEquals, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{"type": "string"},
+			"size": map[string]interface{}{"type": "integer", "minimum": 0},
+		},
+	})
+	_, ok := spec.Params["results"]
+	c.Assert(ok, jc.IsFalse)
+
+	err := spec.ValidateResults(map[string]interface{}{"path": "/tmp/snap", "size": 10})
+	c.Assert(err, jc.ErrorIsNil)
+	// Results not declared by the action are allowed.
+	err = spec.ValidateResults(map[string]interface{}{"path": "/tmp/snap", "Stdout": "done"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = spec.ValidateResults(map[string]interface{}{"size": -1})
+	c.Assert(err, gc.ErrorMatches, `validation failed: \(root\)\.size : must be greater than 0, given "-1"`)
+
+	// Actions without a results section accept anything.
+	spec = getSchemaForAction(c, `
+act:
+  params:
+    outfile: {type: string}
+`)
+	c.Assert(spec.Results, gc.IsNil)
+	err = spec.ValidateResults(map[string]interface{}{"size": "big"})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ActionsSuite) TestActionResultsSchemaRefs(c *gc.C) {
+	spec := getSchemaForAction(c, `
+act:
+  schema-refs: true
+  results:
+    mode:
+      $ref: "#/definitions/mode"
+  definitions:
+    mode:
+      enum: [full, incremental]
+`)
+	err := spec.ValidateResults(map[string]interface{}{"mode": "full"})
+	c.Assert(err, jc.ErrorIsNil)
+	err = spec.ValidateResults(map[string]interface{}{"mode": "partial"})
+	c.Assert(err, gc.ErrorMatches, `validation failed: \(root\)\.mode : must match one of the enum values \["full","incremental"\], given "partial"`)
+}
+
+func (s *ActionsSuite) TestActionResultsErrors(c *gc.C) {
+	_, err := ReadActionsYaml("somecharm", bytes.NewReader([]byte(`
+act:
+  results: [path, size]
+`)))
+	c.Assert(err, gc.ErrorMatches, "results failed to parse as a map")
+
+	_, err = ReadActionsYaml("somecharm", bytes.NewReader([]byte(`
+act:
+  results:
+    path: {$ref: "#/definitions/path"}
+`)))
+	c.Assert(err, gc.ErrorMatches, `schema key "\$ref" not compatible with this version of juju`)
+
+	_, err = ReadActionsYaml("somecharm", bytes.NewReader([]byte(`
+act:
+  results:
+    size: {type: 5}
+`)))
+	c.Assert(err, gc.ErrorMatches, `invalid results schema for action schema act: .*`)
+}
+
+func (s *ActionsSuite) TestActionAdditionalProperties(c *gc.C) {
+	spec := getSchemaForAction(c, `
+act:
+  params:
+    outfile: {type: string}
+  additional-properties: false
+`)
+	c.Assert(spec.AdditionalProperties, gc.NotNil)
+	c.Assert(*spec.AdditionalProperties, jc.IsFalse)
+	c.Assert(spec.Params["additionalProperties"], gc.Equals, false)
+	_, ok := spec.Params["additional-properties"]
+	c.Assert(ok, jc.IsFalse)
+	err := spec.ValidateParams(map[string]interface{}{"outfile": "out", "quality": 5})
+	c.Assert(err, gc.ErrorMatches, `validation failed: \(root\) : additional property "quality" is not allowed, .*`)
+
+	spec = getSchemaForAction(c, `
+act:
+  params:
+    outfile: {type: string}
+`)
+	c.Assert(spec.AdditionalProperties, gc.IsNil)
+
+	spec = getSchemaForAction(c, `
+act:
+  params:
+    outfile: {type: string}
+  additionalProperties: true
+`)
+	c.Assert(spec.AdditionalProperties, gc.NotNil)
+	c.Assert(*spec.AdditionalProperties, jc.IsTrue)
+	c.Assert(spec.Params["additionalProperties"], gc.Equals, true)
+
+	spec = getSchemaForAction(c, `
+act:
+  params:
+    outfile: {type: string}
+  additionalProperties: {type: string}
+`)
+	c.Assert(spec.AdditionalProperties, gc.IsNil)
+	c.Assert(spec.Params["additionalProperties"], jc.DeepEquals, map[string]interface{}{"type": "string"})
+	err = spec.ValidateParams(map[string]interface{}{"outfile": "out", "quality": 5})
+	c.Assert(err, gc.ErrorMatches, `validation failed: \(root\) : must be of type string, given 5`)
+
+	_, err = ReadActionsYaml("somecharm", bytes.NewReader([]byte(`
+act:
+  additional-properties: "no"
+`)))
+	c.Assert(err, gc.ErrorMatches, `value for schema key "additional-properties" must be a bool`)
+
+	_, err = ReadActionsYaml("somecharm", bytes.NewReader([]byte(`
+act:
+  additional-properties: false
+  additionalProperties: false
+`)))
+	c.Assert(err, gc.ErrorMatches, `action act declares both additional-properties and additionalProperties`)
+}
+
+func (s *ActionsSuite) TestActionExecution(c *gc.C) {
+	actions, err := ReadActionsYaml("somecharm", bytes.NewReader([]byte(`
+snapshot:
+  execution:
+    script: bin/snapshot
+restart:
+  execution:
+    command: systemctl restart mysql
+backup:
+  description: Back up the database.
+`)))
+	c.Assert(err, jc.ErrorIsNil)
+	specs := actions.ActionSpecs
+	c.Assert(specs["snapshot"].Execution, jc.DeepEquals, &ActionExecution{Script: "bin/snapshot"})
+	c.Assert(specs["snapshot"].Execution.IsCommand(), jc.IsFalse)
+	c.Assert(specs["restart"].Execution, jc.DeepEquals, &ActionExecution{Command: "systemctl restart mysql"})
+	c.Assert(specs["restart"].Execution.IsCommand(), jc.IsTrue)
+	c.Assert(specs["backup"].Execution, gc.IsNil)
+	c.Assert(specs["backup"].Execution.IsCommand(), jc.IsFalse)
+	_, ok := specs["snapshot"].Params["execution"]
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(actions.Commands(), jc.DeepEquals, map[string]string{
+		"restart": "systemctl restart mysql",
+	})
+}
+
+func (s *ActionsSuite) TestActionExecutionErrors(c *gc.C) {
+	for i, test := range []struct {
+		execution string
+		err       string
+	}{{
+		execution: `bin/snapshot`,
+		err:       `invalid execution for action act: expected a map`,
+	}, {
+		execution: `{script: bin/snapshot, command: ls}`,
+		err:       `invalid execution for action act: script and command are mutually exclusive`,
+	}, {
+		execution: `{}`,
+		err:       `invalid execution for action act: expected a script or a command`,
+	}, {
+		execution: `{command: "  "}`,
+		err:       `invalid execution for action act: expected a script or a command`,
+	}, {
+		execution: `{command: "ls\nrm -rf /"}`,
+		err:       `invalid execution for action act: command must be a single line`,
+	}, {
+		execution: `{script: /bin/snapshot}`,
+		err:       `invalid execution for action act: script "/bin/snapshot" must be within the charm`,
+	}, {
+		execution: `{script: bin/../../snapshot}`,
+		err:       `invalid execution for action act: script "bin/../../snapshot" must be within the charm`,
+	}, {
+		execution: `{script: 5}`,
+		err:       `invalid execution for action act: value for key "script" must be a string`,
+	}, {
+		execution: `{program: bin/snapshot}`,
+		err:       `invalid execution for action act: unknown key "program"`,
+	}} {
+		c.Logf("test %d: %s", i, test.execution)
+		_, err := ReadActionsYaml("somecharm", bytes.NewReader([]byte("act:\n  execution: "+test.execution+"\n")))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/juju/mgo/v3/bson"
+)
+
+// Annotations holds the annotations of a bundle application or
+// machine. Annotation values are strings, but when decoded from YAML,
+// JSON or BSON, numbers and booleans are also accepted and converted
+// to their string form, as GUI positions such as gui-x and gui-y are
+// often written as numbers.
+type Annotations map[string]string
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (a *Annotations) UnmarshalYAML(f func(interface{}) error) error {
+	var raw map[string]interface{}
+	if err := f(&raw); err != nil {
+		return err
+	}
+	return a.set(raw)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *Annotations) UnmarshalJSON(b []byte) error {
+	var raw map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	return a.set(raw)
+}
+
+// SetBSON implements the bson.Setter interface.
+func (a *Annotations) SetBSON(raw bson.Raw) error {
+	var m map[string]interface{}
+	if err := raw.Unmarshal(&m); err != nil {
+		return err
+	}
+	return a.set(m)
+}
+
+// set sets the annotations from their decoded values.
+func (a *Annotations) set(raw map[string]interface{}) error {
+	if raw == nil {
+		*a = nil
+		return nil
+	}
+	result := make(Annotations, len(raw))
+	for key, value := range raw {
+		s, err := annotationValue(value)
+		if err != nil {
+			return errors.Annotatef(err, "annotation %q", key)
+		}
+		result[key] = s
+	}
+	*a = result
+	return nil
+}
+
+// annotationValue returns the string form of a decoded annotation
+// value, which must be a scalar.
+func annotationValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	}
+	return "", errors.NotValidf("value of type %T", v)
+}
+
+// GUIPosition returns the position of the application in the GUI, as
+// held in its gui-x and gui-y annotations. It reports false if either
+// annotation is missing or is not a number.
+func (spec *ApplicationSpec) GUIPosition() (x, y float64, ok bool) {
+	xs, xok := spec.Annotations["gui-x"]
+	ys, yok := spec.Annotations["gui-y"]
+	if !xok || !yok {
+		return 0, 0, false
+	}
+	x, errX := strconv.ParseFloat(xs, 64)
+	y, errY := strconv.ParseFloat(ys, 64)
+	if errX != nil || errY != nil {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+// SetGUIPosition sets the gui-x and gui-y annotations of the
+// application.
+func (spec *ApplicationSpec) SetGUIPosition(x, y float64) {
+	if spec.Annotations == nil {
+		spec.Annotations = make(Annotations)
+	}
+	spec.Annotations["gui-x"] = strconv.FormatFloat(x, 'f', -1, 64)
+	spec.Annotations["gui-y"] = strconv.FormatFloat(y, 'f', -1, 64)
+}
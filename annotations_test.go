@@ -0,0 +1,96 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/juju/mgo/v3/bson"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/charm/v12"
+)
+
+type AnnotationsSuite struct{}
+
+var _ = gc.Suite(&AnnotationsSuite{})
+
+var numericAnnotations = charm.Annotations{
+	"gui-x":   "100",
+	"gui-y":   "-50.5",
+	"visible": "true",
+	"note":    "text",
+	"empty":   "",
+}
+
+func (s *AnnotationsSuite) TestYAML(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(`
+applications:
+  mysql:
+    charm: ch:mysql
+    annotations:
+      gui-x: 100
+      gui-y: -50.5
+      visible: true
+      note: text
+      empty:
+machines:
+  "0":
+    annotations:
+      gui-x: 1
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd.Applications["mysql"].Annotations, jc.DeepEquals, numericAnnotations)
+	c.Assert(bd.Machines["0"].Annotations, jc.DeepEquals, charm.Annotations{"gui-x": "1"})
+}
+
+func (s *AnnotationsSuite) TestJSON(c *gc.C) {
+	var spec charm.ApplicationSpec
+	err := json.Unmarshal([]byte(`{
+		"charm": "ch:mysql",
+		"annotations": {"gui-x": 100, "gui-y": -50.5, "visible": true, "note": "text", "empty": null}
+	}`), &spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec.Annotations, jc.DeepEquals, numericAnnotations)
+}
+
+func (s *AnnotationsSuite) TestBSON(c *gc.C) {
+	data, err := bson.Marshal(bson.M{
+		"charm": "ch:mysql",
+		"annotations": bson.M{
+			"gui-x": 100, "gui-y": -50.5, "visible": true, "note": "text", "empty": nil,
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	var spec charm.ApplicationSpec
+	err = bson.Unmarshal(data, &spec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec.Annotations, jc.DeepEquals, numericAnnotations)
+}
+
+func (s *AnnotationsSuite) TestInvalidValue(c *gc.C) {
+	var spec charm.ApplicationSpec
+	err := yaml.Unmarshal([]byte("annotations: {gui-x: [1, 2]}"), &spec)
+	c.Assert(err, gc.ErrorMatches, `annotation "gui-x": value of type \[\]interface {} not valid`)
+}
+
+func (s *AnnotationsSuite) TestGUIPosition(c *gc.C) {
+	var spec charm.ApplicationSpec
+	_, _, ok := spec.GUIPosition()
+	c.Assert(ok, jc.IsFalse)
+
+	spec.SetGUIPosition(100, -50.5)
+	c.Assert(spec.Annotations, jc.DeepEquals, charm.Annotations{"gui-x": "100", "gui-y": "-50.5"})
+	x, y, ok := spec.GUIPosition()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(x, gc.Equals, 100.0)
+	c.Assert(y, gc.Equals, -50.5)
+
+	spec.Annotations["gui-y"] = "top"
+	_, _, ok = spec.GUIPosition()
+	c.Assert(ok, jc.IsFalse)
+}
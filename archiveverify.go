@@ -0,0 +1,167 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// ArchiveProblem describes a problem found when verifying a charm
+// archive.
+type ArchiveProblem struct {
+	// Path holds the name of the archive entry with the problem, or
+	// is empty if the problem concerns the archive as a whole.
+	Path string
+
+	// Message describes the problem.
+	Message string
+}
+
+// String returns a description of the problem.
+func (p ArchiveProblem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%q: %s", p.Path, p.Message)
+}
+
+// ArchiveReport holds the result of verifying a charm archive.
+type ArchiveReport struct {
+	// Entries holds the number of archive entries checked.
+	Entries int
+
+	// Problems holds the problems found, in the order in which the
+	// entries they concern appear in the archive.
+	Problems []ArchiveProblem
+}
+
+// OK reports whether no problems were found.
+func (r *ArchiveReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Err returns nil if no problems were found, or otherwise an error
+// satisfying errors.NotValid describing them.
+func (r *ArchiveReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	problems := make([]string, len(r.Problems))
+	for i, p := range r.Problems {
+		problems[i] = p.String()
+	}
+	return errors.NewNotValid(nil, "charm archive: "+strings.Join(problems, "; "))
+}
+
+func (r *ArchiveReport) addProblem(path, f string, a ...interface{}) {
+	r.Problems = append(r.Problems, ArchiveProblem{Path: path, Message: fmt.Sprintf(f, a...)})
+}
+
+// Verify checks the integrity of the archive as it is now, without
+// relying on what was read when the archive was opened. It checks that:
+//
+//   - the zip structure is valid, and each entry's checksum matches its
+//     content;
+//   - no entry is duplicated, has an absolute path or lies outside the
+//     charm, and no symlink points outside the charm;
+//   - metadata.yaml is present and can be parsed, as can actions.yaml
+//     if present;
+//   - the revision file, if present, holds a single non-negative
+//     integer.
+//
+// The problems found are described by the returned report. An error
+// is returned only if the archive cannot be opened for reasons other
+// than its content, such as its file having been removed.
+func (a *CharmArchive) Verify() (*ArchiveReport, error) {
+	report := &ArchiveReport{}
+	zipr, err := a.zopen.openZip()
+	if errors.Is(err, zip.ErrFormat) || errors.Is(err, zip.ErrAlgorithm) || errors.Is(err, zip.ErrChecksum) {
+		report.addProblem("", "invalid zip file: %v", err)
+		return report, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer zipr.Close()
+
+	seen := make(map[string]bool)
+	contents := make(map[string][]byte)
+	for _, f := range zipr.File {
+		report.Entries++
+		name := strings.TrimSuffix(f.Name, "/")
+		if seen[name] {
+			report.addProblem(f.Name, "duplicate entry")
+		}
+		seen[name] = true
+		clean := path.Clean(name)
+		switch {
+		case path.IsAbs(f.Name) || strings.HasPrefix(f.Name, `\`) || (len(f.Name) > 1 && f.Name[1] == ':'):
+			report.addProblem(f.Name, "absolute path")
+			continue
+		case clean == ".." || strings.HasPrefix(clean, "../"):
+			report.addProblem(f.Name, "path outside of the charm")
+			continue
+		}
+
+		isSymlink := f.Mode()&os.ModeSymlink != 0
+		keep := isSymlink || clean == "metadata.yaml" || clean == "actions.yaml" || clean == "revision"
+		data, err := readArchiveEntry(f, keep)
+		if err != nil {
+			report.addProblem(f.Name, "cannot read entry: %v", err)
+			continue
+		}
+		if isSymlink {
+			if err := checkSymlinkTarget("", clean, string(data)); err != nil {
+				report.addProblem(f.Name, "%v", err)
+			}
+		} else if keep {
+			contents[clean] = data
+		}
+	}
+
+	data, ok := contents["metadata.yaml"]
+	if !ok {
+		report.addProblem("metadata.yaml", "file not found")
+	} else if meta, err := ReadMeta(bytes.NewReader(data)); err != nil {
+		report.addProblem("metadata.yaml", "cannot parse: %v", err)
+	} else if data, ok := contents["actions.yaml"]; ok {
+		if _, err := ReadActionsYaml(meta.Name, bytes.NewReader(data)); err != nil {
+			report.addProblem("actions.yaml", "cannot parse: %v", err)
+		}
+	}
+	if data, ok := contents["revision"]; ok {
+		revision := strings.TrimSpace(string(data))
+		if n, err := strconv.Atoi(revision); err != nil || n < 0 {
+			report.addProblem("revision", "invalid revision %q", revision)
+		}
+	}
+	return report, nil
+}
+
+// readArchiveEntry reads the content of the given archive entry in
+// full, so that the zip reader checks its checksum, and returns it if
+// keep is true.
+func readArchiveEntry(f *zip.File, keep bool) ([]byte, error) {
+	if f.FileInfo().IsDir() {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	if keep {
+		return io.ReadAll(rc)
+	}
+	_, err = io.Copy(io.Discard, rc)
+	return nil, err
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type ArchiveVerifySuite struct{}
+
+var _ = gc.Suite(&ArchiveVerifySuite{})
+
+type archiveEntry struct {
+	name    string
+	content string
+	mode    os.FileMode
+}
+
+// makeArchive returns a zip archive holding the given entries, stored
+// uncompressed so that tests may corrupt their content.
+func makeArchive(c *gc.C, entries ...archiveEntry) []byte {
+	var buf bytes.Buffer
+	zipw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		h := &zip.FileHeader{Name: e.name, Method: zip.Store}
+		mode := e.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		h.SetMode(mode)
+		w, err := zipw.CreateHeader(h)
+		c.Assert(err, jc.ErrorIsNil)
+		_, err = w.Write([]byte(e.content))
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	c.Assert(zipw.Close(), jc.ErrorIsNil)
+	return buf.Bytes()
+}
+
+const verifyMetadata = "name: verify\nsummary: s\ndescription: d\n"
+
+func (s *ArchiveVerifySuite) TestVerifyOK(c *gc.C) {
+	archive, err := charm.ReadCharmArchive(archivePath(c, readCharmDir(c, "dummy")))
+	c.Assert(err, jc.ErrorIsNil)
+	report, err := archive.Verify()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report.Problems, gc.HasLen, 0)
+	c.Assert(report.OK(), jc.IsTrue)
+	c.Assert(report.Err(), jc.ErrorIsNil)
+	c.Assert(report.Entries > 0, jc.IsTrue)
+}
+
+func (s *ArchiveVerifySuite) TestVerifyProblems(c *gc.C) {
+	data := makeArchive(c,
+		archiveEntry{name: "metadata.yaml", content: verifyMetadata},
+		archiveEntry{name: "revision", content: "12 extra\n"},
+		archiveEntry{name: "actions.yaml", content: "bad action!:\n  description: x\n"},
+		archiveEntry{name: "/etc/passwd", content: "root"},
+		archiveEntry{name: "../escape", content: "x"},
+		archiveEntry{name: "hooks/install", content: "#!/bin/sh"},
+		archiveEntry{name: "hooks/install", content: "#!/bin/sh"},
+		archiveEntry{name: "hooks/link", content: "/etc/shadow", mode: os.ModeSymlink | 0777},
+		archiveEntry{name: "hooks/up", content: "../../outside", mode: os.ModeSymlink | 0777},
+	)
+	archive, err := charm.ReadCharmArchiveBytes(data)
+	c.Assert(err, jc.ErrorIsNil)
+	report, err := archive.Verify()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report.Entries, gc.Equals, 9)
+	c.Assert(report.Problems, jc.DeepEquals, []charm.ArchiveProblem{
+		{Path: "/etc/passwd", Message: "absolute path"},
+		{Path: "../escape", Message: "path outside of the charm"},
+		{Path: "hooks/install", Message: "duplicate entry"},
+		{Path: "hooks/link", Message: `symlink "hooks/link" is absolute: "/etc/shadow"`},
+		{Path: "hooks/up", Message: `symlink "hooks/up" links out of charm: "../../outside"`},
+		{Path: "actions.yaml", Message: "cannot parse: bad action name bad action!"},
+		{Path: "revision", Message: `invalid revision "12 extra"`},
+	})
+	c.Assert(report.OK(), jc.IsFalse)
+	err = report.Err()
+	c.Assert(err, gc.ErrorMatches, `charm archive: "/etc/passwd": absolute path; .*; "revision": invalid revision "12 extra"`)
+	c.Assert(err, jc.ErrorIs, errors.NotValid)
+}
+
+func (s *ArchiveVerifySuite) TestVerifyChecksum(c *gc.C) {
+	data := makeArchive(c,
+		archiveEntry{name: "metadata.yaml", content: verifyMetadata},
+		archiveEntry{name: "hooks/install", content: "#!/bin/sh\necho installed\n"},
+	)
+	i := bytes.Index(data, []byte("echo installed"))
+	c.Assert(i, jc.GreaterThan, 0)
+	data[i] = 'E'
+
+	archive, err := charm.ReadCharmArchiveBytes(data)
+	c.Assert(err, jc.ErrorIsNil)
+	report, err := archive.Verify()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report.Problems, jc.DeepEquals, []charm.ArchiveProblem{
+		{Path: "hooks/install", Message: "cannot read entry: zip: checksum error"},
+	})
+}
+
+func (s *ArchiveVerifySuite) TestVerifyMissingMetadata(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "verify.charm")
+	err := os.WriteFile(path, makeArchive(c, archiveEntry{name: "metadata.yaml", content: verifyMetadata}), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	archive, err := charm.ReadCharmArchive(path)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = os.WriteFile(path, makeArchive(c, archiveEntry{name: "revision", content: "1"}), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	report, err := archive.Verify()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report.Problems, jc.DeepEquals, []charm.ArchiveProblem{
+		{Path: "metadata.yaml", Message: "file not found"},
+	})
+
+	err = os.WriteFile(path, []byte("not a zip file"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	report, err = archive.Verify()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(report.Problems, jc.DeepEquals, []charm.ArchiveProblem{
+		{Path: "", Message: "invalid zip file: zip: not a valid zip file"},
+	})
+
+	c.Assert(os.Remove(path), jc.ErrorIsNil)
+	_, err = archive.Verify()
+	c.Assert(err, jc.ErrorIs, os.ErrNotExist)
+}
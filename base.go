@@ -10,6 +10,7 @@ import (
 	"github.com/juju/collections/set"
 	"github.com/juju/errors"
 	"github.com/juju/os/v2"
+	"github.com/juju/os/v2/series"
 	"github.com/juju/utils/v3/arch"
 )
 
@@ -76,7 +77,7 @@ func ParseBase(s string, archs ...string) (Base, error) {
 
 	base.Architectures = make([]string, len(archs))
 	for i, v := range archs {
-		base.Architectures[i] = arch.NormaliseArch(v)
+		base.Architectures[i] = NormaliseArchitecture(v)
 	}
 
 	err = base.Validate()
@@ -90,6 +91,27 @@ func ParseBase(s string, archs ...string) (Base, error) {
 	return base, nil
 }
 
+// baseFromSeries returns the base equivalent to the given series. Only
+// Ubuntu and CentOS series have a base equivalent.
+func baseFromSeries(s string) (Base, error) {
+	osType, err := series.GetOSFromSeries(s)
+	if err != nil {
+		return Base{}, errors.Trace(err)
+	}
+	var channel string
+	switch osType {
+	case os.Ubuntu:
+		if channel, err = series.SeriesVersion(s); err != nil {
+			return Base{}, errors.Trace(err)
+		}
+	case os.CentOS:
+		channel = strings.TrimPrefix(s, "centos")
+	default:
+		return Base{}, errors.NotSupportedf("converting %s series %q to a base", osType, s)
+	}
+	return ParseBase(strings.ToLower(osType.String()) + "@" + channel)
+}
+
 // validOSForBase is a string set of valid OS names for a base.
 var validOSForBase = set.NewStrings(
 	strings.ToLower(os.Ubuntu.String()),
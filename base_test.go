@@ -95,6 +95,13 @@ func (s *baseSuite) TestParseBaseWithArchitectures(c *gc.C) {
 				Name:          strings.ToLower(os.Ubuntu.String()),
 				Channel:       mustParseChannel("20.04/stable"),
 				Architectures: []string{arch.AMD64, arch.PPC64EL}},
+		}, {
+			baseString: "ubuntu@22.04",
+			archs:      []string{"x86_64", "aarch64"},
+			parsedBase: charm.Base{
+				Name:          strings.ToLower(os.Ubuntu.String()),
+				Channel:       mustParseChannel("22.04/stable"),
+				Architectures: []string{arch.AMD64, arch.ARM64}},
 		}, {
 			baseString: "windows@win10/stable",
 			archs:      []string{"testme"},
@@ -0,0 +1,199 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"github.com/juju/os/v2/series"
+)
+
+// OSPolicy determines which operating systems are accepted in the bases
+// declared by charms and bundles, and which of those are deprecated.
+// The zero value is not usable; use NewOSPolicy or DefaultOSPolicy.
+type OSPolicy struct {
+	allowed    set.Strings
+	deprecated map[string]string
+}
+
+// NewOSPolicy returns a policy accepting only the named operating
+// systems, such as "ubuntu", none of which is deprecated.
+func NewOSPolicy(names ...string) *OSPolicy {
+	p := &OSPolicy{
+		allowed:    set.NewStrings(),
+		deprecated: make(map[string]string),
+	}
+	for _, name := range names {
+		p.allowed.Add(strings.ToLower(name))
+	}
+	return p
+}
+
+// DefaultOSPolicy returns a policy accepting every operating system
+// that Base.Validate accepts, with Windows, OSX and CentOS deprecated.
+func DefaultOSPolicy() *OSPolicy {
+	p := NewOSPolicy(validOSForBase.Values()...)
+	p.Deprecate("windows", "Windows workloads are no longer supported")
+	p.Deprecate("osx", "OSX workloads are no longer supported")
+	p.Deprecate("centos", "CentOS has reached end of life")
+	return p
+}
+
+// Deprecate marks the named operating system as deprecated for the
+// given reason. Bases using it are still accepted, but reported by
+// CheckBase.
+func (p *OSPolicy) Deprecate(name, reason string) {
+	p.deprecated[strings.ToLower(name)] = reason
+}
+
+// Allowed returns the names of the operating systems accepted by the
+// policy, sorted.
+func (p *OSPolicy) Allowed() []string {
+	return p.allowed.SortedValues()
+}
+
+// BaseWarning describes a base using a deprecated operating system.
+type BaseWarning struct {
+	// Location holds where the base was declared, such as
+	// "manifest" or "application mysql".
+	Location string
+
+	// Base holds the base, or series, as it was declared.
+	Base string
+
+	// Reason holds why the operating system is deprecated.
+	Reason string
+}
+
+// String returns a human readable description of the warning.
+func (w BaseWarning) String() string {
+	return fmt.Sprintf("%s: base %q is deprecated: %s", w.Location, w.Base, w.Reason)
+}
+
+// checkOS returns an error if the named operating system is not
+// allowed by the policy, and a warning if it is deprecated.
+func (p *OSPolicy) checkOS(location, base, name string) (*BaseWarning, error) {
+	name = strings.ToLower(name)
+	if !p.allowed.Contains(name) {
+		return nil, errors.NotValidf("%s: base %q: os %q (expected one of %s)",
+			location, base, name, strings.Join(p.Allowed(), ", "))
+	}
+	if reason, ok := p.deprecated[name]; ok {
+		return &BaseWarning{Location: location, Base: base, Reason: reason}, nil
+	}
+	return nil, nil
+}
+
+// CheckBase returns an error satisfying errors.IsNotValid if the
+// operating system of b is not allowed by the policy, and a warning if
+// it is deprecated. The location describes where the base was declared
+// and is used in the error and warning.
+func (p *OSPolicy) CheckBase(location string, b Base) (*BaseWarning, error) {
+	return p.checkOS(location, b.String(), b.Name)
+}
+
+// CheckCharm checks the bases declared in the charm's manifest and the
+// series declared in its metadata against the policy. It returns an
+// error for the first one whose operating system is not allowed, and a
+// warning for each one whose operating system is deprecated.
+func (p *OSPolicy) CheckCharm(ch CharmMeta) ([]BaseWarning, error) {
+	var warnings []BaseWarning
+	if manifest := ch.Manifest(); manifest != nil {
+		for _, b := range manifest.Bases {
+			w, err := p.CheckBase("manifest", b)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if w != nil {
+				warnings = append(warnings, *w)
+			}
+		}
+	}
+	for _, s := range ch.Meta().Series {
+		w, err := p.checkSeries("metadata", s)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if w != nil {
+			warnings = append(warnings, *w)
+		}
+	}
+	return warnings, nil
+}
+
+// checkSeries checks the operating system of the named series. Series
+// that are not known, or whose operating system cannot be used in a
+// base, such as "kubernetes", are not checked.
+func (p *OSPolicy) checkSeries(location, s string) (*BaseWarning, error) {
+	osType, err := series.GetOSFromSeries(s)
+	if err != nil {
+		return nil, nil
+	}
+	name := strings.ToLower(osType.String())
+	if !validOSForBase.Contains(name) {
+		return nil, nil
+	}
+	return p.checkOS(location, s, name)
+}
+
+// CheckBundle checks the default base and series of the bundle, and the
+// bases and series of its machines and applications, against the
+// policy. It returns an error for the first one whose operating system
+// is not allowed, and a warning for each one whose operating system is
+// deprecated. Malformed bases are left for Verify to report.
+func (p *OSPolicy) CheckBundle(bd *BundleData) ([]BaseWarning, error) {
+	var warnings []BaseWarning
+	check := func(location, base, s string) error {
+		var (
+			w   *BaseWarning
+			err error
+		)
+		switch {
+		case base != "":
+			b, perr := ParseBase(base)
+			if perr != nil {
+				return nil
+			}
+			w, err = p.checkOS(location, base, b.Name)
+		case s != "":
+			w, err = p.checkSeries(location, s)
+		}
+		if w != nil {
+			warnings = append(warnings, *w)
+		}
+		return errors.Trace(err)
+	}
+	if err := check("bundle", bd.DefaultBase, bd.Series); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(bd.Machines))
+	for id := range bd.Machines {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if m := bd.Machines[id]; m != nil {
+			if err := check("machine "+id, m.Base, m.Series); err != nil {
+				return nil, err
+			}
+		}
+	}
+	names := make([]string, 0, len(bd.Applications))
+	for name := range bd.Applications {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if app := bd.Applications[name]; app != nil {
+			if err := check("application "+name, app.Base, app.Series); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return warnings, nil
+}
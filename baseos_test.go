@@ -0,0 +1,101 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type OSPolicySuite struct{}
+
+var _ = gc.Suite(&OSPolicySuite{})
+
+func (s *OSPolicySuite) TestDefaultPolicy(c *gc.C) {
+	policy := charm.DefaultOSPolicy()
+	c.Assert(policy.Allowed(), jc.DeepEquals, []string{"centos", "genericlinux", "opensuse", "osx", "ubuntu", "windows"})
+
+	w, err := policy.CheckBase("manifest", mustParseBase(c, "ubuntu@22.04"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w, gc.IsNil)
+
+	w, err = policy.CheckBase("manifest", mustParseBase(c, "centos@7"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w, gc.NotNil)
+	c.Assert(w.String(), gc.Equals, `manifest: base "centos@7/stable" is deprecated: CentOS has reached end of life`)
+}
+
+func (s *OSPolicySuite) TestRestrictedPolicy(c *gc.C) {
+	policy := charm.NewOSPolicy("Ubuntu")
+	_, err := policy.CheckBase("manifest", mustParseBase(c, "centos@7"))
+	c.Assert(err, gc.ErrorMatches, `manifest: base "centos@7/stable": os "centos" \(expected one of ubuntu\) not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *OSPolicySuite) TestCheckBundle(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(`
+default-base: ubuntu@22.04
+machines:
+  "0":
+    base: centos@7
+applications:
+  mysql:
+    charm: ch:mysql
+    series: win2019
+  wordpress:
+    charm: ch:wordpress
+`))
+	c.Assert(err, jc.ErrorIsNil)
+
+	warnings, err := charm.DefaultOSPolicy().CheckBundle(bd)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(warnings, jc.DeepEquals, []charm.BaseWarning{{
+		Location: "machine 0",
+		Base:     "centos@7",
+		Reason:   "CentOS has reached end of life",
+	}, {
+		Location: "application mysql",
+		Base:     "win2019",
+		Reason:   "Windows workloads are no longer supported",
+	}})
+
+	_, err = charm.NewOSPolicy("ubuntu").CheckBundle(bd)
+	c.Assert(err, gc.ErrorMatches, `machine 0: base "centos@7": os "centos" \(expected one of ubuntu\) not valid`)
+}
+
+func (s *OSPolicySuite) TestReadCharmWithOSPolicy(c *gc.C) {
+	path := cloneDir(c, charmDirPath(c, "format-seriesmanifest"))
+	err := os.WriteFile(filepath.Join(path, "manifest.yaml"), []byte(`
+bases:
+  - name: ubuntu
+    channel: "22.04"
+  - name: centos
+    channel: "7"
+`), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var warnings []charm.BaseWarning
+	_, err = charm.ReadCharm(path, charm.WithOSPolicy(charm.DefaultOSPolicy(), func(w charm.BaseWarning) {
+		warnings = append(warnings, w)
+	}))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(warnings, gc.HasLen, 1)
+	c.Assert(warnings[0].Base, gc.Equals, "centos@7/stable")
+
+	_, err = charm.ReadCharm(path, charm.WithOSPolicy(charm.NewOSPolicy("ubuntu"), nil))
+	c.Assert(err, gc.ErrorMatches, `manifest: base "centos@7/stable": os "centos" \(expected one of ubuntu\) not valid`)
+}
+
+func mustParseBase(c *gc.C, s string) charm.Base {
+	b, err := charm.ParseBase(s)
+	c.Assert(err, jc.ErrorIsNil)
+	return b
+}
@@ -4,9 +4,14 @@
 package charm
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"github.com/juju/errors"
 )
 
 // The Bundle interface is implemented by any type that
@@ -39,3 +44,35 @@ func ReadBundle(path string) (Bundle, error) {
 func IsValidLocalCharmOrBundlePath(path string) bool {
 	return strings.HasPrefix(path, ".") || filepath.IsAbs(path)
 }
+
+// isLocalCharmOrBundleRef reports whether ref refers to a local charm or
+// bundle, either by a path valid for IsValidLocalCharmOrBundlePath or by
+// a file URL.
+func isLocalCharmOrBundleRef(ref string) bool {
+	return IsValidLocalCharmOrBundlePath(ref) || strings.HasPrefix(ref, "file:")
+}
+
+// LocalCharmOrBundlePath returns the filesystem path of the local charm
+// or bundle referred to by ref, which must either satisfy
+// IsValidLocalCharmOrBundlePath or be a file URL such as
+// "file:///srv/charms/mysql.charm". File URLs must hold an absolute path
+// and no host other than "localhost". Paths are returned unchanged.
+func LocalCharmOrBundlePath(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "file:") {
+		if !IsValidLocalCharmOrBundlePath(ref) {
+			return "", errors.NotValidf("local charm or bundle path %q", ref)
+		}
+		return ref, nil
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", errors.NewNotValid(err, fmt.Sprintf("file URL %q", ref))
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", errors.NotValidf("file URL %q with host %q", ref, u.Host)
+	}
+	if u.Opaque != "" || !path.IsAbs(u.Path) {
+		return "", errors.NotValidf("file URL %q without an absolute path", ref)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
@@ -4,6 +4,8 @@
 package charm_test
 
 import (
+	"path/filepath"
+
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -53,6 +55,46 @@ func (*BundleSuite) TestReadMultiDocBundleArchive(c *gc.C) {
 	checkWordpressBundle(c, b, path)
 }
 
+func (*BundleSuite) TestLocalCharmOrBundlePath(c *gc.C) {
+	for i, test := range []struct {
+		ref    string
+		expect string
+		err    string
+	}{{
+		ref:    "./builds/foo.charm",
+		expect: "./builds/foo.charm",
+	}, {
+		ref:    "/srv/charms/foo",
+		expect: "/srv/charms/foo",
+	}, {
+		ref:    "file:///srv/charms/foo.charm",
+		expect: "/srv/charms/foo.charm",
+	}, {
+		ref:    "file://localhost/srv/charms/foo.charm",
+		expect: "/srv/charms/foo.charm",
+	}, {
+		ref: "ch:foo",
+		err: `local charm or bundle path "ch:foo" not valid`,
+	}, {
+		ref: "file://host/foo.charm",
+		err: `file URL "file://host/foo.charm" with host "host" not valid`,
+	}, {
+		ref: "file:foo.charm",
+		err: `file URL "file:foo.charm" without an absolute path not valid`,
+	}} {
+		c.Logf("test %d: %s", i, test.ref)
+		path, err := charm.LocalCharmOrBundlePath(test.ref)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(path, gc.Equals, filepath.FromSlash(test.expect))
+	}
+	// File URLs are not paths that can be read directly.
+	c.Assert(charm.IsValidLocalCharmOrBundlePath("file:///srv/foo.charm"), jc.IsFalse)
+}
+
 func checkWordpressBundle(c *gc.C, b charm.Bundle, path string) {
 	// Load the charms required by the bundle.
 	wordpressCharm := readCharmDir(c, "wordpress")
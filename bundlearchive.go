@@ -5,10 +5,9 @@ package charm
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"io/ioutil"
-
-	ziputil "github.com/juju/utils/v3/zip"
 )
 
 type BundleArchive struct {
@@ -61,7 +60,7 @@ func readBundleArchive(zopen zipOpener) (*BundleArchive, error) {
 	if err != nil {
 		return nil, err
 	}
-	a.data, a.containsOverlays, err = readBaseFromMultidocBundle(reader)
+	a.data, a.containsOverlays, err = readBaseFromMultidocBundle("bundle.yaml", reader)
 	reader.Close()
 	if err != nil {
 		return nil, err
@@ -97,10 +96,30 @@ func (a *BundleArchive) ContainsOverlays() bool {
 // If any errors occur during the expansion procedure, the process will
 // abort.
 func (a *BundleArchive) ExpandTo(dir string) error {
+	return a.ExpandToContextWithOptions(context.Background(), dir)
+}
+
+// ExpandToContext is like ExpandTo, except that it stops and returns the
+// context's error if ctx is done before the bundle has been expanded.
+func (a *BundleArchive) ExpandToContext(ctx context.Context, dir string) error {
+	return a.ExpandToContextWithOptions(ctx, dir)
+}
+
+// ExpandToWithOptions is like ExpandTo, except that the expansion is
+// configured by the given options.
+func (a *BundleArchive) ExpandToWithOptions(dir string, options ...ExpandOption) error {
+	return a.ExpandToContextWithOptions(context.Background(), dir, options...)
+}
+
+// ExpandToContextWithOptions is like ExpandToContext, except that the
+// expansion is configured by the given options.
+func (a *BundleArchive) ExpandToContextWithOptions(ctx context.Context, dir string, options ...ExpandOption) error {
 	zipr, err := a.zopen.openZip()
 	if err != nil {
 		return err
 	}
 	defer zipr.Close()
-	return ziputil.ExtractAll(zipr.Reader, dir)
+	return expandArchive(ctx, zipr.Reader, dir, options, func(string) error {
+		return nil
+	})
 }
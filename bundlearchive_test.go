@@ -109,3 +109,18 @@ func (s *BundleArchiveSuite) TestExpandTo(c *gc.C) {
 	c.Assert(bdir.ReadMe(), gc.Equals, archive.ReadMe())
 	c.Assert(bdir.Data(), gc.DeepEquals, archive.Data())
 }
+
+func (s *BundleArchiveSuite) TestExpandToAtomic(c *gc.C) {
+	parent := c.MkDir()
+	dir := filepath.Join(parent, "bundle")
+	archive, err := charm.ReadBundleArchive(s.archivePath)
+	c.Assert(err, gc.IsNil)
+	err = archive.ExpandToWithOptions(dir, charm.AtomicExpand())
+	c.Assert(err, gc.IsNil)
+	bdir, err := charm.ReadBundleDir(dir)
+	c.Assert(err, gc.IsNil)
+	c.Assert(bdir.Data(), gc.DeepEquals, archive.Data())
+	entries, err := os.ReadDir(parent)
+	c.Assert(err, gc.IsNil)
+	c.Assert(entries, gc.HasLen, 1)
+}
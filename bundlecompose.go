@@ -0,0 +1,295 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names/v5"
+)
+
+// BundleInclude references another bundle whose applications, machines,
+// SAAS entries and relations are merged into the including bundle by
+// ComposeBundle, for example:
+//
+//	include:
+//	- bundle: ./fragments/database.yaml
+//	  prefix: db
+type BundleInclude struct {
+	// Bundle holds the reference to the included bundle, which is
+	// interpreted by the BundleResolver, such as a path or URL.
+	Bundle string `bson:"bundle" json:"bundle" yaml:"bundle"`
+
+	// Prefix holds the prefix given to the names of the applications
+	// and SAAS entries of the included bundle, separated from them by
+	// a hyphen. If it is empty, the names are used unchanged.
+	Prefix string `bson:"prefix,omitempty" json:"prefix,omitempty" yaml:"prefix,omitempty"`
+}
+
+// BundleResolver is implemented by types that can fetch the bundles
+// referenced by the include section of a bundle.
+type BundleResolver interface {
+	// ResolveBundle returns the bundle data referenced by ref, which
+	// is held by the bundle at the location from, or by the bundle
+	// being composed if from is empty. It also returns the location
+	// of the referenced bundle, which identifies it when detecting
+	// include cycles, and is passed as from to resolve the references
+	// the bundle holds in turn.
+	ResolveBundle(from, ref string) (bd *BundleData, location string, err error)
+}
+
+// LocalBundleResolver returns a BundleResolver that reads bundles from
+// the local file system. A reference is the path of a bundle file or
+// directory. If it is not absolute, it is relative to the directory of
+// the bundle holding it, or to baseDir for the bundle being composed.
+// The location of a bundle is the cleaned absolute path of its bundle
+// file.
+func LocalBundleResolver(baseDir string) BundleResolver {
+	return localBundleResolver{baseDir: baseDir}
+}
+
+type localBundleResolver struct {
+	baseDir string
+}
+
+// ResolveBundle implements BundleResolver.
+func (r localBundleResolver) ResolveBundle(from, ref string) (*BundleData, string, error) {
+	path := ref
+	if !filepath.IsAbs(path) {
+		baseDir := r.baseDir
+		if from != "" {
+			baseDir = filepath.Dir(from)
+		}
+		path = filepath.Join(baseDir, path)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	if info.IsDir() {
+		dir, err := ReadBundleDir(path)
+		if err != nil {
+			return nil, "", errors.Trace(err)
+		}
+		return dir.Data(), filepath.Join(path, "bundle.yaml"), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	defer f.Close()
+	bd, err := ReadBundleData(f, WithBundleSource(path))
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	return bd, path, nil
+}
+
+// maxIncludeDepth bounds how deeply bundle includes may be nested.
+const maxIncludeDepth = 10
+
+// ComposeBundle returns a copy of bd with the bundles it includes,
+// and those they include in turn, merged into it. The applications and
+// SAAS entries of an included bundle are renamed with the include's
+// prefix, and its machines are renumbered after those already in the
+// bundle; placements and relations are updated to match. Applications
+// that do not declare a base or series are given the default of the
+// bundle that declares them. It is an error for two bundles to define
+// an application or SAAS entry with the same name, or for a bundle to
+// include itself, as identified by the locations returned by resolver.
+func ComposeBundle(bd *BundleData, resolver BundleResolver) (*BundleData, error) {
+	return composeBundle(bd, resolver, nil)
+}
+
+// composeBundle composes the bundle bd, which was included through the
+// bundles at the given locations, the last of which holds bd.
+func composeBundle(bd *BundleData, resolver BundleResolver, locations []string) (*BundleData, error) {
+	if len(locations) > maxIncludeDepth {
+		return nil, errors.Errorf("bundle includes nested more than %d deep", maxIncludeDepth)
+	}
+	result := *bd
+	result.Includes = nil
+	result.Applications = make(map[string]*ApplicationSpec, len(bd.Applications))
+	for name, app := range bd.Applications {
+		result.Applications[name] = app
+	}
+	result.Machines = make(map[string]*MachineSpec, len(bd.Machines))
+	for id, m := range bd.Machines {
+		result.Machines[id] = m
+	}
+	result.Saas = make(map[string]*SaasSpec, len(bd.Saas))
+	for name, saas := range bd.Saas {
+		result.Saas[name] = saas
+	}
+	result.Relations = append([][]string(nil), bd.Relations...)
+	result.RelationSpecs = append([]RelationSpec(nil), bd.RelationSpecs...)
+
+	for _, inc := range bd.Includes {
+		if inc.Bundle == "" {
+			return nil, errors.NotValidf("bundle include without a bundle")
+		}
+		from := ""
+		if len(locations) > 0 {
+			from = locations[len(locations)-1]
+		}
+		included, location, err := resolver.ResolveBundle(from, inc.Bundle)
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot resolve included bundle %q", inc.Bundle)
+		}
+		for _, l := range locations {
+			if l == location {
+				return nil, errors.Errorf("bundle %q includes itself", inc.Bundle)
+			}
+		}
+		included, err = composeBundle(included, resolver, append(locations[:len(locations):len(locations)], location))
+		if err != nil {
+			return nil, errors.Annotatef(err, "included bundle %q", inc.Bundle)
+		}
+		if err := result.mergeIncluded(included, inc); err != nil {
+			return nil, errors.Annotatef(err, "included bundle %q", inc.Bundle)
+		}
+	}
+	if len(result.Applications) == 0 {
+		result.Applications = nil
+	}
+	if len(result.Machines) == 0 {
+		result.Machines = nil
+	}
+	if len(result.Saas) == 0 {
+		result.Saas = nil
+	}
+	return &result, nil
+}
+
+// mergeIncluded merges the already composed bundle included by inc
+// into bd.
+func (bd *BundleData) mergeIncluded(included *BundleData, inc BundleInclude) error {
+	// A bundle declaring itself a machine bundle may include bundles
+	// declaring no type, and vice versa.
+	if (included.Type == kubernetes) != (bd.Type == kubernetes) {
+		return errors.Errorf("bundle type %q does not match %q", included.Type, bd.Type)
+	}
+	rename := func(name string) string {
+		if inc.Prefix == "" {
+			return name
+		}
+		return inc.Prefix + "-" + name
+	}
+
+	// Renumber the included machines after those already present.
+	offset := 0
+	for id := range bd.Machines {
+		if n, err := strconv.Atoi(id); err == nil && n >= offset {
+			offset = n + 1
+		}
+	}
+	machineIds := make([]string, 0, len(included.Machines))
+	for id := range included.Machines {
+		machineIds = append(machineIds, id)
+	}
+	sort.Strings(machineIds)
+	renumber := func(id string) string {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return id
+		}
+		return strconv.Itoa(n + offset)
+	}
+	for _, id := range machineIds {
+		bd.Machines[renumber(id)] = included.Machines[id]
+	}
+
+	for name, saas := range included.Saas {
+		newName := rename(name)
+		if _, ok := bd.Saas[newName]; ok {
+			return errors.AlreadyExistsf("SAAS %q", newName)
+		}
+		bd.Saas[newName] = saas
+	}
+
+	for name, app := range included.Applications {
+		newName := rename(name)
+		if !names.IsValidApplication(newName) {
+			return errors.NotValidf("application name %q", newName)
+		}
+		if _, ok := bd.Applications[newName]; ok {
+			return errors.AlreadyExistsf("application %q", newName)
+		}
+		if app == nil {
+			bd.Applications[newName] = nil
+			continue
+		}
+		newApp := *app
+		if newApp.Base == "" && newApp.Series == "" {
+			newApp.Base, newApp.Series = included.DefaultBase, included.Series
+		}
+		newApp.To = make([]string, len(app.To))
+		for i, p := range app.To {
+			newApp.To[i] = renamePlacement(p, rename, renumber)
+		}
+		if len(newApp.To) == 0 {
+			newApp.To = nil
+		}
+		if app.Placement_ != "" {
+			newApp.Placement_ = renamePlacement(app.Placement_, rename, renumber)
+		}
+		bd.Applications[newName] = &newApp
+	}
+
+	renameEndpoint := func(ep string) string {
+		app, rel, hasRel := strings.Cut(ep, ":")
+		if hasRel {
+			return rename(app) + ":" + rel
+		}
+		return rename(app)
+	}
+	for _, rel := range included.Relations {
+		newRel := make([]string, len(rel))
+		for i, ep := range rel {
+			newRel[i] = renameEndpoint(ep)
+		}
+		bd.Relations = append(bd.Relations, newRel)
+	}
+	for _, spec := range included.RelationSpecs {
+		spec.Provider = renameEndpoint(spec.Provider)
+		spec.Requirer = renameEndpoint(spec.Requirer)
+		bd.RelationSpecs = append(bd.RelationSpecs, spec)
+	}
+	return nil
+}
+
+// renamePlacement returns the placement directive p with any application
+// renamed by rename and any machine renumbered by renumber. Directives
+// that cannot be parsed are returned unchanged for Verify to report.
+func renamePlacement(p string, rename, renumber func(string) string) string {
+	up, err := ParsePlacement(p)
+	if err != nil {
+		return p
+	}
+	var s string
+	switch {
+	case up.Application != "":
+		s = rename(up.Application)
+		if up.Unit != -1 {
+			s += "/" + strconv.Itoa(up.Unit)
+		}
+	case up.Machine == "new":
+		s = up.Machine
+	default:
+		s = renumber(up.Machine)
+	}
+	if up.ContainerType != "" {
+		s = up.ContainerType + ":" + s
+	}
+	return s
+}
@@ -0,0 +1,244 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/charm/v12"
+)
+
+type BundleComposeSuite struct{}
+
+var _ = gc.Suite(&BundleComposeSuite{})
+
+// mapBundleResolver resolves bundles from a map of YAML documents. The
+// references are their own locations.
+type mapBundleResolver map[string]string
+
+func (r mapBundleResolver) ResolveBundle(from, ref string) (*charm.BundleData, string, error) {
+	doc, ok := r[ref]
+	if !ok {
+		return nil, "", errors.NotFoundf("bundle %q", ref)
+	}
+	bd, err := charm.ReadBundleData(strings.NewReader(doc))
+	return bd, ref, err
+}
+
+func readBundle(c *gc.C, doc string) *charm.BundleData {
+	bd, err := charm.ReadBundleData(strings.NewReader(doc))
+	c.Assert(err, jc.ErrorIsNil)
+	return bd
+}
+
+const databaseBundle = `
+default-base: ubuntu@22.04
+applications:
+  mysql:
+    charm: ch:mysql
+    num_units: 2
+    to: ["0", "lxd:1"]
+  router:
+    charm: ch:mysql-router
+    base: ubuntu@20.04
+    num_units: 1
+    to: ["mysql/0"]
+machines:
+  "0":
+  "1":
+relations:
+- ["router:db", "mysql:db"]
+`
+
+func (s *BundleComposeSuite) TestComposeBundle(c *gc.C) {
+	bd := readBundle(c, `
+applications:
+  wordpress:
+    charm: ch:wordpress
+    num_units: 1
+    to: ["0"]
+machines:
+  "0":
+relations:
+- ["wordpress:db", "db-router"]
+include:
+- bundle: database
+  prefix: db
+`)
+	resolver := mapBundleResolver{"database": databaseBundle}
+	composed, err := charm.ComposeBundle(bd, resolver)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(composed.Includes, gc.IsNil)
+	c.Assert(composed.Applications, gc.HasLen, 3)
+	mysql := composed.Applications["db-mysql"]
+	c.Assert(mysql.Charm, gc.Equals, "ch:mysql")
+	c.Assert(mysql.To, jc.DeepEquals, []string{"1", "lxd:2"})
+	c.Assert(mysql.Base, gc.Equals, "ubuntu@22.04")
+	router := composed.Applications["db-router"]
+	c.Assert(router.To, jc.DeepEquals, []string{"db-mysql/0"})
+	c.Assert(router.Base, gc.Equals, "ubuntu@20.04")
+	c.Assert(composed.Machines, gc.HasLen, 3)
+	c.Assert(composed.Relations, jc.DeepEquals, [][]string{
+		{"wordpress:db", "db-router"},
+		{"db-router:db", "db-mysql:db"},
+	})
+
+	// The original bundle is unchanged.
+	c.Assert(bd.Applications, gc.HasLen, 1)
+	c.Assert(bd.Includes, gc.HasLen, 1)
+
+	err = composed.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *BundleComposeSuite) TestComposeNested(c *gc.C) {
+	bd := readBundle(c, `
+include:
+- bundle: outer
+  prefix: a
+`)
+	resolver := mapBundleResolver{
+		"outer": `
+include:
+- bundle: inner
+  prefix: b
+`,
+		"inner": `
+applications:
+  mysql:
+    charm: ch:mysql
+`,
+	}
+	composed, err := charm.ComposeBundle(bd, resolver)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(composed.Applications, gc.HasLen, 1)
+	c.Assert(composed.Applications["a-b-mysql"], gc.NotNil)
+}
+
+func (s *BundleComposeSuite) TestComposeCycle(c *gc.C) {
+	bd := readBundle(c, `
+include:
+- bundle: loop
+`)
+	resolver := mapBundleResolver{"loop": `
+include:
+- bundle: loop
+`}
+	_, err := charm.ComposeBundle(bd, resolver)
+	c.Assert(err, gc.ErrorMatches, `included bundle "loop": bundle "loop" includes itself`)
+}
+
+func (s *BundleComposeSuite) TestComposeConflict(c *gc.C) {
+	bd := readBundle(c, `
+applications:
+  mysql:
+    charm: ch:mysql
+include:
+- bundle: database
+`)
+	_, err := charm.ComposeBundle(bd, mapBundleResolver{"database": databaseBundle})
+	c.Assert(err, gc.ErrorMatches, `included bundle "database": application "mysql" already exists`)
+	c.Assert(errors.Is(err, errors.AlreadyExists), jc.IsTrue)
+}
+
+func (s *BundleComposeSuite) TestComposeTypeMismatch(c *gc.C) {
+	bd := readBundle(c, `
+bundle: kubernetes
+include:
+- bundle: database
+`)
+	_, err := charm.ComposeBundle(bd, mapBundleResolver{"database": databaseBundle})
+	c.Assert(err, gc.ErrorMatches, `included bundle "database": bundle type "" does not match "kubernetes"`)
+}
+
+func (s *BundleComposeSuite) TestComposeUnresolved(c *gc.C) {
+	bd := readBundle(c, `
+include:
+- bundle: missing
+`)
+	_, err := charm.ComposeBundle(bd, mapBundleResolver{})
+	c.Assert(err, gc.ErrorMatches, `cannot resolve included bundle "missing": bundle "missing" not found`)
+	c.Assert(errors.Is(err, errors.NotFound), jc.IsTrue)
+}
+
+func (s *BundleComposeSuite) TestIncludesRoundTrip(c *gc.C) {
+	bd := readBundle(c, `
+include:
+- bundle: ./database.yaml
+  prefix: db
+`)
+	c.Assert(bd.Includes, jc.DeepEquals, []charm.BundleInclude{{Bundle: "./database.yaml", Prefix: "db"}})
+	data, err := yaml.Marshal(bd)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), jc.Contains, "include:\n- bundle: ./database.yaml\n  prefix: db\n")
+}
+
+func (s *BundleComposeSuite) TestLocalBundleResolver(c *gc.C) {
+	dir := c.MkDir()
+	err := os.WriteFile(filepath.Join(dir, "database.yaml"), []byte(databaseBundle), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	bd := readBundle(c, `
+include:
+- bundle: database.yaml
+  prefix: db
+`)
+	composed, err := charm.ComposeBundle(bd, charm.LocalBundleResolver(dir))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(composed.Applications, gc.HasLen, 2)
+
+	_, _, err = charm.LocalBundleResolver(dir).ResolveBundle("", "missing.yaml")
+	c.Assert(errors.Cause(err), jc.Satisfies, os.IsNotExist)
+}
+
+func (s *BundleComposeSuite) TestLocalBundleResolverNested(c *gc.C) {
+	// Includes are relative to the bundle holding them.
+	dir := c.MkDir()
+	err := os.MkdirAll(filepath.Join(dir, "fragments", "db"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	for name, content := range map[string]string{
+		"fragments/outer.yaml":       "include:\n- bundle: db/database.yaml\n  prefix: db\n",
+		"fragments/db/database.yaml": databaseBundle,
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	bd := readBundle(c, `
+include:
+- bundle: fragments/outer.yaml
+`)
+	composed, err := charm.ComposeBundle(bd, charm.LocalBundleResolver(dir))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(composed.Applications["db-mysql"], gc.NotNil)
+
+	_, location, err := charm.LocalBundleResolver(dir).ResolveBundle(filepath.Join(dir, "fragments", "outer.yaml"), "./db/../db/database.yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(location, gc.Equals, filepath.Join(dir, "fragments", "db", "database.yaml"))
+}
+
+func (s *BundleComposeSuite) TestLocalBundleResolverCycle(c *gc.C) {
+	// References to the same file are recognised however they are
+	// written.
+	dir := c.MkDir()
+	for name, content := range map[string]string{
+		"a.yaml": "include:\n- bundle: ./b.yaml\n",
+		"b.yaml": "include:\n- bundle: a.yaml\n",
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	bd := readBundle(c, `
+include:
+- bundle: ./a.yaml
+`)
+	_, err := charm.ComposeBundle(bd, charm.LocalBundleResolver(dir))
+	c.Assert(err, gc.ErrorMatches, `included bundle "./a.yaml": included bundle "./b.yaml": bundle "a.yaml" includes itself`)
+}
@@ -4,29 +4,38 @@
 package charm
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 	"os"
 	"path/filepath"
-	"regexp"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/juju/collections/set"
 	"github.com/juju/errors"
 	"github.com/juju/mgo/v3/bson"
 	"github.com/juju/names/v5"
 	"github.com/juju/utils/v3/keyvalues"
 )
 
-const kubernetes = "kubernetes"
+// These are the types a bundle may declare. A bundle declaring no type
+// is a machine bundle.
+const (
+	kubernetes    = "kubernetes"
+	machineBundle = "machine"
+)
 
 // BundleData holds the contents of the bundle.
 type BundleData struct {
 	// Type is used to signify whether this bundle is for IAAS or Kubernetes deployments.
-	// Valid values are "Kubernetes" or "", with empty signifying an IAAS bundle.
+	// Valid values are "kubernetes", "machine" or "", with empty signifying an IAAS bundle.
 	Type string `bson:"bundle,omitempty" json:"bundle,omitempty" yaml:"bundle,omitempty"`
 
 	// Applications holds one entry for each application
@@ -34,12 +43,17 @@ type BundleData struct {
 	// the application name.
 	Applications map[string]*ApplicationSpec `bson:"applications,omitempty" json:"applications,omitempty" yaml:"applications,omitempty"`
 
+	// LegacyServices holds the applications of bundles written before
+	// the "services" key was renamed to "applications". Normalize moves
+	// these entries into Applications.
+	LegacyServices map[string]*ApplicationSpec `bson:"services,omitempty" json:"services,omitempty" yaml:"services,omitempty"`
+
 	// Machines holds one entry for each machine referred to
 	// by unit placements. These will be mapped onto actual
 	// machines at bundle deployment time.
 	// It is an error if a machine is specified but
 	// not referred to by a unit placement directive.
-	Machines map[string]*MachineSpec `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Machines map[string]*MachineSpec `bson:"machines,omitempty" json:"machines,omitempty" yaml:"machines,omitempty"`
 
 	// Saas holds one entry for each software as a service (SAAS) for cross
 	// model relation (CMR). These will be mapped to the consuming side when
@@ -50,7 +64,7 @@ type BundleData struct {
 	// the bundle deploys applications. A series defined for an application
 	// takes precedence.
 	// Series and Base cannot be mixed.
-	Series string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Series string `bson:"series,omitempty" json:"series,omitempty" yaml:"series,omitempty"`
 
 	// Base holds the default base to use when the bundle deploys
 	// applications. A base defined for an application takes precedence.
@@ -65,28 +79,72 @@ type BundleData struct {
 	// The relation is made between each. If the relation
 	// name is omitted, it will be inferred from the available
 	// relations defined in the applications' charms.
-	Relations [][]string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	//
+	// A relation may instead be given as a map naming its provider and
+	// requirer endpoints, which is held in RelationSpecs as well as
+	// here.
+	Relations [][]string `bson:"relations,omitempty" json:"relations,omitempty" yaml:"relations,omitempty"`
+
+	// RelationSpecs holds the relations declared in map form, which can
+	// say which space a relation is made via. The endpoints of each are
+	// also held in Relations, so code needing only the endpoints can
+	// ignore this field. When the bundle is marshalled, a relation is
+	// written in map form only if it has a matching spec with a space.
+	RelationSpecs []RelationSpec `bson:"-" json:"-" yaml:"-"`
+
+	// Includes holds references to other bundles to be merged into this
+	// one. They are resolved by ComposeBundle.
+	Includes []BundleInclude `bson:"include,omitempty" json:"include,omitempty" yaml:"include,omitempty"`
 
 	// White listed set of tags to categorize bundles as we do charms.
-	Tags []string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Tags []string `bson:"tags,omitempty" json:"tags,omitempty" yaml:"tags,omitempty"`
 
 	// Short paragraph explaining what the bundle is useful for.
-	Description string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Description string `bson:"description,omitempty" json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// RelationSpec represents a relation declared in map form in a bundle's
+// relations, for example:
+//
+//	relations:
+//	- provider: mysql:db
+//	  requirer: wordpress:db
+//	  via: db-space
+type RelationSpec struct {
+	// Provider holds the providing endpoint, specified as either
+	// an application name or an (application, relation) pair.
+	Provider string `bson:"provider" json:"provider" yaml:"provider"`
+
+	// Requirer holds the requiring endpoint, specified in the same
+	// way as Provider.
+	Requirer string `bson:"requirer" json:"requirer" yaml:"requirer"`
+
+	// Via holds the name of the space the relation is made via, if
+	// any.
+	Via string `bson:"via,omitempty" json:"via,omitempty" yaml:"via,omitempty"`
 }
 
 // SaasSpec represents a single software as a service (SAAS) node.
 // This will be mapped to consuming of offers from a bundle deployment.
 type SaasSpec struct {
-	URL string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	URL string `bson:"url,omitempty" json:"url,omitempty" yaml:"url,omitempty"`
 }
 
 // MachineSpec represents a notional machine that will be mapped
 // onto an actual machine at bundle deployment time.
 type MachineSpec struct {
-	Constraints string            `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
-	Annotations map[string]string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
-	Series      string            `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
-	Base        string            `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Constraints string      `bson:"constraints,omitempty" json:"constraints,omitempty" yaml:"constraints,omitempty"`
+	Annotations Annotations `bson:"annotations,omitempty" json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Series      string      `bson:"series,omitempty" json:"series,omitempty" yaml:"series,omitempty"`
+	Base        string      `bson:"base,omitempty" json:"base,omitempty" yaml:"base,omitempty"`
+
+	// Zones optionally holds the availability zones in which the
+	// machine may be provisioned, in order of preference.
+	Zones []string `bson:"zones,omitempty" json:"zones,omitempty" yaml:"zones,omitempty"`
+
+	// Volumes optionally describes the disks the machine should be
+	// provisioned with.
+	Volumes *MachineVolumes `bson:"volumes,omitempty" json:"volumes,omitempty" yaml:"volumes,omitempty"`
 }
 
 // ApplicationSpec represents a single application that will
@@ -94,7 +152,7 @@ type MachineSpec struct {
 type ApplicationSpec struct {
 	// Charm holds the charm URL of the charm to
 	// use for the given application.
-	Charm string `bson:",omitempty" yaml:",omitempty" json:",omitempty"`
+	Charm string `bson:"charm,omitempty" yaml:"charm,omitempty" json:"charm,omitempty"`
 
 	// Channel describes the preferred channel to use when deploying a
 	// remote charm.
@@ -105,18 +163,18 @@ type ApplicationSpec struct {
 
 	// Series is the series to use when deploying the application.
 	// Series and Base cannot be mixed.
-	Series string `bson:",omitempty" yaml:",omitempty" json:",omitempty"`
+	Series string `bson:"series,omitempty" yaml:"series,omitempty" json:"series,omitempty"`
 
 	// Base is the base to use when deploying the application.
 	// Series and Base cannot be mixed.
-	Base string `bson:",omitempty" yaml:",omitempty" json:",omitempty"`
+	Base string `bson:"base,omitempty" yaml:"base,omitempty" json:"base,omitempty"`
 
 	// Resources is the set of resource revisions to deploy for the
 	// application. Bundles only support charm store resources and not ones
 	// that were uploaded to the controller.
 	// A resource value can either be an integer revision number,
 	// or a string holding a path to a local resource file.
-	Resources map[string]interface{} `bson:",omitempty" yaml:",omitempty" json:",omitempty"`
+	Resources map[string]interface{} `bson:"resources,omitempty" yaml:"resources,omitempty" json:"resources,omitempty"`
 
 	// NumUnits holds the number of units of the
 	// application that will be deployed.
@@ -125,10 +183,11 @@ type ApplicationSpec struct {
 	// For a subordinate application, this actually represents
 	// an arbitrary number of units depending on
 	// the application it is related to.
-	NumUnits int `bson:",omitempty" yaml:"num_units,omitempty" json:",omitempty"`
+	NumUnits int `bson:"numunits,omitempty" yaml:"num_units,omitempty" json:"num_units,omitempty"`
 
 	// Scale_ holds the number of pods required for the application.
-	// For IAAS bundles, this will be an alias for NumUnits.
+	// For bundles that do not declare their type, this will be an
+	// alias for NumUnits. It is not valid for machine bundles.
 	Scale_ int `bson:"scale,omitempty" yaml:"scale,omitempty" json:"scale,omitempty"`
 
 	// To is interpreted according to whether this is an
@@ -172,18 +231,20 @@ type ApplicationSpec struct {
 	//
 	// For example:
 	//
-	//     wordpress/0 wordpress/1 lxc:0 kvm:new
+	//     wordpress/0 wordpress/1 lxd:0 kvm:new
 	//
 	//  specifies that the first two units get hulk-smashed
 	//  onto the first two units of the wordpress application,
-	//  the third unit gets allocated onto an lxc container
+	//  the third unit gets allocated onto an lxd container
 	//  on machine 0, and subsequent units get allocated
 	//  on kvm containers on new machines.
 	//
 	// The above example is the same as this:
 	//
-	//     wordpress wordpress lxc:0 kvm:new
-	To []string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	//     wordpress wordpress lxd:0 kvm:new
+	//
+	// The legacy container type "lxc" is accepted as "lxd".
+	To []string `bson:"to,omitempty" json:"to,omitempty" yaml:"to,omitempty"`
 
 	// Placement_ holds a model selector/affinity expression used to specify
 	// pod placement for Kubernetes applications.
@@ -191,7 +252,7 @@ type ApplicationSpec struct {
 	Placement_ string `bson:"placement,omitempty" json:"placement,omitempty" yaml:"placement,omitempty"`
 
 	// Expose holds whether the application must be exposed.
-	Expose bool `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Expose bool `bson:"expose,omitempty" json:"expose,omitempty" yaml:"expose,omitempty"`
 
 	// ExposedEndpoints defines on a per-endpoint basis, the list of space
 	// names and/or CIDRs that should be able to access the ports opened
@@ -201,29 +262,32 @@ type ApplicationSpec struct {
 	//
 	// This attribute cannot be used in tandem with the 'expose: true'
 	// flag; a validation error will be raised if both fields are specified.
+	// BundleData.NormalizeExpose resolves the conflict by dropping the
+	// flag, which narrows the exposure of the application to the
+	// endpoints listed here.
 	ExposedEndpoints map[string]ExposedEndpointSpec `bson:"exposed-endpoints,omitempty" json:"exposed-endpoints,omitempty" yaml:"exposed-endpoints,omitempty" source:"overlay-only"`
 
 	// Options holds the configuration values
 	// to apply to the new application. They should
 	// be compatible with the charm configuration.
-	Options map[string]interface{} `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Options map[string]interface{} `bson:"options,omitempty" json:"options,omitempty" yaml:"options,omitempty"`
 
 	// Annotations holds any annotations to apply to the
 	// application when deployed.
-	Annotations map[string]string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Annotations Annotations `bson:"annotations,omitempty" json:"annotations,omitempty" yaml:"annotations,omitempty"`
 
 	// Constraints holds the default constraints to apply
 	// when creating new machines for units of the application.
 	// This is ignored for units with explicit placement directives.
-	Constraints string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Constraints string `bson:"constraints,omitempty" json:"constraints,omitempty" yaml:"constraints,omitempty"`
 
 	// Storage holds the constraints for storage to assign
 	// to units of the application.
-	Storage map[string]string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Storage map[string]string `bson:"storage,omitempty" json:"storage,omitempty" yaml:"storage,omitempty"`
 
 	// Devices holds the constraints for devices to assign
 	// to units of the application.
-	Devices map[string]string `bson:",omitempty" json:",omitempty" yaml:",omitempty"`
+	Devices map[string]string `bson:"devices,omitempty" json:"devices,omitempty" yaml:"devices,omitempty"`
 
 	// EndpointBindings maps how endpoints are bound to spaces
 	EndpointBindings map[string]string `bson:"bindings,omitempty" json:"bindings,omitempty" yaml:"bindings,omitempty"`
@@ -242,21 +306,111 @@ type ApplicationSpec struct {
 	RequiresTrust bool `bson:"trust,omitempty" json:"trust,omitempty" yaml:"trust,omitempty"`
 }
 
-// maskedBundleData and bundleData are here to perform a way to normalize the
-// bundle data when unmarshalling via a codec.
-// By abusing the types we can prevent a recursive function call so that the
-// unmarshalling doesn't call itself.
-//
-// In reality this is so wrong in so many ways:
-//  1. Why has the model type got anything to do with how it's transferred over
-//     the wire to other consumables? The bundle data should have a package of
-//     wire protocols (DTOs) that can packed and unpacked into a bundle/charm
-//     model. That model should be pure!
-//  2. This should be a two step process, unmarshal and then normalize.
-type maskedBundleData BundleData
-
+// bundleData is the form in which bundle data is transferred by the
+// codecs. It differs from BundleData only in its relations, each of
+// which may be either a list of endpoints or a map. Having a separate
+// type also prevents the codec methods of BundleData from calling
+// themselves.
 type bundleData struct {
-	maskedBundleData `bson:",inline" yaml:",inline" json:",inline"`
+	Type           string                      `bson:"bundle,omitempty" json:"bundle,omitempty" yaml:"bundle,omitempty"`
+	Applications   map[string]*ApplicationSpec `bson:"applications,omitempty" json:"applications,omitempty" yaml:"applications,omitempty"`
+	LegacyServices map[string]*ApplicationSpec `bson:"services,omitempty" json:"services,omitempty" yaml:"services,omitempty"`
+	Machines       map[string]*MachineSpec     `bson:"machines,omitempty" json:"machines,omitempty" yaml:"machines,omitempty"`
+	Saas           map[string]*SaasSpec        `bson:"saas,omitempty" json:"saas,omitempty" yaml:"saas,omitempty"`
+	Series         string                      `bson:"series,omitempty" json:"series,omitempty" yaml:"series,omitempty"`
+	DefaultBase    string                      `bson:"default-base,omitempty" json:"default-base,omitempty" yaml:"default-base,omitempty"`
+	Relations      []relationEntry             `bson:"relations,omitempty" json:"relations,omitempty" yaml:"relations,omitempty"`
+	Includes       []BundleInclude             `bson:"include,omitempty" json:"include,omitempty" yaml:"include,omitempty"`
+	Tags           []string                    `bson:"tags,omitempty" json:"tags,omitempty" yaml:"tags,omitempty"`
+	Description    string                      `bson:"description,omitempty" json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// bundleData returns the bundle data held in in.
+func (in *bundleData) bundleData() BundleData {
+	bd := BundleData{
+		Type:           in.Type,
+		Applications:   in.Applications,
+		LegacyServices: in.LegacyServices,
+		Machines:       in.Machines,
+		Saas:           in.Saas,
+		Series:         in.Series,
+		DefaultBase:    in.DefaultBase,
+		Includes:       in.Includes,
+		Tags:           in.Tags,
+		Description:    in.Description,
+	}
+	for _, rel := range in.Relations {
+		if rel.spec == nil {
+			bd.Relations = append(bd.Relations, rel.endpoints)
+			continue
+		}
+		bd.Relations = append(bd.Relations, []string{rel.spec.Provider, rel.spec.Requirer})
+		bd.RelationSpecs = append(bd.RelationSpecs, *rel.spec)
+	}
+	return bd
+}
+
+// relationEntry holds one entry of a bundle's relations, which is a
+// list of endpoints unless spec is set.
+type relationEntry struct {
+	endpoints []string
+	spec      *RelationSpec
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (rel *relationEntry) UnmarshalYAML(f func(interface{}) error) error {
+	var raw interface{}
+	if err := f(&raw); err != nil {
+		return err
+	}
+	if _, ok := raw.(map[interface{}]interface{}); ok {
+		rel.spec = &RelationSpec{}
+		return f(rel.spec)
+	}
+	return f(&rel.endpoints)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (rel relationEntry) MarshalYAML() (interface{}, error) {
+	if rel.spec != nil {
+		return rel.spec, nil
+	}
+	return rel.endpoints, nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (rel *relationEntry) UnmarshalJSON(b []byte) error {
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+		rel.spec = &RelationSpec{}
+		return json.Unmarshal(b, rel.spec)
+	}
+	return json.Unmarshal(b, &rel.endpoints)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (rel relationEntry) MarshalJSON() ([]byte, error) {
+	if rel.spec != nil {
+		return json.Marshal(rel.spec)
+	}
+	return json.Marshal(rel.endpoints)
+}
+
+// SetBSON implements the bson.Setter interface.
+func (rel *relationEntry) SetBSON(raw bson.Raw) error {
+	if raw.Kind == 0x03 {
+		// An embedded document.
+		rel.spec = &RelationSpec{}
+		return raw.Unmarshal(rel.spec)
+	}
+	return raw.Unmarshal(&rel.endpoints)
+}
+
+// GetBSON implements the bson.Getter interface.
+func (rel relationEntry) GetBSON() (interface{}, error) {
+	if rel.spec != nil {
+		return rel.spec, nil
+	}
+	return rel.endpoints, nil
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
@@ -265,7 +419,7 @@ func (bd *BundleData) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &in); err != nil {
 		return err
 	}
-	*bd = BundleData(in.maskedBundleData)
+	*bd = in.bundleData()
 	return bd.normalizeData()
 }
 
@@ -275,14 +429,12 @@ func (bd *BundleData) UnmarshalYAML(f func(interface{}) error) error {
 	if err := f(&in); err != nil {
 		return err
 	}
-	*bd = BundleData(in.maskedBundleData)
+	*bd = in.bundleData()
 	return bd.normalizeData()
 }
 
 // SetBSON implements the bson.Setter interface.
 func (bd *BundleData) SetBSON(raw bson.Raw) error {
-	// TODO(wallyworld) - bson deserialisation is not handling the inline directive,
-	// so we need to unmarshal the bundle data manually.
 	var in *bundleData
 	if err := raw.Unmarshal(&in); err != nil {
 		return err
@@ -290,10 +442,142 @@ func (bd *BundleData) SetBSON(raw bson.Raw) error {
 	if in == nil {
 		return bson.SetZero
 	}
-	*bd = BundleData(in.maskedBundleData)
+	*bd = in.bundleData()
 	return bd.normalizeData()
 }
 
+// MarshalJSON implements the json.Marshaler interface. Any legacy
+// services are written as applications.
+func (bd BundleData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bd.marshaled())
+}
+
+// MarshalYAML implements the yaml.Marshaler interface. Any legacy
+// services are written as applications.
+func (bd BundleData) MarshalYAML() (interface{}, error) {
+	return bd.marshaled(), nil
+}
+
+// GetBSON implements the bson.Getter interface. Any legacy services
+// are written as applications.
+func (bd *BundleData) GetBSON() (interface{}, error) {
+	if bd == nil {
+		return nil, nil
+	}
+	return bd.marshaled(), nil
+}
+
+// marshaled returns the bundle data to marshal, with any legacy
+// services merged into the applications so that only the
+// "applications" key is written. Applications take precedence over
+// services with the same name.
+func (bd BundleData) marshaled() *bundleData {
+	out := bundleData{
+		Type:           bd.Type,
+		Applications:   bd.Applications,
+		LegacyServices: bd.LegacyServices,
+		Machines:       bd.Machines,
+		Saas:           bd.Saas,
+		Series:         bd.Series,
+		DefaultBase:    bd.DefaultBase,
+		Relations:      marshaledBundleRelations(bd.Relations, bd.RelationSpecs),
+		Includes:       bd.Includes,
+		Tags:           bd.Tags,
+		Description:    bd.Description,
+	}
+	if len(out.LegacyServices) > 0 {
+		apps := make(map[string]*ApplicationSpec, len(out.Applications)+len(out.LegacyServices))
+		for name, app := range out.LegacyServices {
+			apps[name] = app
+		}
+		for name, app := range out.Applications {
+			apps[name] = app
+		}
+		out.Applications = apps
+		out.LegacyServices = nil
+	}
+	return &out
+}
+
+// marshaledBundleRelations returns the relations to marshal. Relations
+// with a spec that names a space are written in map form, so the space
+// is kept; all others are written as lists for compatibility with older
+// readers.
+func marshaledBundleRelations(relations [][]string, specs []RelationSpec) []relationEntry {
+	if relations == nil {
+		return nil
+	}
+	out := make([]relationEntry, len(relations))
+	for i, rel := range relations {
+		out[i].endpoints = rel
+		if len(rel) != 2 {
+			continue
+		}
+		for _, spec := range specs {
+			if spec.Via != "" && spec.Provider == rel[0] && spec.Requirer == rel[1] {
+				spec := spec
+				out[i].spec = &spec
+				break
+			}
+		}
+	}
+	return out
+}
+
+// ExpandedPlacements returns the placement of each unit of an IAAS
+// application, parsed from To according to the rules described there:
+// the last directive is replicated until there is one for each of
+// NumUnits units, "new" is used if To is empty, and directives naming
+// only an application are given the unit number following the previous
+// one for that application. If To holds more directives than NumUnits,
+// a placement is returned for each directive.
+func (spec *ApplicationSpec) ExpandedPlacements() ([]UnitPlacement, error) {
+	to := spec.To
+	if len(to) == 0 {
+		to = []string{"new"}
+	}
+	n := spec.NumUnits
+	if len(spec.To) > n {
+		n = len(spec.To)
+	}
+	placements := make([]UnitPlacement, 0, n)
+	nextUnit := make(map[string]int)
+	for i := 0; i < n; i++ {
+		p := to[min(i, len(to)-1)]
+		up, err := ParsePlacement(p)
+		if err != nil {
+			return nil, err
+		}
+		if up.Application != "" {
+			if up.Unit == -1 {
+				up.Unit = nextUnit[up.Application]
+			}
+			nextUnit[up.Application] = up.Unit + 1
+		}
+		placements = append(placements, *up)
+	}
+	return placements, nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It also
+// accepts the number of units under the "NumUnits" key written by
+// earlier versions.
+func (spec *ApplicationSpec) UnmarshalJSON(b []byte) error {
+	type plainApplicationSpec ApplicationSpec
+	var in struct {
+		plainApplicationSpec
+		LegacyNumUnits *int `json:"NumUnits"`
+	}
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	*spec = ApplicationSpec(in.plainApplicationSpec)
+	if in.LegacyNumUnits != nil && spec.NumUnits == 0 {
+		spec.NumUnits = *in.LegacyNumUnits
+	}
+	return nil
+}
+
 func (bd *BundleData) normalizeData() error {
 	if bd.Applications == nil {
 		return nil
@@ -303,11 +587,17 @@ func (bd *BundleData) normalizeData() error {
 		if app == nil {
 			continue
 		}
-		// Kubernetes bundles use "scale" instead of "num_units".
-		if app.Scale_ > 0 && app.NumUnits > 0 {
+		// Kubernetes bundles use "scale" instead of "num_units". A
+		// negative scale is carried over so that verification reports
+		// it. Bundles declaring themselves machine bundles must use
+		// "num_units".
+		if app.Scale_ != 0 && app.NumUnits != 0 {
 			return fmt.Errorf("cannot specify both scale and num_units for application %q", appName)
 		}
-		if app.Scale_ > 0 && app.NumUnits == 0 {
+		if app.Scale_ != 0 && bd.Type == machineBundle {
+			return fmt.Errorf("scale not valid for machine application %q, use num_units", appName)
+		}
+		if app.Scale_ != 0 {
 			app.NumUnits = app.Scale_
 			app.Scale_ = 0
 		}
@@ -327,6 +617,92 @@ func (bd *BundleData) normalizeData() error {
 	return nil
 }
 
+// Normalize rewrites legacy bundle constructs into their canonical form.
+// It performs the following conversions:
+//
+//   - Applications declared under the legacy "services" key are moved to
+//     "applications".
+//   - Relations listing more than two endpoints are expanded into one
+//     relation between the first endpoint and each of the others.
+//   - For machine bundles, num_units is raised to the number of unit
+//     placement directives when it is smaller.
+//   - Series declared for the bundle, its applications and its machines
+//     are replaced by the equivalent base, unless a base is already set.
+//     Series without a known base equivalent are left untouched.
+//
+// Normalize also applies the scale and placement normalisation performed
+// when bundle data is unmarshalled. It is safe to call more than once.
+func (bd *BundleData) Normalize() error {
+	for name, app := range bd.LegacyServices {
+		if _, ok := bd.Applications[name]; ok {
+			return errors.Errorf("application %q defined in both applications and services", name)
+		}
+		if bd.Applications == nil {
+			bd.Applications = make(map[string]*ApplicationSpec)
+		}
+		bd.Applications[name] = app
+	}
+	bd.LegacyServices = nil
+
+	if err := bd.normalizeData(); err != nil {
+		return errors.Trace(err)
+	}
+	bd.Relations = expandRelations(bd.Relations)
+
+	if bd.DefaultBase == "" {
+		bd.DefaultBase, bd.Series = normalizeSeries(bd.Series)
+	}
+	for _, app := range bd.Applications {
+		if app == nil {
+			continue
+		}
+		if bd.Type != kubernetes && app.NumUnits < len(app.To) {
+			app.NumUnits = len(app.To)
+		}
+		if app.Base == "" {
+			app.Base, app.Series = normalizeSeries(app.Series)
+		}
+	}
+	for _, m := range bd.Machines {
+		if m != nil && m.Base == "" {
+			m.Base, m.Series = normalizeSeries(m.Series)
+		}
+	}
+	return nil
+}
+
+// expandRelations returns the relations with every relation that lists
+// more than two endpoints replaced by the pairs it implies. Relations with
+// fewer than two endpoints are retained so that verification reports them.
+func expandRelations(relations [][]string) [][]string {
+	var expanded [][]string
+	for _, rel := range relations {
+		if len(rel) <= 2 {
+			expanded = append(expanded, rel)
+			continue
+		}
+		for _, other := range rel[1:] {
+			expanded = append(expanded, []string{rel[0], other})
+		}
+	}
+	return expanded
+}
+
+// normalizeSeries returns the base equivalent to the given series, along
+// with the series to retain. The series is retained, and the base empty,
+// if the series has no known base equivalent.
+func normalizeSeries(series string) (string, string) {
+	if series == "" {
+		return "", ""
+	}
+	base, err := baseFromSeries(series)
+	if err != nil {
+		return "", series
+	}
+	// Series map onto stable channels, so only the track is written.
+	return base.Name + "@" + base.Channel.Track, ""
+}
+
 // ExposedEndpointSpec describes the expose parameters for an application
 // endpoint.
 type ExposedEndpointSpec struct {
@@ -347,20 +723,126 @@ type OfferSpec struct {
 	Endpoints []string `bson:"endpoints" json:"endpoints" yaml:"endpoints" source:"overlay-only"`
 
 	// The access control list for this offer. The keys are users and the
-	// values are access permissions.
+	// values are access permissions. In bundles the list may also be
+	// given as access permissions mapped to lists of users.
 	ACL map[string]string `bson:"acl,omitempty" json:"acl,omitempty" yaml:"acl,omitempty" source:"overlay-only"`
 }
 
+// ReadBundleDataOption configures the behaviour of ReadBundleData.
+type ReadBundleDataOption func(*readBundleDataOptions)
+
+type readBundleDataOptions struct {
+	strictApplications bool
+	source             string
+}
+
+// WithBundleSource gives the name of the source of the bundle data, such
+// as the path of the bundle.yaml file, which is recorded in the
+// BundleDocument of each part and in the *YAMLPositionError returned for
+// a problem that can be located.
+func WithBundleSource(name string) ReadBundleDataOption {
+	return func(opts *readBundleDataOptions) {
+		opts.source = name
+	}
+}
+
+// WithStrictApplications makes ReadBundleData return an error if an
+// application holds a field that is not recognised, rather than silently
+// ignoring it. Where a known field has a similar name, such as "options"
+// for "optons", the error suggests it.
+func WithStrictApplications() ReadBundleDataOption {
+	return func(opts *readBundleDataOptions) {
+		opts.strictApplications = true
+	}
+}
+
 // ReadBundleData reads bundle data from the given reader.
 // The returned data is not verified - call Verify to ensure
 // that it is OK.
-func ReadBundleData(r io.Reader) (*BundleData, error) {
-	bd, _, err := readBaseFromMultidocBundle(r)
+func ReadBundleData(r io.Reader, options ...ReadBundleDataOption) (*BundleData, error) {
+	var opts readBundleDataOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	parts, err := parseBundleParts(opts.source, r)
 	if err != nil {
 		return nil, err
 	}
+	if len(parts) == 0 {
+		return nil, errors.NotValidf("empty bundle")
+	}
+	if opts.strictApplications {
+		if err := checkApplicationFields(parts[0]); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return parts[0].Data, nil
+}
 
-	return bd, nil
+// applicationFields returns the names of the fields an application may
+// hold in bundle YAML.
+var applicationFields = sync.OnceValue(func() []string {
+	var fields []string
+	t := reflect.TypeOf(ApplicationSpec{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+})
+
+// checkApplicationFields returns an error if any application in the
+// given bundle document holds a field that is not recognised. The
+// error is located at the field when the document's position is known.
+func checkApplicationFields(part *BundleDataPart) error {
+	for _, section := range []string{"applications", "services"} {
+		apps := part.PresenceMap.forField(section)
+		names := make([]string, 0, len(apps))
+		for name := range apps {
+			names = append(names, fmt.Sprint(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			app := apps.forField(name)
+			fields := make([]string, 0, len(app))
+			for field := range app {
+				fields = append(fields, fmt.Sprint(field))
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				if err := checkApplicationField(name, field); err != nil {
+					if part.Document != nil {
+						return part.Document.fieldError(section+"."+name+"."+field, err)
+					}
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkApplicationField returns an error if field is not a known
+// application field, suggesting the closest known field if there is
+// one within two edits.
+func checkApplicationField(app, field string) error {
+	known := applicationFields()
+	suggestion, best := "", 3
+	for _, candidate := range known {
+		if candidate == field {
+			return nil
+		}
+		if d := levenshtein(field, candidate); d < best {
+			suggestion, best = candidate, d
+		}
+	}
+	if suggestion == "" {
+		return errors.NotValidf("application %q field %q", app, field)
+	}
+	return errors.NewNotValid(nil, fmt.Sprintf("application %q field %q not valid; did you mean %q?", app, field, suggestion))
 }
 
 // readBaseFromMultidocBundle reads the bundle data corresponding to the first
@@ -369,9 +851,10 @@ func ReadBundleData(r io.Reader) (*BundleData, error) {
 // ignored.
 //
 // Clients that are interested in reading multi-doc bundle data should use the
-// new helpers: LocalBundleDataSource and StreamBundleDataSource.
-func readBaseFromMultidocBundle(r io.Reader) (*BundleData, bool, error) {
-	parts, err := parseBundleParts(r)
+// new helpers: LocalBundleDataSource and StreamBundleDataSource. The
+// source names the file the bundle is read from.
+func readBaseFromMultidocBundle(source string, r io.Reader) (*BundleData, bool, error) {
+	parts, err := parseBundleParts(source, r)
 	if err != nil {
 		return nil, false, err
 	}
@@ -385,10 +868,18 @@ func readBaseFromMultidocBundle(r io.Reader) (*BundleData, bool, error) {
 
 // VerificationError holds an error generated by BundleData.Verify,
 // holding all the verification errors found when verifying.
+// Errors found by bundle verification are *CodedError values, so
+// errors.Is and errors.As can be applied to a VerificationError to
+// look for a particular code or error type.
 type VerificationError struct {
 	Errors []error
 }
 
+// Unwrap returns the errors held by err.
+func (err *VerificationError) Unwrap() []error {
+	return err.Errors
+}
+
 func (err *VerificationError) Error() string {
 	switch len(err.Errors) {
 	case 0:
@@ -404,24 +895,34 @@ type bundleDataVerifier struct {
 	bundleDir string
 	bd        *BundleData
 
+	// checkResources holds whether resource revisions and local
+	// resource paths are checked.
+	checkResources bool
+
 	// machines holds the reference counts of all machines
 	// as referred to by placement directives.
 	machineRefCounts map[string]int
 
 	charms map[string]Charm
 
+	// appConstraints and machineConstraints hold the constraints of
+	// the applications and machines, keyed by name and id, as parsed
+	// by the registered constraints parser.
+	appConstraints     map[string]Constraints
+	machineConstraints map[string]Constraints
+
 	errors            []error
 	verifyConstraints func(c string) error
 	verifyStorage     func(s string) error
 	verifyDevices     func(s string) error
 }
 
-func (verifier *bundleDataVerifier) addErrorf(f string, a ...interface{}) {
-	verifier.addError(fmt.Errorf(f, a...))
+func (verifier *bundleDataVerifier) addErrorf(code VerificationErrorCode, f string, a ...interface{}) {
+	verifier.addError(code, fmt.Errorf(f, a...))
 }
 
-func (verifier *bundleDataVerifier) addError(err error) {
-	verifier.errors = append(verifier.errors, err)
+func (verifier *bundleDataVerifier) addError(code VerificationErrorCode, err error) {
+	verifier.errors = append(verifier.errors, &CodedError{Code: code, Err: err})
 }
 
 func (verifier *bundleDataVerifier) err() error {
@@ -442,6 +943,103 @@ func (bd *BundleData) RequiredCharms() []string {
 	return req
 }
 
+// RequiredBases returns a sorted slice of all the distinct bases
+// required by the bundle's applications and machines. Applications
+// without a base of their own use the bundle's default base. Series
+// are converted to their equivalent base where possible.
+func (bd *BundleData) RequiredBases() []string {
+	bases := set.NewStrings()
+	defaultBase := requiredBase(bd.DefaultBase, bd.Series)
+	for _, app := range bd.Applications {
+		if app == nil {
+			continue
+		}
+		if base := requiredBase(app.Base, app.Series); base != "" {
+			bases.Add(base)
+		} else if defaultBase != "" {
+			bases.Add(defaultBase)
+		}
+	}
+	for _, m := range bd.Machines {
+		if m == nil {
+			continue
+		}
+		if base := requiredBase(m.Base, m.Series); base != "" {
+			bases.Add(base)
+		}
+	}
+	return bases.SortedValues()
+}
+
+// EffectiveBase returns the base that the named application's units
+// will run on. The application's own base or series takes precedence,
+// then the base or series of a machine declared in the bundle that the
+// application is placed directly onto, then the bundle's default base
+// or series. It reports false if the application does not exist or no
+// valid base can be determined.
+func (bd *BundleData) EffectiveBase(app string) (Base, bool) {
+	spec := bd.Applications[app]
+	if spec == nil {
+		return Base{}, false
+	}
+	candidates := []string{requiredBase(spec.Base, spec.Series)}
+	for _, p := range spec.To {
+		up, err := ParsePlacement(p)
+		if err != nil || up.Machine == "" || up.Machine == "new" || up.ContainerType != "" {
+			continue
+		}
+		if m := bd.Machines[up.Machine]; m != nil {
+			candidates = append(candidates, requiredBase(m.Base, m.Series))
+		}
+	}
+	candidates = append(candidates, requiredBase(bd.DefaultBase, bd.Series))
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		base, err := ParseBase(candidate)
+		if err != nil {
+			return Base{}, false
+		}
+		return base, true
+	}
+	return Base{}, false
+}
+
+// requiredBase returns base, or the base equivalent to series if base
+// is empty.
+func requiredBase(base, series string) string {
+	if base != "" {
+		return base
+	}
+	base, _ = normalizeSeries(series)
+	return base
+}
+
+// RequiredResources returns the names of the resources the bundle
+// specifies for each of its charms, keyed by the charm URL. The names
+// for each charm are sorted and unique, even when several applications
+// use the same charm.
+func (bd *BundleData) RequiredResources() map[string][]string {
+	names := make(map[string]set.Strings)
+	for _, app := range bd.Applications {
+		if app == nil || len(app.Resources) == 0 {
+			continue
+		}
+		if names[app.Charm] == nil {
+			names[app.Charm] = set.NewStrings()
+		}
+		for name := range app.Resources {
+			names[app.Charm].Add(name)
+		}
+	}
+	req := make(map[string][]string, len(names))
+	for curl, resources := range names {
+		req[curl] = resources.SortedValues()
+	}
+	return req
+}
+
 // VerifyLocal verifies that a local bundle file is consistent.
 // A local bundle file may contain references to charms which are
 // referred to by a directory, either relative or absolute.
@@ -455,7 +1053,47 @@ func (bd *BundleData) VerifyLocal(
 	verifyStorage func(s string) error,
 	verifyDevices func(s string) error,
 ) error {
-	return bd.verifyBundle(bundleDir, verifyConstraints, verifyStorage, verifyDevices, nil)
+	return bd.VerifyLocalContext(context.Background(), bundleDir, verifyConstraints, verifyStorage, verifyDevices)
+}
+
+// VerifyLocalContext is like VerifyLocal, except that it stops and
+// returns the context's error if ctx is done before the verification
+// completes.
+func (bd *BundleData) VerifyLocalContext(
+	ctx context.Context,
+	bundleDir string,
+	verifyConstraints func(c string) error,
+	verifyStorage func(s string) error,
+	verifyDevices func(s string) error,
+) error {
+	return bd.verifyBundle(ctx, bundleDir, verifyConstraints, verifyStorage, verifyDevices, nil, false)
+}
+
+// VerifyLocalWithResources is like VerifyLocal, except that it also
+// checks the resources of the bundle's applications: revisions must not
+// be negative, and local resource files, which are specified by path,
+// must exist. Relative resource paths are interpreted relative to
+// bundleDir, as charm paths are.
+func (bd *BundleData) VerifyLocalWithResources(
+	bundleDir string,
+	verifyConstraints func(c string) error,
+	verifyStorage func(s string) error,
+	verifyDevices func(s string) error,
+) error {
+	return bd.VerifyLocalWithResourcesContext(context.Background(), bundleDir, verifyConstraints, verifyStorage, verifyDevices)
+}
+
+// VerifyLocalWithResourcesContext is like VerifyLocalWithResources,
+// except that it stops and returns the context's error if ctx is done
+// before the verification completes.
+func (bd *BundleData) VerifyLocalWithResourcesContext(
+	ctx context.Context,
+	bundleDir string,
+	verifyConstraints func(c string) error,
+	verifyStorage func(s string) error,
+	verifyDevices func(s string) error,
+) error {
+	return bd.verifyBundle(ctx, bundleDir, verifyConstraints, verifyStorage, verifyDevices, nil, true)
 }
 
 // Verify is a convenience method that calls VerifyWithCharms
@@ -481,11 +1119,18 @@ func (bd *BundleData) Verify(
 // - All applications referred to by relations are specified in the bundle.
 // - All basic constraints are valid.
 // - All storage constraints are valid.
+// - All placement directives use a supported container type.
+// - All units placed directly onto a machine have the machine's base.
+// - The bundle, applications and machines do not declare both a series and a base.
 //
 // If charms is not nil, it should hold a map with an entry for each
 // charm url returned by bd.RequiredCharms. The verification will then
 // also check that applications are defined with valid charms,
 // relations are correctly made and options are defined correctly.
+// Relations are also checked against the limit declared for each
+// endpoint, if any, devices against the type and count required
+// by the charm, and machine bases against the bases supported by the
+// charms of the units placed on them.
 //
 // If the verification fails, Verify returns a *VerificationError describing
 // all the problems found.
@@ -495,15 +1140,103 @@ func (bd *BundleData) VerifyWithCharms(
 	verifyDevices func(s string) error,
 	charms map[string]Charm,
 ) error {
-	return bd.verifyBundle("", verifyConstraints, verifyStorage, verifyDevices, charms)
+	return bd.VerifyWithCharmsContext(context.Background(), verifyConstraints, verifyStorage, verifyDevices, charms)
+}
+
+// VerifyWithCharmsContext is like VerifyWithCharms, except that it stops
+// and returns the context's error if ctx is done before the verification
+// completes.
+func (bd *BundleData) VerifyWithCharmsContext(
+	ctx context.Context,
+	verifyConstraints func(c string) error,
+	verifyStorage func(s string) error,
+	verifyDevices func(s string) error,
+	charms map[string]Charm,
+) error {
+	return bd.verifyBundle(ctx, "", verifyConstraints, verifyStorage, verifyDevices, charms, false)
+}
+
+// CharmResolver returns the charm with the given URL. It should return
+// an error satisfying errors.NotFound if there is no such charm.
+type CharmResolver func(url string) (Charm, error)
+
+// VerifyWithCharmResolver is like VerifyWithCharms, except that the
+// charms are obtained by calling resolver rather than from a prebuilt
+// map. The resolver is called once for each distinct charm used by the
+// bundle's applications, with the URL in its normalised form, so that
+// "mysql" and "ch:mysql" refer to the same charm. URLs that cannot be
+// parsed, such as local charm paths, are passed unchanged.
+//
+// A charm that the resolver reports as not found is reported as a
+// verification error; any other error from the resolver is returned
+// as it is.
+func (bd *BundleData) VerifyWithCharmResolver(
+	resolver CharmResolver,
+	verifyConstraints func(c string) error,
+	verifyStorage func(s string) error,
+	verifyDevices func(s string) error,
+) error {
+	return bd.VerifyWithCharmResolverContext(context.Background(), resolver, verifyConstraints, verifyStorage, verifyDevices)
+}
+
+// VerifyWithCharmResolverContext is like VerifyWithCharmResolver, except
+// that it stops and returns the context's error if ctx is done before
+// the verification completes.
+func (bd *BundleData) VerifyWithCharmResolverContext(
+	ctx context.Context,
+	resolver CharmResolver,
+	verifyConstraints func(c string) error,
+	verifyStorage func(s string) error,
+	verifyDevices func(s string) error,
+) error {
+	charms, err := bd.resolveCharms(ctx, resolver)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return bd.verifyBundle(ctx, "", verifyConstraints, verifyStorage, verifyDevices, charms, false)
+}
+
+// resolveCharms returns the charms used by the bundle's applications,
+// keyed by the charm URL as given in the bundle.
+func (bd *BundleData) resolveCharms(ctx context.Context, resolver CharmResolver) (map[string]Charm, error) {
+	charms := make(map[string]Charm)
+	resolved := make(map[string]Charm)
+	for _, url := range bd.RequiredCharms() {
+		if _, ok := charms[url]; ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		normalised := url
+		if curl, err := ParseURL(url); err == nil {
+			normalised = curl.String()
+		}
+		ch, ok := resolved[normalised]
+		if !ok {
+			var err error
+			ch, err = resolver(normalised)
+			if errors.Is(err, errors.NotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Annotatef(err, "cannot resolve charm %q", url)
+			}
+			resolved[normalised] = ch
+		}
+		charms[url] = ch
+	}
+	return charms, nil
 }
 
 func (bd *BundleData) verifyBundle(
+	ctx context.Context,
 	bundleDir string,
 	verifyConstraints func(c string) error,
 	verifyStorage func(s string) error,
 	verifyDevices func(s string) error,
 	charms map[string]Charm,
+	checkResources bool,
 ) error {
 	if verifyConstraints == nil {
 		verifyConstraints = func(string) error {
@@ -522,19 +1255,23 @@ func (bd *BundleData) verifyBundle(
 	}
 	verifier := &bundleDataVerifier{
 		bundleDir:         bundleDir,
+		checkResources:    checkResources,
 		verifyConstraints: verifyConstraints,
 		verifyStorage:     verifyStorage,
 		verifyDevices:     verifyDevices,
 		bd:                bd,
 		machineRefCounts:  make(map[string]int),
 		charms:            charms,
+
+		appConstraints:     make(map[string]Constraints),
+		machineConstraints: make(map[string]Constraints),
 	}
-	if bd.Type != "" && bd.Type != kubernetes {
-		verifier.addErrorf("bundle has an invalid type %q", bd.Type)
+	if bd.Type != "" && bd.Type != kubernetes && bd.Type != machineBundle {
+		verifier.addErrorf(CodeInvalidBundle, "bundle has an invalid type %q", bd.Type)
 	}
 	if bd.Type == kubernetes {
 		if len(bd.Machines) > 0 {
-			verifier.addErrorf("bundle machines not valid for Kubernetes bundles")
+			verifier.addErrorf(CodeInvalidBundle, "bundle machines not valid for Kubernetes bundles")
 		}
 		bd.Machines = nil
 	}
@@ -542,205 +1279,268 @@ func (bd *BundleData) verifyBundle(
 		verifier.machineRefCounts[id] = 0
 	}
 	if bd.Series != "" && !IsValidSeries(bd.Series) {
-		verifier.addErrorf("bundle declares an invalid series %q", bd.Series)
+		verifier.addErrorf(CodeInvalidBundle, "bundle declares an invalid series %q", bd.Series)
 	}
 	if bd.DefaultBase != "" {
 		if _, err := ParseBase(bd.DefaultBase); err != nil {
-			verifier.addErrorf("bundle declares an invalid base %q", bd.DefaultBase)
+			verifier.addErrorf(CodeInvalidBundle, "bundle declares an invalid base %q", bd.DefaultBase)
+		}
+		if bd.Series != "" {
+			verifier.addErrorf(CodeInvalidBundle, "bundle declares both series %q and default-base %q", bd.Series, bd.DefaultBase)
 		}
 	}
-	verifier.verifySaas()
-	verifier.verifyMachines()
-	verifier.verifyApplications()
-	verifier.verifyRelations()
-	verifier.verifyOptions()
-	verifier.verifyEndpointBindings()
+	for _, verify := range []func(){
+		verifier.verifySaas,
+		verifier.verifyMachines,
+		verifier.verifyApplications,
+		verifier.verifyConstraintConflicts,
+		verifier.verifyRelations,
+		verifier.verifyOptions,
+		verifier.verifyEndpointBindings,
+		verifier.verifyExposedEndpointNames,
+	} {
+		if err := ctx.Err(); err != nil {
+			return errors.Trace(err)
+		}
+		verify()
+	}
 
 	for id, count := range verifier.machineRefCounts {
 		if count == 0 {
-			verifier.addErrorf("machine %q is not referred to by a placement directive", id)
+			verifier.addErrorf(CodeInvalidMachine, "machine %q is not referred to by a placement directive", id)
 		}
 	}
 	return verifier.err()
 }
 
 var (
-	validMachineId   = regexp.MustCompile("^" + names.NumberSnippet + "$")
-	validStorageName = regexp.MustCompile("^" + names.StorageNameSnippet + "$")
-	validDeviceName  = regexp.MustCompile("^" + "(?:[a-z][a-z0-9]*(?:-[a-z0-9]*[a-z][a-z0-9]*)*)" + "$")
+	validMachineId   = lazyRegexp("^" + names.NumberSnippet + "$")
+	validStorageName = lazyRegexp("^" + names.StorageNameSnippet + "$")
+	validDeviceName  = lazyRegexp("^" + "(?:[a-z][a-z0-9]*(?:-[a-z0-9]*[a-z][a-z0-9]*)*)" + "$")
 
 	// When the operator consumes the offer a pseudo-application with the
 	// offer name will be created by the controller. So using the application
 	// name regex makes sense here. Likewise we can use the relation regex
 	// to validate the endpoint name.
-	validOfferName         = regexp.MustCompile("^" + names.ApplicationSnippet + "$")
-	validOfferEndpointName = regexp.MustCompile("^" + names.RelationSnippet + "$")
+	validOfferName         = lazyRegexp("^" + names.ApplicationSnippet + "$")
+	validOfferEndpointName = lazyRegexp("^" + names.RelationSnippet + "$")
 )
 
 func (verifier *bundleDataVerifier) verifySaas() {
 	for name, saas := range verifier.bd.Saas {
 		if _, ok := verifier.bd.Applications[name]; ok {
-			verifier.addErrorf("application %[1]q already exists with SAAS %[1]q name", name)
+			verifier.addErrorf(CodeInvalidSaas, "application %[1]q already exists with SAAS %[1]q name", name)
 		}
-		if !validOfferName.MatchString(name) {
-			verifier.addErrorf("invalid SAAS name %q found", name)
+		if !validOfferName().MatchString(name) {
+			verifier.addErrorf(CodeInvalidSaas, "invalid SAAS name %q found", name)
 		}
 		if saas == nil {
 			continue
 		}
 		if saas.URL != "" && !IsValidOfferURL(saas.URL) {
-			verifier.addErrorf("invalid offer URL %q for SAAS %s", saas.URL, name)
+			verifier.addErrorf(CodeInvalidSaas, "invalid offer URL %q for SAAS %s", saas.URL, name)
 		}
 	}
 }
 
 func (verifier *bundleDataVerifier) verifyMachines() {
 	for id, m := range verifier.bd.Machines {
-		if !validMachineId.MatchString(id) {
-			verifier.addErrorf("invalid machine id %q found in machines", id)
+		if !validMachineId().MatchString(id) {
+			verifier.addErrorf(CodeInvalidMachine, "invalid machine id %q found in machines", id)
 		}
 		if m == nil {
 			continue
 		}
 		if m.Constraints != "" {
 			if err := verifier.verifyConstraints(m.Constraints); err != nil {
-				verifier.addErrorf("invalid constraints %q in machine %q: %v", m.Constraints, id, err)
+				verifier.addErrorf(CodeInvalidConstraints, "invalid constraints %q in machine %q: %v", m.Constraints, id, err)
+			} else if cons, err := ParseConstraints(m.Constraints); err != nil {
+				verifier.addErrorf(CodeInvalidConstraints, "invalid constraints %q in machine %q: %v", m.Constraints, id, err)
+			} else if cons != nil {
+				verifier.machineConstraints[id] = cons
 			}
 		}
 		if m.Series != "" && !IsValidSeries(m.Series) {
-			verifier.addErrorf("invalid series %q for machine %q", m.Series, id)
+			verifier.addErrorf(CodeInvalidMachine, "invalid series %q for machine %q", m.Series, id)
 		}
 		if m.Base != "" {
 			if _, err := ParseBase(m.Base); err != nil {
-				verifier.addErrorf("invalid base %q for machine %q", m.Base, id)
+				verifier.addErrorf(CodeInvalidMachine, "invalid base %q for machine %q", m.Base, id)
+			}
+			if m.Series != "" {
+				verifier.addErrorf(CodeInvalidMachine, "machine %q declares both series %q and base %q", id, m.Series, m.Base)
 			}
 		}
+		verifier.verifyMachineLayout(id, m)
 	}
 }
 
+// verifyLocalCharm checks that the local charm of the named application
+// exists, and if it is an archive rather than a directory, that it is a
+// readable zip file. Relative paths are interpreted relative to the
+// bundle directory.
+func (verifier *bundleDataVerifier) verifyLocalCharm(name, ref string) {
+	charmPath, err := LocalCharmOrBundlePath(ref)
+	if err != nil {
+		verifier.addErrorf(CodeInvalidCharm, "invalid charm path in application %q: %v", name, err)
+		return
+	}
+	if !filepath.IsAbs(charmPath) {
+		charmPath = filepath.Join(verifier.bundleDir, charmPath)
+	}
+	info, err := os.Stat(charmPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			verifier.addErrorf(CodeInvalidCharm, "charm path in application %q does not exist: %v", name, charmPath)
+		} else {
+			verifier.addErrorf(CodeInvalidCharm, "invalid charm path in application %q: %v", name, err)
+		}
+		return
+	}
+	if info.IsDir() {
+		return
+	}
+	zipr, err := zip.OpenReader(charmPath)
+	if err != nil {
+		verifier.addErrorf(CodeInvalidCharm, "charm archive in application %q is not a readable zip file: %v", name, err)
+		return
+	}
+	_ = zipr.Close()
+}
+
 func (verifier *bundleDataVerifier) verifyApplications() {
 	if len(verifier.bd.Applications) == 0 {
-		verifier.addErrorf("at least one application must be specified")
+		verifier.addErrorf(CodeInvalidApplication, "at least one application must be specified")
 		return
 	}
 	for name, app := range verifier.bd.Applications {
 		if app == nil {
-			verifier.addErrorf("bundle application for key %q is undefined", name)
+			verifier.addErrorf(CodeInvalidApplication, "bundle application for key %q is undefined", name)
 			continue
 		}
 		if app.Charm == "" {
-			verifier.addErrorf("empty charm path")
+			verifier.addErrorf(CodeInvalidCharm, "empty charm path")
 		}
 		if _, ok := verifier.bd.Saas[name]; ok {
-			verifier.addErrorf("SAAS %[1]q already exists with application %[1]q name", name)
+			verifier.addErrorf(CodeInvalidApplication, "SAAS %[1]q already exists with application %[1]q name", name)
 		}
 		// Charm may be a local directory or a charm URL.
 		var curl *URL
 		var err error
-		if strings.HasPrefix(app.Charm, ".") || filepath.IsAbs(app.Charm) {
-			charmPath := app.Charm
-			if !filepath.IsAbs(charmPath) {
-				charmPath = filepath.Join(verifier.bundleDir, charmPath)
-			}
-			if _, err := os.Stat(charmPath); err != nil {
-				if os.IsNotExist(err) {
-					verifier.addErrorf("charm path in application %q does not exist: %v", name, charmPath)
-				} else {
-					verifier.addErrorf("invalid charm path in application %q: %v", name, err)
-				}
-			}
+		if isLocalCharmOrBundleRef(app.Charm) {
+			verifier.verifyLocalCharm(name, app.Charm)
 		} else if curl, err = ParseURL(app.Charm); err != nil {
-			verifier.addErrorf("invalid charm URL in application %q: %v", name, err)
+			verifier.addError(CodeInvalidCharm, errors.Annotatef(err, "invalid charm URL in application %q", name))
 		}
 
 		// Check the revision.
 		if curl != nil {
-			if CharmHub.Matches(curl.Schema) && curl.Revision != -1 {
-				verifier.addErrorf("cannot specify revision in %q, please use revision", curl.String())
+			if curl.IsCharmHub() && curl.Revision != -1 {
+				verifier.addErrorf(CodeInvalidCharm, "cannot specify revision in %q, please use revision", curl.String())
 			}
 			if app.Revision != nil {
-				if CharmHub.Matches(curl.Schema) && app.Channel == "" {
-					verifier.addErrorf("application %q with a revision requires a channel for future upgrades, please use channel", name)
+				if curl.IsCharmHub() && app.Channel == "" {
+					verifier.addErrorf(CodeInvalidCharm, "application %q with a revision requires a channel for future upgrades, please use channel", name)
 				}
 				if *app.Revision < 0 {
-					verifier.addErrorf("the revision for application %q must be zero or greater", name)
+					verifier.addErrorf(CodeInvalidCharm, "the revision for application %q must be zero or greater", name)
 				}
 			}
 		}
 
 		// Check the Series.
 		if curl != nil && curl.Series != "" && app.Series != "" && curl.Series != app.Series {
-			verifier.addErrorf("the charm URL for application %q has a series which does not match, please remove the series from the URL", name)
+			verifier.addErrorf(CodeInvalidCharm, "the charm URL for application %q has a series which does not match, please remove the series from the URL", name)
 		}
 		if app.Series != "" && !IsValidSeries(app.Series) {
-			verifier.addErrorf("application %q declares an invalid series %q", name, app.Series)
+			verifier.addErrorf(CodeInvalidApplication, "application %q declares an invalid series %q", name, app.Series)
 		}
 		// Check the Base
 		if app.Base != "" {
 			if _, err := ParseBase(app.Base); err != nil {
-				verifier.addErrorf("application %q declares an invalid base %q", name, app.Base)
+				verifier.addErrorf(CodeInvalidApplication, "application %q declares an invalid base %q", name, app.Base)
+			}
+			if app.Series != "" {
+				verifier.addErrorf(CodeInvalidApplication, "application %q declares both series %q and base %q", name, app.Series, app.Base)
 			}
 		}
 		// Check the Constraints.
 		if err := verifier.verifyConstraints(app.Constraints); err != nil {
-			verifier.addErrorf("invalid constraints %q in application %q: %v", app.Constraints, name, err)
+			verifier.addErrorf(CodeInvalidConstraints, "invalid constraints %q in application %q: %v", app.Constraints, name, err)
+		} else if cons, err := ParseConstraints(app.Constraints); err != nil {
+			verifier.addErrorf(CodeInvalidConstraints, "invalid constraints %q in application %q: %v", app.Constraints, name, err)
+		} else if cons != nil {
+			verifier.appConstraints[name] = cons
 		}
 		// Check the Storage.
 		for storageName, storageConstraints := range app.Storage {
-			if !validStorageName.MatchString(storageName) {
-				verifier.addErrorf("invalid storage name %q in application %q", storageName, name)
+			if !validStorageName().MatchString(storageName) {
+				verifier.addErrorf(CodeInvalidStorage, "invalid storage name %q in application %q", storageName, name)
 			}
 			if err := verifier.verifyStorage(storageConstraints); err != nil {
-				verifier.addErrorf("invalid storage %q in application %q: %v", storageName, name, err)
+				verifier.addErrorf(CodeInvalidStorage, "invalid storage %q in application %q: %v", storageName, name, err)
 			}
 		}
 		// Check the Devices.
 		for deviceName, deviceConstraints := range app.Devices {
-			if !validDeviceName.MatchString(deviceName) {
-				verifier.addErrorf("invalid device name %q in application %q", deviceName, name)
+			if !validDeviceName().MatchString(deviceName) {
+				verifier.addErrorf(CodeInvalidDevices, "invalid device name %q in application %q", deviceName, name)
 			}
 			if err := verifier.verifyDevices(deviceConstraints); err != nil {
-				verifier.addErrorf("invalid device %q in application %q: %v", deviceName, name, err)
+				verifier.addErrorf(CodeInvalidDevices, "invalid device %q in application %q: %v", deviceName, name, err)
+				continue
+			}
+			if ch, ok := verifier.charms[app.Charm]; ok {
+				verifier.verifyCharmDevice(name, deviceName, deviceConstraints, ch.Meta())
 			}
 		}
 		// Check the offers.
 		for offerName, oSpec := range app.Offers {
-			if !validOfferName.MatchString(offerName) {
-				verifier.addErrorf("invalid offer name %q in application %q", offerName, name)
+			if !validOfferName().MatchString(offerName) {
+				verifier.addErrorf(CodeInvalidOffer, "invalid offer name %q in application %q", offerName, name)
 			}
 
 			for _, endpoint := range oSpec.Endpoints {
-				if !validOfferEndpointName.MatchString(endpoint) {
-					verifier.addErrorf("invalid endpoint name %q for offer %q in application %q", endpoint, offerName, name)
+				if !validOfferEndpointName().MatchString(endpoint) {
+					verifier.addErrorf(CodeInvalidOffer, "invalid endpoint name %q for offer %q in application %q", endpoint, offerName, name)
 				}
 			}
+			verifier.verifyOfferACL(name, offerName, oSpec.ACL)
 		}
 		if verifier.charms != nil {
 			if ch, ok := verifier.charms[app.Charm]; ok {
+				verifier.verifyCharmPlatform(name, app.Charm, ch.Meta())
 				if ch.Meta().Subordinate {
 					if len(app.To) > 0 {
-						verifier.addErrorf("application %q is subordinate but specifies unit placement", name)
+						verifier.addErrorf(CodeInvalidApplication, "application %q is subordinate but specifies unit placement", name)
 					}
 					if app.NumUnits > 0 {
-						verifier.addErrorf("application %q is subordinate but has non-zero num_units", name)
+						verifier.addErrorf(CodeInvalidApplication, "application %q is subordinate but has non-zero num_units", name)
 					}
 				}
 			} else {
-				verifier.addErrorf("application %q refers to non-existent charm %q", name, app.Charm)
+				verifier.addErrorf(CodeInvalidCharm, "application %q refers to non-existent charm %q", name, app.Charm)
 			}
 		}
 		for resName, rev := range app.Resources {
 			if resName == "" {
-				verifier.addErrorf("missing resource name on application %q", name)
+				verifier.addErrorf(CodeInvalidResource, "missing resource name on application %q", name)
 			}
-			switch rev.(type) {
-			case int, string:
+			switch rev := rev.(type) {
+			case int:
+				if verifier.checkResources && rev < 0 {
+					verifier.addErrorf(CodeInvalidResource, "negative revision %d for resource %q on application %q", rev, resName, name)
+				}
+			case string:
+				if verifier.checkResources {
+					verifier.verifyResourcePath(name, resName, rev)
+				}
 			default:
-				verifier.addErrorf("resource revision %q is not int or string", name)
+				verifier.addErrorf(CodeInvalidResource, "resource revision %q is not int or string", name)
 			}
 		}
 		if app.NumUnits < 0 {
-			verifier.addErrorf("negative number of units specified on application %q", name)
+			verifier.addErrorf(CodeInvalidApplication, "negative number of units specified on application %q", name)
 		}
 		if verifier.bd.Type == kubernetes {
 			verifier.verifyKubernetesPlacement(name, app.To)
@@ -757,63 +1557,276 @@ func (verifier *bundleDataVerifier) verifyApplications() {
 		// to 0.0.0.0/0!
 		if len(app.ExposedEndpoints) != 0 {
 			if app.Expose {
-				verifier.addErrorf(`exposed-endpoints cannot be specified together with "exposed:true" in application %q as this poses a security risk when deploying bundles to older controllers`, name)
+				verifier.addErrorf(CodeInvalidExpose, `exposed-endpoints cannot be specified together with "exposed:true" in application %q as this poses a security risk when deploying bundles to older controllers`, name)
 			} else {
-				for epName, expDetails := range app.ExposedEndpoints {
-					for _, cidr := range expDetails.ExposeToCIDRs {
-						if _, _, err := net.ParseCIDR(cidr); err != nil {
-							verifier.addErrorf("invalid CIDR %q for expose to CIDRs field for endpoint %q in application %q", cidr, epName, name)
-						}
-					}
-				}
+				verifier.verifyExposedEndpoints(name, app)
 			}
 		}
 	}
 }
 
+// verifyResourcePath checks that the local file given for the named
+// resource of the named application exists.
+func (verifier *bundleDataVerifier) verifyResourcePath(appName, resName, resPath string) {
+	if resPath == "" {
+		verifier.addErrorf(CodeInvalidResource, "empty path for resource %q on application %q", resName, appName)
+		return
+	}
+	if !filepath.IsAbs(resPath) {
+		resPath = filepath.Join(verifier.bundleDir, resPath)
+	}
+	info, err := os.Stat(resPath)
+	switch {
+	case os.IsNotExist(err):
+		verifier.addErrorf(CodeInvalidResource, "resource %q path in application %q does not exist: %v", resName, appName, resPath)
+	case err != nil:
+		verifier.addErrorf(CodeInvalidResource, "invalid resource %q path in application %q: %v", resName, appName, err)
+	case info.IsDir():
+		verifier.addErrorf(CodeInvalidResource, "resource %q path in application %q is a directory: %v", resName, appName, resPath)
+	}
+}
+
+// verifyCharmDevice checks that the device constraints given for the
+// named device of an application are consistent with the device
+// requirements declared by the application's charm.
+func (verifier *bundleDataVerifier) verifyCharmDevice(appName, deviceName, constraints string, meta *Meta) {
+	device, ok := meta.Devices[deviceName]
+	if !ok {
+		verifier.addErrorf(CodeInvalidDevices, "application %q specifies device %q which is not defined by charm %q", appName, deviceName, meta.Name)
+		return
+	}
+	cons, err := ParseDeviceConstraint(constraints)
+	if err != nil {
+		verifier.addErrorf(CodeInvalidDevices, "invalid device %q in application %q: %v", deviceName, appName, err)
+		return
+	}
+	if cons.Type != device.Type {
+		verifier.addErrorf(CodeInvalidDevices, "device %q in application %q has type %q, but charm %q requires %q", deviceName, appName, cons.Type, meta.Name, device.Type)
+	}
+	switch {
+	case device.CountMax < 0 && cons.Count < device.CountMin:
+		verifier.addErrorf(CodeInvalidDevices, "device %q in application %q requests %d devices, but charm %q requires at least %d", deviceName, appName, cons.Count, meta.Name, device.CountMin)
+	case cons.Count < device.CountMin || (device.CountMax >= 0 && cons.Count > device.CountMax):
+		verifier.addErrorf(CodeInvalidDevices, "device %q in application %q requests %d devices, but charm %q requires between %d and %d", deviceName, appName, cons.Count, meta.Name, device.CountMin, device.CountMax)
+	}
+}
+
 func (verifier *bundleDataVerifier) verifyPlacement(name string, numUnits int, to []string) {
 	if numUnits >= 0 && len(to) > numUnits {
-		verifier.addErrorf("too many units specified in unit placement for application %q", name)
+		verifier.addErrorf(CodeInvalidPlacement, "too many units specified in unit placement for application %q", name)
 	}
 	for _, p := range to {
 		up, err := ParsePlacement(p)
 		if err != nil {
-			verifier.addError(err)
+			verifier.addError(CodeInvalidPlacement, err)
 			continue
 		}
 		switch {
 		case up.Application != "":
 			spec, ok := verifier.bd.Applications[up.Application]
 			if !ok {
-				verifier.addErrorf("placement %q refers to an application not defined in this bundle", p)
+				verifier.addError(CodeInvalidPlacement, &PlacementError{
+					Placement: p,
+					Reason:    fmt.Sprintf("placement %q refers to an application not defined in this bundle", p),
+				})
 				continue
 			}
 			if up.Unit >= 0 && up.Unit >= spec.NumUnits {
-				verifier.addErrorf("placement %q specifies a unit greater than the %d unit(s) started by the target application", p, spec.NumUnits)
+				verifier.addError(CodeInvalidPlacement, &PlacementError{
+					Placement: p,
+					Reason:    fmt.Sprintf("placement %q specifies a unit greater than the %d unit(s) started by the target application", p, spec.NumUnits),
+				})
 			}
 		case up.Machine == "new":
 		default:
 			_, ok := verifier.bd.Machines[up.Machine]
 			if !ok {
-				verifier.addErrorf("placement %q refers to a machine not defined in this bundle", p)
+				verifier.addError(CodeInvalidPlacement, &PlacementError{
+					Placement: p,
+					Reason:    fmt.Sprintf("placement %q refers to a machine not defined in this bundle", p),
+				})
 				continue
 			}
 			verifier.machineRefCounts[up.Machine]++
+			verifier.verifyMachinePlacement(name, p, up)
+		}
+		if up.ContainerType != "" {
+			if err := ValidateContainerType(up.ContainerType); err != nil {
+				verifier.addError(CodeInvalidPlacement, &PlacementError{
+					Placement: p,
+					Reason:    fmt.Sprintf("placement %q: %v", p, err),
+				})
+			}
+		}
+	}
+}
+
+// supportedContainerTypes holds the container types that units may be
+// placed into.
+var supportedContainerTypes = set.NewStrings("lxd", "kvm")
+
+// legacyContainerTypes maps legacy container types, which existing
+// bundles still use, to the container types that replace them.
+var legacyContainerTypes = map[string]string{
+	"lxc": "lxd",
+}
+
+// NormaliseContainerType returns the container type that replaces the
+// given legacy container type, such as "lxd" for "lxc", or the given
+// container type if it is not a legacy one.
+func NormaliseContainerType(containerType string) string {
+	if replacement, ok := legacyContainerTypes[containerType]; ok {
+		return replacement
+	}
+	return containerType
+}
+
+// IsValidContainerType reports whether units can be placed into
+// containers of the given type, such as "lxd" in the placement
+// directive "lxd:0". Legacy container types are accepted as the
+// container types that replace them; see NormaliseContainerType.
+func IsValidContainerType(containerType string) bool {
+	return supportedContainerTypes.Contains(NormaliseContainerType(containerType))
+}
+
+// ValidateContainerType returns an error if units cannot be placed into
+// containers of the given type.
+func ValidateContainerType(containerType string) error {
+	if IsValidContainerType(containerType) {
+		return nil
+	}
+	return errors.NewNotValid(nil, fmt.Sprintf("container type %q not valid, expected one of %s", containerType, strings.Join(supportedContainerTypes.SortedValues(), ", ")))
+}
+
+// verifyMachinePlacement checks that the named application can be placed
+// onto the machine declared in the bundle as specified by the placement
+// p. Units placed directly onto a machine must run on the machine's base,
+// so that base must match any base declared by the application and be
+// supported by its charm. Containers can only be hosted by Linux machines.
+func (verifier *bundleDataVerifier) verifyMachinePlacement(appName, p string, up *UnitPlacement) {
+	m := verifier.bd.Machines[up.Machine]
+	if m == nil {
+		return
+	}
+	machineBase := requiredBase(m.Base, m.Series)
+	if machineBase == "" {
+		return
+	}
+	base, err := ParseBase(machineBase)
+	if err != nil {
+		// Invalid machine bases are reported by verifyMachines.
+		return
+	}
+	if up.ContainerType != "" {
+		if !containerHostOS.Contains(base.Name) {
+			verifier.addError(CodeInvalidPlacement, &PlacementError{
+				Placement: p,
+				Reason:    fmt.Sprintf("placement %q for application %q puts a %s container on machine %q, but machines with base %q cannot host containers", p, appName, up.ContainerType, up.Machine, machineBase),
+			})
+		}
+		// The container runs the application's base, not the
+		// machine's.
+		return
+	}
+	app := verifier.bd.Applications[appName]
+	if appBase := requiredBase(app.Base, app.Series); appBase != "" && !sameBase(appBase, base) {
+		verifier.addError(CodeInvalidPlacement, &PlacementError{
+			Placement: p,
+			Reason:    fmt.Sprintf("placement %q puts application %q with base %q on machine %q with base %q; place it in a container or use a machine with a matching base", p, appName, appBase, up.Machine, machineBase),
+		})
+		return
+	}
+	if ch, ok := verifier.charms[app.Charm]; ok && !charmSupportsBase(ch, base) {
+		verifier.addError(CodeInvalidPlacement, &PlacementError{
+			Placement: p,
+			Reason:    fmt.Sprintf("placement %q puts application %q on machine %q with base %q, which is not supported by charm %q", p, appName, up.Machine, machineBase, app.Charm),
+		})
+	}
+}
+
+// containerHostOS holds the names of the operating systems whose
+// machines can host containers.
+var containerHostOS = set.NewStrings("ubuntu", "centos", "opensuse", "genericlinux")
+
+// sameBase reports whether the base string s denotes the same operating
+// system and track as base.
+func sameBase(s string, base Base) bool {
+	other, err := ParseBase(s)
+	if err != nil {
+		return false
+	}
+	return other.Name == base.Name && other.Channel.Track == base.Channel.Track
+}
+
+// charmSupportsBase reports whether the charm can be deployed on the
+// given base, according to the bases declared in its manifest or, for
+// older charms, the series declared in its metadata. Charms declaring
+// neither are assumed to support any base.
+func charmSupportsBase(ch Charm, base Base) bool {
+	var supported []Base
+	if manifest := ch.Manifest(); manifest != nil && len(manifest.Bases) > 0 {
+		supported = manifest.Bases
+	} else {
+		for _, s := range ch.Meta().Series {
+			if b, err := baseFromSeries(s); err == nil {
+				supported = append(supported, b)
+			}
+		}
+		if len(supported) == 0 {
+			return true
+		}
+	}
+	for _, b := range supported {
+		if b.Name == base.Name && b.Channel.Track == base.Channel.Track {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyCharmPlatform checks that the charm of the named application can
+// be deployed by the bundle: Kubernetes bundles cannot deploy charms
+// declaring only machine series, and machine bundles cannot deploy
+// charms which only run on Kubernetes. Charms that don't say which
+// platform they run on are accepted by both.
+func (verifier *bundleDataVerifier) verifyCharmPlatform(appName, charmName string, meta *Meta) {
+	var machineSeries, kubernetesSeries bool
+	for _, series := range meta.Series {
+		if series == kubernetes {
+			kubernetesSeries = true
+		} else {
+			machineSeries = true
 		}
 	}
+	kubernetesOnly := len(meta.Containers) > 0 || meta.Deployment != nil || (kubernetesSeries && !machineSeries)
+	machineOnly := !kubernetesOnly && machineSeries && !kubernetesSeries
+	if verifier.bd.Type == kubernetes && machineOnly {
+		verifier.addErrorf(CodeInvalidCharm, "application %q: machine charm %q not valid for Kubernetes bundles", appName, charmName)
+	} else if verifier.bd.Type != kubernetes && kubernetesOnly {
+		verifier.addErrorf(CodeInvalidCharm, "application %q: Kubernetes charm %q not valid for machine bundles", appName, charmName)
+	}
 }
 
 func (verifier *bundleDataVerifier) verifyKubernetesPlacement(name string, to []string) {
 	if len(to) > 1 {
-		verifier.addErrorf("too many placement directives for application %q", name)
+		verifier.addErrorf(CodeInvalidPlacement, "too many placement directives for application %q", name)
 		return
 	}
 	if len(to) == 0 {
 		return
 	}
+	// Kubernetes units are placed by node selector, so reject the
+	// machine and unit directives used by machine bundles outright
+	// rather than reporting them as malformed selectors.
+	if !strings.Contains(to[0], "=") {
+		if up, err := ParsePlacement(to[0]); err == nil && (up.Machine != "" || up.ContainerType != "" || up.Unit >= 0) {
+			verifier.addErrorf(CodeInvalidPlacement, "machine placement %q not valid for Kubernetes application %q", to[0], name)
+			return
+		}
+	}
 	_, err := keyvalues.Parse(strings.Split(to[0], ","), false)
 	if err != nil {
-		verifier.addErrorf("%v for application %q", err, name)
+		verifier.addErrorf(CodeInvalidPlacement, "%v for application %q", err, name)
 	}
 }
 
@@ -831,9 +1844,10 @@ func (verifier *bundleDataVerifier) getCharmMetaForApplication(appName string) (
 
 func (verifier *bundleDataVerifier) verifyRelations() {
 	seen := make(map[[2]endpoint]bool)
+	relationCounts := make(map[endpoint]int)
 	for _, relPair := range verifier.bd.Relations {
 		if len(relPair) != 2 {
-			verifier.addErrorf("relation %q has %d endpoint(s), not 2", relPair, len(relPair))
+			verifier.addErrorf(CodeInvalidRelation, "relation %q has %d endpoint(s), not 2", relPair, len(relPair))
 			continue
 		}
 		var epPair [2]endpoint
@@ -841,7 +1855,7 @@ func (verifier *bundleDataVerifier) verifyRelations() {
 		for i, svcRel := range relPair {
 			ep, err := parseEndpoint(svcRel)
 			if err != nil {
-				verifier.addError(err)
+				verifier.addError(CodeInvalidRelation, err)
 				relParseErr = true
 				continue
 			}
@@ -851,10 +1865,10 @@ func (verifier *bundleDataVerifier) verifyRelations() {
 			_, foundApp := verifier.bd.Applications[ep.application]
 			_, foundSaas := verifier.bd.Saas[ep.application]
 			if !foundApp && !foundSaas {
-				verifier.addErrorf("relation %q refers to application %q not defined in this bundle", relPair, ep.application)
+				verifier.addErrorf(CodeInvalidRelation, "relation %q refers to application %q not defined in this bundle", relPair, ep.application)
 			}
 			if foundApp && foundSaas {
-				verifier.addErrorf("ambiguous relation %q refers to a application and a SAAS in this bundle", ep.application)
+				verifier.addErrorf(CodeInvalidRelation, "ambiguous relation %q refers to a application and a SAAS in this bundle", ep.application)
 			}
 			epPair[i] = ep
 		}
@@ -864,21 +1878,21 @@ func (verifier *bundleDataVerifier) verifyRelations() {
 			continue
 		}
 		if epPair[0].application == epPair[1].application {
-			verifier.addErrorf("relation %q relates an application to itself", relPair)
+			verifier.addErrorf(CodeInvalidRelation, "relation %q relates an application to itself", relPair)
 		}
 		// Resolve endpoint relations if necessary and we have
 		// the necessary charm information.
 		if (epPair[0].relation == "" || epPair[1].relation == "") && verifier.charms != nil {
 			iep0, iep1, err := inferEndpoints(epPair[0], epPair[1], verifier.getCharmMetaForApplication)
 			if err != nil {
-				verifier.addErrorf("cannot infer endpoint between %s and %s: %v", epPair[0], epPair[1], err)
+				verifier.addErrorf(CodeInvalidRelation, "cannot infer endpoint between %s and %s: %v", epPair[0], epPair[1], err)
 			} else {
 				// Change the endpoints that get recorded
 				// as seen, so we'll diagnose a duplicate
 				// relation even if one relation specifies
 				// the relations explicitly and the other does
 				// not.
-				epPair[0], epPair[1] = iep0, iep1
+				epPair[0], epPair[1] = iep0.endpoint(), iep1.endpoint()
 			}
 		}
 
@@ -888,15 +1902,51 @@ func (verifier *bundleDataVerifier) verifyRelations() {
 			epPair[1], epPair[0] = epPair[0], epPair[1]
 		}
 		if _, ok := seen[epPair]; ok {
-			verifier.addErrorf("relation %q is defined more than once", relPair)
+			verifier.addErrorf(CodeInvalidRelation, "relation %q is defined more than once", relPair)
 		}
 		if verifier.charms != nil && epPair[0].relation != "" && epPair[1].relation != "" {
 			// We have charms to verify against, and the
 			// endpoint has been fully specified or inferred.
 			verifier.verifyRelation(epPair[0], epPair[1])
+			if !seen[epPair] {
+				relationCounts[epPair[0]]++
+				relationCounts[epPair[1]]++
+			}
 		}
 		seen[epPair] = true
 	}
+	for _, spec := range verifier.bd.RelationSpecs {
+		if spec.Via != "" && !names.IsValidSpace(spec.Via) {
+			verifier.addErrorf(CodeInvalidRelation, "relation %q declares an invalid space %q", []string{spec.Provider, spec.Requirer}, spec.Via)
+		}
+	}
+	verifier.verifyRelationLimits(relationCounts)
+}
+
+// verifyRelationLimits checks that no endpoint takes part in more
+// relations than the limit declared for it in the charm metadata.
+func (verifier *bundleDataVerifier) verifyRelationLimits(relationCounts map[endpoint]int) {
+	eps := make([]endpoint, 0, len(relationCounts))
+	for ep := range relationCounts {
+		eps = append(eps, ep)
+	}
+	sort.Slice(eps, func(i, j int) bool {
+		return eps[i].less(eps[j])
+	})
+	for _, ep := range eps {
+		meta, err := verifier.getCharmMetaForApplication(ep.application)
+		if err != nil {
+			// An error will be produced by verifyApplications for this case.
+			continue
+		}
+		rel, ok := meta.CombinedRelations()[ep.relation]
+		if !ok || rel.Limit <= 0 {
+			continue
+		}
+		if count := relationCounts[ep]; count > rel.Limit {
+			verifier.addErrorf(CodeInvalidRelation, "endpoint %q is used by %d relations, exceeding its limit of %d", ep, count, rel.Limit)
+		}
+	}
 }
 
 func (verifier *bundleDataVerifier) verifyEndpointBindings() {
@@ -925,6 +1975,7 @@ func (verifier *bundleDataVerifier) verifyEndpointBindings() {
 
 			if !(isInProvides || isInRequires || isInPeers || isInExtraBindings) {
 				verifier.addErrorf(
+					CodeInvalidBinding,
 					"application %q wants to bind endpoint %q to space %q, "+
 						"but the endpoint is not defined by the charm",
 					name, endpoint, space)
@@ -934,13 +1985,6 @@ func (verifier *bundleDataVerifier) verifyEndpointBindings() {
 	}
 }
 
-var infoRelation = Relation{
-	Name:      "juju-info",
-	Role:      RoleProvider,
-	Interface: "juju-info",
-	Scope:     ScopeContainer,
-}
-
 // verifyRelation verifies a single relation.
 // It checks that both endpoints of the relation are
 // defined, and that the relationship is correctly
@@ -960,24 +2004,24 @@ func (verifier *bundleDataVerifier) verifyRelation(ep0, ep1 endpoint) {
 		return
 	}
 	relProv0, okProv0 := charm0.Meta().Provides[ep0.relation]
-	// The juju-info relation is provided implicitly by every
-	// charm - use it if required.
-	if !okProv0 && ep0.relation == infoRelation.Name {
-		relProv0, okProv0 = infoRelation, true
+	// Implicit relations, such as juju-info, are provided by every
+	// charm - use them if required.
+	if !okProv0 {
+		relProv0, okProv0 = implicitRelation(ep0.relation)
 	}
 	relReq0, okReq0 := charm0.Meta().Requires[ep0.relation]
 	if !okProv0 && !okReq0 {
-		verifier.addErrorf("charm %q used by application %q does not define relation %q", svc0.Charm, ep0.application, ep0.relation)
+		verifier.addErrorf(CodeInvalidRelation, "charm %q used by application %q does not define relation %q", svc0.Charm, ep0.application, ep0.relation)
 	}
 	relProv1, okProv1 := charm1.Meta().Provides[ep1.relation]
-	// The juju-info relation is provided implicitly by every
-	// charm - use it if required.
-	if !okProv1 && ep1.relation == infoRelation.Name {
-		relProv1, okProv1 = infoRelation, true
+	// Implicit relations, such as juju-info, are provided by every
+	// charm - use them if required.
+	if !okProv1 {
+		relProv1, okProv1 = implicitRelation(ep1.relation)
 	}
 	relReq1, okReq1 := charm1.Meta().Requires[ep1.relation]
 	if !okProv1 && !okReq1 {
-		verifier.addErrorf("charm %q used by application %q does not define relation %q", svc1.Charm, ep1.application, ep1.relation)
+		verifier.addErrorf(CodeInvalidRelation, "charm %q used by application %q does not define relation %q", svc1.Charm, ep1.application, ep1.relation)
 	}
 
 	var relProv, relReq Relation
@@ -990,17 +2034,17 @@ func (verifier *bundleDataVerifier) verifyRelation(ep0, ep1 endpoint) {
 		relProv, relReq = relProv1, relReq0
 		epProv, epReq = ep1, ep0
 	case okProv0 && okProv1:
-		verifier.addErrorf("relation %q to %q relates provider to provider", ep0, ep1)
+		verifier.addErrorf(CodeInvalidRelation, "relation %q to %q relates provider to provider", ep0, ep1)
 		return
 	case okReq0 && okReq1:
-		verifier.addErrorf("relation %q to %q relates requirer to requirer", ep0, ep1)
+		verifier.addErrorf(CodeInvalidRelation, "relation %q to %q relates requirer to requirer", ep0, ep1)
 		return
 	default:
 		// Errors were added above.
 		return
 	}
 	if relProv.Interface != relReq.Interface {
-		verifier.addErrorf("mismatched interface between %q and %q (%q vs %q)", epProv, epReq, relProv.Interface, relReq.Interface)
+		verifier.addErrorf(CodeInvalidRelation, "mismatched interface between %q and %q (%q vs %q)", epProv, epReq, relProv.Interface, relReq.Interface)
 	}
 }
 
@@ -1020,18 +2064,18 @@ func (verifier *bundleDataVerifier) verifyOptions() {
 		for name, value := range svc.Options {
 			opt, ok := config.Options[name]
 			if !ok {
-				verifier.addErrorf("cannot validate application %q: configuration option %q not found in charm %q", appName, name, svc.Charm)
+				verifier.addErrorf(CodeInvalidOption, "cannot validate application %q: configuration option %q not found in charm %q", appName, name, svc.Charm)
 				continue
 			}
 			_, err := opt.validate(name, value)
 			if err != nil {
-				verifier.addErrorf("cannot validate application %q: %v", appName, err)
+				verifier.addErrorf(CodeInvalidOption, "cannot validate application %q: %v", appName, err)
 			}
 		}
 	}
 }
 
-var validApplicationRelation = regexp.MustCompile("^(" + names.ApplicationSnippet + "):(" + names.RelationSnippet + ")$")
+var validApplicationRelation = lazyRegexp("^(" + names.ApplicationSnippet + "):(" + names.RelationSnippet + ")$")
 
 type endpoint struct {
 	application string
@@ -1053,7 +2097,7 @@ func (ep endpoint) less(other endpoint) bool {
 }
 
 func parseEndpoint(ep string) (endpoint, error) {
-	m := validApplicationRelation.FindStringSubmatch(ep)
+	m := validApplicationRelation().FindStringSubmatch(ep)
 	if m != nil {
 		return endpoint{
 			application: m[1],
@@ -1068,48 +2112,34 @@ func parseEndpoint(ep string) (endpoint, error) {
 	}, nil
 }
 
-// endpointInfo holds information about one endpoint of a relation.
-type endpointInfo struct {
-	applicationName string
+// Endpoint holds information about one endpoint of a relation:
+// the application it belongs to and the charm relation it uses.
+type Endpoint struct {
+	ApplicationName string
 	Relation
 }
 
 // String returns the unique identifier of the relation endpoint.
-func (ep endpointInfo) String() string {
-	return ep.applicationName + ":" + ep.Name
+func (ep Endpoint) String() string {
+	return ep.ApplicationName + ":" + ep.Name
 }
 
 // canRelateTo returns whether a relation may be established between ep
 // and other.
-func (ep endpointInfo) canRelateTo(other endpointInfo) bool {
-	return ep.applicationName != other.applicationName &&
+func (ep Endpoint) canRelateTo(other Endpoint) bool {
+	return ep.ApplicationName != other.ApplicationName &&
 		ep.Interface == other.Interface &&
-		ep.Role != RolePeer &&
-		counterpartRole(ep.Role) == other.Role
+		ep.Role.CanRelateTo(other.Role)
 }
 
 // endpoint returns the endpoint specifier for ep.
-func (ep endpointInfo) endpoint() endpoint {
+func (ep Endpoint) endpoint() endpoint {
 	return endpoint{
-		application: ep.applicationName,
+		application: ep.ApplicationName,
 		relation:    ep.Name,
 	}
 }
 
-// counterpartRole returns the RelationRole that the given RelationRole
-// can relate to.
-func counterpartRole(r RelationRole) RelationRole {
-	switch r {
-	case RoleProvider:
-		return RoleRequirer
-	case RoleRequirer:
-		return RoleProvider
-	case RolePeer:
-		return RolePeer
-	}
-	panic(fmt.Errorf("unknown relation role %q", r))
-}
-
 type UnitPlacement struct {
 	// ContainerType holds the container type of the new
 	// new unit, or empty if unspecified.
@@ -1138,19 +2168,27 @@ var snippetReplacer = strings.NewReplacer(
 // make the expression easier to comprehend and maintain, we replace
 // symbolic snippet references in the regexp by their actual regexps
 // using snippetReplacer.
-var validPlacement = regexp.MustCompile(
+var validPlacement = lazyRegexp(
 	snippetReplacer.Replace(
 		"^(?:(container):)?(?:(application)(?:/(number))?|(number))$",
 	),
 )
 
+func invalidPlacementSyntax(p string) error {
+	return &PlacementError{
+		Placement: p,
+		Reason:    fmt.Sprintf("invalid placement syntax %q", p),
+	}
+}
+
 // ParsePlacement parses a unit placement directive, as
 // specified in the To clause of an application entry in the
-// applications section of a bundle.
+// applications section of a bundle. An invalid directive results
+// in a *PlacementError.
 func ParsePlacement(p string) (*UnitPlacement, error) {
-	m := validPlacement.FindStringSubmatch(p)
+	m := validPlacement().FindStringSubmatch(p)
 	if m == nil {
-		return nil, fmt.Errorf("invalid placement syntax %q", p)
+		return nil, invalidPlacementSyntax(p)
 	}
 	up := UnitPlacement{
 		ContainerType: m[1],
@@ -1166,50 +2204,66 @@ func ParsePlacement(p string) (*UnitPlacement, error) {
 	}
 	if up.Application == "new" {
 		if up.Unit != -1 {
-			return nil, fmt.Errorf("invalid placement syntax %q", p)
+			return nil, invalidPlacementSyntax(p)
 		}
 		up.Machine, up.Application = "new", ""
 	}
 	return &up, nil
 }
 
+// InferEndpoints returns the endpoints of the relation between ep0 and
+// ep1, each given in the "application" or "application:relation" form
+// used by bundle relations. Missing relation names are inferred from the
+// charm metadata returned by metaFor for each application, which is also
+// used to fill in the interface and role of each endpoint. The juju-info
+// relation implicitly provided by every application is considered, but
+// is only chosen if no other relation is possible.
+//
+// An error is returned if the endpoints cannot be related, or if more
+// than one relation is possible between them.
+func InferEndpoints(ep0, ep1 string, metaFor func(app string) (*Meta, error)) (Endpoint, Endpoint, error) {
+	epSpec0, err := parseEndpoint(ep0)
+	if err != nil {
+		return Endpoint{}, Endpoint{}, errors.Trace(err)
+	}
+	epSpec1, err := parseEndpoint(ep1)
+	if err != nil {
+		return Endpoint{}, Endpoint{}, errors.Trace(err)
+	}
+	return inferEndpoints(epSpec0, epSpec1, metaFor)
+}
+
 // inferEndpoints infers missing relation names from the given endpoint
 // specifications, using the given get function to retrieve charm
-// data if necessary. It returns the fully specified endpoints.
-func inferEndpoints(epSpec0, epSpec1 endpoint, get func(svc string) (*Meta, error)) (endpoint, endpoint, error) {
-	if epSpec0.relation != "" && epSpec1.relation != "" {
-		// The endpoints are already specified explicitly so
-		// there is no need to fetch any charm data to infer
-		// them.
-		return epSpec0, epSpec1, nil
-	}
+// data. It returns the fully specified endpoints.
+func inferEndpoints(epSpec0, epSpec1 endpoint, get func(svc string) (*Meta, error)) (Endpoint, Endpoint, error) {
 	eps0, err := possibleEndpoints(epSpec0, get)
 	if err != nil {
-		return endpoint{}, endpoint{}, err
+		return Endpoint{}, Endpoint{}, err
 	}
 	eps1, err := possibleEndpoints(epSpec1, get)
 	if err != nil {
-		return endpoint{}, endpoint{}, err
+		return Endpoint{}, Endpoint{}, err
 	}
-	var candidates [][]endpointInfo
+	var candidates [][]Endpoint
 	for _, ep0 := range eps0 {
 		for _, ep1 := range eps1 {
 			if ep0.canRelateTo(ep1) {
-				candidates = append(candidates, []endpointInfo{ep0, ep1})
+				candidates = append(candidates, []Endpoint{ep0, ep1})
 			}
 		}
 	}
 	switch len(candidates) {
 	case 0:
-		return endpoint{}, endpoint{}, fmt.Errorf("no relations found")
+		return Endpoint{}, Endpoint{}, fmt.Errorf("no relations found")
 	case 1:
-		return candidates[0][0].endpoint(), candidates[0][1].endpoint(), nil
+		return candidates[0][0], candidates[0][1], nil
 	}
 
 	// There's ambiguity; try discarding implicit relations.
 	filtered := discardImplicitRelations(candidates)
 	if len(filtered) == 1 {
-		return filtered[0][0].endpoint(), filtered[0][1].endpoint(), nil
+		return filtered[0][0], filtered[0][1], nil
 	}
 	// The ambiguity cannot be resolved, so return an error.
 	var keys []string
@@ -1217,12 +2271,12 @@ func inferEndpoints(epSpec0, epSpec1 endpoint, get func(svc string) (*Meta, erro
 		keys = append(keys, fmt.Sprintf("%q", relationKey(cand)))
 	}
 	sort.Strings(keys)
-	return endpoint{}, endpoint{}, fmt.Errorf("ambiguous relation: %s %s could refer to %s",
+	return Endpoint{}, Endpoint{}, fmt.Errorf("ambiguous relation: %s %s could refer to %s",
 		epSpec0, epSpec1, strings.Join(keys, "; "))
 }
 
-func discardImplicitRelations(candidates [][]endpointInfo) [][]endpointInfo {
-	var filtered [][]endpointInfo
+func discardImplicitRelations(candidates [][]Endpoint) [][]Endpoint {
+	var filtered [][]Endpoint
 outer:
 	for _, cand := range candidates {
 		for _, ep := range cand {
@@ -1237,7 +2291,7 @@ outer:
 
 // relationKey returns a string describing the relation defined by
 // endpoints, for use in various contexts (including error messages).
-func relationKey(endpoints []endpointInfo) string {
+func relationKey(endpoints []Endpoint) string {
 	var names []string
 	for _, ep := range endpoints {
 		names = append(names, ep.String())
@@ -1248,17 +2302,17 @@ func relationKey(endpoints []endpointInfo) string {
 
 // possibleEndpoints returns all the endpoints that the given endpoint spec
 // could refer to.
-func possibleEndpoints(epSpec endpoint, get func(svc string) (*Meta, error)) ([]endpointInfo, error) {
+func possibleEndpoints(epSpec endpoint, get func(svc string) (*Meta, error)) ([]Endpoint, error) {
 	meta, err := get(epSpec.application)
 	if err != nil {
 		return nil, err
 	}
 
-	var eps []endpointInfo
+	var eps []Endpoint
 	add := func(r Relation) {
 		if epSpec.relation == "" || epSpec.relation == r.Name {
-			eps = append(eps, endpointInfo{
-				applicationName: epSpec.application,
+			eps = append(eps, Endpoint{
+				ApplicationName: epSpec.application,
 				Relation:        r,
 			})
 		}
@@ -1270,12 +2324,10 @@ func possibleEndpoints(epSpec endpoint, get func(svc string) (*Meta, error)) ([]
 	for _, r := range meta.Requires {
 		add(r)
 	}
-	// Every application implicitly provides a juju-info relation.
-	add(Relation{
-		Name:      "juju-info",
-		Role:      RoleProvider,
-		Interface: "juju-info",
-		Scope:     ScopeGlobal,
-	})
+	// Every application implicitly provides a juju-info relation,
+	// along with any other registered implicit relations.
+	for _, r := range ImplicitRelations() {
+		add(r)
+	}
 	return eps, nil
 }
@@ -4,18 +4,23 @@
 package charm_test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/juju/errors"
 	"github.com/juju/mgo/v3/bson"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
 
 	"github.com/juju/charm/v12"
+	"github.com/juju/charm/v12/charmtest"
 )
 
 type bundleDataSuite struct {
@@ -376,6 +381,65 @@ func (*bundleDataSuite) TestParse(c *gc.C) {
 	}
 }
 
+func (*bundleDataSuite) TestReadStrictApplications(c *gc.C) {
+	for i, test := range []struct {
+		about  string
+		bundle string
+		expect string
+	}{{
+		about: "known fields",
+		bundle: `
+applications:
+  mysql:
+    charm: mysql
+    num_units: 2
+    options:
+      key: value
+`,
+	}, {
+		about: "misspelt field",
+		bundle: `
+applications:
+  mysql:
+    charm: mysql
+    optons:
+      key: value
+`,
+		expect: `application "mysql" field "optons" not valid; did you mean "options"\?`,
+	}, {
+		about: "field with a dash",
+		bundle: `
+services:
+  mysql:
+    charm: mysql
+    num-units: 2
+`,
+		expect: `application "mysql" field "num-units" not valid; did you mean "num_units"\?`,
+	}, {
+		about: "field without a suggestion",
+		bundle: `
+applications:
+  mysql:
+    charm: mysql
+    frobnicate: true
+`,
+		expect: `application "mysql" field "frobnicate" not valid`,
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		bd, err := charm.ReadBundleData(strings.NewReader(test.bundle))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(bd, gc.NotNil)
+
+		_, err = charm.ReadBundleData(strings.NewReader(test.bundle), charm.WithStrictApplications())
+		if test.expect == "" {
+			c.Check(err, jc.ErrorIsNil)
+			continue
+		}
+		c.Check(err, gc.ErrorMatches, test.expect)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
 func (*bundleDataSuite) TestCodecRoundTrip(c *gc.C) {
 	for i, test := range parseTests {
 		if test.expectedErr != "" {
@@ -439,6 +503,172 @@ func (s *bundleDataSuite) TestBSONNilData(c *gc.C) {
 	c.Assert(result["test"], gc.IsNil)
 }
 
+var bundleDataCodecs = []struct {
+	name      string
+	marshal   func(interface{}) ([]byte, error)
+	unmarshal func([]byte, interface{}) error
+}{{
+	name:      "bson",
+	marshal:   bson.Marshal,
+	unmarshal: bson.Unmarshal,
+}, {
+	name:      "json",
+	marshal:   json.Marshal,
+	unmarshal: json.Unmarshal,
+}, {
+	name:      "yaml",
+	marshal:   yaml.Marshal,
+	unmarshal: yaml.Unmarshal,
+}}
+
+func (s *bundleDataSuite) TestCodecFieldNames(c *gc.C) {
+	bd := &charm.BundleData{
+		DefaultBase: "ubuntu@22.04",
+		LegacyServices: map[string]*charm.ApplicationSpec{
+			"mysql": {Charm: "ch:mysql", NumUnits: 1, To: []string{"0"}},
+		},
+		Applications: map[string]*charm.ApplicationSpec{
+			"wordpress": {Charm: "ch:wordpress", NumUnits: 2, Base: "ubuntu@22.04"},
+		},
+		Machines: map[string]*charm.MachineSpec{
+			"0": {Constraints: "mem=2G", Base: "ubuntu@22.04"},
+		},
+		Saas: map[string]*charm.SaasSpec{
+			"logs": {URL: "admin/default.logs"},
+		},
+		Relations:   [][]string{{"wordpress:db", "mysql:server"}},
+		Tags:        []string{"blog"},
+		Description: "a blog",
+	}
+	expect := &charm.BundleData{
+		DefaultBase: "ubuntu@22.04",
+		Applications: map[string]*charm.ApplicationSpec{
+			"mysql":     {Charm: "ch:mysql", NumUnits: 1, To: []string{"0"}},
+			"wordpress": {Charm: "ch:wordpress", NumUnits: 2, Base: "ubuntu@22.04"},
+		},
+		Machines:    bd.Machines,
+		Saas:        bd.Saas,
+		Relations:   bd.Relations,
+		Tags:        bd.Tags,
+		Description: bd.Description,
+	}
+	for _, codec := range bundleDataCodecs {
+		c.Logf("codec %s", codec.name)
+		data, err := codec.marshal(bd)
+		c.Assert(err, jc.ErrorIsNil)
+
+		var fields map[string]interface{}
+		err = codec.unmarshal(data, &fields)
+		c.Assert(err, jc.ErrorIsNil)
+		var keys []string
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		c.Check(keys, jc.SameContents, []string{
+			"applications", "default-base", "machines", "saas", "relations", "tags", "description",
+		})
+
+		var got charm.BundleData
+		err = codec.unmarshal(data, &got)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(&got, jc.DeepEquals, expect)
+	}
+	// Marshaling does not modify the bundle data.
+	c.Assert(bd.LegacyServices, gc.HasLen, 1)
+}
+
+func (s *bundleDataSuite) TestRelationSpecs(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(`
+applications:
+    mysql:
+        charm: ch:mysql
+        num_units: 1
+    wordpress:
+        charm: ch:wordpress
+        num_units: 1
+    haproxy:
+        charm: ch:haproxy
+        num_units: 1
+relations:
+    - ["wordpress:website", "haproxy:reverseproxy"]
+    - provider: mysql:db
+      requirer: wordpress:db
+      via: db-space
+    - {provider: "mysql:db-admin", requirer: "wordpress:db-admin"}
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd.Relations, jc.DeepEquals, [][]string{
+		{"wordpress:website", "haproxy:reverseproxy"},
+		{"mysql:db", "wordpress:db"},
+		{"mysql:db-admin", "wordpress:db-admin"},
+	})
+	c.Assert(bd.RelationSpecs, jc.DeepEquals, []charm.RelationSpec{
+		{Provider: "mysql:db", Requirer: "wordpress:db", Via: "db-space"},
+		{Provider: "mysql:db-admin", Requirer: "wordpress:db-admin"},
+	})
+
+	for _, codec := range bundleDataCodecs {
+		c.Logf("codec %s", codec.name)
+		data, err := codec.marshal(bd)
+		c.Assert(err, jc.ErrorIsNil)
+
+		// Only the relation with a space is written in map form.
+		var fields struct {
+			Relations []interface{} `bson:"relations" json:"relations" yaml:"relations"`
+		}
+		err = codec.unmarshal(data, &fields)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(fields.Relations, gc.HasLen, 3)
+		c.Check(fields.Relations[0], gc.FitsTypeOf, []interface{}{})
+		c.Check(fields.Relations[1], gc.Not(gc.FitsTypeOf), []interface{}{})
+		c.Check(fields.Relations[2], gc.FitsTypeOf, []interface{}{})
+
+		var got charm.BundleData
+		err = codec.unmarshal(data, &got)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(got.Relations, jc.DeepEquals, bd.Relations)
+		c.Check(got.RelationSpecs, jc.DeepEquals, bd.RelationSpecs[:1])
+	}
+}
+
+func (s *bundleDataSuite) TestVerifyRelationSpecSpace(c *gc.C) {
+	data := `
+applications:
+    mysql:
+        charm: mysql
+        num_units: 1
+    wordpress:
+        charm: wordpress
+        num_units: 1
+relations:
+    - provider: mysql:server
+      requirer: wordpress:db
+      via: "Not A Space"
+`
+	assertVerifyErrors(c, data, nil, []string{
+		`relation ["mysql:server" "wordpress:db"] declares an invalid space "Not A Space"`,
+	})
+}
+
+func (s *bundleDataSuite) TestJSONLegacyFieldNames(c *gc.C) {
+	var bd charm.BundleData
+	err := json.Unmarshal([]byte(`{
+		"applications": {"mysql": {"Charm": "ch:mysql", "NumUnits": 3, "To": ["0"]}},
+		"Machines": {"0": {"Constraints": "mem=2G"}},
+		"Description": "legacy"
+	}`), &bd)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(&bd, jc.DeepEquals, &charm.BundleData{
+		Applications: map[string]*charm.ApplicationSpec{
+			"mysql": {Charm: "ch:mysql", NumUnits: 3, To: []string{"0"}},
+		},
+		Machines: map[string]*charm.MachineSpec{
+			"0": {Constraints: "mem=2G"},
+		},
+		Description: "legacy",
+	})
+}
+
 var verifyErrorsTests = []struct {
 	about  string
 	data   string
@@ -526,6 +756,7 @@ relations:
 	errors: []string{
 		`bundle declares an invalid series "9wrong"`,
 		`bundle declares an invalid base "invalidbase"`,
+		`bundle declares both series "9wrong" and default-base "invalidbase"`,
 		`invalid offer URL "!some-bogus/url" for SAAS apache2`,
 		`invalid storage name "no_underscores" in application "ceph"`,
 		`invalid storage "invalid-storage" in application "ceph-osd": bad storage constraint`,
@@ -551,6 +782,7 @@ relations:
 		`invalid relation syntax "mediawiki/db"`,
 		`invalid series "bad series" for machine "0"`,
 		`invalid base "bad base" for machine "0"`,
+		`machine "0" declares both series "bad series" and base "bad base"`,
 		`ambiguous relation "riak" refers to a application and a SAAS in this bundle`,
 		`SAAS "riak" already exists with application "riak" name`,
 		`application "riak" already exists with SAAS "riak" name`,
@@ -702,6 +934,105 @@ func (*bundleDataSuite) TestVerifyLocalCharm(c *gc.C) {
 	}
 }
 
+func (*bundleDataSuite) TestVerifyLocalCharmArchive(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(mediawikiBundle))
+	c.Assert(err, gc.IsNil)
+	bundleDir := c.MkDir()
+	err = os.MkdirAll(filepath.Join(bundleDir, "builds"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	f, err := os.Create(filepath.Join(bundleDir, "builds", "mediawiki.charm"))
+	c.Assert(err, jc.ErrorIsNil)
+	err = (&charmtest.Charm{Metadata: "name: mediawiki\nsummary: s\ndescription: d\n"}).WriteArchive(f)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(f.Close(), jc.ErrorIsNil)
+	err = os.WriteFile(filepath.Join(bundleDir, "builds", "broken.charm"), []byte("not a zip"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	absArchive := filepath.Join(bundleDir, "builds", "mediawiki.charm")
+
+	for i, u := range []string{
+		"./builds/mediawiki.charm",
+		absArchive,
+		"file://" + filepath.ToSlash(absArchive),
+		"file://localhost" + filepath.ToSlash(absArchive),
+	} {
+		c.Logf("test %d: %s", i, u)
+		bd.Applications["mediawiki"].Charm = u
+		err := bd.VerifyLocal(bundleDir, nil, nil, nil)
+		c.Check(err, jc.ErrorIsNil)
+	}
+
+	for i, test := range []struct {
+		charm string
+		err   string
+	}{{
+		charm: "./builds/broken.charm",
+		err:   `charm archive in application "mediawiki" is not a readable zip file: .*`,
+	}, {
+		charm: "file://" + filepath.ToSlash(filepath.Join(bundleDir, "missing.charm")),
+		err:   `charm path in application "mediawiki" does not exist: .*missing.charm`,
+	}, {
+		charm: "file://example.com/mediawiki.charm",
+		err:   `invalid charm path in application "mediawiki": file URL "file://example.com/mediawiki.charm" with host "example.com" not valid`,
+	}, {
+		charm: "file:builds/mediawiki.charm",
+		err:   `invalid charm path in application "mediawiki": file URL "file:builds/mediawiki.charm" without an absolute path not valid`,
+	}} {
+		c.Logf("test %d: %s", i, test.charm)
+		bd.Applications["mediawiki"].Charm = test.charm
+		err := bd.VerifyLocal(bundleDir, nil, nil, nil)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (*bundleDataSuite) TestVerifyLocalWithResources(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(mediawikiBundle))
+	c.Assert(err, gc.IsNil)
+	bundleDir := c.MkDir()
+	err = os.WriteFile(filepath.Join(bundleDir, "image.yaml"), []byte("registrypath: example/image\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	// The mysql application uses resources/data.tar.
+	err = os.MkdirAll(filepath.Join(bundleDir, "resources"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.WriteFile(filepath.Join(bundleDir, "resources", "data.tar"), nil, 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	absFile := filepath.Join(c.MkDir(), "data.tar")
+	err = os.WriteFile(absFile, nil, 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	bd.Applications["mediawiki"].Resources = map[string]interface{}{
+		"image": "./image.yaml",
+		"data":  absFile,
+		"rev":   3,
+	}
+	err = bd.VerifyLocalWithResources(bundleDir, nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	bd.Applications["mediawiki"].Resources = map[string]interface{}{
+		"image":  "./missing.yaml",
+		"data":   bundleDir,
+		"rev":    -1,
+		"empty":  "",
+		"ignore": 1,
+	}
+	// Plain verification does not check resources.
+	err = bd.VerifyLocal(bundleDir, nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = bd.VerifyLocalWithResources(bundleDir, nil, nil, nil)
+	c.Assert(err, gc.FitsTypeOf, (*charm.VerificationError)(nil))
+	var messages []string
+	for _, e := range err.(*charm.VerificationError).Errors {
+		c.Check(e, jc.ErrorIs, charm.CodeInvalidResource)
+		messages = append(messages, e.Error())
+	}
+	c.Assert(messages, jc.SameContents, []string{
+		`resource "image" path in application "mediawiki" does not exist: ` + filepath.Join(bundleDir, "missing.yaml"),
+		`resource "data" path in application "mediawiki" is a directory: ` + bundleDir,
+		`negative revision -1 for resource "rev" on application "mediawiki"`,
+		`empty path for resource "empty" on application "mediawiki"`,
+	})
+}
+
 func (s *bundleDataSuite) TestVerifyBundleUsingJujuInfoRelation(c *gc.C) {
 	err := s.testPrepareAndMutateBeforeVerifyWithCharms(c, nil)
 	c.Assert(err, gc.IsNil)
@@ -724,6 +1055,61 @@ func (s *bundleDataSuite) testPrepareAndMutateBeforeVerifyWithCharms(c *gc.C, mu
 	return bd.VerifyWithCharms(nil, nil, nil, charms)
 }
 
+func (s *bundleDataSuite) TestVerifyWithCharmResolver(c *gc.C) {
+	bd := readBundleDir(c, "wordpress-with-logging").Data()
+	charms := map[string]charm.Charm{
+		"ch:wordpress": readCharmDir(c, "wordpress"),
+		"ch:mysql":     readCharmDir(c, "mysql"),
+		"ch:logging":   readCharmDir(c, "logging"),
+	}
+	var resolved []string
+	resolver := func(url string) (charm.Charm, error) {
+		resolved = append(resolved, url)
+		if ch, ok := charms[url]; ok {
+			return ch, nil
+		}
+		return nil, errors.NotFoundf("charm %q", url)
+	}
+	err := bd.VerifyWithCharmResolver(resolver, nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	// The bundle refers to the logging charm as "logging".
+	c.Assert(resolved, jc.SameContents, []string{"ch:wordpress", "ch:mysql", "ch:logging"})
+
+	// Charms the resolver cannot find are reported as verification errors.
+	delete(charms, "ch:mysql")
+	err = bd.VerifyWithCharmResolver(resolver, nil, nil, nil)
+	c.Assert(err, gc.ErrorMatches, `application "mysql" refers to non-existent charm "ch:mysql"`)
+}
+
+func (s *bundleDataSuite) TestVerifyWithCharmResolverSharedCharm(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(`
+applications:
+  db1:
+    charm: mysql
+  db2:
+    charm: ch:mysql
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	calls := 0
+	err = bd.VerifyWithCharmResolver(func(url string) (charm.Charm, error) {
+		calls++
+		c.Check(url, gc.Equals, "ch:mysql")
+		return readCharmDir(c, "mysql"), nil
+	}, nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(calls, gc.Equals, 1)
+}
+
+func (s *bundleDataSuite) TestVerifyWithCharmResolverError(c *gc.C) {
+	bd := readBundleDir(c, "wordpress-with-logging").Data()
+	err := bd.VerifyWithCharmResolver(func(url string) (charm.Charm, error) {
+		return nil, errors.New("store unavailable")
+	}, nil, nil, nil)
+	c.Assert(err, gc.ErrorMatches, `cannot resolve charm ".*": store unavailable`)
+	_, ok := err.(*charm.VerificationError)
+	c.Assert(ok, jc.IsFalse)
+}
+
 func (s *bundleDataSuite) TestVerifyBundleWithUnknownEndpointBindingGiven(c *gc.C) {
 	err := s.testPrepareAndMutateBeforeVerifyWithCharms(c, func(bd *charm.BundleData) {
 		bd.Applications["wordpress"].EndpointBindings["foo"] = "bar"
@@ -812,6 +1198,89 @@ applications:
 	c.Assert(err, gc.ErrorMatches, `bundle has an invalid type "foo"`)
 }
 
+func (s *bundleDataSuite) TestParseMachineBundleType(c *gc.C) {
+	data := `
+bundle: machine
+
+applications:
+    mysql:
+        charm: mysql
+        num_units: 2
+        to: ["0", "new"]
+machines:
+    0:
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd.Type, gc.Equals, "machine")
+	c.Assert(bd.Applications["mysql"].NumUnits, gc.Equals, 2)
+}
+
+func (s *bundleDataSuite) TestInvalidMachineBundleScale(c *gc.C) {
+	data := `
+bundle: machine
+
+applications:
+    mysql:
+        charm: mysql
+        scale: 2
+`
+	_, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.ErrorMatches, `.*scale not valid for machine application "mysql", use num_units`)
+}
+
+func (s *bundleDataSuite) TestVerifyCharmPlatform(c *gc.C) {
+	withMeta := func(name string, f func(*charm.Meta)) charm.Charm {
+		ch := testCharm(name, "").(testCharmImpl)
+		f(ch.meta)
+		return ch
+	}
+	charms := map[string]charm.Charm{
+		"machine": withMeta("machine", func(meta *charm.Meta) {
+			meta.Series = []string{"jammy"}
+		}),
+		"sidecar": withMeta("sidecar", func(meta *charm.Meta) {
+			meta.Containers = map[string]charm.Container{"workload": {Resource: "image"}}
+		}),
+		"podspec": withMeta("podspec", func(meta *charm.Meta) {
+			meta.Series = []string{"kubernetes"}
+		}),
+		"any": testCharm("any", ""),
+	}
+
+	assertVerifyErrors(c, `
+bundle: kubernetes
+applications:
+    machine:
+        charm: machine
+    sidecar:
+        charm: sidecar
+    podspec:
+        charm: podspec
+    any:
+        charm: any
+`, charms, []string{
+		`application "machine": machine charm "machine" not valid for Kubernetes bundles`,
+	})
+
+	assertVerifyErrors(c, `
+applications:
+    machine:
+        charm: machine
+    sidecar:
+        charm: sidecar
+    podspec:
+        charm: podspec
+    any:
+        charm: any
+`, charms, []string{
+		`application "sidecar": Kubernetes charm "sidecar" not valid for machine bundles`,
+		`application "podspec": Kubernetes charm "podspec" not valid for machine bundles`,
+	})
+}
+
 func (s *bundleDataSuite) TestInvalidScaleAndNumUnits(c *gc.C) {
 	data := `
 bundle: kubernetes
@@ -870,9 +1339,21 @@ applications:
     hadoop:
         charm: "hadoop-k8s"
         to: ["foo"]
+    kafka:
+        charm: "kafka-k8s"
+        to: ["lxd:0"]
+    zookeeper:
+        charm: "zookeeper-k8s"
+        to: ["kafka/0"]
+    spark:
+        charm: "spark-k8s"
+        scale: -1
 `
 	errors := []string{
 		`expected "key=value", got "foo" for application "hadoop"`,
+		`machine placement "lxd:0" not valid for Kubernetes application "kafka"`,
+		`machine placement "kafka/0" not valid for Kubernetes application "zookeeper"`,
+		`negative number of units specified on application "spark"`,
 		`bundle machines not valid for Kubernetes bundles`,
 		`too many placement directives for application "casandra"`,
 	}
@@ -888,6 +1369,127 @@ func (*bundleDataSuite) TestRequiredCharms(c *gc.C) {
 	c.Assert(reqCharms, gc.DeepEquals, []string{"mediawiki", "mysql"})
 }
 
+func (*bundleDataSuite) TestRequiredBases(c *gc.C) {
+	data := `
+default-base: ubuntu@22.04
+applications:
+    wordpress:
+        charm: ch:wordpress
+    mysql:
+        charm: ch:mysql
+        base: ubuntu@20.04
+    legacy:
+        charm: ch:legacy
+        series: bionic
+    other:
+        charm: ch:other
+        base: ubuntu@20.04
+    empty:
+machines:
+    0:
+        base: centos@7
+    1:
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	c.Assert(bd.RequiredBases(), jc.DeepEquals, []string{"centos@7", "ubuntu@18.04", "ubuntu@20.04", "ubuntu@22.04"})
+
+	bd, err = charm.ReadBundleData(strings.NewReader(mediawikiBundle))
+	c.Assert(err, gc.IsNil)
+	c.Assert(bd.RequiredBases(), jc.DeepEquals, []string{"ubuntu@20.04", "ubuntu@22.04"})
+}
+
+func (*bundleDataSuite) TestEffectiveBase(c *gc.C) {
+	data := `
+default-base: ubuntu@22.04
+applications:
+    wordpress:
+        charm: ch:wordpress
+        to: ["0"]
+    mysql:
+        charm: ch:mysql
+        base: ubuntu@20.04
+        to: ["0"]
+    legacy:
+        charm: ch:legacy
+        series: bionic
+    container:
+        charm: ch:container
+        to: ["lxd:0"]
+    default:
+        charm: ch:default
+machines:
+    0:
+        base: centos@7
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	for app, expect := range map[string]string{
+		"wordpress": "centos@7/stable",
+		"mysql":     "ubuntu@20.04/stable",
+		"legacy":    "ubuntu@18.04/stable",
+		"container": "ubuntu@22.04/stable",
+		"default":   "ubuntu@22.04/stable",
+	} {
+		base, ok := bd.EffectiveBase(app)
+		c.Check(ok, jc.IsTrue, gc.Commentf("%s", app))
+		c.Check(base.String(), gc.Equals, expect, gc.Commentf("%s", app))
+	}
+	_, ok := bd.EffectiveBase("missing")
+	c.Assert(ok, jc.IsFalse)
+
+	bd.DefaultBase = ""
+	_, ok = bd.EffectiveBase("default")
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (*bundleDataSuite) TestVerifySeriesAndBase(c *gc.C) {
+	data := `
+default-base: ubuntu@22.04
+series: jammy
+applications:
+    mysql:
+        charm: ch:mysql
+        base: ubuntu@20.04
+        series: focal
+        num_units: 1
+        to: ["0"]
+machines:
+    0:
+        base: ubuntu@20.04
+        series: focal
+`
+	assertVerifyErrors(c, data, nil, []string{
+		`bundle declares both series "jammy" and default-base "ubuntu@22.04"`,
+		`application "mysql" declares both series "focal" and base "ubuntu@20.04"`,
+		`machine "0" declares both series "focal" and base "ubuntu@20.04"`,
+	})
+}
+
+func (*bundleDataSuite) TestRequiredResources(c *gc.C) {
+	data := `
+applications:
+    wordpress:
+        charm: ch:wordpress
+        resources:
+            theme: 3
+            plugins: ./plugins.tgz
+    blog:
+        charm: ch:wordpress
+        resources:
+            theme: 4
+            logo: 1
+    mysql:
+        charm: ch:mysql
+    empty:
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	c.Assert(bd.RequiredResources(), jc.DeepEquals, map[string][]string{
+		"ch:wordpress": {"logo", "plugins", "theme"},
+	})
+}
+
 // testCharm returns a charm with the given name
 // and relations. The relations are specified as
 // a string of the form:
@@ -966,10 +1568,11 @@ func parseRelations(s string, role charm.RelationRole) map[string]charm.Relation
 }
 
 type testCharmImpl struct {
-	meta   *charm.Meta
-	config *charm.Config
+	meta     *charm.Meta
+	config   *charm.Config
+	manifest *charm.Manifest
 	// Implement charm.Charm, but panic if anything other than
-	// Meta or Config methods are called.
+	// Meta, Config or Manifest methods are called.
 	charm.Charm
 }
 
@@ -977,6 +1580,10 @@ func (c testCharmImpl) Meta() *charm.Meta {
 	return c.meta
 }
 
+func (c testCharmImpl) Manifest() *charm.Manifest {
+	return c.manifest
+}
+
 func (c testCharmImpl) Config() *charm.Config {
 	return c.config
 }
@@ -1392,6 +1999,20 @@ applications:
 	},
 }}
 
+func (*bundleDataSuite) TestVerifyContextCancelled(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(mediawikiBundle))
+	c.Assert(err, gc.IsNil)
+	err = bd.VerifyWithCharmsContext(context.Background(), nil, nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = bd.VerifyWithCharmsContext(ctx, nil, nil, nil, nil)
+	c.Assert(errors.Cause(err), gc.Equals, context.Canceled)
+	err = bd.VerifyLocalContext(ctx, c.MkDir(), nil, nil, nil)
+	c.Assert(errors.Cause(err), gc.Equals, context.Canceled)
+}
+
 func (*bundleDataSuite) TestVerifyWithCharmsErrors(c *gc.C) {
 	for i, test := range verifyWithCharmsErrorsTests {
 		c.Logf("test %d: %s", i, test.about)
@@ -1557,3 +2178,493 @@ relations:
 	})
 
 }
+
+func (*bundleDataSuite) TestNormalize(c *gc.C) {
+	data := `
+series: focal
+services:
+    wordpress:
+        charm: wordpress
+        series: jammy
+        to: ["0", "1"]
+    mysql:
+        charm: mysql
+        num_units: 1
+        base: ubuntu@20.04
+        series: bionic
+    logging:
+        charm: logging
+        series: win2012
+machines:
+    "0":
+        series: jammy
+    "1":
+relations:
+    - ["logging:info", "wordpress:juju-info", "mysql:juju-info"]
+    - ["wordpress:db", "mysql:server"]
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	c.Assert(bd.Applications, gc.HasLen, 0)
+	c.Assert(bd.LegacyServices, gc.HasLen, 3)
+
+	err = bd.Normalize()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd, jc.DeepEquals, &charm.BundleData{
+		DefaultBase: "ubuntu@20.04",
+		Applications: map[string]*charm.ApplicationSpec{
+			"wordpress": {
+				Charm:    "wordpress",
+				Base:     "ubuntu@22.04",
+				NumUnits: 2,
+				To:       []string{"0", "1"},
+			},
+			"mysql": {
+				Charm:    "mysql",
+				NumUnits: 1,
+				Base:     "ubuntu@20.04",
+				Series:   "bionic",
+			},
+			"logging": {
+				Charm:  "logging",
+				Series: "win2012",
+			},
+		},
+		Machines: map[string]*charm.MachineSpec{
+			"0": {Base: "ubuntu@22.04"},
+			"1": nil,
+		},
+		Relations: [][]string{
+			{"logging:info", "wordpress:juju-info"},
+			{"logging:info", "mysql:juju-info"},
+			{"wordpress:db", "mysql:server"},
+		},
+	})
+
+	// Normalizing again makes no further changes.
+	before := *bd
+	err = bd.Normalize()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*bd, jc.DeepEquals, before)
+}
+
+func (*bundleDataSuite) TestNormalizeDuplicateLegacyService(c *gc.C) {
+	data := `
+applications:
+    wordpress:
+        charm: wordpress
+services:
+    wordpress:
+        charm: wordpress
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	err = bd.Normalize()
+	c.Assert(err, gc.ErrorMatches, `application "wordpress" defined in both applications and services`)
+}
+
+func (*bundleDataSuite) TestVerifyErrorCodes(c *gc.C) {
+	data := `
+applications:
+    wordpress:
+        charm: wordpress
+        num_units: 1
+        to: ["mysql/3"]
+    mysql:
+        charm: "bad charm"
+relations:
+    - ["wordpress:db", "memcached:cache"]
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIs, charm.CodeInvalidPlacement)
+	c.Assert(err, jc.ErrorIs, charm.CodeInvalidCharm)
+	c.Assert(err, jc.ErrorIs, charm.CodeInvalidRelation)
+	c.Assert(errors.Is(err, charm.CodeInvalidMachine), jc.IsFalse)
+
+	var placementErr *charm.PlacementError
+	c.Assert(errors.As(err, &placementErr), jc.IsTrue)
+	c.Assert(placementErr.Placement, gc.Equals, "mysql/3")
+
+	var urlErr *charm.URLParseError
+	c.Assert(errors.As(err, &urlErr), jc.IsTrue)
+	c.Assert(urlErr.URL, gc.Equals, "bad charm")
+
+	for _, e := range err.(*charm.VerificationError).Errors {
+		var coded *charm.CodedError
+		c.Assert(errors.As(e, &coded), jc.IsTrue, gc.Commentf("%v", e))
+	}
+}
+
+func (*bundleDataSuite) TestParsePlacementError(c *gc.C) {
+	_, err := charm.ParsePlacement("new/0")
+	c.Assert(err, gc.ErrorMatches, `invalid placement syntax "new/0"`)
+	c.Assert(err, gc.FitsTypeOf, (*charm.PlacementError)(nil))
+	c.Assert(err.(*charm.PlacementError).Placement, gc.Equals, "new/0")
+}
+
+var expandedPlacementsTests = []struct {
+	about    string
+	numUnits int
+	to       []string
+	expect   []charm.UnitPlacement
+}{{
+	about: "no units",
+}, {
+	about:    "no directives",
+	numUnits: 2,
+	expect: []charm.UnitPlacement{
+		{Machine: "new", Unit: -1},
+		{Machine: "new", Unit: -1},
+	},
+}, {
+	about:    "last directive replicated",
+	numUnits: 4,
+	to:       []string{"wordpress/1", "lxd:0", "kvm:new"},
+	expect: []charm.UnitPlacement{
+		{Application: "wordpress", Unit: 1},
+		{ContainerType: "lxd", Machine: "0", Unit: -1},
+		{ContainerType: "kvm", Machine: "new", Unit: -1},
+		{ContainerType: "kvm", Machine: "new", Unit: -1},
+	},
+}, {
+	about:    "application units numbered in turn",
+	numUnits: 4,
+	to:       []string{"mysql", "wordpress/3", "wordpress"},
+	expect: []charm.UnitPlacement{
+		{Application: "mysql", Unit: 0},
+		{Application: "wordpress", Unit: 3},
+		{Application: "wordpress", Unit: 4},
+		{Application: "wordpress", Unit: 5},
+	},
+}, {
+	about:    "more directives than units",
+	numUnits: 1,
+	to:       []string{"0", "1"},
+	expect: []charm.UnitPlacement{
+		{Machine: "0", Unit: -1},
+		{Machine: "1", Unit: -1},
+	},
+}}
+
+func (*bundleDataSuite) TestExpandedPlacements(c *gc.C) {
+	for i, test := range expandedPlacementsTests {
+		c.Logf("test %d: %s", i, test.about)
+		spec := &charm.ApplicationSpec{NumUnits: test.numUnits, To: test.to}
+		placements, err := spec.ExpandedPlacements()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(placements, jc.DeepEquals, test.expect)
+	}
+}
+
+func (*bundleDataSuite) TestExpandedPlacementsError(c *gc.C) {
+	spec := &charm.ApplicationSpec{NumUnits: 2, To: []string{"0", "new/0"}}
+	_, err := spec.ExpandedPlacements()
+	c.Assert(err, gc.ErrorMatches, `invalid placement syntax "new/0"`)
+	c.Assert(err, gc.FitsTypeOf, (*charm.PlacementError)(nil))
+}
+
+func (*bundleDataSuite) TestVerifyRelationLimits(c *gc.C) {
+	data := `
+applications:
+    wordpress:
+        charm: ch:wordpress
+    mysql:
+        charm: ch:mysql
+    mysql-replica:
+        charm: ch:mysql
+relations:
+    - ["wordpress:db", "mysql:server"]
+    - ["wordpress", "mysql-replica"]
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	charms := map[string]charm.Charm{
+		"ch:wordpress": readCharmDir(c, "wordpress"),
+		"ch:mysql":     readCharmDir(c, "mysql"),
+	}
+	err = bd.VerifyWithCharms(nil, nil, nil, charms)
+	c.Assert(err, gc.ErrorMatches, `endpoint "wordpress:db" is used by 2 relations, exceeding its limit of 1`)
+	c.Assert(err, jc.ErrorIs, charm.CodeInvalidRelation)
+
+	// Relations to an endpoint without a limit are unrestricted.
+	bd.Applications["other"] = &charm.ApplicationSpec{Charm: "ch:wordpress"}
+	bd.Relations = [][]string{
+		{"wordpress:db", "mysql:server"},
+		{"other:db", "mysql:server"},
+	}
+	err = bd.VerifyWithCharms(nil, nil, nil, charms)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (*bundleDataSuite) TestInferEndpoints(c *gc.C) {
+	metas := map[string]*charm.Meta{
+		"wordpress": readCharmDir(c, "wordpress").Meta(),
+		"mysql":     readCharmDir(c, "mysql").Meta(),
+		"logging":   readCharmDir(c, "logging").Meta(),
+	}
+	metas["mysql-client"] = &charm.Meta{
+		Name: "mysql-client",
+		Requires: map[string]charm.Relation{
+			"db":     {Name: "db", Role: charm.RoleRequirer, Interface: "mysql", Scope: charm.ScopeGlobal},
+			"backup": {Name: "backup", Role: charm.RoleRequirer, Interface: "mysql", Scope: charm.ScopeGlobal},
+		},
+	}
+	metaFor := func(app string) (*charm.Meta, error) {
+		meta, ok := metas[app]
+		if !ok {
+			return nil, errors.NotFoundf("application %q", app)
+		}
+		return meta, nil
+	}
+
+	ep0, ep1, err := charm.InferEndpoints("wordpress", "mysql", metaFor)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ep0.String(), gc.Equals, "wordpress:db")
+	c.Assert(ep0.Interface, gc.Equals, "mysql")
+	c.Assert(ep0.Role, gc.Equals, charm.RoleRequirer)
+	c.Assert(ep1.String(), gc.Equals, "mysql:server")
+	c.Assert(ep1.Role, gc.Equals, charm.RoleProvider)
+
+	// The implicit juju-info relation is used when nothing else fits.
+	ep0, ep1, err = charm.InferEndpoints("logging", "mysql", metaFor)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ep0.String(), gc.Equals, "logging:info")
+	c.Assert(ep1.String(), gc.Equals, "mysql:juju-info")
+	c.Assert(ep1.Interface, gc.Equals, "juju-info")
+
+	// Explicit relations are preferred over the implicit one.
+	ep0, ep1, err = charm.InferEndpoints("logging", "wordpress", metaFor)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ep0.String(), gc.Equals, "logging:logging-directory")
+	c.Assert(ep1.String(), gc.Equals, "wordpress:logging-dir")
+
+	// Fully specified endpoints are still checked against the charms.
+	ep0, ep1, err = charm.InferEndpoints("mysql:server", "wordpress:db", metaFor)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ep0.String(), gc.Equals, "mysql:server")
+	c.Assert(ep1.String(), gc.Equals, "wordpress:db")
+	_, _, err = charm.InferEndpoints("mysql:server", "wordpress:cache", metaFor)
+	c.Assert(err, gc.ErrorMatches, "no relations found")
+
+	_, _, err = charm.InferEndpoints("mysql", "mysql-client", metaFor)
+	c.Assert(err, gc.ErrorMatches, `ambiguous relation: mysql mysql-client could refer to "mysql-client:backup mysql:server"; "mysql-client:db mysql:server"`)
+
+	_, _, err = charm.InferEndpoints("mysql", "unknown", metaFor)
+	c.Assert(err, jc.ErrorIs, errors.NotFound)
+
+	_, _, err = charm.InferEndpoints("mysql:", "wordpress", metaFor)
+	c.Assert(err, gc.ErrorMatches, `invalid relation syntax "mysql:"`)
+}
+
+func (*bundleDataSuite) TestVerifyDevicesWithCharms(c *gc.C) {
+	data := `
+applications:
+    miner:
+        charm: ch:miner
+        num_units: 1
+        devices:
+            gpu: 2,nvidia.com/gpu
+            accel: 1,gpu
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	charms := map[string]charm.Charm{
+		"ch:miner": testCharmImpl{
+			meta: &charm.Meta{
+				Name: "miner",
+				Devices: map[string]charm.Device{
+					"gpu":   {Name: "gpu", Type: "nvidia.com/gpu", CountMin: 1, CountMax: 2},
+					"accel": {Name: "accel", Type: "gpu", CountMin: 1, CountMax: -1},
+					"tpu":   {Name: "tpu", Type: "tpu", CountMin: 2, CountMax: -1},
+				},
+			},
+			config: charm.NewConfig(),
+		},
+	}
+	err = bd.VerifyWithCharms(nil, nil, nil, charms)
+	c.Assert(err, jc.ErrorIsNil)
+
+	bd.Applications["miner"].Devices = map[string]string{
+		"gpu":   "3,amd.com/gpu",
+		"accel": "gpu,bad",
+		"tpu":   "1,tpu",
+		"other": "gpu",
+	}
+	err = bd.VerifyWithCharms(nil, nil, nil, charms)
+	c.Assert(err, jc.ErrorIs, charm.CodeInvalidDevices)
+	var verr *charm.VerificationError
+	c.Assert(errors.As(err, &verr), jc.IsTrue)
+	var msgs []string
+	for _, e := range verr.Errors {
+		msgs = append(msgs, e.Error())
+	}
+	c.Assert(msgs, jc.SameContents, []string{
+		`device "gpu" in application "miner" has type "amd.com/gpu", but charm "miner" requires "nvidia.com/gpu"`,
+		`device "gpu" in application "miner" requests 3 devices, but charm "miner" requires between 1 and 2`,
+		`device "tpu" in application "miner" requests 1 devices, but charm "miner" requires at least 2`,
+		`invalid device "accel" in application "miner": device attribute "bad" in "gpu,bad" not valid`,
+		`application "miner" specifies device "other" which is not defined by charm "miner"`,
+	})
+
+	// Devices rejected by the verifyDevices function are not checked
+	// against the charm.
+	err = bd.VerifyWithCharms(nil, nil, func(string) error {
+		return errors.New("bad device")
+	}, charms)
+	c.Assert(errors.As(err, &verr), jc.IsTrue)
+	msgs = nil
+	for _, e := range verr.Errors {
+		msgs = append(msgs, e.Error())
+	}
+	c.Assert(msgs, jc.SameContents, []string{
+		`invalid device "gpu" in application "miner": bad device`,
+		`invalid device "accel" in application "miner": bad device`,
+		`invalid device "tpu" in application "miner": bad device`,
+		`invalid device "other" in application "miner": bad device`,
+	})
+}
+
+func (*bundleDataSuite) TestVerifyMachinePlacementBases(c *gc.C) {
+	data := `
+applications:
+    dummy:
+        charm: ch:dummy
+        num_units: 3
+        to: ["0", "lxd:1", "kvm:2"]
+    legacy:
+        charm: ch:legacy
+        num_units: 1
+        to: ["0"]
+machines:
+    0:
+        base: ubuntu@20.04
+    1:
+        base: ubuntu@22.04
+    2:
+        series: bionic
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	charms := map[string]charm.Charm{
+		"ch:dummy": readCharmDir(c, "dummy"),
+		"ch:legacy": testCharmImpl{
+			meta:   &charm.Meta{Name: "legacy", Series: []string{"focal", "jammy"}},
+			config: charm.NewConfig(),
+		},
+	}
+	err = bd.VerifyWithCharms(nil, nil, nil, charms)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The charm must support the base of the machine its units are
+	// placed on, unless they are placed in a container.
+	bd.Machines["0"].Base = "ubuntu@22.04"
+	bd.Machines["1"].Base = "ubuntu@18.04"
+	err = bd.VerifyWithCharms(nil, nil, nil, charms)
+	c.Assert(err, gc.ErrorMatches, `placement "0" puts application "dummy" on machine "0" with base "ubuntu@22.04", which is not supported by charm "ch:dummy"`)
+	c.Assert(err, jc.ErrorIs, charm.CodeInvalidPlacement)
+	var placementErr *charm.PlacementError
+	c.Assert(errors.As(err, &placementErr), jc.IsTrue)
+	c.Assert(placementErr.Placement, gc.Equals, "0")
+
+	// An application declaring its own base must match the machine.
+	bd.Machines["0"].Base = "ubuntu@20.04"
+	bd.Applications["legacy"].Series = "jammy"
+	err = bd.VerifyWithCharms(nil, nil, nil, charms)
+	c.Assert(err, gc.ErrorMatches, `placement "0" puts application "legacy" with base "ubuntu@22.04" on machine "0" with base "ubuntu@20.04"; place it in a container or use a machine with a matching base`)
+	bd.Applications["legacy"].Series = ""
+
+	// Containers can only be hosted by Linux machines.
+	bd.Machines["1"].Base = "windows@win10"
+	err = bd.VerifyWithCharms(nil, nil, nil, charms)
+	c.Assert(err, gc.ErrorMatches, `placement "lxd:1" for application "dummy" puts a lxd container on machine "1", but machines with base "windows@win10" cannot host containers`)
+}
+
+func (*bundleDataSuite) TestVerifyPlacementContainerType(c *gc.C) {
+	data := `
+applications:
+    mysql:
+        charm: ch:mysql
+        num_units: 2
+        to: ["lxc:0", "docker:new"]
+machines:
+    0:
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIs, charm.CodeInvalidPlacement)
+	// The legacy "lxc" container type is accepted as "lxd".
+	c.Assert(err.(*charm.VerificationError).Errors, jc.SameContents, []error{
+		&charm.CodedError{Code: charm.CodeInvalidPlacement, Err: &charm.PlacementError{
+			Placement: "docker:new",
+			Reason:    `placement "docker:new": container type "docker" not valid, expected one of kvm, lxd`,
+		}},
+	})
+}
+
+func (*bundleDataSuite) TestValidateContainerType(c *gc.C) {
+	for _, t := range []string{"lxd", "kvm", "lxc"} {
+		c.Check(charm.IsValidContainerType(t), jc.IsTrue)
+		c.Check(charm.ValidateContainerType(t), jc.ErrorIsNil)
+	}
+	c.Check(charm.NormaliseContainerType("lxc"), gc.Equals, "lxd")
+	c.Check(charm.NormaliseContainerType("kvm"), gc.Equals, "kvm")
+	c.Check(charm.NormaliseContainerType("docker"), gc.Equals, "docker")
+	for t, msg := range map[string]string{
+		"docker": `container type "docker" not valid, expected one of kvm, lxd`,
+		"":       `container type "" not valid, expected one of kvm, lxd`,
+	} {
+		c.Check(charm.IsValidContainerType(t), jc.IsFalse)
+		err := charm.ValidateContainerType(t)
+		c.Check(err, gc.ErrorMatches, msg)
+		c.Check(err, jc.ErrorIs, errors.NotValid)
+	}
+}
+
+func (*bundleDataSuite) TestInferEndpointsRegisteredImplicitRelation(c *gc.C) {
+	err := charm.RegisterImplicitRelation(charm.Relation{
+		Name:      "juju-dashboard",
+		Role:      charm.RoleProvider,
+		Interface: "juju-dashboard",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer charm.UnregisterImplicitRelation("juju-dashboard")
+
+	metas := map[string]*charm.Meta{
+		"mysql": readCharmDir(c, "mysql").Meta(),
+		"dashboard": {
+			Name: "dashboard",
+			Requires: map[string]charm.Relation{
+				"target": {Name: "target", Role: charm.RoleRequirer, Interface: "juju-dashboard", Scope: charm.ScopeGlobal},
+			},
+		},
+	}
+	metaFor := func(app string) (*charm.Meta, error) {
+		return metas[app], nil
+	}
+	ep0, ep1, err := charm.InferEndpoints("dashboard", "mysql", metaFor)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ep0.String(), gc.Equals, "dashboard:target")
+	c.Assert(ep1.String(), gc.Equals, "mysql:juju-dashboard")
+
+	data := `
+applications:
+    mysql:
+        charm: ch:mysql
+        num_units: 1
+    dashboard:
+        charm: ch:dashboard
+        num_units: 1
+relations:
+    - ["dashboard:target", "mysql:juju-dashboard"]
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, gc.IsNil)
+	err = bd.VerifyWithCharms(nil, nil, nil, map[string]charm.Charm{
+		"ch:mysql":     readCharmDir(c, "mysql"),
+		"ch:dashboard": testCharmImpl{meta: metas["dashboard"], config: charm.NewConfig()},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
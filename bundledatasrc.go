@@ -4,7 +4,6 @@
 package charm
 
 import (
-	"bytes"
 	"io"
 	"io/ioutil"
 	"os"
@@ -49,6 +48,10 @@ type BundleDataPart struct {
 	Data            *BundleData
 	PresenceMap     FieldPresenceMap
 	UnmarshallError error
+
+	// Document records where the part was found in the bundle file
+	// it was read from.
+	Document *BundleDocument
 }
 
 // BundleDataSource is implemented by types that can parse bundle data into a
@@ -131,7 +134,7 @@ func LocalBundleDataSource(path string) (BundleDataSource, error) {
 	}
 	defer func() { _ = f.Close() }()
 
-	parts, pErr := parseBundleParts(f)
+	parts, pErr := parseBundleParts(path, f)
 	if pErr == nil {
 		absPath, err := filepath.Abs(path)
 		if err != nil {
@@ -159,7 +162,7 @@ func LocalBundleDataSource(path string) (BundleDataSource, error) {
 	}
 	defer func() { _ = r.Close() }()
 
-	if parts, pErr = parseBundleParts(r); pErr == nil {
+	if parts, pErr = parseBundleParts(path, r); pErr == nil {
 		return &resolvedBundleDataSource{
 			basePath: "", // use empty base path for archives
 			parts:    parts,
@@ -185,7 +188,7 @@ func isNotExistsError(err error) bool {
 // StreamBundleDataSource reads a (potentially multi-part) bundle from r and
 // returns a BundleDataSource for it.
 func StreamBundleDataSource(r io.Reader, basePath string) (BundleDataSource, error) {
-	parts, err := parseBundleParts(r)
+	parts, err := parseBundleParts("", r)
 	if err != nil {
 		return nil, errors.NotValidf("cannot unmarshal bundle contents: %v", err)
 	}
@@ -193,52 +196,43 @@ func StreamBundleDataSource(r io.Reader, basePath string) (BundleDataSource, err
 	return &resolvedBundleDataSource{parts: parts, basePath: basePath}, nil
 }
 
-func parseBundleParts(r io.Reader) ([]*BundleDataPart, error) {
-	b, err := ioutil.ReadAll(r)
+// parseBundleParts parses the documents of the multi-document bundle
+// read from r, recording source as the name of the file they were read
+// from.
+func parseBundleParts(source string, r io.Reader) ([]*BundleDataPart, error) {
+	docs, err := SplitBundleDocuments(r)
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		// Ideally, we would be using a single reader and we would
-		// rewind it to read each block in structured and raw mode.
-		// Unfortunately, the yaml parser seems to parse all documents
-		// at once so we need to use two decoders. The third is to allow
-		// for validation of the yaml by using strict decoding. However
-		// we still want to return non strict bundle parts so that
-		// force may be used in deploy.
-		structDec = yaml.NewDecoder(bytes.NewReader(b))
-		strictDec = yaml.NewDecoder(bytes.NewReader(b))
-		rawDec    = yaml.NewDecoder(bytes.NewReader(b))
-		parts     []*BundleDataPart
-	)
-
-	for docIdx := 0; ; docIdx++ {
-		var part BundleDataPart
-
-		err = structDec.Decode(&part.Data)
-		if err == io.EOF {
-			break
-		} else if err != nil && !strings.HasPrefix(err.Error(), "yaml: unmarshal errors:") {
-			return nil, errors.Annotatef(err, "unmarshal document %d", docIdx)
+	// Each document is decoded three times: in structured and raw mode,
+	// and with strict decoding to validate the yaml. We still want to
+	// return non strict bundle parts so that force may be used in
+	// deploy.
+	parts := make([]*BundleDataPart, 0, len(docs))
+	for _, doc := range docs {
+		doc.Source = source
+		part := BundleDataPart{Document: doc}
+
+		data := doc.yamlData()
+		err = yaml.Unmarshal(data, &part.Data)
+		if err != nil && !strings.HasPrefix(err.Error(), "yaml: unmarshal errors:") {
+			return nil, errors.Annotatef(doc.fileError(err), "unmarshal document %d", doc.Index)
 		}
 
-		var data *BundleData
-		strictDec.SetStrict(true)
-		err = strictDec.Decode(&data)
-		if err == io.EOF {
-			break
-		} else if err != nil {
+		var bd *BundleData
+		err = yaml.UnmarshalStrict(data, &bd)
+		if err != nil {
 			if strings.HasPrefix(err.Error(), "yaml: unmarshal errors:") {
-				friendlyErrors := userFriendlyUnmarshalErrors(err)
-				part.UnmarshallError = errors.Annotatef(friendlyErrors, "unmarshal document %d", docIdx)
+				friendlyErrors := doc.fileError(userFriendlyUnmarshalErrors(err))
+				part.UnmarshallError = errors.Annotatef(friendlyErrors, "unmarshal document %d", doc.Index)
 			} else {
-				return nil, errors.Annotatef(err, "unmarshal document %d", docIdx)
+				return nil, errors.Annotatef(doc.fileError(err), "unmarshal document %d", doc.Index)
 			}
 		}
 
 		// We have already checked for errors for the previous unmarshal attempt
-		_ = rawDec.Decode(&part.PresenceMap)
+		_ = yaml.Unmarshal(data, &part.PresenceMap)
 		parts = append(parts, &part)
 	}
 
@@ -253,5 +247,5 @@ func userFriendlyUnmarshalErrors(err error) error {
 	friendlyText = strings.ReplaceAll(friendlyText, "type charm.RelationSpec", "relations")
 	friendlyText = strings.ReplaceAll(friendlyText, "type charm.MachineSpec", "machines")
 	friendlyText = strings.ReplaceAll(friendlyText, "type charm.SaasSpec", "saas")
-	return errors.New(friendlyText)
+	return errors.Wrap(err, errors.New(friendlyText))
 }
@@ -128,7 +128,7 @@ applications:
           foo: "consume"
 `)
 
-	parts, err := parseBundleParts(r)
+	parts, err := parseBundleParts("", r)
 	c.Assert(err, gc.IsNil)
 	c.Assert(parts, gc.HasLen, 3)
 	c.Assert(parts[0].UnmarshallError, gc.NotNil)
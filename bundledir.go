@@ -4,6 +4,7 @@
 package charm
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -31,7 +32,7 @@ func ReadBundleDir(path string) (dir *BundleDir, err error) {
 	if err != nil {
 		return nil, err
 	}
-	dir.data, dir.containsOverlays, err = readBaseFromMultidocBundle(file)
+	dir.data, dir.containsOverlays, err = readBaseFromMultidocBundle(file.Name(), file)
 	file.Close()
 	if err != nil {
 		return nil, err
@@ -60,7 +61,14 @@ func (dir *BundleDir) ContainsOverlays() bool {
 }
 
 func (dir *BundleDir) ArchiveTo(w io.Writer) error {
-	return writeArchive(w, dir.Path, -1, "", nil, nil)
+	return dir.ArchiveToContext(context.Background(), w)
+}
+
+// ArchiveToContext is like ArchiveTo, except that it stops and returns
+// the context's error if ctx is done before all the bundle's files have
+// been archived.
+func (dir *BundleDir) ArchiveToContext(ctx context.Context, w io.Writer) error {
+	return writeArchive(ctx, w, dir.Path, -1, "", nil, nil)
 }
 
 // join builds a path rooted at the bundle's expanded directory
@@ -0,0 +1,210 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// BundleDocument holds one of the YAML documents of a multi-document
+// bundle file, such as a bundle followed by its overlays, together with
+// its position in the file.
+type BundleDocument struct {
+	// Index holds the position of the document in the file, starting
+	// at zero.
+	Index int
+
+	// Offset holds the byte offset of the start of the document.
+	Offset int64
+
+	// Line holds the line number, starting at one, on which the
+	// document starts. This is the line holding the document's "---"
+	// marker, if it has one.
+	Line int
+
+	// Data holds the document's contents, including its marker.
+	Data []byte
+
+	// Source holds the name of the file the document was read from,
+	// such as the path of a bundle.yaml file, or is empty if it is not
+	// known.
+	Source string
+}
+
+// String returns a description of the document's position suitable for
+// error messages.
+func (d *BundleDocument) String() string {
+	return fmt.Sprintf("document %d (line %d)", d.Index, d.Line)
+}
+
+// SplitBundleDocuments reads a multi-document bundle file from r and
+// splits it into its documents. Anything before the first "---" marker
+// that holds only blank lines and comments is treated as part of the
+// first document, as a YAML parser would.
+func SplitBundleDocuments(r io.Reader) ([]*BundleDocument, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var (
+		docs  []*BundleDocument
+		start int
+		line  = 1
+		first = 1
+	)
+	addDoc := func(end int) {
+		docs = append(docs, &BundleDocument{
+			Index:  len(docs),
+			Offset: int64(start),
+			Line:   first,
+			Data:   data[start:end],
+		})
+	}
+	for pos := 0; pos < len(data); line++ {
+		end := bytes.IndexByte(data[pos:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += pos + 1
+		}
+		if pos > start && isDocumentMarker(data[pos:end]) {
+			if len(docs) > 0 || !isBlankYAML(data[start:pos]) {
+				addDoc(pos)
+				start, first = pos, line
+			}
+		}
+		pos = end
+	}
+	if len(docs) > 0 || !isBlankYAML(data[start:]) {
+		addDoc(len(data))
+	}
+	return docs, nil
+}
+
+// isDocumentMarker reports whether line starts a new YAML document.
+func isDocumentMarker(line []byte) bool {
+	rest, ok := bytes.CutPrefix(line, []byte("---"))
+	return ok && (len(bytes.TrimSpace(rest)) == 0 || rest[0] == ' ' || rest[0] == '\t')
+}
+
+// isBlankYAML reports whether data holds nothing but blank lines and
+// comments.
+func isBlankYAML(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlData returns the document's contents preceded by a blank line for
+// each line of the file before the document, so that the YAML parser
+// reports line numbers in the file rather than in the document.
+func (d *BundleDocument) yamlData() []byte {
+	if d.Line <= 1 {
+		return d.Data
+	}
+	return append(bytes.Repeat([]byte("\n"), d.Line-1), d.Data...)
+}
+
+// yamlErrorLinePattern matches the line reference that starts the
+// messages of the errors returned by the YAML parser.
+var yamlErrorLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// fileError returns err, returned by the YAML parser for the data
+// returned by yamlData, as a *YAMLPositionError locating the problem in
+// the file holding the document. The line is that of a syntax error, or
+// that of the first of the errors held by a *yaml.TypeError. If err
+// reports no line, it is returned unchanged.
+func (d *BundleDocument) fileError(err error) error {
+	msg := err.Error()
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		msg = typeErr.Errors[0]
+	}
+	m := yamlErrorLinePattern.FindStringSubmatch(msg)
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &YAMLPositionError{File: d.Source, Line: line, Err: err}
+}
+
+// fieldError returns err located at the field with the given dotted
+// path in the document, as described by yamlFieldError.
+func (d *BundleDocument) fieldError(path string, err error) error {
+	return yamlFieldError(d.Source, d.yamlData(), path, err)
+}
+
+// VerifyBundleDocuments checks that each of the documents split from a
+// multi-document bundle file is well formed: every document must hold
+// only known bundle fields, and the first document, which holds the
+// bundle itself, must not use fields that can only appear in overlays.
+// Any problems are returned as a *VerificationError whose entries are
+// *CodedError values recording the document in which they were found.
+func VerifyBundleDocuments(docs []*BundleDocument) error {
+	if len(docs) == 0 {
+		return errors.NotValidf("empty bundle")
+	}
+	var errs []error
+	for _, doc := range docs {
+		var bd *BundleData
+		err := yaml.UnmarshalStrict(doc.yamlData(), &bd)
+		if err != nil {
+			if !strings.HasPrefix(err.Error(), "yaml: unmarshal errors:") {
+				errs = append(errs, &CodedError{
+					Code:     CodeInvalidBundle,
+					Err:      doc.fileError(err),
+					Document: doc,
+				})
+				continue
+			}
+			var typeErr *yaml.TypeError
+			if errors.As(err, &typeErr) {
+				for _, msg := range typeErr.Errors {
+					errs = append(errs, &CodedError{
+						Code:     CodeInvalidBundle,
+						Err:      doc.fileError(userFriendlyUnmarshalErrors(errors.New(msg))),
+						Document: doc,
+					})
+				}
+			}
+		}
+		if doc.Index != 0 || bd == nil {
+			continue
+		}
+		if err := VerifyNoOverlayFieldsPresent(bd); err != nil {
+			errs = append(errs, withDocument(err, doc).(*VerificationError).Errors...)
+		}
+	}
+	if len(errs) > 0 {
+		return &VerificationError{errs}
+	}
+	return nil
+}
+
+// withDocument records doc as the source of each of the verification
+// errors in err that do not already record one.
+func withDocument(err error, doc *BundleDocument) error {
+	verr, ok := err.(*VerificationError)
+	if !ok || doc == nil {
+		return err
+	}
+	for _, e := range verr.Errors {
+		if coded, ok := e.(*CodedError); ok && coded.Document == nil {
+			coded.Document = doc
+		}
+	}
+	return verr
+}
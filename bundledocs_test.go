@@ -0,0 +1,140 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"errors"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/charm/v12"
+)
+
+type BundleDocumentsSuite struct{}
+
+var _ = gc.Suite(&BundleDocumentsSuite{})
+
+const multiDocBundle = `# bundle.yaml
+---
+applications:
+  wordpress:
+    charm: wordpress
+    offers:
+      blog:
+        endpoints: [website]
+--- # overlay.yaml
+applications:
+  wordpress:
+    num_units: 2
+    constrain: mem=8G
+`
+
+func (s *BundleDocumentsSuite) TestSplitBundleDocuments(c *gc.C) {
+	docs, err := charm.SplitBundleDocuments(strings.NewReader(multiDocBundle))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(docs, gc.HasLen, 2)
+
+	c.Assert(docs[0].Index, gc.Equals, 0)
+	c.Assert(docs[0].Offset, gc.Equals, int64(0))
+	c.Assert(docs[0].Line, gc.Equals, 1)
+	c.Assert(string(docs[0].Data), gc.Matches, `(?s)# bundle.yaml\n---\napplications:.*endpoints: \[website\]\n`)
+
+	c.Assert(docs[1].Index, gc.Equals, 1)
+	c.Assert(docs[1].Offset, gc.Equals, int64(strings.Index(multiDocBundle, "--- # overlay.yaml")))
+	c.Assert(docs[1].Line, gc.Equals, 9)
+	c.Assert(docs[1].String(), gc.Equals, "document 1 (line 9)")
+	c.Assert(string(docs[1].Data), gc.Equals, multiDocBundle[docs[1].Offset:])
+}
+
+func (s *BundleDocumentsSuite) TestSplitBundleDocumentsEmpty(c *gc.C) {
+	docs, err := charm.SplitBundleDocuments(strings.NewReader("# nothing here\n\n"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(docs, gc.HasLen, 0)
+
+	err = charm.VerifyBundleDocuments(docs)
+	c.Assert(err, gc.ErrorMatches, "empty bundle not valid")
+}
+
+func (s *BundleDocumentsSuite) TestSplitBundleDocumentsIgnoresIndentedMarkers(c *gc.C) {
+	docs, err := charm.SplitBundleDocuments(strings.NewReader(`
+applications:
+  wordpress:
+    options:
+      banner: |
+        ---
+        welcome
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(docs, gc.HasLen, 1)
+}
+
+func (s *BundleDocumentsSuite) TestVerifyBundleDocuments(c *gc.C) {
+	docs, err := charm.SplitBundleDocuments(strings.NewReader(multiDocBundle))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = charm.VerifyBundleDocuments(docs)
+	var verr *charm.VerificationError
+	c.Assert(errors.As(err, &verr), jc.IsTrue)
+	c.Assert(verr.Errors, gc.HasLen, 3)
+
+	var messages []string
+	for _, e := range verr.Errors {
+		var coded *charm.CodedError
+		c.Assert(errors.As(e, &coded), jc.IsTrue)
+		c.Assert(coded.Document, gc.NotNil)
+		messages = append(messages, e.Error())
+	}
+	c.Assert(messages, jc.SameContents, []string{
+		"document 1 (line 9): line 13: field constrain not found in applications",
+		"document 0 (line 1): applications.wordpress.offers can only appear in an overlay section",
+		"document 0 (line 1): applications.wordpress.offers.blog.endpoints can only appear in an overlay section",
+	})
+	c.Assert(errors.Is(err, charm.CodeOverlayOnlyField), jc.IsTrue)
+	c.Assert(errors.Is(err, charm.CodeInvalidBundle), jc.IsTrue)
+}
+
+func (s *BundleDocumentsSuite) TestVerifyBundleDocumentsSyntaxError(c *gc.C) {
+	docs, err := charm.SplitBundleDocuments(strings.NewReader(`
+applications:
+  mysql:
+    charm: mysql
+---
+applications:
+  mysql: [
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(docs, gc.HasLen, 2)
+	err = charm.VerifyBundleDocuments(docs)
+	c.Assert(err, gc.ErrorMatches, `document 1 \(line 5\): yaml: line 7: .*`)
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsTrue)
+	c.Assert(perr.Position(), gc.Equals, ":7")
+}
+
+func (s *BundleDocumentsSuite) TestReadBundlePartsKeepsCause(c *gc.C) {
+	ds, err := charm.StreamBundleDataSource(strings.NewReader(multiDocBundle), "")
+	c.Assert(err, jc.ErrorIsNil)
+	err = ds.Parts()[1].UnmarshallError
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsTrue)
+	c.Assert(perr.Line, gc.Equals, 13)
+	var typeErr *yaml.TypeError
+	c.Assert(errors.As(err, &typeErr), jc.IsTrue)
+	c.Assert(typeErr.Errors, jc.DeepEquals, []string{"line 13: field constrain not found in type charm.ApplicationSpec"})
+}
+
+func (s *BundleDocumentsSuite) TestReadAndMergeRecordsDocument(c *gc.C) {
+	ds, err := charm.StreamBundleDataSource(strings.NewReader(multiDocBundle), "")
+	c.Assert(err, jc.ErrorIsNil)
+	parts := ds.Parts()
+	c.Assert(parts, gc.HasLen, 2)
+	c.Assert(parts[1].Document.Line, gc.Equals, 9)
+	c.Assert(parts[1].UnmarshallError, gc.ErrorMatches, "(?s)unmarshal document 1: .*line 13: field constrain not found in applications")
+
+	_, err = charm.ReadAndMergeBundleData(ds)
+	c.Assert(err, gc.ErrorMatches, `document 0 \(line 1\): .* can only appear in an overlay section \(and 1 more errors\)`)
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/names/v5"
+)
+
+// allNetworkCIDRs holds the CIDRs an application is exposed to by
+// "expose: true".
+var allNetworkCIDRs = []string{"0.0.0.0/0", "::/0"}
+
+// ExposeWarning describes a redundant or conflicting exposure setting
+// of a bundle application, as returned by BundleData.CheckExpose.
+type ExposeWarning struct {
+	// Application holds the name of the application.
+	Application string
+
+	// Endpoint holds the name of the exposed endpoint the warning
+	// concerns, or is empty if it concerns the wildcard entry or the
+	// application as a whole.
+	Endpoint string
+
+	// Message describes the redundancy or conflict.
+	Message string
+}
+
+// String returns a description of the warning.
+func (w ExposeWarning) String() string {
+	if w.Endpoint == "" {
+		return fmt.Sprintf("application %q: %s", w.Application, w.Message)
+	}
+	return fmt.Sprintf("application %q endpoint %q: %s", w.Application, w.Endpoint, w.Message)
+}
+
+// CheckExpose returns a warning for each redundant exposure setting of
+// the bundle's applications: spaces or CIDRs listed twice for an
+// endpoint, endpoints exposed exactly as the wildcard ("") entry
+// exposes all endpoints, and exposed-endpoints equivalent to
+// "expose: true". It also returns a warning for each application that
+// declares both "expose: true" and exposed-endpoints, which Verify
+// rejects. The warnings are sorted by application and endpoint.
+// NormalizeExpose removes the redundancies and conflicts warned about.
+func (bd *BundleData) CheckExpose() []ExposeWarning {
+	var warnings []ExposeWarning
+	for name, app := range bd.Applications {
+		if app == nil || len(app.ExposedEndpoints) == 0 {
+			continue
+		}
+		if app.Expose {
+			warnings = append(warnings, ExposeWarning{
+				Application: name,
+				Message:     `"expose: true" conflicts with exposed-endpoints, which limit the exposure of the application`,
+			})
+		}
+		wildcard, hasWildcard := app.ExposedEndpoints[""]
+		for epName, spec := range app.ExposedEndpoints {
+			warn := func(f string, a ...interface{}) {
+				warnings = append(warnings, ExposeWarning{Application: name, Endpoint: epName, Message: fmt.Sprintf(f, a...)})
+			}
+			for _, space := range duplicates(spec.ExposeToSpaces) {
+				warn("space %q listed more than once", space)
+			}
+			for _, cidr := range duplicates(spec.ExposeToCIDRs) {
+				warn("CIDR %q listed more than once", cidr)
+			}
+			if epName != "" && hasWildcard && spec.equivalent(wildcard) {
+				warn("exposed as by the wildcard entry")
+			}
+		}
+		if !app.Expose && hasWildcard && len(app.ExposedEndpoints) == 1 && wildcard.allNetworks() {
+			warnings = append(warnings, ExposeWarning{
+				Application: name,
+				Message:     `exposed-endpoints expose all endpoints to all networks, as "expose: true" does`,
+			})
+		}
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		if warnings[i].Application != warnings[j].Application {
+			return warnings[i].Application < warnings[j].Application
+		}
+		return warnings[i].Endpoint < warnings[j].Endpoint
+	})
+	return warnings
+}
+
+// NormalizeExpose folds the exposure settings of each of the bundle's
+// applications into a single form:
+//
+//   - "expose: true" is dropped from applications that also declare
+//     exposed-endpoints, which controllers aware of them apply instead.
+//     This narrows the exposure of the application to the endpoints,
+//     spaces and CIDRs listed; call CheckExpose first to report it.
+//     Verify rejects the combination, as older controllers would expose
+//     the application to all networks.
+//   - spaces and CIDRs listed twice for an endpoint are removed, as are
+//     endpoints exposed exactly as by the wildcard ("") entry.
+//   - exposed-endpoints that only expose all endpoints to all networks
+//     are replaced by "expose: true".
+//
+// The exposure of the applications is otherwise unchanged.
+func (bd *BundleData) NormalizeExpose() {
+	for _, app := range bd.Applications {
+		if app == nil || len(app.ExposedEndpoints) == 0 {
+			continue
+		}
+		app.Expose = false
+		for epName, spec := range app.ExposedEndpoints {
+			app.ExposedEndpoints[epName] = ExposedEndpointSpec{
+				ExposeToSpaces: uniqueStrings(spec.ExposeToSpaces),
+				ExposeToCIDRs:  uniqueStrings(spec.ExposeToCIDRs),
+			}
+		}
+		wildcard, ok := app.ExposedEndpoints[""]
+		if !ok {
+			continue
+		}
+		for epName, spec := range app.ExposedEndpoints {
+			if epName != "" && spec.equivalent(wildcard) {
+				delete(app.ExposedEndpoints, epName)
+			}
+		}
+		if len(app.ExposedEndpoints) == 1 && wildcard.allNetworks() {
+			app.Expose = true
+			app.ExposedEndpoints = nil
+		}
+	}
+}
+
+// verifyExposedEndpoints checks the spaces and CIDRs to which the
+// endpoints of the named application are exposed.
+func (verifier *bundleDataVerifier) verifyExposedEndpoints(name string, app *ApplicationSpec) {
+	for epName, spec := range app.ExposedEndpoints {
+		for _, space := range spec.ExposeToSpaces {
+			if !names.IsValidSpace(space) {
+				verifier.addErrorf(CodeInvalidExpose, "invalid space %q for expose to spaces field for endpoint %q in application %q", space, epName, name)
+			}
+		}
+		for _, cidr := range spec.ExposeToCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				verifier.addErrorf(CodeInvalidExpose, "invalid CIDR %q for expose to CIDRs field for endpoint %q in application %q", cidr, epName, name)
+			}
+		}
+	}
+}
+
+// verifyExposedEndpointNames checks that the endpoints exposed by each
+// application are defined by its charm, either as relations or as
+// extra bindings.
+func (verifier *bundleDataVerifier) verifyExposedEndpointNames() {
+	for name, app := range verifier.bd.Applications {
+		if app == nil || len(app.ExposedEndpoints) == 0 {
+			continue
+		}
+		ch, ok := verifier.charms[app.Charm]
+		if !ok {
+			continue
+		}
+		meta := ch.Meta()
+		endpoints := meta.CombinedRelations()
+		for epName := range app.ExposedEndpoints {
+			if epName == "" {
+				continue
+			}
+			_, isRelation := endpoints[epName]
+			_, isExtraBinding := meta.ExtraBindings[epName]
+			if !isRelation && !isExtraBinding {
+				verifier.addErrorf(CodeInvalidExpose, "application %q exposes endpoint %q not defined by its charm", name, epName)
+			}
+		}
+	}
+}
+
+// equivalent reports whether s exposes its endpoint to the same spaces
+// and CIDRs as other.
+func (s ExposedEndpointSpec) equivalent(other ExposedEndpointSpec) bool {
+	return sameValues(s.ExposeToSpaces, other.ExposeToSpaces) && sameValues(s.ExposeToCIDRs, other.ExposeToCIDRs)
+}
+
+// allNetworks reports whether s exposes its endpoint to all networks,
+// either by listing the all networks CIDRs alone or by listing no
+// spaces or CIDRs at all.
+func (s ExposedEndpointSpec) allNetworks() bool {
+	if len(s.ExposeToSpaces) != 0 {
+		return false
+	}
+	if len(s.ExposeToCIDRs) == 0 {
+		return true
+	}
+	return sameValues(s.ExposeToCIDRs, allNetworkCIDRs)
+}
+
+// sameValues reports whether a and b hold the same values, ignoring
+// their order and repetitions.
+func sameValues(a, b []string) bool {
+	return slices.Equal(set.NewStrings(a...).SortedValues(), set.NewStrings(b...).SortedValues())
+}
+
+// duplicates returns the values listed more than once in values, in the
+// order of their second occurrence.
+func duplicates(values []string) []string {
+	seen := set.NewStrings()
+	reported := set.NewStrings()
+	var dups []string
+	for _, v := range values {
+		if seen.Contains(v) && !reported.Contains(v) {
+			dups = append(dups, v)
+			reported.Add(v)
+		}
+		seen.Add(v)
+	}
+	return dups
+}
+
+// uniqueStrings returns values without repeated entries, keeping the
+// first occurrence of each.
+func uniqueStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	seen := set.NewStrings()
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen.Contains(v) {
+			unique = append(unique, v)
+			seen.Add(v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type ExposeSuite struct{}
+
+var _ = gc.Suite(&ExposeSuite{})
+
+const redundantExposeBundle = `
+applications:
+    wordpress:
+        charm: wordpress
+        num_units: 1
+        exposed-endpoints:
+            "":
+                expose-to-cidrs: ["10.0.0.0/8"]
+            website:
+                expose-to-cidrs: ["10.0.0.0/8", "10.0.0.0/8"]
+            admin:
+                expose-to-spaces: [alpha, alpha]
+    haproxy:
+        charm: haproxy
+        num_units: 1
+        exposed-endpoints:
+            "":
+                expose-to-cidrs: ["::/0", "0.0.0.0/0"]
+    mysql:
+        charm: mysql
+        num_units: 1
+        expose: true
+        exposed-endpoints:
+            server:
+                expose-to-spaces: [db]
+`
+
+func (s *ExposeSuite) readBundle(c *gc.C, data string) *charm.BundleData {
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	return bd
+}
+
+func (s *ExposeSuite) TestCheckExpose(c *gc.C) {
+	bd := s.readBundle(c, redundantExposeBundle)
+	var warnings []string
+	for _, w := range bd.CheckExpose() {
+		warnings = append(warnings, w.String())
+	}
+	c.Assert(warnings, jc.DeepEquals, []string{
+		`application "haproxy": exposed-endpoints expose all endpoints to all networks, as "expose: true" does`,
+		`application "mysql": "expose: true" conflicts with exposed-endpoints, which limit the exposure of the application`,
+		`application "wordpress" endpoint "admin": space "alpha" listed more than once`,
+		`application "wordpress" endpoint "website": CIDR "10.0.0.0/8" listed more than once`,
+		`application "wordpress" endpoint "website": exposed as by the wildcard entry`,
+	})
+}
+
+func (s *ExposeSuite) TestCheckExposeNoWarnings(c *gc.C) {
+	bd := s.readBundle(c, `
+applications:
+    wordpress:
+        charm: wordpress
+        expose: true
+    haproxy:
+        charm: haproxy
+        exposed-endpoints:
+            "":
+                expose-to-cidrs: ["0.0.0.0/0"]
+            admin:
+                expose-to-spaces: [alpha]
+`)
+	c.Assert(bd.CheckExpose(), gc.HasLen, 0)
+}
+
+func (s *ExposeSuite) TestNormalizeExpose(c *gc.C) {
+	bd := s.readBundle(c, redundantExposeBundle)
+	bd.NormalizeExpose()
+	c.Assert(bd.CheckExpose(), gc.HasLen, 0)
+
+	wordpress := bd.Applications["wordpress"]
+	c.Assert(wordpress.Expose, jc.IsFalse)
+	c.Assert(wordpress.ExposedEndpoints, jc.DeepEquals, map[string]charm.ExposedEndpointSpec{
+		"":      {ExposeToCIDRs: []string{"10.0.0.0/8"}},
+		"admin": {ExposeToSpaces: []string{"alpha"}},
+	})
+
+	haproxy := bd.Applications["haproxy"]
+	c.Assert(haproxy.Expose, jc.IsTrue)
+	c.Assert(haproxy.ExposedEndpoints, gc.IsNil)
+
+	mysql := bd.Applications["mysql"]
+	c.Assert(mysql.Expose, jc.IsFalse)
+	c.Assert(mysql.ExposedEndpoints, jc.DeepEquals, map[string]charm.ExposedEndpointSpec{
+		"server": {ExposeToSpaces: []string{"db"}},
+	})
+
+	// The conflict between expose and exposed-endpoints is resolved.
+	err := bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ExposeSuite) TestVerifyExposeErrors(c *gc.C) {
+	data := `
+applications:
+    wordpress:
+        charm: wordpress
+        num_units: 1
+        exposed-endpoints:
+            website:
+                expose-to-spaces: ["Not A Space"]
+            unknown:
+                expose-to-cidrs: ["10.0.0.0/8"]
+            public:
+                expose-to-cidrs: ["10.0.0.0/8"]
+`
+	wordpress := testCharm("wordpress", "website:http | db:mysql")
+	wordpress.Meta().ExtraBindings = map[string]charm.ExtraBinding{
+		"public": {Name: "public"},
+	}
+	charms := map[string]charm.Charm{
+		"wordpress": wordpress,
+	}
+	assertVerifyErrors(c, data, charms, []string{
+		`invalid space "Not A Space" for expose to spaces field for endpoint "website" in application "wordpress"`,
+		`application "wordpress" exposes endpoint "unknown" not defined by its charm`,
+	})
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+)
+
+// RelationEdge is a relation between two applications of a bundle.
+type RelationEdge struct {
+	// Endpoints holds the endpoints of the relation, in the order in
+	// which the bundle declares them. If the charms of both
+	// applications are known, the endpoints are fully inferred;
+	// otherwise they only hold the application name and, if the
+	// bundle gives it, the relation name.
+	Endpoints [2]Endpoint
+
+	// Via holds the name of the space the relation is made via, if
+	// any.
+	Via string
+}
+
+// Inferred reports whether the endpoints of the relation were inferred
+// from the charms of its applications.
+func (e RelationEdge) Inferred() bool {
+	return e.Endpoints[0].Interface != "" && e.Endpoints[1].Interface != ""
+}
+
+// requirer returns the indexes of the requiring and providing endpoints
+// of the relation, and whether the relation has a known direction.
+func (e RelationEdge) requirer() (requirer, provider int, ok bool) {
+	switch {
+	case e.Endpoints[0].Role == RoleRequirer && e.Endpoints[1].Role == RoleProvider:
+		return 0, 1, true
+	case e.Endpoints[0].Role == RoleProvider && e.Endpoints[1].Role == RoleRequirer:
+		return 1, 0, true
+	}
+	return 0, 0, false
+}
+
+// RelationGraph describes the topology of the relations of a bundle,
+// as returned by BundleData.RelationGraph.
+type RelationGraph struct {
+	// Nodes holds the names of the bundle's applications and SAAS
+	// entries, sorted.
+	Nodes []string
+
+	// Edges holds the relations of the bundle, in the order in which
+	// the bundle declares them.
+	Edges []RelationEdge
+
+	// edgesOf holds the indexes in Edges of the relations of each
+	// node.
+	edgesOf map[string][]int
+}
+
+// RelationGraph returns the graph of the bundle's applications and SAAS
+// entries and the relations between them. If charms is not nil, it
+// should hold the charms used by the bundle's applications as described
+// by VerifyWithCharms, and the endpoints of relations between
+// applications whose charms are given are inferred as by
+// InferEndpoints. Relations listing more than two endpoints are
+// expanded as by Normalize.
+//
+// An error is returned if a relation is malformed, refers to an
+// application not in the bundle, or cannot be inferred.
+func (bd *BundleData) RelationGraph(charms map[string]Charm) (*RelationGraph, error) {
+	g := &RelationGraph{
+		edgesOf: make(map[string][]int),
+	}
+	for name := range bd.Applications {
+		g.Nodes = append(g.Nodes, name)
+	}
+	for name := range bd.Saas {
+		if _, ok := bd.Applications[name]; !ok {
+			g.Nodes = append(g.Nodes, name)
+		}
+	}
+	sort.Strings(g.Nodes)
+
+	metaFor := func(app string) (*Meta, error) {
+		spec := bd.Applications[app]
+		if spec == nil {
+			return nil, errors.NotFoundf("charm for %q", app)
+		}
+		ch, ok := charms[spec.Charm]
+		if !ok {
+			return nil, errors.NotFoundf("charm %q", spec.Charm)
+		}
+		return ch.Meta(), nil
+	}
+	for _, rel := range expandRelations(bd.Relations) {
+		if len(rel) != 2 {
+			return nil, errors.NotValidf("relation %q with %d endpoint(s)", rel, len(rel))
+		}
+		var specs [2]endpoint
+		for i, ep := range rel {
+			spec, err := parseEndpoint(ep)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if !g.hasNode(spec.application) {
+				return nil, errors.NotValidf("relation %q referring to unknown application %q", rel, spec.application)
+			}
+			specs[i] = spec
+		}
+		relSpec, isSpec := bd.relationSpec(rel)
+		edge := RelationEdge{Via: relSpec.Via}
+		_, err0 := metaFor(specs[0].application)
+		_, err1 := metaFor(specs[1].application)
+		if charms != nil && err0 == nil && err1 == nil {
+			ep0, ep1, err := inferEndpoints(specs[0], specs[1], metaFor)
+			if err != nil {
+				return nil, errors.Annotatef(err, "cannot infer endpoints of relation %q", rel)
+			}
+			edge.Endpoints = [2]Endpoint{ep0, ep1}
+		} else {
+			for i, spec := range specs {
+				edge.Endpoints[i] = Endpoint{ApplicationName: spec.application, Relation: Relation{Name: spec.relation}}
+			}
+			// Relations declared in map form give the roles of their
+			// endpoints.
+			if isSpec {
+				edge.Endpoints[0].Role, edge.Endpoints[1].Role = RoleProvider, RoleRequirer
+			}
+		}
+		i := len(g.Edges)
+		g.Edges = append(g.Edges, edge)
+		g.edgesOf[specs[0].application] = append(g.edgesOf[specs[0].application], i)
+		if specs[1].application != specs[0].application {
+			g.edgesOf[specs[1].application] = append(g.edgesOf[specs[1].application], i)
+		}
+	}
+	return g, nil
+}
+
+// relationSpec returns the spec of the given relation, and whether the
+// relation was declared in map form.
+func (bd *BundleData) relationSpec(rel []string) (RelationSpec, bool) {
+	for _, spec := range bd.RelationSpecs {
+		if spec.Provider == rel[0] && spec.Requirer == rel[1] {
+			return spec, true
+		}
+	}
+	return RelationSpec{}, false
+}
+
+// hasNode reports whether the graph has a node with the given name.
+func (g *RelationGraph) hasNode(name string) bool {
+	i := sort.SearchStrings(g.Nodes, name)
+	return i < len(g.Nodes) && g.Nodes[i] == name
+}
+
+// EdgesOf returns the relations of the named node, in the order in
+// which the bundle declares them.
+func (g *RelationGraph) EdgesOf(name string) []RelationEdge {
+	var edges []RelationEdge
+	for _, i := range g.edgesOf[name] {
+		edges = append(edges, g.Edges[i])
+	}
+	return edges
+}
+
+// Neighbours returns the sorted names of the nodes related to the
+// named node.
+func (g *RelationGraph) Neighbours(name string) []string {
+	seen := make(map[string]bool)
+	var neighbours []string
+	for _, i := range g.edgesOf[name] {
+		for _, ep := range g.Edges[i].Endpoints {
+			if ep.ApplicationName != name && !seen[ep.ApplicationName] {
+				seen[ep.ApplicationName] = true
+				neighbours = append(neighbours, ep.ApplicationName)
+			}
+		}
+	}
+	sort.Strings(neighbours)
+	return neighbours
+}
+
+// Orphans returns the sorted names of the nodes without relations to
+// other nodes.
+func (g *RelationGraph) Orphans() []string {
+	var orphans []string
+	for _, name := range g.Nodes {
+		if len(g.Neighbours(name)) == 0 {
+			orphans = append(orphans, name)
+		}
+	}
+	return orphans
+}
+
+// Dependencies returns the sorted names of the nodes providing the
+// relations required by the named node. Only relations whose roles
+// are known, because they were inferred or declared in map form, are
+// considered.
+func (g *RelationGraph) Dependencies(name string) []string {
+	seen := make(map[string]bool)
+	var deps []string
+	for _, i := range g.edgesOf[name] {
+		edge := g.Edges[i]
+		requirer, provider, ok := edge.requirer()
+		if !ok || edge.Endpoints[requirer].ApplicationName != name {
+			continue
+		}
+		dep := edge.Endpoints[provider].ApplicationName
+		if dep != name && !seen[dep] {
+			seen[dep] = true
+			deps = append(deps, dep)
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// Cycles returns the groups of nodes that depend on each other, as
+// reported by Dependencies, through a cycle of relations. Each group is
+// sorted, and the groups are sorted by their first node. Deploy
+// planners ordering applications by their dependencies must deploy
+// the nodes of each group together.
+func (g *RelationGraph) Cycles() [][]string {
+	// Tarjan's strongly connected components algorithm.
+	var (
+		index   int
+		stack   []string
+		cycles  [][]string
+		indexOf = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		visit   func(name string)
+	)
+	visit = func(name string) {
+		indexOf[name], lowlink[name] = index, index
+		index++
+		stack = append(stack, name)
+		onStack[name] = true
+		for _, dep := range g.Dependencies(name) {
+			if _, ok := indexOf[dep]; !ok {
+				visit(dep)
+				lowlink[name] = min(lowlink[name], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[name] = min(lowlink[name], indexOf[dep])
+			}
+		}
+		if lowlink[name] != indexOf[name] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == name {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, name := range g.Nodes {
+		if _, ok := indexOf[name]; !ok {
+			visit(name)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type RelationGraphSuite struct{}
+
+var _ = gc.Suite(&RelationGraphSuite{})
+
+const relationGraphBundle = `
+applications:
+    wordpress:
+        charm: wordpress
+        num_units: 1
+    mysql:
+        charm: mysql
+        num_units: 1
+    haproxy:
+        charm: haproxy
+        num_units: 1
+    ntp:
+        charm: ntp
+    memcached:
+        charm: memcached
+        num_units: 1
+saas:
+    logs:
+        url: other:admin/default.logs
+relations:
+    - ["wordpress:db", "mysql"]
+    - ["haproxy", "wordpress"]
+    - provider: logs:logs
+      requirer: mysql:logs
+      via: admin-space
+`
+
+func (s *RelationGraphSuite) readBundle(c *gc.C, data string) *charm.BundleData {
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	return bd
+}
+
+func edgeStrings(edges []charm.RelationEdge) []string {
+	var s []string
+	for _, e := range edges {
+		s = append(s, e.Endpoints[0].String()+" "+e.Endpoints[1].String())
+	}
+	return s
+}
+
+func (s *RelationGraphSuite) TestRelationGraph(c *gc.C) {
+	bd := s.readBundle(c, relationGraphBundle)
+	g, err := bd.RelationGraph(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(g.Nodes, jc.DeepEquals, []string{"haproxy", "logs", "memcached", "mysql", "ntp", "wordpress"})
+	c.Assert(edgeStrings(g.Edges), jc.DeepEquals, []string{
+		"wordpress:db mysql:",
+		"haproxy: wordpress:",
+		"logs:logs mysql:logs",
+	})
+	for _, e := range g.Edges {
+		c.Check(e.Inferred(), jc.IsFalse)
+	}
+	c.Assert(g.Edges[2].Via, gc.Equals, "admin-space")
+	c.Assert(g.Edges[2].Endpoints[0].Role, gc.Equals, charm.RoleProvider)
+	c.Assert(g.Edges[2].Endpoints[1].Role, gc.Equals, charm.RoleRequirer)
+
+	c.Assert(edgeStrings(g.EdgesOf("mysql")), jc.DeepEquals, []string{
+		"wordpress:db mysql:",
+		"logs:logs mysql:logs",
+	})
+	c.Assert(g.EdgesOf("ntp"), gc.HasLen, 0)
+	c.Assert(g.Neighbours("wordpress"), jc.DeepEquals, []string{"haproxy", "mysql"})
+	c.Assert(g.Orphans(), jc.DeepEquals, []string{"memcached", "ntp"})
+
+	// Only the relation declared in map form has known roles.
+	c.Assert(g.Dependencies("mysql"), jc.DeepEquals, []string{"logs"})
+	c.Assert(g.Dependencies("wordpress"), gc.HasLen, 0)
+	c.Assert(g.Cycles(), gc.HasLen, 0)
+}
+
+func (s *RelationGraphSuite) TestRelationGraphWithCharms(c *gc.C) {
+	bd := s.readBundle(c, relationGraphBundle)
+	charms := map[string]charm.Charm{
+		"wordpress": testCharm("wordpress", "website:http | db:mysql cache:memcache"),
+		"mysql":     testCharm("mysql", "server:mysql | logs:logging"),
+		"haproxy":   testCharm("haproxy", "| reverseproxy:http"),
+		"ntp":       testCharm("ntp-sub", ""),
+		"memcached": testCharm("memcached", "cache:memcache"),
+	}
+	g, err := bd.RelationGraph(charms)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(edgeStrings(g.Edges), jc.DeepEquals, []string{
+		"wordpress:db mysql:server",
+		"haproxy:reverseproxy wordpress:website",
+		"logs:logs mysql:logs",
+	})
+	c.Assert(g.Edges[0].Inferred(), jc.IsTrue)
+	c.Assert(g.Edges[0].Endpoints[0].Interface, gc.Equals, "mysql")
+	c.Assert(g.Edges[0].Endpoints[0].Role, gc.Equals, charm.RoleRequirer)
+	c.Assert(g.Edges[1].Inferred(), jc.IsTrue)
+	// The SAAS entry has no charm, so its relation is not inferred.
+	c.Assert(g.Edges[2].Inferred(), jc.IsFalse)
+
+	c.Assert(g.Dependencies("wordpress"), jc.DeepEquals, []string{"mysql"})
+	c.Assert(g.Dependencies("haproxy"), jc.DeepEquals, []string{"wordpress"})
+	c.Assert(g.Dependencies("mysql"), jc.DeepEquals, []string{"logs"})
+	c.Assert(g.Cycles(), gc.HasLen, 0)
+}
+
+func (s *RelationGraphSuite) TestRelationGraphCycles(c *gc.C) {
+	bd := s.readBundle(c, `
+applications:
+    a: {charm: a}
+    b: {charm: b}
+    c: {charm: c}
+    d: {charm: d}
+    e: {charm: e}
+relations:
+    - ["a", "b:x"]
+    - ["b", "c:y"]
+    - ["c", "a:z"]
+    - ["d", "e:x"]
+    - ["e", "d:x"]
+`)
+	charms := map[string]charm.Charm{
+		"a": testCharm("a", "z:z | x:x"),
+		"b": testCharm("b", "x:x | y:y"),
+		"c": testCharm("c", "y:y | z:z"),
+		"d": testCharm("d", "x:x | w:x"),
+		"e": testCharm("e", "x:x | w:x"),
+	}
+	g, err := bd.RelationGraph(charms)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(g.Cycles(), jc.DeepEquals, [][]string{
+		{"a", "b", "c"},
+		{"d", "e"},
+	})
+}
+
+func (s *RelationGraphSuite) TestRelationGraphExpandsRelations(c *gc.C) {
+	bd := s.readBundle(c, `
+applications:
+    wordpress: {charm: wordpress}
+    mysql: {charm: mysql}
+    logging: {charm: logging}
+relations:
+    - ["logging", "wordpress", "mysql"]
+`)
+	g, err := bd.RelationGraph(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(edgeStrings(g.Edges), jc.DeepEquals, []string{
+		"logging: wordpress:",
+		"logging: mysql:",
+	})
+	c.Assert(g.Neighbours("logging"), jc.DeepEquals, []string{"mysql", "wordpress"})
+	c.Assert(g.Orphans(), gc.HasLen, 0)
+}
+
+func (s *RelationGraphSuite) TestRelationGraphErrors(c *gc.C) {
+	for i, test := range []struct {
+		relations string
+		charms    map[string]charm.Charm
+		err       string
+	}{{
+		relations: `[["wordpress", "unknown"]]`,
+		err:       `relation \["wordpress" "unknown"\] referring to unknown application "unknown" not valid`,
+	}, {
+		relations: `[["wordpress"]]`,
+		err:       `relation \["wordpress"\] with 1 endpoint\(s\) not valid`,
+	}, {
+		relations: `[["wordpress:", "mysql"]]`,
+		err:       `.*"wordpress:".*`,
+	}, {
+		relations: `[["wordpress", "mysql"]]`,
+		charms: map[string]charm.Charm{
+			"wordpress": testCharm("wordpress", "website:http"),
+			"mysql":     testCharm("mysql", "server:mysql"),
+		},
+		err: `cannot infer endpoints of relation \["wordpress" "mysql"\]: .*`,
+	}} {
+		c.Logf("test %d: %s", i, test.relations)
+		bd := s.readBundle(c, `
+applications:
+    wordpress: {charm: wordpress}
+    mysql: {charm: mysql}
+relations: `+test.relations)
+		_, err := bd.RelationGraph(test.charms)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+
+	bd := s.readBundle(c, `
+applications:
+    wordpress: {charm: wordpress}
+relations: [["wordpress", "unknown"]]
+`)
+	_, err := bd.RelationGraph(nil)
+	c.Assert(err, jc.ErrorIs, errors.NotValid)
+}
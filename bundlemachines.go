@@ -0,0 +1,183 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"github.com/juju/utils/v3"
+)
+
+// MachineVolumes describes the disks a bundle machine should be
+// provisioned with, for example:
+//
+//	machines:
+//	  "0":
+//	    volumes:
+//	      root-disk:
+//	        size: 50G
+//	      disks:
+//	      - size: 100G
+//	        pool: ebs-ssd
+type MachineVolumes struct {
+	// RootDisk describes the machine's root disk. If it is nil, the
+	// provider's default root disk is used.
+	RootDisk *MachineDisk `bson:"root-disk,omitempty" json:"root-disk,omitempty" yaml:"root-disk,omitempty"`
+
+	// Disks describes the additional disks attached to the machine.
+	Disks []MachineDisk `bson:"disks,omitempty" json:"disks,omitempty" yaml:"disks,omitempty"`
+}
+
+// MachineDisk describes a disk of a bundle machine.
+type MachineDisk struct {
+	// Size holds the size of the disk, such as "100G". A number
+	// without a suffix is in megabytes.
+	Size string `bson:"size" json:"size" yaml:"size"`
+
+	// Pool optionally names the storage pool, or for a root disk the
+	// root disk source, from which the disk is provisioned.
+	Pool string `bson:"pool,omitempty" json:"pool,omitempty" yaml:"pool,omitempty"`
+}
+
+// SizeMB returns the size of the disk in megabytes.
+func (d MachineDisk) SizeMB() (uint64, error) {
+	if d.Size == "" {
+		return 0, errors.NotValidf("empty disk size")
+	}
+	size, err := utils.ParseSize(d.Size)
+	if err != nil {
+		return 0, errors.NewNotValid(err, "disk size "+d.Size)
+	}
+	if size == 0 {
+		return 0, errors.NotValidf("disk size %q", d.Size)
+	}
+	return size, nil
+}
+
+// verifyMachineLayout checks the zones and volumes of the machine with
+// the given id, which may not also be given by its constraints.
+func (verifier *bundleDataVerifier) verifyMachineLayout(id string, m *MachineSpec) {
+	constraintKeys := set.NewStrings()
+	for _, field := range strings.Fields(m.Constraints) {
+		key, _, _ := strings.Cut(field, "=")
+		constraintKeys.Add(key)
+	}
+
+	if len(m.Zones) > 0 && constraintKeys.Contains("zones") {
+		verifier.addErrorf(CodeInvalidMachine, "machine %q declares zones in both its constraints and zones", id)
+	}
+	seen := set.NewStrings()
+	for _, zone := range m.Zones {
+		switch {
+		case zone == "" || strings.IndexFunc(zone, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) >= 0:
+			verifier.addErrorf(CodeInvalidMachine, "invalid zone %q for machine %q", zone, id)
+		case seen.Contains(zone):
+			verifier.addErrorf(CodeInvalidMachine, "duplicate zone %q for machine %q", zone, id)
+		}
+		seen.Add(zone)
+	}
+
+	if m.Volumes == nil {
+		return
+	}
+	if root := m.Volumes.RootDisk; root != nil {
+		if constraintKeys.Contains("root-disk") {
+			verifier.addErrorf(CodeInvalidMachine, "machine %q declares a root disk in both its constraints and volumes", id)
+		}
+		verifier.verifyMachineDisk(id, "root disk", *root)
+	}
+	for i, disk := range m.Volumes.Disks {
+		verifier.verifyMachineDisk(id, "disk "+strconv.Itoa(i), disk)
+	}
+}
+
+// verifyMachineDisk checks the given disk of the machine with the given
+// id.
+func (verifier *bundleDataVerifier) verifyMachineDisk(id, what string, disk MachineDisk) {
+	if _, err := disk.SizeMB(); err != nil {
+		verifier.addErrorf(CodeInvalidMachine, "invalid %s for machine %q: %v", what, id, err)
+	}
+	if strings.IndexFunc(disk.Pool, unicode.IsSpace) >= 0 {
+		verifier.addErrorf(CodeInvalidMachine, "invalid pool %q for %s of machine %q", disk.Pool, what, id)
+	}
+}
+
+// RemapMachines renames the bundle's machines according to mapping,
+// which maps the id of a machine declared in the machines section to
+// its new id, such as the id of an existing machine in the model that
+// the bundle is deployed to. Machines not in mapping keep their ids.
+// Placements in the applications' To sections that refer to a renamed
+// machine, with or without a container type, are rewritten to match.
+//
+// An error is returned, and the bundle left unchanged, if mapping
+// refers to a machine the bundle does not declare, or to an invalid
+// machine id, or if two machines would end up with the same id.
+func (bd *BundleData) RemapMachines(mapping map[string]string) error {
+	from := make([]string, 0, len(mapping))
+	for id := range mapping {
+		from = append(from, id)
+	}
+	sort.Strings(from)
+
+	machines := make(map[string]*MachineSpec, len(bd.Machines))
+	for id, m := range bd.Machines {
+		if _, ok := mapping[id]; !ok {
+			machines[id] = m
+		}
+	}
+	for _, id := range from {
+		to := mapping[id]
+		m, ok := bd.Machines[id]
+		if !ok {
+			return errors.NotFoundf("machine %q", id)
+		}
+		if !validMachineId().MatchString(to) {
+			return errors.NotValidf("machine id %q for machine %q", to, id)
+		}
+		if _, ok := machines[to]; ok {
+			return errors.NotValidf("mapping machine %q to %q: machine %q already used", id, to, to)
+		}
+		machines[to] = m
+	}
+
+	placements := make(map[string][]string)
+	for name, app := range bd.Applications {
+		if app == nil {
+			continue
+		}
+		var changed bool
+		to := make([]string, len(app.To))
+		for i, p := range app.To {
+			to[i] = p
+			up, err := ParsePlacement(p)
+			if err != nil || up.Machine == "" {
+				// Invalid placements are left for Verify to report.
+				continue
+			}
+			if id, ok := mapping[up.Machine]; ok {
+				to[i] = id
+				if up.ContainerType != "" {
+					to[i] = up.ContainerType + ":" + id
+				}
+				changed = true
+			}
+		}
+		if changed {
+			placements[name] = to
+		}
+	}
+
+	if len(bd.Machines) > 0 {
+		bd.Machines = machines
+	}
+	for name, to := range placements {
+		bd.Applications[name].To = to
+	}
+	return nil
+}
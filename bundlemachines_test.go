@@ -0,0 +1,180 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/charm/v12"
+)
+
+type MachineLayoutSuite struct{}
+
+var _ = gc.Suite(&MachineLayoutSuite{})
+
+const machineLayoutBundle = `
+applications:
+  mysql:
+    charm: ch:mysql
+    num_units: 1
+    to: ["0"]
+machines:
+  "0":
+    constraints: mem=8G
+    zones: [us-east-1a, us-east-1b]
+    volumes:
+      root-disk:
+        size: 50G
+      disks:
+      - size: 100G
+        pool: ebs-ssd
+      - size: "512"
+`
+
+func (s *MachineLayoutSuite) TestParse(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(machineLayoutBundle), charm.WithStrictApplications())
+	c.Assert(err, jc.ErrorIsNil)
+	m := bd.Machines["0"]
+	c.Assert(m.Zones, jc.DeepEquals, []string{"us-east-1a", "us-east-1b"})
+	c.Assert(m.Volumes, jc.DeepEquals, &charm.MachineVolumes{
+		RootDisk: &charm.MachineDisk{Size: "50G"},
+		Disks: []charm.MachineDisk{
+			{Size: "100G", Pool: "ebs-ssd"},
+			{Size: "512"},
+		},
+	})
+	size, err := m.Volumes.RootDisk.SizeMB()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(size, gc.Equals, uint64(50*1024))
+	size, err = m.Volumes.Disks[1].SizeMB()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(size, gc.Equals, uint64(512))
+
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *MachineLayoutSuite) TestRoundTrip(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(machineLayoutBundle))
+	c.Assert(err, jc.ErrorIsNil)
+
+	data, err := yaml.Marshal(bd)
+	c.Assert(err, jc.ErrorIsNil)
+	bd1, err := charm.ReadBundleData(strings.NewReader(string(data)))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd1.Machines, jc.DeepEquals, bd.Machines)
+
+	data, err = json.Marshal(bd)
+	c.Assert(err, jc.ErrorIsNil)
+	var bd2 charm.BundleData
+	err = json.Unmarshal(data, &bd2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd2.Machines, jc.DeepEquals, bd.Machines)
+}
+
+func (s *MachineLayoutSuite) TestVerifyErrors(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(`
+applications:
+  mysql:
+    charm: ch:mysql
+    num_units: 1
+    to: ["0"]
+machines:
+  "0":
+    constraints: zones=us-east-1a root-disk=10G
+    zones: [us-east-1a, "bad zone", us-east-1a]
+    volumes:
+      root-disk:
+        size: 50G
+      disks:
+      - size: lots
+      - pool: ebs
+      - size: 10G
+        pool: "two words"
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, gc.FitsTypeOf, (*charm.VerificationError)(nil))
+	var messages []string
+	for _, e := range err.(*charm.VerificationError).Errors {
+		c.Check(e, jc.ErrorIs, charm.CodeInvalidMachine)
+		messages = append(messages, e.Error())
+	}
+	c.Assert(messages, jc.SameContents, []string{
+		`machine "0" declares zones in both its constraints and zones`,
+		`invalid zone "bad zone" for machine "0"`,
+		`duplicate zone "us-east-1a" for machine "0"`,
+		`machine "0" declares a root disk in both its constraints and volumes`,
+		`invalid disk 0 for machine "0": disk size lots: expected a non-negative number, got "lots"`,
+		`invalid disk 1 for machine "0": empty disk size not valid`,
+		`invalid pool "two words" for disk 2 of machine "0"`,
+	})
+}
+
+const remapMachinesBundle = `
+applications:
+  mysql:
+    charm: ch:mysql
+    num_units: 2
+    to: ["0", "lxd:1"]
+  wordpress:
+    charm: ch:wordpress
+    num_units: 2
+    to: ["mysql/0", "2"]
+machines:
+  "0":
+    constraints: mem=8G
+  "1":
+  "2":
+`
+
+func (s *MachineLayoutSuite) TestRemapMachines(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(remapMachinesBundle))
+	c.Assert(err, jc.ErrorIsNil)
+	err = bd.RemapMachines(map[string]string{"0": "7", "1": "2", "2": "0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd.Machines, jc.DeepEquals, map[string]*charm.MachineSpec{
+		"7": {Constraints: "mem=8G"},
+		"2": nil,
+		"0": nil,
+	})
+	c.Assert(bd.Applications["mysql"].To, jc.DeepEquals, []string{"7", "lxd:2"})
+	c.Assert(bd.Applications["wordpress"].To, jc.DeepEquals, []string{"mysql/0", "0"})
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *MachineLayoutSuite) TestRemapMachinesErrors(c *gc.C) {
+	for i, test := range []struct {
+		mapping map[string]string
+		err     string
+	}{{
+		mapping: map[string]string{"3": "4"},
+		err:     `machine "3" not found`,
+	}, {
+		mapping: map[string]string{"0": "lxd:4"},
+		err:     `machine id "lxd:4" for machine "0" not valid`,
+	}, {
+		mapping: map[string]string{"0": "1"},
+		err:     `mapping machine "0" to "1": machine "1" already used not valid`,
+	}, {
+		mapping: map[string]string{"0": "5", "1": "5"},
+		err:     `mapping machine "1" to "5": machine "5" already used not valid`,
+	}} {
+		c.Logf("test %d: %v", i, test.mapping)
+		bd, err := charm.ReadBundleData(strings.NewReader(remapMachinesBundle))
+		c.Assert(err, jc.ErrorIsNil)
+		err = bd.RemapMachines(test.mapping)
+		c.Check(err, gc.ErrorMatches, test.err)
+
+		// The bundle is left unchanged.
+		c.Check(bd.Machines, gc.HasLen, 3)
+		c.Check(bd.Applications["mysql"].To, jc.DeepEquals, []string{"0", "lxd:1"})
+	}
+}
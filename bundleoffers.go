@@ -0,0 +1,118 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/names/v5"
+)
+
+// validOfferAccess holds the access levels that may be granted on an
+// offer.
+var validOfferAccess = set.NewStrings("read", "consume", "admin")
+
+// offerSpec is the serialized form of OfferSpec, whose ACL may either
+// map users to access levels or access levels to lists of users.
+type offerSpec struct {
+	Endpoints []string               `json:"endpoints" yaml:"endpoints"`
+	ACL       map[string]interface{} `json:"acl,omitempty" yaml:"acl,omitempty"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface. The ACL may
+// be given as users mapped to access levels, as in {bob: consume}, or
+// as access levels mapped to lists of users, as in {consume: [bob]}, or
+// as a mixture of both.
+func (o *OfferSpec) UnmarshalYAML(f func(interface{}) error) error {
+	var in offerSpec
+	if err := f(&in); err != nil {
+		return err
+	}
+	return o.fromSerialized(in)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting
+// the same forms of ACL as UnmarshalYAML.
+func (o *OfferSpec) UnmarshalJSON(b []byte) error {
+	var in offerSpec
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	return o.fromSerialized(in)
+}
+
+// fromSerialized sets o from its serialized form.
+func (o *OfferSpec) fromSerialized(in offerSpec) error {
+	acl, err := parseOfferACL(in.ACL)
+	if err != nil {
+		return err
+	}
+	*o = OfferSpec{
+		Endpoints: in.Endpoints,
+		ACL:       acl,
+	}
+	return nil
+}
+
+// parseOfferACL returns the given ACL with users mapped to access
+// levels. Entries whose value is a list map an access level to the
+// users granted it.
+func parseOfferACL(in map[string]interface{}) (map[string]string, error) {
+	if in == nil {
+		return nil, nil
+	}
+	acl := make(map[string]string, len(in))
+	grant := func(user, access string) error {
+		if prev, ok := acl[user]; ok && prev != access {
+			return fmt.Errorf("offer acl grants user %q both %q and %q access", user, prev, access)
+		}
+		acl[user] = access
+		return nil
+	}
+	// Visit the entries in order, so that conflicts are reported
+	// consistently.
+	keys := make([]string, 0, len(in))
+	for key := range in {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch value := in[key].(type) {
+		case string:
+			if err := grant(key, value); err != nil {
+				return nil, err
+			}
+		case []interface{}:
+			for _, user := range value {
+				user, ok := user.(string)
+				if !ok {
+					return nil, fmt.Errorf("offer acl %q expected a list of user names, got %v", key, value)
+				}
+				if err := grant(user, key); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, fmt.Errorf("offer acl %q expected an access level or a list of user names, got %v", key, in[key])
+		}
+	}
+	return acl, nil
+}
+
+// verifyOfferACL checks the users and access levels granted by the ACL
+// of the named offer of the named application.
+func (verifier *bundleDataVerifier) verifyOfferACL(appName, offerName string, acl map[string]string) {
+	for user, access := range acl {
+		if !names.IsValidUser(user) {
+			verifier.addErrorf(CodeInvalidOffer, "invalid user %q in acl of offer %q in application %q", user, offerName, appName)
+		}
+		if !validOfferAccess.Contains(access) {
+			verifier.addErrorf(CodeInvalidOffer, "invalid access %q for user %q in acl of offer %q in application %q (expected one of %s)",
+				access, user, offerName, appName, "read, consume, admin")
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/charm/v12"
+)
+
+type OfferACLSuite struct{}
+
+var _ = gc.Suite(&OfferACLSuite{})
+
+const offerACLBundle = `
+applications:
+  mysql:
+    charm: ch:mysql
+    num_units: 1
+    offers:
+      db:
+        endpoints: [db]
+        acl:
+          admin: [admin]
+          consume: [bob, alice@external]
+          carol: read
+`
+
+func (s *OfferACLSuite) TestParse(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(offerACLBundle))
+	c.Assert(err, jc.ErrorIsNil)
+	offer := bd.Applications["mysql"].Offers["db"]
+	c.Assert(offer, jc.DeepEquals, &charm.OfferSpec{
+		Endpoints: []string{"db"},
+		ACL: map[string]string{
+			"admin":          "admin",
+			"bob":            "consume",
+			"alice@external": "consume",
+			"carol":          "read",
+		},
+	})
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *OfferACLSuite) TestRoundTrip(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(offerACLBundle))
+	c.Assert(err, jc.ErrorIsNil)
+
+	data, err := yaml.Marshal(bd)
+	c.Assert(err, jc.ErrorIsNil)
+	bd1, err := charm.ReadBundleData(strings.NewReader(string(data)))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd1.Applications, jc.DeepEquals, bd.Applications)
+
+	data, err = json.Marshal(bd)
+	c.Assert(err, jc.ErrorIsNil)
+	var bd2 charm.BundleData
+	err = json.Unmarshal(data, &bd2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd2.Applications, jc.DeepEquals, bd.Applications)
+}
+
+func (s *OfferACLSuite) TestJSON(c *gc.C) {
+	var offer charm.OfferSpec
+	err := json.Unmarshal([]byte(`{"endpoints": ["db"], "acl": {"consume": ["bob"], "admin": "admin"}}`), &offer)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(offer.ACL, jc.DeepEquals, map[string]string{"bob": "consume", "admin": "admin"})
+
+	err = json.Unmarshal([]byte(`{"acl": {"consume": [5]}}`), &offer)
+	c.Assert(err, gc.ErrorMatches, `offer acl "consume" expected a list of user names, got \[5\]`)
+}
+
+func (s *OfferACLSuite) TestParseErrors(c *gc.C) {
+	for i, test := range []struct {
+		acl string
+		err string
+	}{{
+		acl: `{consume: [bob], bob: admin}`,
+		err: `unmarshal document 0: offer acl grants user "bob" both "admin" and "consume" access`,
+	}, {
+		acl: `{consume: [bob], read: [bob]}`,
+		err: `unmarshal document 0: offer acl grants user "bob" both "consume" and "read" access`,
+	}, {
+		acl: `{consume: {bob: true}}`,
+		err: `unmarshal document 0: offer acl "consume" expected an access level or a list of user names, got map\[bob:true\]`,
+	}} {
+		c.Logf("test %d: %s", i, test.acl)
+		_, err := charm.ReadBundleData(strings.NewReader(`
+applications:
+  mysql:
+    charm: ch:mysql
+    offers:
+      db:
+        endpoints: [db]
+        acl: ` + test.acl + `
+`))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *OfferACLSuite) TestVerifyErrors(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(`
+applications:
+  mysql:
+    charm: ch:mysql
+    num_units: 1
+    offers:
+      db:
+        endpoints: [db]
+        acl:
+          consume: ["not a user"]
+          bob: write
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, gc.FitsTypeOf, (*charm.VerificationError)(nil))
+	var messages []string
+	for _, e := range err.(*charm.VerificationError).Errors {
+		c.Check(e, jc.ErrorIs, charm.CodeInvalidOffer)
+		messages = append(messages, e.Error())
+	}
+	c.Assert(messages, jc.SameContents, []string{
+		`invalid user "not a user" in acl of offer "db" in application "mysql"`,
+		`invalid access "write" for user "bob" in acl of offer "db" in application "mysql" (expected one of read, consume, admin)`,
+	})
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// DeployPlan holds the order in which the parts of a bundle should be
+// deployed, as returned by BundleData.DeployOrder. Deployers should
+// add the machines, consume the SAAS entries, deploy the applications,
+// add the relations and create the offers, each in the order given.
+type DeployPlan struct {
+	// Machines holds the ids of the bundle's machines, in numeric
+	// order.
+	Machines []string
+
+	// Saas holds the names of the bundle's SAAS entries, sorted.
+	Saas []string
+
+	// Applications holds the names of the bundle's applications.
+	// Applications whose units are placed on the units of other
+	// applications come after them, and subordinate applications come
+	// after all principal ones. Applications are otherwise sorted by
+	// name.
+	Applications []string
+
+	// Relations holds the relations of the bundle, expanded as by
+	// Normalize. Each relation comes after those whose applications
+	// are all deployed earlier; relations are otherwise in the order in
+	// which the bundle declares them.
+	Relations [][]string
+
+	// Offers holds the offers of the bundle's applications, ordered
+	// as their applications and then by offer name.
+	Offers []DeployOffer
+}
+
+// DeployOffer identifies an offer of a bundle application.
+type DeployOffer struct {
+	// Application holds the name of the offering application.
+	Application string
+
+	// Offer holds the name of the offer.
+	Offer string
+}
+
+// DeployOrder returns the order in which the parts of the bundle should
+// be deployed. If charms is not nil, it should hold the charms used by
+// the bundle's applications as described by VerifyWithCharms, and is
+// used to find the subordinate applications; otherwise no application
+// is taken to be subordinate. The order only depends on the content of
+// the bundle, so that deploying the same bundle twice follows the same
+// plan.
+//
+// An error is returned if the applications' placements refer to each
+// other in a cycle, which Verify does not check.
+func (bd *BundleData) DeployOrder(charms map[string]Charm) (*DeployPlan, error) {
+	plan := &DeployPlan{}
+	for id := range bd.Machines {
+		plan.Machines = append(plan.Machines, id)
+	}
+	sort.Slice(plan.Machines, func(i, j int) bool {
+		return lessMachineId(plan.Machines[i], plan.Machines[j])
+	})
+	for name := range bd.Saas {
+		plan.Saas = append(plan.Saas, name)
+	}
+	sort.Strings(plan.Saas)
+
+	apps, err := bd.applicationOrder(charms)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	plan.Applications = apps
+
+	position := make(map[string]int)
+	for i, name := range apps {
+		position[name] = i
+	}
+	// Relations are ordered by the last application they need, which
+	// may be a SAAS entry deployed before every application.
+	last := func(rel []string) int {
+		n := -1
+		for _, ep := range rel {
+			name, _, _ := strings.Cut(ep, ":")
+			if i, ok := position[name]; ok && i > n {
+				n = i
+			}
+		}
+		return n
+	}
+	plan.Relations = expandRelations(bd.Relations)
+	sort.SliceStable(plan.Relations, func(i, j int) bool {
+		return last(plan.Relations[i]) < last(plan.Relations[j])
+	})
+
+	for _, name := range apps {
+		offers := make([]string, 0, len(bd.Applications[name].Offers))
+		for offer := range bd.Applications[name].Offers {
+			offers = append(offers, offer)
+		}
+		sort.Strings(offers)
+		for _, offer := range offers {
+			plan.Offers = append(plan.Offers, DeployOffer{Application: name, Offer: offer})
+		}
+	}
+	return plan, nil
+}
+
+// applicationOrder returns the names of the bundle's applications in
+// the order described by DeployPlan.Applications.
+func (bd *BundleData) applicationOrder(charms map[string]Charm) ([]string, error) {
+	// after holds, for each application, the applications whose units
+	// its units are placed on.
+	after := make(map[string][]string)
+	subordinate := make(map[string]bool)
+	var names []string
+	for name, app := range bd.Applications {
+		if app == nil {
+			continue
+		}
+		names = append(names, name)
+		if ch, ok := charms[app.Charm]; ok {
+			subordinate[name] = ch.Meta().Subordinate
+		}
+		for _, p := range app.To {
+			up, err := ParsePlacement(p)
+			if err != nil || up.Application == "" || up.Application == name {
+				// Invalid placements are left for Verify to report.
+				continue
+			}
+			if _, ok := bd.Applications[up.Application]; ok {
+				after[name] = append(after[name], up.Application)
+			}
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if subordinate[names[i]] != subordinate[names[j]] {
+			return !subordinate[names[i]]
+		}
+		return names[i] < names[j]
+	})
+
+	var order []string
+	done := make(map[string]bool)
+	for len(order) < len(names) {
+		// Deploy the first application, in the sorted order, whose
+		// placement targets are all deployed.
+		next := ""
+		for _, name := range names {
+			if done[name] {
+				continue
+			}
+			ready := true
+			for _, dep := range after[name] {
+				ready = ready && done[dep]
+			}
+			if ready {
+				next = name
+				break
+			}
+		}
+		if next == "" {
+			var cycle []string
+			for _, name := range names {
+				if !done[name] {
+					cycle = append(cycle, name)
+				}
+			}
+			return nil, errors.NotValidf("placement cycle between applications %s", strings.Join(cycle, ", "))
+		}
+		done[next] = true
+		order = append(order, next)
+	}
+	return order, nil
+}
+
+// lessMachineId reports whether the machine id a sorts before b. Numeric
+// ids are compared as numbers.
+func lessMachineId(a, b string) bool {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil && na != nb {
+		return na < nb
+	}
+	return a < b
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type DeployOrderSuite struct{}
+
+var _ = gc.Suite(&DeployOrderSuite{})
+
+const deployOrderBundle = `
+machines:
+    "10": {}
+    "2": {}
+    "1": {}
+saas:
+    logs:
+        url: other:admin/default.logs
+applications:
+    wordpress:
+        charm: wordpress
+        num_units: 2
+        to: ["lxd:mysql/0", "1"]
+    mysql:
+        charm: mysql
+        num_units: 1
+        to: ["2"]
+        offers:
+            replica:
+                endpoints: [server]
+            db:
+                endpoints: [server]
+    telegraf:
+        charm: telegraf
+    haproxy:
+        charm: haproxy
+        num_units: 1
+        to: ["10"]
+        offers:
+            frontend:
+                endpoints: [website]
+relations:
+    - ["telegraf", "wordpress", "mysql"]
+    - ["haproxy", "wordpress"]
+    - ["mysql", "logs"]
+`
+
+func (s *DeployOrderSuite) TestDeployOrder(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(deployOrderBundle))
+	c.Assert(err, jc.ErrorIsNil)
+	charms := map[string]charm.Charm{
+		"wordpress": testCharm("wordpress", "website:http | db:mysql"),
+		"mysql":     testCharm("mysql", "server:mysql"),
+		"telegraf":  testCharm("telegraf-sub", "| juju-info:juju-info"),
+		"haproxy":   testCharm("haproxy", "| reverseproxy:http"),
+	}
+	plan, err := bd.DeployOrder(charms)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(plan, jc.DeepEquals, &charm.DeployPlan{
+		Machines:     []string{"1", "2", "10"},
+		Saas:         []string{"logs"},
+		Applications: []string{"haproxy", "mysql", "wordpress", "telegraf"},
+		Relations: [][]string{
+			{"mysql", "logs"},
+			{"haproxy", "wordpress"},
+			{"telegraf", "wordpress"},
+			{"telegraf", "mysql"},
+		},
+		Offers: []charm.DeployOffer{
+			{Application: "haproxy", Offer: "frontend"},
+			{Application: "mysql", Offer: "db"},
+			{Application: "mysql", Offer: "replica"},
+		},
+	})
+
+	// Without charms no application is known to be subordinate.
+	plan, err = bd.DeployOrder(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(plan.Applications, jc.DeepEquals, []string{"haproxy", "mysql", "telegraf", "wordpress"})
+	c.Assert(plan.Relations, jc.DeepEquals, [][]string{
+		{"mysql", "logs"},
+		{"telegraf", "mysql"},
+		{"telegraf", "wordpress"},
+		{"haproxy", "wordpress"},
+	})
+}
+
+func (s *DeployOrderSuite) TestDeployOrderIsDeterministic(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(deployOrderBundle))
+	c.Assert(err, jc.ErrorIsNil)
+	want, err := bd.DeployOrder(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	for i := 0; i < 10; i++ {
+		plan, err := bd.DeployOrder(nil)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(plan, jc.DeepEquals, want)
+	}
+}
+
+func (s *DeployOrderSuite) TestDeployOrderPlacementCycle(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(`
+applications:
+    a:
+        charm: a
+        num_units: 1
+        to: ["b/0"]
+    b:
+        charm: b
+        num_units: 1
+        to: ["lxd:a/0"]
+    c:
+        charm: c
+        num_units: 1
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = bd.DeployOrder(nil)
+	c.Assert(err, gc.ErrorMatches, `placement cycle between applications a, b not valid`)
+	c.Assert(err, jc.ErrorIs, errors.NotValid)
+}
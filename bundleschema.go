@@ -0,0 +1,261 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// bundleFieldDocs documents each field of a bundle, keyed by its path
+// as described by BundleFieldDocs.
+var bundleFieldDocs = map[string]string{
+	"bundle":           `The type of the bundle: "machine" or empty for machine bundles, or "kubernetes".`,
+	"applications":     "The applications to deploy, keyed by application name.",
+	"services":         `The applications to deploy, keyed by application name. Deprecated: use "applications".`,
+	"machines":         "The machines to create, keyed by machine id, which may be referred to by placement directives.",
+	"saas":             "The offers from other models to consume, keyed by the name of the local SAAS application.",
+	"series":           "The default series for applications and machines. Deprecated: use default-base.",
+	"default-base":     `The default base for applications and machines, such as "ubuntu@22.04".`,
+	"relations":        `The relations to add, each either a pair of endpoints such as ["wordpress:db", "mysql:db"] or a mapping with provider, requirer and optional via keys.`,
+	"include":          "Other bundles whose applications, SAAS entries and relations are merged into this one.",
+	"include.*.bundle": "The reference to the included bundle, such as a path or URL.",
+	"include.*.prefix": "The prefix given, followed by a hyphen, to the names of the included applications and SAAS entries.",
+	"tags":             "Tags describing the bundle.",
+	"description":      "A description of the bundle.",
+
+	"saas.*.url": `The URL of the consumed offer, such as "admin/default.mysql".`,
+
+	"machines.*.constraints":                              "The constraints with which the machine is provisioned.",
+	"machines.*.annotations":                              "Annotations to set on the machine.",
+	"machines.*.series":                                   "The series of the machine. Series and base cannot be mixed.",
+	"machines.*.base":                                     "The base of the machine. Series and base cannot be mixed.",
+	"machines.*.zones":                                    "The availability zones in which the machine may be provisioned, in order of preference.",
+	"machines.*.volumes":                                  "The disks with which the machine is provisioned.",
+	"machines.*.volumes.root-disk":                        "The machine's root disk. If omitted, the provider's default root disk is used.",
+	"machines.*.volumes.root-disk.size":                   `The size of the disk, such as "100G". A number without a suffix is in megabytes.`,
+	"machines.*.volumes.root-disk.pool":                   "The root disk source from which the disk is provisioned.",
+	"machines.*.volumes.disks":                            "The additional disks attached to the machine.",
+	"machines.*.volumes.disks.*.size":                     `The size of the disk, such as "100G". A number without a suffix is in megabytes.`,
+	"machines.*.volumes.disks.*.pool":                     "The storage pool from which the disk is provisioned.",
+	"applications.*.charm":                                "The charm to deploy, as a charm URL or a local path.",
+	"applications.*.channel":                              "The channel from which to deploy the charm.",
+	"applications.*.revision":                             "The revision of the charm to deploy.",
+	"applications.*.series":                               "The series to deploy the application on. Series and base cannot be mixed.",
+	"applications.*.base":                                 "The base to deploy the application on. Series and base cannot be mixed.",
+	"applications.*.resources":                            "The resources to deploy, keyed by resource name, each a revision number or a path to a local file.",
+	"applications.*.num_units":                            "The number of units to deploy. For Kubernetes bundles this is an alias for scale.",
+	"applications.*.scale":                                "The number of pods to deploy. For bundles that do not declare their type this is an alias for num_units; it is not valid for machine bundles.",
+	"applications.*.to":                                   `Where to place the units, such as "new", "0", "lxd:1" or "wordpress/0". For Kubernetes bundles, a single node selector.`,
+	"applications.*.placement":                            "The pod placement for Kubernetes applications.",
+	"applications.*.expose":                               "Whether the application is exposed to all.",
+	"applications.*.exposed-endpoints":                    `The spaces and CIDRs that may reach each endpoint once exposed, keyed by endpoint name, with "" for all endpoints. Cannot be used with expose.`,
+	"applications.*.exposed-endpoints.*.expose-to-spaces": "The spaces that may reach the endpoint.",
+	"applications.*.exposed-endpoints.*.expose-to-cidrs":  "The CIDRs that may reach the endpoint.",
+	"applications.*.options":                              "The charm configuration values to set.",
+	"applications.*.annotations":                          "Annotations to set on the application.",
+	"applications.*.constraints":                          "The constraints for new machines created for the application's units.",
+	"applications.*.storage":                              `The storage constraints, such as "ebs,3,10G", keyed by store name.`,
+	"applications.*.devices":                              `The device constraints, such as "2,nvidia.com/gpu", keyed by device name.`,
+	"applications.*.bindings":                             `The spaces to which endpoints are bound, keyed by endpoint name, with "" for the default space.`,
+	"applications.*.offers":                               "The offers to create for the application, keyed by offer name.",
+	"applications.*.offers.*.endpoints":                   "The endpoints made available by the offer.",
+	"applications.*.offers.*.acl":                         "The access granted to users, either as users mapped to an access level or as access levels mapped to lists of users.",
+	"applications.*.plan":                                 "The plan under which the application is deployed.",
+	"applications.*.trust":                                "Whether the application is trusted with the model's credentials.",
+}
+
+// BundleFieldDocs returns the documentation for each of the fields a
+// bundle may hold, keyed by the field's path. A path joins the names of
+// the fields leading to the field with dots, with "*" standing for any
+// entry of a map or list, as in "applications.*.charm" or
+// "machines.*.volumes.disks.*.size".
+func BundleFieldDocs() map[string]string {
+	docs := make(map[string]string, len(bundleFieldDocs))
+	for path, doc := range bundleFieldDocs {
+		docs[path] = doc
+	}
+	return docs
+}
+
+// BundleJSONSchema returns a JSON schema, in draft 7 form, describing
+// the bundle data read by ReadBundleData. It is generated from the
+// BundleData structure, so that editors and external validators may
+// check bundles against the same fields as this package. Fields are
+// described using BundleFieldDocs.
+//
+// As ReadBundleData reads numbers and booleans given for string fields,
+// such as machine ids in placement directives, as strings, the schema
+// accepts any scalar for them. Map keys given as numbers, such as
+// machine ids, must be converted to strings when the bundle is
+// converted to JSON.
+//
+// The schema only checks the structure of a bundle; the bundle must
+// still be verified, such as with BundleData.Verify.
+func BundleJSONSchema() map[string]interface{} {
+	var gen bundleSchemaGenerator
+	schema := gen.typeSchema("", reflect.TypeOf(BundleData{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "Juju bundle"
+	return schema
+}
+
+// bundleSchemaPaths returns the paths of all the fields described by
+// BundleJSONSchema.
+func bundleSchemaPaths() []string {
+	var gen bundleSchemaGenerator
+	gen.typeSchema("", reflect.TypeOf(BundleData{}))
+	sort.Strings(gen.paths)
+	return gen.paths
+}
+
+// bundleSchemaGenerator generates the JSON schema of a bundle,
+// recording the paths of the fields it describes.
+type bundleSchemaGenerator struct {
+	paths []string
+}
+
+// fieldSchemas holds the schemas of the fields whose serialised form is
+// not described by their Go type, keyed by path.
+var fieldSchemas = map[string]func() map[string]interface{}{
+	"services": func() map[string]interface{} {
+		return map[string]interface{}{
+			"allOf":      []interface{}{map[string]interface{}{"$ref": "#/properties/applications"}},
+			"deprecated": true,
+		}
+	},
+	"relations": func() map[string]interface{} {
+		endpoint := scalarSchema()
+		return map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{
+						"type":  "array",
+						"items": endpoint,
+					},
+					map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"provider": endpoint,
+							"requirer": endpoint,
+							"via":      endpoint,
+						},
+						"required":             []interface{}{"provider", "requirer"},
+						"additionalProperties": false,
+					},
+				},
+			},
+		}
+	},
+	"applications.*.offers.*.acl": func() map[string]interface{} {
+		return map[string]interface{}{
+			"type": "object",
+			"additionalProperties": map[string]interface{}{
+				"oneOf": []interface{}{
+					scalarSchema(),
+					map[string]interface{}{"type": "array", "items": scalarSchema()},
+				},
+			},
+		}
+	},
+}
+
+// scalarSchema returns the schema of a string field, whose value may be
+// given as any scalar as the YAML decoder converts numbers and booleans
+// to strings.
+func scalarSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": []interface{}{"string", "number", "boolean"},
+	}
+}
+
+// typeSchema returns the schema of the value of type t found at path.
+func (gen *bundleSchemaGenerator) typeSchema(path string, t reflect.Type) map[string]interface{} {
+	if schema, ok := fieldSchemas[path]; ok {
+		return schema()
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return scalarSchema()
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": gen.typeSchema(joinFieldPath(path, "*"), t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": gen.typeSchema(joinFieldPath(path, "*"), t.Elem()),
+		}
+	case reflect.Struct:
+		return gen.structSchema(path, t)
+	}
+	panic(fmt.Sprintf("no JSON schema for %s at %q", t, path))
+}
+
+// optionalFields holds the paths of the fields that are not omitted
+// when empty but need not be given, as overlays may leave them out.
+var optionalFields = map[string]bool{
+	// An overlay may change the ACL of an offer without repeating
+	// its endpoints.
+	"applications.*.offers.*.endpoints": true,
+}
+
+// structSchema returns the schema of the struct of type t found at
+// path, which describes the fields serialised to YAML. Fields that are
+// not omitted when empty are required, unless listed in optionalFields.
+func (gen *bundleSchemaGenerator) structSchema(path string, t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []interface{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fieldPath := joinFieldPath(path, name)
+		gen.paths = append(gen.paths, fieldPath)
+		schema := gen.typeSchema(fieldPath, field.Type)
+		if doc, ok := bundleFieldDocs[fieldPath]; ok {
+			schema["description"] = doc
+		}
+		properties[name] = schema
+		if opts == "" && !optionalFields[fieldPath] {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// joinFieldPath returns the path of the named field or entry of the
+// value found at path.
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/gojsonschema"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/juju/charm/v12"
+)
+
+type BundleSchemaSuite struct{}
+
+var _ = gc.Suite(&BundleSchemaSuite{})
+
+func (*BundleSchemaSuite) TestFieldDocsCoverSchema(c *gc.C) {
+	docs := charm.BundleFieldDocs()
+	paths := make([]string, 0, len(docs))
+	for path, doc := range docs {
+		c.Check(doc, gc.Not(gc.Equals), "", gc.Commentf("path %q", path))
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	c.Assert(paths, jc.DeepEquals, charm.BundleSchemaPaths())
+}
+
+func (*BundleSchemaSuite) TestSchemaIsJSON(c *gc.C) {
+	data, err := json.Marshal(charm.BundleJSONSchema())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = gojsonschema.NewSchema(gojsonschema.NewStringLoader(string(data)))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (*BundleSchemaSuite) TestSchemaDescribesFields(c *gc.C) {
+	schema := charm.BundleJSONSchema()
+	apps := schema["properties"].(map[string]interface{})["applications"].(map[string]interface{})
+	app := apps["additionalProperties"].(map[string]interface{})
+	charmField := app["properties"].(map[string]interface{})["charm"].(map[string]interface{})
+	c.Check(charmField, jc.DeepEquals, map[string]interface{}{
+		"type":        []interface{}{"string", "number", "boolean"},
+		"description": charm.BundleFieldDocs()["applications.*.charm"],
+	})
+}
+
+var bundleSchemaTests = []struct {
+	about  string
+	bundle string
+	errors []string
+}{{
+	about: "valid bundle",
+	bundle: `
+default-base: ubuntu@22.04
+applications:
+    wordpress:
+        charm: wordpress
+        revision: 12
+        num_units: 2
+        to: ["0", "lxd:0"]
+        options:
+            blog-title: My Blog
+            port: 80
+        annotations:
+            gui-x: 100
+        storage:
+            data: ebs,1,10G
+        resources:
+            theme: 3
+        offers:
+            blog:
+                endpoints: [website]
+                acl:
+                    admin: admin
+                    consume: [bob, alice]
+    mysql:
+        charm: mysql
+machines:
+    "0":
+        constraints: mem=4G
+        volumes:
+            root-disk:
+                size: 50G
+            disks:
+            - size: 100G
+              pool: ebs-ssd
+saas:
+    logs:
+        url: admin/default.logs
+relations:
+- ["wordpress:db", "mysql:db"]
+- provider: wordpress:logging
+  requirer: logs:logging
+include:
+- bundle: ./fragments/cache.yaml
+  prefix: cache
+`,
+}, {
+	about: "scalars read as strings",
+	bundle: `
+applications:
+    wordpress:
+        charm: wordpress
+        num_units: 2
+        to: [0, lxd:1]
+        options:
+            port: 80
+        annotations:
+            gui-x: 100
+            visible: true
+        bindings:
+            "": 0
+machines:
+    0:
+        annotations:
+            rack: 12
+    1: {}
+`,
+}, {
+	about: "unknown field",
+	bundle: `
+applications:
+    wordpress:
+        charm: wordpress
+        num-units: 1
+`,
+	errors: []string{`\(root\)\.applications : additional property "num-units" is not allowed, .*`},
+}, {
+	about: "wrong type",
+	bundle: `
+applications:
+    wordpress:
+        charm: wordpress
+        num_units: two
+`,
+	errors: []string{`\(root\)\.applications\.num_units : must be of type integer, given "two"`},
+}, {
+	about: "disk without size",
+	bundle: `
+machines:
+    "0":
+        volumes:
+            disks:
+            - pool: ebs
+`,
+	errors: []string{`\(root\)\.machines\.volumes\.disks\.0 : "size" property is missing and required, .*`},
+}, {
+	about: "relation without requirer",
+	bundle: `
+relations:
+- provider: wordpress:logging
+`,
+	errors: []string{
+		`\(root\)\.relations\.0 : "requirer" property is missing and required, .*`,
+		`\(root\)\.relations\.0 : must validate one and only one schema \(oneOf\), .*`,
+	},
+}}
+
+func (*BundleSchemaSuite) TestSchemaValidation(c *gc.C) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(charm.BundleJSONSchema()))
+	c.Assert(err, jc.ErrorIsNil)
+	for i, test := range bundleSchemaTests {
+		c.Logf("test %d: %s", i, test.about)
+		var doc interface{}
+		err := yamlv3.Unmarshal([]byte(strings.TrimSpace(test.bundle)), &doc)
+		c.Assert(err, jc.ErrorIsNil)
+
+		result, err := schema.Validate(gojsonschema.NewGoLoader(jsonDocument(doc)))
+		c.Assert(err, jc.ErrorIsNil)
+		var errs []string
+		for _, e := range result.Errors() {
+			errs = append(errs, e.String())
+		}
+		sort.Strings(errs)
+		c.Check(errs, gc.HasLen, len(test.errors))
+		for j, e := range errs {
+			if j < len(test.errors) {
+				c.Check(e, gc.Matches, test.errors[j])
+			}
+		}
+	}
+}
+
+func (*BundleSchemaSuite) TestSchemaValidatesTestBundles(c *gc.C) {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(charm.BundleJSONSchema()))
+	c.Assert(err, jc.ErrorIsNil)
+	paths, err := filepath.Glob("internal/test-charm-repo/bundle/*/bundle.yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(paths, gc.Not(gc.HasLen), 0)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		c.Assert(err, jc.ErrorIsNil)
+		docs, err := charm.SplitBundleDocuments(f)
+		_ = f.Close()
+		c.Assert(err, jc.ErrorIsNil)
+		for _, doc := range docs {
+			c.Logf("%s: document %d", path, doc.Index)
+			var raw interface{}
+			err := yamlv3.Unmarshal(doc.Data, &raw)
+			c.Assert(err, jc.ErrorIsNil)
+			result, err := schema.Validate(gojsonschema.NewGoLoader(jsonDocument(raw)))
+			c.Assert(err, jc.ErrorIsNil)
+
+			// The schema accepts exactly the documents that can be
+			// read strictly, which reports unknown fields.
+			var bd charm.BundleData
+			strictErr := yaml.UnmarshalStrict(doc.Data, &bd)
+			c.Check(result.Valid(), gc.Equals, strictErr == nil, gc.Commentf("schema errors %v, strict error %v", result.Errors(), strictErr))
+		}
+	}
+}
+
+// jsonDocument returns the YAML document v with any map keys converted
+// to strings, so that it can be validated as JSON.
+func jsonDocument(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonDocument(value)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = jsonDocument(value)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, value := range v {
+			l[i] = jsonDocument(value)
+		}
+		return l
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/errors"
+)
+
+// SeriesBaseMapper returns the base equivalent to the given series.
+type SeriesBaseMapper func(series string) (Base, error)
+
+// DefaultSeriesBaseMapper maps Ubuntu and CentOS series onto their
+// equivalent bases, such as "jammy" onto ubuntu@22.04.
+func DefaultSeriesBaseMapper(series string) (Base, error) {
+	return baseFromSeries(series)
+}
+
+// SeriesMigration records a series replaced by a base by
+// MigrateSeriesToBases.
+type SeriesMigration struct {
+	// Location describes where the series was declared: "bundle",
+	// or the machine or application, such as `application "mysql"`.
+	Location string
+
+	// Field holds the field that held the series: "series", or
+	// "charm" for a series in an application's charm URL.
+	Field string
+
+	// Series holds the series that was removed.
+	Series string
+
+	// Base holds the base that was set in its place. It is empty if
+	// the location already declared a base.
+	Base string
+}
+
+// String returns a description of the migration.
+func (m SeriesMigration) String() string {
+	if m.Base == "" {
+		return fmt.Sprintf("%s: removed series %q from %s", m.Location, m.Series, m.Field)
+	}
+	return fmt.Sprintf("%s: replaced series %q in %s with base %q", m.Location, m.Series, m.Field, m.Base)
+}
+
+// MigrateSeriesToBases rewrites the series declared by the bundle, its
+// machines and its applications, including those in the applications'
+// charm URLs, as bases, using mapping to find the base for each series.
+// If mapping is nil, DefaultSeriesBaseMapper is used. A base already
+// declared alongside a series is kept. The changes made are returned,
+// sorted by location.
+//
+// If any series cannot be mapped, an error is returned and the bundle
+// is left unchanged.
+func (bd *BundleData) MigrateSeriesToBases(mapping SeriesBaseMapper) ([]SeriesMigration, error) {
+	if mapping == nil {
+		mapping = DefaultSeriesBaseMapper
+	}
+
+	// Map every series first, so that the bundle is either migrated
+	// completely or not at all.
+	bases := make(map[string]string)
+	mapSeries := func(location, series string) error {
+		if series == "" {
+			return nil
+		}
+		if _, ok := bases[series]; ok {
+			return nil
+		}
+		base, err := mapping(series)
+		if err != nil {
+			return errors.Annotatef(err, "%s: cannot migrate series %q", location, series)
+		}
+		bases[series] = base.Name + "@" + base.Channel.Track
+		return nil
+	}
+	if err := mapSeries("bundle", bd.Series); err != nil {
+		return nil, err
+	}
+	for id, m := range bd.Machines {
+		if m == nil {
+			continue
+		}
+		if err := mapSeries(fmt.Sprintf("machine %q", id), m.Series); err != nil {
+			return nil, err
+		}
+	}
+	charmURLs := make(map[string]*URL)
+	for name, app := range bd.Applications {
+		if app == nil {
+			continue
+		}
+		location := fmt.Sprintf("application %q", name)
+		if err := mapSeries(location, app.Series); err != nil {
+			return nil, err
+		}
+		curl, err := ParseURL(app.Charm)
+		if err != nil || curl.Series == "" {
+			continue
+		}
+		if err := mapSeries(location, curl.Series); err != nil {
+			return nil, err
+		}
+		charmURLs[name] = curl
+	}
+
+	var changes []SeriesMigration
+	migrate := func(location, field string, series string, base *string) {
+		change := SeriesMigration{Location: location, Field: field, Series: series}
+		if *base == "" {
+			*base = bases[series]
+			change.Base = *base
+		}
+		changes = append(changes, change)
+	}
+	if bd.Series != "" {
+		migrate("bundle", "series", bd.Series, &bd.DefaultBase)
+		bd.Series = ""
+	}
+	for id, m := range bd.Machines {
+		if m == nil || m.Series == "" {
+			continue
+		}
+		migrate(fmt.Sprintf("machine %q", id), "series", m.Series, &m.Base)
+		m.Series = ""
+	}
+	for name, app := range bd.Applications {
+		if app == nil {
+			continue
+		}
+		location := fmt.Sprintf("application %q", name)
+		if app.Series != "" {
+			migrate(location, "series", app.Series, &app.Base)
+			app.Series = ""
+		}
+		if curl, ok := charmURLs[name]; ok {
+			migrate(location, "charm", curl.Series, &app.Base)
+			app.Charm = curl.WithSeries("").String()
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Location < changes[j].Location
+	})
+	return changes, nil
+}
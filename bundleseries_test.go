@@ -0,0 +1,88 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type SeriesMigrationSuite struct{}
+
+var _ = gc.Suite(&SeriesMigrationSuite{})
+
+func seriesBundle() *charm.BundleData {
+	return &charm.BundleData{
+		Series: "jammy",
+		Applications: map[string]*charm.ApplicationSpec{
+			"mysql":     {Charm: "ch:amd64/focal/mysql-3", Series: "focal"},
+			"wordpress": {Charm: "ch:amd64/xenial/wordpress"},
+			"haproxy":   {Charm: "ch:haproxy", Base: "ubuntu@20.04", Series: "focal"},
+		},
+		Machines: map[string]*charm.MachineSpec{
+			"0": {Series: "bionic"},
+			"1": nil,
+		},
+	}
+}
+
+func (s *SeriesMigrationSuite) TestMigrateSeriesToBases(c *gc.C) {
+	bd := seriesBundle()
+	changes, err := bd.MigrateSeriesToBases(nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(bd.Series, gc.Equals, "")
+	c.Assert(bd.DefaultBase, gc.Equals, "ubuntu@22.04")
+	c.Assert(bd.Machines["0"], jc.DeepEquals, &charm.MachineSpec{Base: "ubuntu@18.04"})
+	c.Assert(bd.Applications["mysql"].Charm, gc.Equals, "ch:amd64/mysql-3")
+	c.Assert(bd.Applications["mysql"].Series, gc.Equals, "")
+	c.Assert(bd.Applications["mysql"].Base, gc.Equals, "ubuntu@20.04")
+	c.Assert(bd.Applications["wordpress"].Charm, gc.Equals, "ch:amd64/wordpress")
+	c.Assert(bd.Applications["wordpress"].Base, gc.Equals, "ubuntu@16.04")
+	c.Assert(bd.Applications["haproxy"].Base, gc.Equals, "ubuntu@20.04")
+
+	descriptions := make([]string, len(changes))
+	for i, change := range changes {
+		descriptions[i] = change.String()
+	}
+	c.Assert(descriptions, jc.SameContents, []string{
+		`bundle: replaced series "jammy" in series with base "ubuntu@22.04"`,
+		`machine "0": replaced series "bionic" in series with base "ubuntu@18.04"`,
+		`application "mysql": replaced series "focal" in series with base "ubuntu@20.04"`,
+		`application "mysql": removed series "focal" from charm`,
+		`application "wordpress": replaced series "xenial" in charm with base "ubuntu@16.04"`,
+		`application "haproxy": removed series "focal" from series`,
+	})
+}
+
+func (s *SeriesMigrationSuite) TestMigrateWithMapping(c *gc.C) {
+	bd := &charm.BundleData{Series: "kinetic-custom"}
+	changes, err := bd.MigrateSeriesToBases(func(series string) (charm.Base, error) {
+		c.Check(series, gc.Equals, "kinetic-custom")
+		return charm.ParseBase("ubuntu@22.10")
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd.DefaultBase, gc.Equals, "ubuntu@22.10")
+	c.Assert(changes, jc.DeepEquals, []charm.SeriesMigration{{
+		Location: "bundle",
+		Field:    "series",
+		Series:   "kinetic-custom",
+		Base:     "ubuntu@22.10",
+	}})
+}
+
+func (s *SeriesMigrationSuite) TestMigrateFailureLeavesBundleUnchanged(c *gc.C) {
+	bd := seriesBundle()
+	_, err := bd.MigrateSeriesToBases(func(series string) (charm.Base, error) {
+		if series == "bionic" {
+			return charm.Base{}, errors.NotSupportedf("series %q", series)
+		}
+		return charm.DefaultSeriesBaseMapper(series)
+	})
+	c.Assert(err, gc.ErrorMatches, `machine "0": cannot migrate series "bionic": series "bionic" not supported`)
+	c.Assert(bd, jc.DeepEquals, seriesBundle())
+}
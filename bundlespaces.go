@@ -0,0 +1,149 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+)
+
+// ConstraintSpaces holds the spaces named by the "spaces" key of a
+// constraints string.
+type ConstraintSpaces struct {
+	// Include holds the spaces that must be available, or nil if
+	// the constraints do not require any.
+	Include []string
+
+	// Exclude holds the spaces that must not be available, given in
+	// the constraints with a "^" prefix.
+	Exclude []string
+}
+
+// SpacesResolver returns the spaces named by the given constraints
+// string, as used by BundleData.VerifySpaces.
+type SpacesResolver func(constraints string) (ConstraintSpaces, error)
+
+// ParseConstraintSpaces returns the spaces named by the "spaces" key of
+// the given constraints, such as "mem=4G spaces=db,^public". The other
+// keys of the constraints are not checked.
+func ParseConstraintSpaces(constraints string) (ConstraintSpaces, error) {
+	var spaces ConstraintSpaces
+	for _, field := range strings.Fields(constraints) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key != "spaces" || value == "" {
+			continue
+		}
+		for _, space := range strings.Split(value, ",") {
+			exclude := strings.HasPrefix(space, "^")
+			space = strings.TrimPrefix(space, "^")
+			if space == "" {
+				return ConstraintSpaces{}, errors.NotValidf("empty space name in constraints %q", constraints)
+			}
+			if exclude {
+				spaces.Exclude = append(spaces.Exclude, space)
+			} else {
+				spaces.Include = append(spaces.Include, space)
+			}
+		}
+	}
+	return spaces, nil
+}
+
+// VerifySpaces checks that the spaces to which each application binds
+// its endpoints are compatible with the space constraints of the
+// application and of the bundle machines its units are placed on,
+// including those hosting the containers of its units. A binding
+// conflicts with constraints that exclude its space, and with machine
+// constraints that require spaces without including it. Such conflicts
+// would otherwise only be found when the bundle is deployed.
+//
+// The spaces named by each constraints string are obtained with
+// resolve, or with ParseConstraintSpaces if it is nil. This check is
+// not made by Verify, as it requires the bundle's constraints to name
+// spaces as they are known to the model being deployed to.
+//
+// If the verification fails, VerifySpaces returns a *VerificationError
+// describing all the conflicts found.
+func (bd *BundleData) VerifySpaces(resolve SpacesResolver) error {
+	if resolve == nil {
+		resolve = ParseConstraintSpaces
+	}
+	verifier := &bundleDataVerifier{bd: bd}
+	verifier.verifySpaces(resolve)
+	return verifier.err()
+}
+
+// verifySpaces checks the endpoint bindings of the bundle's
+// applications against the space constraints resolved by resolve.
+func (verifier *bundleDataVerifier) verifySpaces(resolve SpacesResolver) {
+	resolved := make(map[string]ConstraintSpaces)
+	resolveSpaces := func(constraints string) (ConstraintSpaces, bool) {
+		if spaces, ok := resolved[constraints]; ok {
+			return spaces, true
+		}
+		spaces, err := resolve(constraints)
+		if err != nil {
+			verifier.addErrorf(CodeInvalidConstraints, "cannot resolve spaces in constraints %q: %v", constraints, err)
+			return ConstraintSpaces{}, false
+		}
+		resolved[constraints] = spaces
+		return spaces, true
+	}
+
+	names := make([]string, 0, len(verifier.bd.Applications))
+	for name := range verifier.bd.Applications {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		app := verifier.bd.Applications[name]
+		if app == nil {
+			continue
+		}
+		bound := set.NewStrings()
+		for _, space := range app.EndpointBindings {
+			if space != "" {
+				bound.Add(space)
+			}
+		}
+		if bound.IsEmpty() {
+			continue
+		}
+		if app.Constraints != "" {
+			if spaces, ok := resolveSpaces(app.Constraints); ok {
+				for _, space := range bound.Intersection(set.NewStrings(spaces.Exclude...)).SortedValues() {
+					verifier.addErrorf(CodeInvalidBinding, "application %q binds to space %q excluded by its constraints %q",
+						name, space, app.Constraints)
+				}
+			}
+		}
+		checked := make(map[string]bool)
+		for _, p := range app.To {
+			up, err := ParsePlacement(p)
+			if err != nil || up.Machine == "" || up.Machine == "new" || checked[up.Machine] {
+				continue
+			}
+			checked[up.Machine] = true
+			m := verifier.bd.Machines[up.Machine]
+			if m == nil || m.Constraints == "" {
+				continue
+			}
+			spaces, ok := resolveSpaces(m.Constraints)
+			if !ok {
+				continue
+			}
+			excluded := set.NewStrings(spaces.Exclude...)
+			included := set.NewStrings(spaces.Include...)
+			for _, space := range bound.SortedValues() {
+				if excluded.Contains(space) || (!included.IsEmpty() && !included.Contains(space)) {
+					verifier.addErrorf(CodeInvalidBinding, "application %q binds to space %q not available to machine %q with constraints %q",
+						name, space, up.Machine, m.Constraints)
+				}
+			}
+		}
+	}
+}
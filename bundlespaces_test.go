@@ -0,0 +1,98 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type bundleSpacesSuite struct{}
+
+var _ = gc.Suite(&bundleSpacesSuite{})
+
+func (*bundleSpacesSuite) TestParseConstraintSpaces(c *gc.C) {
+	spaces, err := charm.ParseConstraintSpaces("mem=4G spaces=db,^public,internal cores=2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, jc.DeepEquals, charm.ConstraintSpaces{
+		Include: []string{"db", "internal"},
+		Exclude: []string{"public"},
+	})
+
+	spaces, err = charm.ParseConstraintSpaces("mem=4G")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, jc.DeepEquals, charm.ConstraintSpaces{})
+
+	_, err = charm.ParseConstraintSpaces("spaces=db,^")
+	c.Assert(err, gc.ErrorMatches, `empty space name in constraints "spaces=db,\^" not valid`)
+	c.Assert(err, jc.ErrorIs, errors.NotValid)
+}
+
+const spacesBundle = `
+applications:
+    mysql:
+        charm: mysql
+        num_units: 3
+        constraints: spaces=^public
+        to: ["0", "lxd:1", "new"]
+        bindings:
+            "": internal
+            db: db
+            website: public
+    wordpress:
+        charm: wordpress
+        num_units: 1
+        to: ["2"]
+        bindings:
+            website: public
+machines:
+    0:
+        constraints: spaces=internal,db
+    1:
+        constraints: spaces=^db
+    2:
+        constraints: mem=4G
+`
+
+func (*bundleSpacesSuite) TestVerifySpaces(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(spacesBundle))
+	c.Assert(err, jc.ErrorIsNil)
+	err = bd.VerifySpaces(nil)
+	c.Assert(err, gc.FitsTypeOf, &charm.VerificationError{})
+	var messages []string
+	for _, err := range err.(*charm.VerificationError).Errors {
+		messages = append(messages, err.Error())
+	}
+	c.Assert(messages, jc.DeepEquals, []string{
+		`application "mysql" binds to space "public" excluded by its constraints "spaces=^public"`,
+		`application "mysql" binds to space "public" not available to machine "0" with constraints "spaces=internal,db"`,
+		`application "mysql" binds to space "db" not available to machine "1" with constraints "spaces=^db"`,
+	})
+	c.Assert(err.(*charm.VerificationError).Errors[0], jc.ErrorIs, charm.CodeInvalidBinding)
+}
+
+func (*bundleSpacesSuite) TestVerifySpacesResolver(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(spacesBundle))
+	c.Assert(err, jc.ErrorIsNil)
+	var resolved []string
+	err = bd.VerifySpaces(func(constraints string) (charm.ConstraintSpaces, error) {
+		resolved = append(resolved, constraints)
+		if constraints == "mem=4G" {
+			return charm.ConstraintSpaces{Include: []string{"internal"}}, nil
+		}
+		return charm.ConstraintSpaces{}, nil
+	})
+	c.Assert(err, gc.ErrorMatches, `application "wordpress" binds to space "public" not available to machine "2" with constraints "mem=4G"`)
+	c.Assert(resolved, jc.SameContents, []string{"spaces=^public", "spaces=internal,db", "spaces=^db", "mem=4G"})
+
+	err = bd.VerifySpaces(func(constraints string) (charm.ConstraintSpaces, error) {
+		return charm.ConstraintSpaces{}, errors.New("boom")
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot resolve spaces in constraints "spaces=\^public": boom \(and 3 more errors\)`)
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// ExpandBundleVariables replaces each reference of the form ${NAME} in
+// the bundle document data with the value of NAME in vars, and returns
+// the result, which may then be passed to ReadBundleData. A variable
+// name consists of letters, digits and underscores and does not start
+// with a digit. The sequence $${ stands for a literal ${, and a $ that
+// is not followed by { is left unchanged.
+//
+// Substitution is textual, so values are inserted into the document as
+// they are; values that are not valid YAML scalars in their position
+// should be quoted in the document. It is an error for the document to
+// refer to a variable that is not in vars, or to contain a malformed
+// reference.
+func ExpandBundleVariables(data []byte, vars map[string]string) ([]byte, error) {
+	var (
+		out       bytes.Buffer
+		undefined = make(map[string]int)
+	)
+	line := 1
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		if b == '\n' {
+			line++
+		}
+		if b != '$' || i+1 >= len(data) {
+			out.WriteByte(b)
+			continue
+		}
+		switch {
+		case data[i+1] == '$' && i+2 < len(data) && data[i+2] == '{':
+			// An escaped reference.
+			out.WriteString("${")
+			i += 2
+		case data[i+1] == '{':
+			end := bytes.IndexByte(data[i+2:], '}')
+			if end < 0 || bytes.IndexByte(data[i+2:i+2+end], '\n') >= 0 {
+				return nil, errors.NotValidf("unterminated variable reference at line %d", line)
+			}
+			name := string(data[i+2 : i+2+end])
+			if !isBundleVariableName(name) {
+				return nil, errors.NotValidf("variable name %q at line %d", name, line)
+			}
+			value, ok := vars[name]
+			if !ok {
+				if _, seen := undefined[name]; !seen {
+					undefined[name] = line
+				}
+			}
+			out.WriteString(value)
+			i += 2 + end
+		default:
+			out.WriteByte(b)
+		}
+	}
+	if len(undefined) > 0 {
+		names := make([]string, 0, len(undefined))
+		for name := range undefined {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		refs := make([]string, len(names))
+		for i, name := range names {
+			refs[i] = fmt.Sprintf("%s (line %d)", name, undefined[name])
+		}
+		return nil, errors.NotFoundf("bundle variables %s", strings.Join(refs, ", "))
+	}
+	return out.Bytes(), nil
+}
+
+// isBundleVariableName reports whether name is a valid bundle variable
+// name.
+func isBundleVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"bytes"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type BundleVariablesSuite struct{}
+
+var _ = gc.Suite(&BundleVariablesSuite{})
+
+var expandBundleVariablesTests = []struct {
+	about  string
+	input  string
+	vars   map[string]string
+	expect string
+	err    string
+}{{
+	about:  "no references",
+	input:  "applications: {}\n",
+	expect: "applications: {}\n",
+}, {
+	about:  "substitution",
+	input:  "charm: ${CHARM}\nchannel: ${CHANNEL}/stable\n",
+	vars:   map[string]string{"CHARM": "ch:mysql", "CHANNEL": "8.0"},
+	expect: "charm: ch:mysql\nchannel: 8.0/stable\n",
+}, {
+	about:  "empty value",
+	input:  "options: {password: '${PW}'}",
+	vars:   map[string]string{"PW": ""},
+	expect: "options: {password: ''}",
+}, {
+	about:  "escaped reference",
+	input:  "command: echo $${HOME} ${X}",
+	vars:   map[string]string{"X": "1"},
+	expect: "command: echo ${HOME} 1",
+}, {
+	about:  "lone dollars are unchanged",
+	input:  "price: $5 $$ $",
+	expect: "price: $5 $$ $",
+}, {
+	about: "undefined variables",
+	input: "a: ${B}\nb: ${A}\nc: ${B}\n",
+	vars:  map[string]string{"C": "c"},
+	err:   `bundle variables A \(line 2\), B \(line 1\) not found`,
+}, {
+	about: "unterminated reference",
+	input: "a: 1\nb: ${A\n}",
+	err:   `unterminated variable reference at line 2 not valid`,
+}, {
+	about: "invalid name",
+	input: "a: ${1A}",
+	err:   `variable name "1A" at line 1 not valid`,
+}, {
+	about: "empty name",
+	input: "a: ${}",
+	err:   `variable name "" at line 1 not valid`,
+}}
+
+func (s *BundleVariablesSuite) TestExpandBundleVariables(c *gc.C) {
+	for i, test := range expandBundleVariablesTests {
+		c.Logf("test %d: %s", i, test.about)
+		result, err := charm.ExpandBundleVariables([]byte(test.input), test.vars)
+		if test.err != "" {
+			c.Assert(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(string(result), gc.Equals, test.expect)
+	}
+}
+
+func (s *BundleVariablesSuite) TestUndefinedIsNotFound(c *gc.C) {
+	_, err := charm.ExpandBundleVariables([]byte("${X}"), nil)
+	c.Assert(errors.Is(err, errors.NotFound), jc.IsTrue)
+}
+
+func (s *BundleVariablesSuite) TestExpandThenRead(c *gc.C) {
+	data, err := charm.ExpandBundleVariables([]byte(`
+applications:
+  mysql:
+    charm: ${CHARM}
+    num_units: ${UNITS}
+`), map[string]string{"CHARM": "ch:mysql", "UNITS": "3"})
+	c.Assert(err, jc.ErrorIsNil)
+	bd, err := charm.ReadBundleData(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd.Applications["mysql"].Charm, gc.Equals, "ch:mysql")
+	c.Assert(bd.Applications["mysql"].NumUnits, gc.Equals, 3)
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+
+	"github.com/juju/collections/set"
+)
+
+// charmhubCategories holds the categories used by Charmhub to group
+// charms.
+var charmhubCategories = []string{
+	"ai-ml",
+	"big-data",
+	"cloud",
+	"containers",
+	"databases",
+	"devops",
+	"identity",
+	"iot",
+	"kubernetes",
+	"logging",
+	"misc",
+	"monitoring",
+	"networking",
+	"observability",
+	"ops",
+	"performance",
+	"security",
+	"storage",
+}
+
+// CategoryTaxonomy holds a set of known charm categories. The zero
+// value is an empty taxonomy ready to use.
+type CategoryTaxonomy struct {
+	names set.Strings
+}
+
+// NewCategoryTaxonomy returns a taxonomy holding the given categories.
+func NewCategoryTaxonomy(names ...string) *CategoryTaxonomy {
+	t := &CategoryTaxonomy{}
+	for _, name := range names {
+		t.Add(name)
+	}
+	return t
+}
+
+// CharmhubCategories returns a new taxonomy holding the categories used
+// by Charmhub, such as databases, monitoring and security.
+func CharmhubCategories() *CategoryTaxonomy {
+	return NewCategoryTaxonomy(charmhubCategories...)
+}
+
+// Add adds the given category to the taxonomy.
+func (t *CategoryTaxonomy) Add(name string) {
+	if t.names == nil {
+		t.names = set.NewStrings()
+	}
+	t.names.Add(name)
+}
+
+// Contains reports whether the taxonomy holds the given category.
+func (t *CategoryTaxonomy) Contains(name string) bool {
+	return t.names.Contains(name)
+}
+
+// Names returns the sorted categories held in the taxonomy.
+func (t *CategoryTaxonomy) Names() []string {
+	return t.names.SortedValues()
+}
+
+// CategoryWarning describes a category or tag that is not in the
+// taxonomy it was checked against.
+type CategoryWarning struct {
+	// Field holds the metadata field declaring the value, either
+	// "categories" or "tags".
+	Field string
+
+	// Value holds the unknown category or tag.
+	Value string
+
+	// Suggestion holds a known category close to the value, if there
+	// is one.
+	Suggestion string
+}
+
+// String returns a description of the warning.
+func (w CategoryWarning) String() string {
+	if w.Suggestion == "" {
+		return fmt.Sprintf("%s: unknown category %q", w.Field, w.Value)
+	}
+	return fmt.Sprintf("%s: unknown category %q; did you mean %q?", w.Field, w.Value, w.Suggestion)
+}
+
+// CheckCategories returns a warning for each of the charm's categories
+// and tags that is not held in the taxonomy, suggesting the closest
+// known category where one is at most two edits away. If taxonomy is
+// nil, the Charmhub categories are used. Categories are reported
+// before tags, each in the order declared.
+func (m Meta) CheckCategories(taxonomy *CategoryTaxonomy) []CategoryWarning {
+	if taxonomy == nil {
+		taxonomy = CharmhubCategories()
+	}
+	known := taxonomy.Names()
+	var warnings []CategoryWarning
+	check := func(field string, values []string) {
+		for _, value := range values {
+			if taxonomy.Contains(value) {
+				continue
+			}
+			warning := CategoryWarning{Field: field, Value: value}
+			best := 3
+			for _, candidate := range known {
+				if d := levenshtein(value, candidate); d < best {
+					warning.Suggestion, best = candidate, d
+				}
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+	check("categories", m.Categories)
+	check("tags", m.Tags)
+	return warnings
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type CategoriesSuite struct{}
+
+var _ = gc.Suite(&CategoriesSuite{})
+
+func (s *CategoriesSuite) TestCategoryTaxonomy(c *gc.C) {
+	var t charm.CategoryTaxonomy
+	c.Assert(t.Contains("databases"), jc.IsFalse)
+	c.Assert(t.Names(), gc.HasLen, 0)
+	t.Add("web")
+	t.Add("databases")
+	c.Assert(t.Contains("web"), jc.IsTrue)
+	c.Assert(t.Names(), jc.DeepEquals, []string{"databases", "web"})
+
+	known := charm.CharmhubCategories()
+	for _, name := range []string{"databases", "monitoring", "security"} {
+		c.Check(known.Contains(name), jc.IsTrue, gc.Commentf("%s", name))
+	}
+}
+
+func (s *CategoriesSuite) TestCheckCategories(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: tagged
+summary: s
+description: d
+categories: [databases, databse]
+tags: [monitoring, wordpress]
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	warnings := meta.CheckCategories(nil)
+	c.Assert(warnings, jc.DeepEquals, []charm.CategoryWarning{{
+		Field:      "categories",
+		Value:      "databse",
+		Suggestion: "databases",
+	}, {
+		Field: "tags",
+		Value: "wordpress",
+	}})
+	c.Assert(warnings[0].String(), gc.Equals, `categories: unknown category "databse"; did you mean "databases"?`)
+	c.Assert(warnings[1].String(), gc.Equals, `tags: unknown category "wordpress"`)
+
+	warnings = meta.CheckCategories(charm.NewCategoryTaxonomy("databases", "databse", "monitoring", "wordpress"))
+	c.Assert(warnings, gc.HasLen, 0)
+}
@@ -4,9 +4,13 @@
 package charm
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/juju/collections/set"
 	"github.com/juju/errors"
@@ -15,6 +19,16 @@ import (
 
 var logger = loggo.GetLogger("juju.charm")
 
+// lazyRegexp returns a function that compiles expr the first time it is
+// called and returns the cached result on every call after that. This keeps
+// regexp compilation out of package initialisation, which matters to short
+// lived binaries that import the package but never parse a charm.
+func lazyRegexp(expr string) func() *regexp.Regexp {
+	return sync.OnceValue(func() *regexp.Regexp {
+		return regexp.MustCompile(expr)
+	})
+}
+
 // CharmMeta describes methods that inform charm operation.
 type CharmMeta interface {
 	Meta() *Meta
@@ -31,9 +45,52 @@ type Charm interface {
 	Revision() int
 }
 
-// ReadCharm reads a Charm from path, which can point to either a charm archive or a
-// charm directory.
-func ReadCharm(path string) (charm Charm, err error) {
+// ReadCharmOption configures the behaviour of ReadCharm.
+type ReadCharmOption func(*readCharmOptions)
+
+type readCharmOptions struct {
+	skipValidation bool
+	osPolicy       *OSPolicy
+	warnBase       func(BaseWarning)
+	termOptions    []TermOption
+}
+
+// SkipDataValidation makes ReadCharm decode the charm without checking
+// that its metadata is valid, skipping checks such as the parsing of
+// terms and the rules on reserved relation names. It is intended for
+// bulk ingestion of charms already known to be valid; the checks can
+// be run later by calling Validate on the charm.
+func SkipDataValidation() ReadCharmOption {
+	return func(opts *readCharmOptions) {
+		opts.skipValidation = true
+	}
+}
+
+// WithOSPolicy makes ReadCharm check the bases and series declared by
+// the charm against policy, returning an error if the charm declares an
+// operating system that the policy does not allow. If warn is not nil,
+// it is called for each base or series whose operating system the
+// policy deprecates.
+func WithOSPolicy(policy *OSPolicy, warn func(BaseWarning)) ReadCharmOption {
+	return func(opts *readCharmOptions) {
+		opts.osPolicy = policy
+		opts.warnBase = warn
+	}
+}
+
+// WithCharmTermOptions makes ReadCharm check that the terms of the charm
+// parse with the given options, such as WithTermTenants. It has no
+// effect if SkipDataValidation is given.
+func WithCharmTermOptions(options ...TermOption) ReadCharmOption {
+	return func(opts *readCharmOptions) {
+		opts.termOptions = options
+	}
+}
+
+// ReadCharm reads a Charm from path, which can point to a charm
+// directory, a charm archive or a gzipped tarball holding a charm. The
+// kind of file is detected from its content rather than its name.
+func ReadCharm(path string, options ...ReadCharmOption) (charm Charm, err error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -41,13 +98,98 @@ func ReadCharm(path string) (charm Charm, err error) {
 	if info.IsDir() {
 		charm, err = ReadCharmDir(path)
 	} else {
-		charm, err = ReadCharmArchive(path)
+		charm, err = readCharmFile(path)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return checkReadCharm(charm, options)
+}
+
+// ReadCharmFromReader reads a Charm from r, which holds either a charm
+// archive or a gzipped tarball holding a charm. The content of r is
+// read into memory, so make sure it fits before using this.
+func ReadCharmFromReader(r io.Reader, options ...ReadCharmOption) (Charm, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var charm Charm
+	switch {
+	case bytes.HasPrefix(data, zipMagic):
+		charm, err = ReadCharmArchiveBytes(data)
+	case bytes.HasPrefix(data, gzipMagic):
+		charm, err = ReadCharmTarball(bytes.NewReader(data))
+	default:
+		return nil, errors.NewNotValid(nil, "charm data is not a zip archive or gzipped tarball")
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	return checkReadCharm(charm, options)
+}
+
+var (
+	zipMagic  = []byte("PK\x03\x04")
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// readCharmFile reads the charm archive or gzipped tarball at path.
+func readCharmFile(path string) (Charm, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer f.Close()
+	magic := make([]byte, len(zipMagic))
+	n, err := io.ReadFull(f, magic)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, errors.Trace(err)
+	}
+	if bytes.HasPrefix(magic[:n], gzipMagic) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, errors.Trace(err)
+		}
+		return ReadCharmTarball(f)
+	}
+	// Anything else is read as a charm archive, which reports an
+	// error if it is not one.
+	return ReadCharmArchive(path)
+}
 
-	return charm, errors.Trace(CheckMeta(charm))
+// checkReadCharm checks the metadata and actions of a charm read by
+// ReadCharm or ReadCharmFromReader, unless SkipDataValidation was given,
+// and its bases if WithOSPolicy was given.
+func checkReadCharm(charm Charm, options []ReadCharmOption) (Charm, error) {
+	var opts readCharmOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.osPolicy != nil {
+		warnings, err := opts.osPolicy.CheckCharm(charm)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if opts.warnBase != nil {
+			for _, w := range warnings {
+				opts.warnBase(w)
+			}
+		}
+	}
+	if opts.skipValidation {
+		return charm, nil
+	}
+	// Charm archives parse their actions lazily, so make sure
+	// they are valid here.
+	if archive, ok := charm.(*CharmArchive); ok {
+		if _, err := archive.ReadActions(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err := CheckMeta(charm); err != nil {
+		return charm, errors.Trace(err)
+	}
+	return charm, errors.Trace(charm.Meta().CheckTerms(opts.termOptions...))
 }
 
 // FormatSelectionReason represents the reason for a format version selection.
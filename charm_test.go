@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
@@ -37,6 +38,73 @@ func (s *CharmSuite) TestReadCharm(c *gc.C) {
 	c.Assert(ch.Meta().Name, gc.Equals, "dummy")
 }
 
+func (s *CharmSuite) TestReadCharmTarball(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "dummy.charm")
+	err := ioutil.WriteFile(path, charmTarball(c, charmDirPath(c, "dummy")), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	ch, err := charm.ReadCharm(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.Meta().Name, gc.Equals, "dummy")
+}
+
+func (s *CharmSuite) TestReadCharmFromReader(c *gc.C) {
+	archive, err := ioutil.ReadFile(archivePath(c, readCharmDir(c, "dummy")))
+	c.Assert(err, jc.ErrorIsNil)
+	ch, err := charm.ReadCharmFromReader(bytes.NewReader(archive))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.Meta().Name, gc.Equals, "dummy")
+
+	ch, err = charm.ReadCharmFromReader(bytes.NewReader(charmTarball(c, charmDirPath(c, "dummy"))))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.Meta().Name, gc.Equals, "dummy")
+
+	_, err = charm.ReadCharmFromReader(strings.NewReader("foo"))
+	c.Assert(err, gc.ErrorMatches, `charm data is not a zip archive or gzipped tarball`)
+}
+
+func (s *CharmSuite) TestReadCharmSkipDataValidation(c *gc.C) {
+	dir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "metadata.yaml"), []byte(`
+name: reserved
+summary: s
+description: d
+series: [quantal]
+provides:
+  juju-admin:
+    interface: http
+`), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = charm.ReadCharm(dir)
+	c.Assert(err, gc.ErrorMatches, `charm "reserved" using a reserved relation name: "juju-admin"`)
+
+	ch, err := charm.ReadCharm(dir, charm.SkipDataValidation())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.Meta().Provides["juju-admin"].Interface, gc.Equals, "http")
+	err = ch.(interface{ Validate() error }).Validate()
+	c.Assert(err, gc.ErrorMatches, `charm "reserved" using a reserved relation name: "juju-admin"`)
+
+	err = readCharmDir(c, "dummy").Validate()
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CharmSuite) TestReadCharmTermOptions(c *gc.C) {
+	dir := c.MkDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "metadata.yaml"), []byte(`
+name: terms
+summary: s
+description: d
+series: [quantal]
+terms: ["jaas:owner/term/1"]
+`), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = charm.ReadCharm(dir, charm.WithCharmTermOptions(charm.WithTermTenants("jaas")))
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = charm.ReadCharm(dir, charm.WithCharmTermOptions(charm.WithTermTenants("cs")))
+	c.Assert(err, gc.ErrorMatches, `term tenant "jaas" not accepted`)
+}
+
 func (s *CharmSuite) TestReadCharmDirEmptyError(c *gc.C) {
 	ch, err := charm.ReadCharm(c.MkDir())
 	c.Assert(err, gc.NotNil)
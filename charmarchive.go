@@ -6,12 +6,14 @@ package charm
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 
 	"github.com/juju/collections/set"
 	"github.com/juju/errors"
@@ -23,6 +25,10 @@ import (
 type CharmArchive struct {
 	zopen zipOpener
 
+	// readActions parses actions.yaml the first time it is called,
+	// and returns the same result thereafter.
+	readActions func() (*Actions, error)
+
 	Path string // May be empty if CharmArchive wasn't read from a file
 	*charmBase
 }
@@ -71,11 +77,14 @@ func readCharmArchive(zopen zipOpener) (archive *CharmArchive, err error) {
 	if err != nil {
 		return nil, err
 	}
-	b.meta, err = ReadMeta(reader)
+	b.meta, err = ReadMeta(reader, WithMetaSource("metadata.yaml"))
 	_ = reader.Close()
 	if err != nil {
 		return nil, err
 	}
+	if b.meta.Translations, err = readArchiveMetaTranslations(zipr); err != nil {
+		return nil, err
+	}
 
 	// Try to read the optional manifest.yaml, it's required to determine if
 	// this charm is v1 or not.
@@ -116,17 +125,25 @@ func readCharmArchive(zopen zipOpener) (archive *CharmArchive, err error) {
 		return nil, err
 	}
 
-	if b.actions, err = getActions(
-		b.meta.Name,
-		func(file string) (io.ReadCloser, error) {
-			return zipOpenFile(zipr, file)
-		},
-		func(err error) bool {
-			_, ok := err.(*noCharmArchiveFile)
-			return ok
-		},
-	); err != nil {
+	// Parsing actions.yaml is comparatively expensive, so only its
+	// content is read here; it is parsed when the actions are first
+	// asked for.
+	reader, err = zipOpenFile(zipr, "actions.yaml")
+	if _, ok := err.(*noCharmArchiveFile); ok {
+		b.readActions = func() (*Actions, error) {
+			return NewActions(), nil
+		}
+	} else if err != nil {
 		return nil, err
+	} else {
+		data, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		b.readActions = sync.OnceValues(func() (*Actions, error) {
+			return ReadActionsYaml(b.meta.Name, bytes.NewReader(data))
+		})
 	}
 
 	reader, err = zipOpenFile(zipr, "revision")
@@ -170,6 +187,25 @@ func readCharmArchive(zopen zipOpener) (archive *CharmArchive, err error) {
 	return b, nil
 }
 
+// ReadActions returns the actions declared by the charm, parsing its
+// actions.yaml the first time it is called. It returns an error if
+// actions.yaml is not valid.
+func (a *CharmArchive) ReadActions() (*Actions, error) {
+	return a.readActions()
+}
+
+// Actions returns the actions declared by the charm, parsing its
+// actions.yaml the first time it is called. If actions.yaml is not
+// valid, Actions returns an empty Actions value; use ReadActions to
+// obtain the error.
+func (a *CharmArchive) Actions() *Actions {
+	actions, err := a.readActions()
+	if err != nil {
+		return NewActions()
+	}
+	return actions
+}
+
 type fileOpener func(string) (io.ReadCloser, error)
 
 func getActions(charmName string, open fileOpener, isNotFound func(error) bool) (actions *Actions, err error) {
@@ -285,43 +321,63 @@ func (a *CharmArchive) ArchiveMembers() (set.Strings, error) {
 // If any errors occur during the expansion procedure, the process will
 // abort.
 func (a *CharmArchive) ExpandTo(dir string) error {
+	return a.ExpandToContextWithOptions(context.Background(), dir)
+}
+
+// ExpandToContext is like ExpandTo, except that it stops and returns the
+// context's error if ctx is done before the charm has been expanded.
+func (a *CharmArchive) ExpandToContext(ctx context.Context, dir string) error {
+	return a.ExpandToContextWithOptions(ctx, dir)
+}
+
+// ExpandToWithOptions is like ExpandTo, except that the expansion is
+// configured by the given options.
+func (a *CharmArchive) ExpandToWithOptions(dir string, options ...ExpandOption) error {
+	return a.ExpandToContextWithOptions(context.Background(), dir, options...)
+}
+
+// ExpandToContextWithOptions is like ExpandToContext, except that the
+// expansion is configured by the given options.
+func (a *CharmArchive) ExpandToContextWithOptions(ctx context.Context, dir string, options ...ExpandOption) error {
 	zipr, err := a.zopen.openZip()
 	if err != nil {
 		return err
 	}
 	defer zipr.Close()
-	if err := ziputil.ExtractAll(zipr.Reader, dir); err != nil {
-		return err
-	}
-	hooksDir := filepath.Join(dir, "hooks")
-	fixHook := fixHookFunc(hooksDir, a.meta.Hooks())
-	if err := filepath.Walk(hooksDir, fixHook); err != nil {
-		if !os.IsNotExist(err) {
+	return expandArchive(ctx, zipr.Reader, dir, options, func(dir string) error {
+		hooksDir := filepath.Join(dir, "hooks")
+		fixHook := fixHookFunc(ctx, hooksDir, a.meta.Hooks())
+		if err := filepath.Walk(hooksDir, fixHook); err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+		}
+		revFile, err := os.Create(filepath.Join(dir, "revision"))
+		if err != nil {
 			return err
 		}
-	}
-	revFile, err := os.Create(filepath.Join(dir, "revision"))
-	if err != nil {
-		return err
-	}
-	if _, err := revFile.Write([]byte(strconv.Itoa(a.revision))); err != nil {
-		return err
-	}
-	if err := revFile.Sync(); err != nil {
-		return err
-	}
-	if err := revFile.Close(); err != nil {
-		return err
-	}
-	return nil
+		if _, err := revFile.Write([]byte(strconv.Itoa(a.revision))); err != nil {
+			return err
+		}
+		if err := revFile.Sync(); err != nil {
+			return err
+		}
+		if err := revFile.Close(); err != nil {
+			return err
+		}
+		return nil
+	})
 }
 
 // fixHookFunc returns a WalkFunc that makes sure hooks are owner-executable.
-func fixHookFunc(hooksDir string, hookNames map[string]bool) filepath.WalkFunc {
+func fixHookFunc(ctx context.Context, hooksDir string, hookNames map[string]bool) filepath.WalkFunc {
 	return func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return errors.Trace(err)
+		}
 		mode := info.Mode()
 		if path != hooksDir && mode.IsDir() {
 			return filepath.SkipDir
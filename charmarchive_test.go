@@ -6,6 +6,7 @@ package charm_test
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -16,12 +17,14 @@ import (
 	"syscall"
 
 	"github.com/juju/collections/set"
+	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/yaml.v2"
 
 	"github.com/juju/charm/v12"
+	"github.com/juju/charm/v12/charmtest"
 )
 
 type CharmArchiveSuite struct {
@@ -155,6 +158,44 @@ func (s *CharmDirSuite) TestReadCharmArchiveWithJujuActions(c *gc.C) {
 	c.Assert(archive.Actions().ActionSpecs, gc.HasLen, 1)
 }
 
+func (s *CharmArchiveSuite) TestReadCharmArchiveActionsLazily(c *gc.C) {
+	var buf bytes.Buffer
+	err := (&charmtest.Charm{
+		Metadata: "name: lazy\nsummary: s\ndescription: d\n",
+		Actions:  "BAD-NAME:\n  description: d\n",
+	}).WriteArchive(&buf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The invalid actions.yaml is not parsed until the actions are
+	// asked for, and the error is kept for later calls.
+	archive, err := charm.ReadCharmArchiveBytes(buf.Bytes())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(archive.Meta().Name, gc.Equals, "lazy")
+	_, err = archive.ReadActions()
+	c.Assert(err, gc.ErrorMatches, "bad action name BAD-NAME")
+	_, err = archive.ReadActions()
+	c.Assert(err, gc.ErrorMatches, "bad action name BAD-NAME")
+	c.Assert(archive.Actions().ActionSpecs, gc.HasLen, 0)
+
+	// ReadCharm still checks the actions, unless told not to.
+	_, err = charm.ReadCharmFromReader(bytes.NewReader(buf.Bytes()))
+	c.Assert(err, gc.ErrorMatches, "bad action name BAD-NAME")
+	_, err = charm.ReadCharmFromReader(bytes.NewReader(buf.Bytes()), charm.SkipDataValidation())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CharmArchiveSuite) TestReadCharmArchiveActionsAfterRemoval(c *gc.C) {
+	path := archivePath(c, readCharmDir(c, "dummy"))
+	archive, err := charm.ReadCharmArchive(path)
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.Remove(path)
+	c.Assert(err, jc.ErrorIsNil)
+	actions, err := archive.ReadActions()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actions.ActionSpecs, gc.Not(gc.HasLen), 0)
+	c.Assert(archive.Actions(), gc.Equals, actions)
+}
+
 func (s *CharmArchiveSuite) TestReadCharmArchiveBytes(c *gc.C) {
 	data, err := ioutil.ReadFile(s.archivePath)
 	c.Assert(err, gc.IsNil)
@@ -234,6 +275,122 @@ func (s *CharmArchiveSuite) TestExpandTo(c *gc.C) {
 	checkDummy(c, dir, path)
 }
 
+func (s *CharmArchiveSuite) TestExpandToContextCancelled(c *gc.C) {
+	archive, err := charm.ReadCharmArchive(s.archivePath)
+	c.Assert(err, gc.IsNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	path := filepath.Join(c.MkDir(), "charm")
+	err = archive.ExpandToContext(ctx, path)
+	c.Assert(errors.Cause(err), gc.Equals, context.Canceled)
+	_, err = os.Stat(filepath.Join(path, "metadata.yaml"))
+	c.Assert(os.IsNotExist(err), jc.IsTrue)
+}
+
+func (s *CharmArchiveSuite) TestExpandToProgress(c *gc.C) {
+	archive, err := charm.ReadCharmArchive(s.archivePath)
+	c.Assert(err, gc.IsNil)
+
+	var reports []charm.ExpandProgress
+	path := filepath.Join(c.MkDir(), "charm")
+	err = archive.ExpandToWithOptions(path, charm.WithExpandProgress(func(p charm.ExpandProgress) {
+		reports = append(reports, p)
+	}))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(reports, gc.Not(gc.HasLen), 0)
+	for i, p := range reports {
+		c.Check(p.Files, gc.Equals, i+1)
+		c.Check(p.TotalFiles, gc.Equals, len(reports))
+		if i > 0 {
+			c.Check(p.Bytes >= reports[i-1].Bytes, jc.IsTrue)
+		}
+	}
+	last := reports[len(reports)-1]
+	c.Assert(last.Bytes, gc.Equals, last.TotalBytes)
+	c.Assert(last.TotalBytes > 0, jc.IsTrue)
+}
+
+func (s *CharmArchiveSuite) TestExpandToAtomic(c *gc.C) {
+	archive, err := charm.ReadCharmArchive(s.archivePath)
+	c.Assert(err, gc.IsNil)
+
+	parent := c.MkDir()
+	path := filepath.Join(parent, "charm")
+	err = os.MkdirAll(path, 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.WriteFile(filepath.Join(path, "stale"), nil, 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// An interrupted expansion leaves the existing directory alone.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = archive.ExpandToContextWithOptions(ctx, path, charm.AtomicExpand(), charm.WithExpandProgress(func(charm.ExpandProgress) {
+		cancel()
+	}))
+	c.Assert(errors.Cause(err), gc.Equals, context.Canceled)
+	entries, err := os.ReadDir(parent)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entries, gc.HasLen, 1)
+	_, err = os.Stat(filepath.Join(path, "stale"))
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = os.Stat(filepath.Join(path, "metadata.yaml"))
+	c.Assert(os.IsNotExist(err), jc.IsTrue)
+
+	// A complete expansion replaces it.
+	err = archive.ExpandToWithOptions(path, charm.AtomicExpand())
+	c.Assert(err, jc.ErrorIsNil)
+	entries, err = os.ReadDir(parent)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entries, gc.HasLen, 1)
+	_, err = os.Stat(filepath.Join(path, "stale"))
+	c.Assert(os.IsNotExist(err), jc.IsTrue)
+	dir, err := charm.ReadCharmDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+	checkDummy(c, dir, path)
+	info, err := os.Stat(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Mode().Perm(), gc.Equals, os.FileMode(0755))
+}
+
+func (s *CharmArchiveSuite) TestExpandToAtomicRecovers(c *gc.C) {
+	archive, err := charm.ReadCharmArchive(s.archivePath)
+	c.Assert(err, gc.IsNil)
+
+	// An expansion interrupted between moving the old directory aside
+	// and renaming the new one into place, and another interrupted
+	// while extracting, left their directories behind.
+	parent := c.MkDir()
+	path := filepath.Join(parent, "charm")
+	for _, dir := range []string{".charm.old", ".charm.tmp-123"} {
+		err = os.MkdirAll(filepath.Join(parent, dir), 0755)
+		c.Assert(err, jc.ErrorIsNil)
+		err = os.WriteFile(filepath.Join(parent, dir, "stale"), nil, 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	// A failed expansion still restores the old directory.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = archive.ExpandToContextWithOptions(ctx, path, charm.AtomicExpand())
+	c.Assert(errors.Cause(err), gc.Equals, context.Canceled)
+	entries, err := os.ReadDir(parent)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entries, gc.HasLen, 1)
+	_, err = os.Stat(filepath.Join(path, "stale"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// An expansion interrupted after the new directory was in place
+	// left the old one behind.
+	err = os.MkdirAll(filepath.Join(parent, ".charm.old"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	err = archive.ExpandToWithOptions(path, charm.AtomicExpand())
+	c.Assert(err, jc.ErrorIsNil)
+	entries, err = os.ReadDir(parent)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entries, gc.HasLen, 1)
+	_, err = os.Stat(filepath.Join(path, "metadata.yaml"))
+	c.Assert(err, jc.ErrorIsNil)
+}
 func (s *CharmArchiveSuite) TestReadCharmArchiveWithVersion(c *gc.C) {
 	clonedPath := cloneDir(c, charmDirPath(c, "versioned"))
 	_, err := os.Create(filepath.Join(clonedPath, ".git"))
@@ -45,6 +45,12 @@ func (c *charmBase) Metrics() *Metrics {
 	return c.metrics
 }
 
+// MeteringInfo returns the metering requirements of the charm, as
+// declared in its metrics.yaml file.
+func (c *charmBase) MeteringInfo() MeteringInfo {
+	return c.metrics.MeteringInfo()
+}
+
 // Actions returns the Actions representing the actions.yaml file
 // for the charm expanded in dir.
 func (c *charmBase) Actions() *Actions {
@@ -63,6 +69,12 @@ func (c *charmBase) Manifest() *Manifest {
 	return c.manifest
 }
 
+// Validate checks that the charm's metadata is valid, as ReadCharm does
+// unless SkipDataValidation is given.
+func (c *charmBase) Validate() error {
+	return CheckMeta(c)
+}
+
 // SetRevision changes the charm revision number. This affects
 // the revision reported by Revision and the revision of the
 // charm created.
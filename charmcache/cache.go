@@ -0,0 +1,209 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package charmcache implements a content-addressed cache of charm
+// archives on the local disk. Archives are stored under the SHA-256
+// digest of their content, in the "sha256:<hex>" form returned by
+// charm.Digest and used by pinned charm URLs, so the same charm
+// downloaded twice is only stored once. The cache may be shared
+// between processes; access is serialised with a lock file held in the
+// cache directory.
+package charmcache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/charm/v12"
+)
+
+const (
+	// archiveSuffix is the suffix of the files holding cached archives.
+	archiveSuffix = ".charm"
+
+	// lockFileName is the name of the lock file within the cache
+	// directory.
+	lockFileName = ".lock"
+
+	// digestPrefix prefixes the hex encoded checksum in digests. It
+	// is left out of archive file names, as colons are not allowed in
+	// file names on all platforms.
+	digestPrefix = "sha256:"
+)
+
+// Cache is a content-addressed cache of charm archives held in a
+// directory.
+type Cache struct {
+	dir string
+}
+
+// New returns a cache that stores archives in dir, creating the
+// directory if necessary.
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Annotate(err, "cannot create charm cache directory")
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Dir returns the directory holding the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Put copies the archive at archivePath into the cache and returns the
+// digest of its content, as returned by charm.ArchiveDigest, which may
+// be passed to Get.
+// Putting an archive that is already cached only marks it as recently
+// used.
+func (c *Cache) Put(archivePath string) (digest string, err error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer f.Close()
+
+	// Copy the archive to a temporary file in the cache directory,
+	// computing the digest along the way, so that it can be moved
+	// into place atomically.
+	tmp, err := os.CreateTemp(c.dir, "put-*")
+	if err != nil {
+		return "", errors.Annotate(err, "cannot create temporary file")
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	digest, err = charm.Digest(io.TeeReader(f, tmp))
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot copy %q", archivePath)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", errors.Trace(err)
+	}
+
+	unlock, err := c.lock()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer unlock()
+
+	path := c.path(digest)
+	if _, err := os.Stat(path); err == nil {
+		return digest, errors.Trace(touch(path))
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", errors.Trace(err)
+	}
+	return digest, nil
+}
+
+// Get returns the path of the archive with the given digest. It returns
+// an error satisfying errors.IsNotFound if the archive is not cached. The
+// archive is marked as recently used, so that GC keeps it in preference
+// to archives that have not been used recently.
+func (c *Cache) Get(digest string) (string, error) {
+	if err := charm.ValidateDigest(digest); err != nil {
+		return "", errors.Trace(err)
+	}
+	unlock, err := c.lock()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer unlock()
+
+	path := c.path(digest)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", errors.NotFoundf("charm archive %q", digest)
+	} else if err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := touch(path); err != nil {
+		return "", errors.Trace(err)
+	}
+	return path, nil
+}
+
+// GC removes the least recently used archives from the cache until the
+// total size of the archives it holds is no more than maxSize bytes.
+func (c *Cache) GC(maxSize int64) error {
+	unlock, err := c.lock()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var (
+		archives []os.FileInfo
+		total    int64
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		sum, ok := strings.CutSuffix(name, archiveSuffix)
+		if !ok || charm.ValidateDigest(digestPrefix+sum) != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		archives = append(archives, info)
+		total += info.Size()
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].ModTime().Before(archives[j].ModTime())
+	})
+	for _, info := range archives {
+		if total <= maxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, info.Name())); err != nil && !os.IsNotExist(err) {
+			return errors.Trace(err)
+		}
+		total -= info.Size()
+	}
+	return nil
+}
+
+// path returns the path of the archive with the given digest, which
+// must be valid.
+func (c *Cache) path(digest string) string {
+	return filepath.Join(c.dir, strings.TrimPrefix(digest, digestPrefix)+archiveSuffix)
+}
+
+// lock acquires the exclusive cache lock and returns a function that
+// releases it. Even Get needs exclusive access, as it updates the
+// modification time that GC relies on.
+func (c *Cache) lock() (func(), error) {
+	f, err := os.OpenFile(filepath.Join(c.dir, lockFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot open charm cache lock")
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, errors.Annotate(err, "cannot lock charm cache")
+	}
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// touch records that the file at path has just been used. The
+// modification time is used, as access times are often not maintained.
+func touch(path string) error {
+	now := time.Now()
+	return os.Chtimes(path, now, now)
+}
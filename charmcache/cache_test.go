@@ -0,0 +1,127 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charmcache_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+	"github.com/juju/charm/v12/charmcache"
+)
+
+type CacheSuite struct {
+	cache *charmcache.Cache
+}
+
+var _ = gc.Suite(&CacheSuite{})
+
+func (s *CacheSuite) SetUpTest(c *gc.C) {
+	cache, err := charmcache.New(filepath.Join(c.MkDir(), "cache"))
+	c.Assert(err, jc.ErrorIsNil)
+	s.cache = cache
+}
+
+// writeArchive writes a fake archive holding content and returns its
+// path and expected digest.
+func writeArchive(c *gc.C, content string) (string, string) {
+	path := filepath.Join(c.MkDir(), "archive.charm")
+	err := os.WriteFile(path, []byte(content), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	digest, err := charm.ArchiveDigest(path)
+	c.Assert(err, jc.ErrorIsNil)
+	return path, digest
+}
+
+func (s *CacheSuite) TestPutGet(c *gc.C) {
+	path, expectDigest := writeArchive(c, "charm content")
+	digest, err := s.cache.Put(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(digest, gc.Equals, expectDigest)
+	c.Assert(charm.ValidateDigest(digest), jc.ErrorIsNil)
+
+	cached, err := s.cache.Get(digest)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(filepath.Dir(cached), gc.Equals, s.cache.Dir())
+	data, err := os.ReadFile(cached)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "charm content")
+
+	// Putting the same content again stores it only once.
+	digest, err = s.cache.Put(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(digest, gc.Equals, expectDigest)
+	entries, err := os.ReadDir(s.cache.Dir())
+	c.Assert(err, jc.ErrorIsNil)
+	var archives int
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".charm") {
+			archives++
+		}
+	}
+	c.Assert(archives, gc.Equals, 1)
+}
+
+func (s *CacheSuite) TestGetNotFound(c *gc.C) {
+	_, digest := writeArchive(c, "never put")
+	_, err := s.cache.Get(digest)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *CacheSuite) TestGetInvalidDigest(c *gc.C) {
+	_, err := s.cache.Get("../../etc/passwd")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `digest "../../etc/passwd" not valid`)
+
+	// The bare hex checksum is not a valid digest.
+	_, digest := writeArchive(c, "charm content")
+	_, err = s.cache.Get(strings.TrimPrefix(digest, "sha256:"))
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *CacheSuite) TestPutMissingArchive(c *gc.C) {
+	_, err := s.cache.Put(filepath.Join(c.MkDir(), "missing.charm"))
+	c.Assert(errors.Cause(err), jc.Satisfies, os.IsNotExist)
+}
+
+func (s *CacheSuite) TestGC(c *gc.C) {
+	var digests []string
+	for _, content := range []string{"aaaa", "bbbb", "cccc"} {
+		path, _ := writeArchive(c, content)
+		digest, err := s.cache.Put(path)
+		c.Assert(err, jc.ErrorIsNil)
+		digests = append(digests, digest)
+	}
+	// Age the archives so that the first is the most recently used.
+	for i, digest := range digests {
+		t := time.Now().Add(-time.Duration(i+1) * time.Hour)
+		name := strings.TrimPrefix(digest, "sha256:") + ".charm"
+		err := os.Chtimes(filepath.Join(s.cache.Dir(), name), t, t)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	err := s.cache.GC(8)
+	c.Assert(err, jc.ErrorIsNil)
+	for i, digest := range digests {
+		_, err := s.cache.Get(digest)
+		if i < 2 {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, jc.Satisfies, errors.IsNotFound)
+		}
+	}
+
+	err = s.cache.GC(0)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, digest := range digests {
+		_, err := s.cache.Get(digest)
+		c.Check(err, jc.Satisfies, errors.IsNotFound)
+	}
+}
@@ -5,18 +5,21 @@ package charm
 
 import (
 	"archive/zip"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/juju/errors"
+	"gopkg.in/yaml.v2"
 )
 
 // defaultJujuIgnore contains jujuignore directives for excluding VCS- and
@@ -46,6 +49,19 @@ var defaultJujuIgnore = `
 type CharmDir struct {
 	Path string
 	*charmBase
+
+	// versionProviders holds the providers used to generate the
+	// version string; if empty, DefaultVersionProviders is used.
+	versionProviders []VersionProvider
+
+	// versionDetectionDisabled records whether version string
+	// generation has been disabled.
+	versionDetectionDisabled bool
+
+	// unsaved holds the contents of the files changed by SetMeta and
+	// SetConfig that have yet to be written by Save, keyed by file
+	// name.
+	unsaved map[string][]byte
 }
 
 // Trick to ensure *CharmDir implements the Charm interface.
@@ -61,6 +77,16 @@ func IsCharmDir(path string) bool {
 
 // ReadCharmDir returns a CharmDir representing an expanded charm directory.
 func ReadCharmDir(path string) (*CharmDir, error) {
+	return ReadCharmDirContext(context.Background(), path)
+}
+
+// ReadCharmDirContext is like ReadCharmDir, except that it stops and
+// returns the context's error if ctx is done before the charm has been
+// read.
+func ReadCharmDirContext(ctx context.Context, path string) (*CharmDir, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
 	b := &CharmDir{
 		Path:      path,
 		charmBase: &charmBase{},
@@ -69,11 +95,17 @@ func ReadCharmDir(path string) (*CharmDir, error) {
 	if err != nil {
 		return nil, errors.Annotatef(err, `reading "metadata.yaml" file`)
 	}
-	b.meta, err = ReadMeta(reader)
+	b.meta, err = ReadMeta(reader, WithMetaSource(reader.Name()))
 	_ = reader.Close()
 	if err != nil {
 		return nil, errors.Annotatef(err, `parsing "metadata.yaml" file`)
 	}
+	if b.meta.Translations, err = ReadMetaTranslations(path); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	// Try to read the optional manifest.yaml, it's required to determine if
 	// this charm is v1 or not.
@@ -113,6 +145,9 @@ func ReadCharmDir(path string) (*CharmDir, error) {
 	} else if !os.IsNotExist(err) {
 		return nil, errors.Annotatef(err, `reading "metrics.yaml" file`)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	if b.actions, err = getActions(
 		b.meta.Name,
@@ -126,6 +161,9 @@ func ReadCharmDir(path string) (*CharmDir, error) {
 	); err != nil {
 		return nil, err
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	if reader, err = os.Open(b.join("revision")); err == nil {
 		_, err = fmt.Fscan(reader, &b.revision)
@@ -214,6 +252,104 @@ func (dir *CharmDir) SetDiskRevision(revision int) error {
 	return err
 }
 
+// SetMeta replaces the charm's metadata. The metadata is written to
+// metadata.yaml when Save is called. It is checked first, together with
+// the charm's manifest, and must read back from its written form; if
+// it is not valid, an error is returned and the charm is not changed.
+func (dir *CharmDir) SetMeta(meta *Meta) error {
+	if meta == nil {
+		return errors.NotValidf("nil metadata")
+	}
+	if err := CheckMeta(&charmBase{meta: meta, manifest: dir.manifest}); err != nil {
+		return errors.Trace(err)
+	}
+	data, err := yaml.Marshal(meta)
+	if err != nil {
+		return errors.Annotate(err, "cannot marshal metadata")
+	}
+	if _, err := ReadMeta(bytes.NewReader(data)); err != nil {
+		return errors.Annotate(err, "metadata cannot be read back")
+	}
+	dir.meta = meta
+	dir.setUnsaved("metadata.yaml", data)
+	return nil
+}
+
+// SetConfig replaces the charm's config. The config is written to
+// config.yaml when Save is called. It must read back from its written
+// form, which checks the types and defaults of its options; if it does
+// not, an error is returned and the charm is not changed.
+func (dir *CharmDir) SetConfig(config *Config) error {
+	if config == nil {
+		return errors.NotValidf("nil config")
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return errors.Annotate(err, "cannot marshal config")
+	}
+	if _, err := ReadConfig(bytes.NewReader(data)); err != nil {
+		return errors.Annotate(err, "config cannot be read back")
+	}
+	dir.config = config
+	dir.setUnsaved("config.yaml", data)
+	return nil
+}
+
+func (dir *CharmDir) setUnsaved(name string, data []byte) {
+	if dir.unsaved == nil {
+		dir.unsaved = make(map[string][]byte)
+	}
+	dir.unsaved[name] = data
+}
+
+// Save writes the files changed by SetMeta and SetConfig back to the
+// charm directory. The files are written in canonical form, so any
+// comments and formatting in the original files are not preserved.
+// Each file is replaced atomically, keeping its permissions. Until Save
+// is called, ArchiveTo archives the files as they are on disk.
+func (dir *CharmDir) Save() error {
+	names := make([]string, 0, len(dir.unsaved))
+	for name := range dir.unsaved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writeFileAtomic(dir.join(name), dir.unsaved[name]); err != nil {
+			return errors.Annotatef(err, "writing %q file", name)
+		}
+		delete(dir.unsaved, name)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to path by way of a temporary file in the
+// same directory, so that path never holds partial contents. The file
+// keeps the permissions of any file it replaces.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return errors.Trace(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(os.Rename(tmp.Name(), path))
+}
+
 // resolveSymlinkedRoot returns the target destination of a
 // charm root directory if the root directory is a symlink.
 func resolveSymlinkedRoot(rootPath string) (string, error) {
@@ -230,21 +366,33 @@ func resolveSymlinkedRoot(rootPath string) (string, error) {
 // ArchiveTo creates a charm file from the charm expanded in dir.
 // By convention a charm archive should have a ".charm" suffix.
 func (dir *CharmDir) ArchiveTo(w io.Writer) error {
+	return dir.ArchiveToContext(context.Background(), w)
+}
+
+// ArchiveToContext is like ArchiveTo, except that it stops and returns
+// the context's error if ctx is done before all the charm's files have
+// been archived.
+func (dir *CharmDir) ArchiveToContext(ctx context.Context, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Trace(err)
+	}
 	ignoreRules, err := dir.buildIgnoreRules()
 	if err != nil {
 		return err
 	}
-	// We update the version to make sure we don't lag behind
-	dir.version, _, err = dir.MaybeGenerateVersionString(logger)
-	if err != nil {
-		// We don't want to stop, even if the version cannot be generated
-		logger.Warningf("trying to generate version string: %v", err)
+	if !dir.versionDetectionDisabled {
+		// We update the version to make sure we don't lag behind
+		dir.version, _, err = dir.MaybeGenerateVersionString(logger)
+		if err != nil {
+			// We don't want to stop, even if the version cannot be generated
+			logger.Warningf("trying to generate version string: %v", err)
+		}
 	}
 
-	return writeArchive(w, dir.Path, dir.revision, dir.version, dir.Meta().Hooks(), ignoreRules)
+	return writeArchive(ctx, w, dir.Path, dir.revision, dir.version, dir.Meta().Hooks(), ignoreRules)
 }
 
-func writeArchive(w io.Writer, path string, revision int, versionString string, hooks map[string]bool, ignoreRules ignoreRuleset) error {
+func writeArchive(ctx context.Context, w io.Writer, path string, revision int, versionString string, hooks map[string]bool, ignoreRules ignoreRuleset) error {
 	zipw := zip.NewWriter(w)
 	defer zipw.Close()
 
@@ -254,7 +402,7 @@ func writeArchive(w io.Writer, path string, revision int, versionString string,
 	if err != nil {
 		return err
 	}
-	zp := zipPacker{zipw, rootPath, hooks, ignoreRules}
+	zp := zipPacker{zipw, ctx, rootPath, hooks, ignoreRules}
 	if revision != -1 {
 		zp.AddFile("revision", strconv.Itoa(revision))
 	}
@@ -266,6 +414,7 @@ func writeArchive(w io.Writer, path string, revision int, versionString string,
 
 type zipPacker struct {
 	*zip.Writer
+	ctx         context.Context
 	root        string
 	hooks       map[string]bool
 	ignoreRules ignoreRuleset
@@ -291,6 +440,9 @@ func (zp *zipPacker) visit(path string, fi os.FileInfo, err error) error {
 	if err != nil {
 		return err
 	}
+	if err := zp.ctx.Err(); err != nil {
+		return errors.Trace(err)
+	}
 
 	relpath, err := filepath.Rel(zp.root, path)
 	if err != nil {
@@ -415,6 +567,33 @@ func (v *vcsCMD) commonErrHandler(err error, charmPath string) error {
 		"%v\nThis means that the charm version won't show in juju status. Charm path %q", v.vcsType, err, charmPath)
 }
 
+// Type implements VersionProvider.
+func (v *vcsCMD) Type() string {
+	return v.vcsType
+}
+
+// Version implements VersionProvider.
+func (v *vcsCMD) Version(charmPath string) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), vcsCheckTimeout)
+	if !v.usesTypeCheck(ctx, charmPath, cancel) {
+		return "", false, nil
+	}
+	cmd := exec.Command(v.vcsType, v.args...)
+	// We need to make sure that the working directory will be the one we execute the commands from.
+	cmd.Dir = charmPath
+	// Version string value is written to stdout if successful.
+	out, err := cmd.Output()
+	if err != nil {
+		// We had an error but we still know that we use a vcs, hence we can stop here and handle it.
+		return "", true, v.commonErrHandler(err, charmPath)
+	}
+	return strings.TrimSuffix(string(out), "\n"), true, nil
+}
+
+// vcsCheckTimeout bounds the time taken to check whether a charm is kept
+// in a particular version control system.
+const vcsCheckTimeout = 2 * time.Second
+
 // usesGit first check checks for the easy case of the current charmdir has a
 // git folder.
 // There can be cases when the charmdir actually uses git and is just a subdir,
@@ -465,67 +644,37 @@ const versionFileVersionType = "versionFile"
 // We want to know whether parent folders use one of these vcs, that's why we
 // try to execute each one of them
 // The second return value is the detected vcs type.
+// The providers set by SetVersionProviders are used, or
+// DefaultVersionProviders if none are set.
 func (dir *CharmDir) MaybeGenerateVersionString(logger Logger) (string, string, error) {
-	// vcsStrategies is the strategies to use to access the version file content.
-	vcsStrategies := map[string]vcsCMD{
-		"hg": vcsCMD{
-			vcsType:       "hg",
-			args:          []string{"id", "-n"},
-			usesTypeCheck: usesHg,
-		},
-		"git": vcsCMD{
-			vcsType:       "git",
-			args:          []string{"describe", "--dirty", "--always"},
-			usesTypeCheck: usesGit,
-		},
-		"bzr": vcsCMD{
-			vcsType:       "bzr",
-			args:          []string{"version-info"},
-			usesTypeCheck: usesBzr,
-		},
-	}
-
-	// Nowadays most vcs used are git, we want to make sure that git is the first one we test
-	vcsOrder := [...]string{"git", "hg", "bzr"}
-	cmdWaitTime := 2 * time.Second
-
-	absPath := dir.Path
-	if !filepath.IsAbs(absPath) {
-		var err error
-		absPath, err = filepath.Abs(dir.Path)
-		if err != nil {
-			return "", "", errors.Annotatef(err, "failed resolving relative path %q", dir.Path)
-		}
+	absPath, err := filepath.Abs(dir.Path)
+	if err != nil {
+		return "", "", errors.Annotatef(err, "failed resolving relative path %q", dir.Path)
 	}
-
-	for _, vcsType := range vcsOrder {
-		vcsCmd := vcsStrategies[vcsType]
-		ctx, cancel := context.WithTimeout(context.Background(), cmdWaitTime)
-		if vcsCmd.usesTypeCheck(ctx, dir.Path, cancel) {
-			cmd := exec.Command(vcsCmd.vcsType, vcsCmd.args...)
-			// We need to make sure that the working directory will be the one we execute the commands from.
-			cmd.Dir = dir.Path
-			// Version string value is written to stdout if successful.
-			out, err := cmd.Output()
-			if err != nil {
-				// We had an error but we still know that we use a vcs, hence we can stop here and handle it.
-				return "", vcsType, vcsCmd.commonErrHandler(err, absPath)
-			}
-			output := strings.TrimSuffix(string(out), "\n")
-			return output, vcsType, nil
-		}
+	version, vcsType, err := DetectVersion(dir.Path, dir.versionProviders...)
+	if err != nil {
+		return "", vcsType, err
 	}
-
-	// If all strategies fail we fallback to check the version below
-	if file, err := os.Open(dir.join("version")); err == nil {
+	switch vcsType {
+	case versionFileVersionType:
 		logger.Debugf("charm is not in version control, but uses a version file, charm path %q", absPath)
-		ver, err := ReadVersion(file)
-		file.Close()
-		if err != nil {
-			return "", versionFileVersionType, err
-		}
-		return ver, versionFileVersionType, nil
+	case "":
+		logger.Infof("charm is not versioned, charm path %q", absPath)
 	}
-	logger.Infof("charm is not versioned, charm path %q", absPath)
-	return "", "", nil
+	return version, vcsType, nil
+}
+
+// SetVersionProviders sets the providers used to generate the charm's
+// version string when it is archived, in order of preference. With no
+// providers, DefaultVersionProviders is used.
+func (dir *CharmDir) SetVersionProviders(providers ...VersionProvider) {
+	dir.versionProviders = providers
+	dir.versionDetectionDisabled = false
+}
+
+// DisableVersionDetection stops ArchiveTo generating a version string
+// for the charm, for hermetic builds. The archive then holds the version
+// read from the charm directory's version file, if any.
+func (dir *CharmDir) DisableVersionDetection() {
+	dir.versionDetectionDisabled = true
 }
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/juju/collections/set"
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
@@ -23,6 +24,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/charm/v12"
+	"github.com/juju/charm/v12/charmtest"
 )
 
 type CharmDirSuite struct {
@@ -89,6 +91,24 @@ func (s *CharmDirSuite) TestReadCharmDirWithCustomMetrics(c *gc.C) {
 	c.Assert(Keys(dir.Metrics()), gc.DeepEquals, []string{"juju-unit-time", "pings"})
 }
 
+func (s *CharmDirSuite) TestMeteringInfo(c *gc.C) {
+	dir, err := charm.ReadCharmDir(charmDirPath(c, "varnish"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(dir.MeteringInfo(), jc.DeepEquals, charm.MeteringInfo{})
+
+	dir, err = charm.ReadCharmDir(charmDirPath(c, "metered-empty"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(dir.MeteringInfo(), jc.DeepEquals, charm.MeteringInfo{Metered: true})
+
+	dir, err = charm.ReadCharmDir(charmDirPath(c, "metered"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(dir.MeteringInfo(), jc.DeepEquals, charm.MeteringInfo{
+		Metered:        true,
+		Metrics:        []string{"pings"},
+		BuiltinMetrics: []string{"juju-unit-time"},
+	})
+}
+
 func (s *CharmDirSuite) TestReadCharmDirWithoutActions(c *gc.C) {
 	path := charmDirPath(c, "wordpress")
 	dir, err := charm.ReadCharmDir(path)
@@ -146,6 +166,22 @@ func (s *CharmDirSuite) TestArchiveTo(c *gc.C) {
 	s.assertArchiveTo(c, baseDir, charmDir)
 }
 
+func (s *CharmDirSuite) TestArchiveToContextCancelled(c *gc.C) {
+	dir := readCharmDir(c, "dummy")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var buf bytes.Buffer
+	err := dir.ArchiveToContext(ctx, &buf)
+	c.Assert(errors.Cause(err), gc.Equals, context.Canceled)
+}
+
+func (s *CharmDirSuite) TestReadCharmDirContextCancelled(c *gc.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := charm.ReadCharmDirContext(ctx, charmDirPath(c, "dummy"))
+	c.Assert(errors.Cause(err), gc.Equals, context.Canceled)
+}
+
 func (s *CharmDirSuite) TestArchiveToWithIgnoredFiles(c *gc.C) {
 	charmDir := cloneDir(c, charmDirPath(c, "dummy"))
 	dir, err := charm.ReadCharmDir(charmDir)
@@ -747,3 +783,104 @@ func (s *CharmSuite) TestCheckGitTimeout(c *gc.C) {
 	isUsing := charm.UsesGit(ctx, charmDir, cancel)
 	c.Assert(isUsing, gc.Equals, false)
 }
+
+func (s *CharmDirSuite) TestSize(c *gc.C) {
+	charmDir := cloneDir(c, charmDirPath(c, "dummy"))
+	dir, err := charm.ReadCharmDir(charmDir)
+	c.Assert(err, jc.ErrorIsNil)
+	before, err := dir.Size()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(before.Files, jc.GreaterThan, 0)
+
+	err = ioutil.WriteFile(filepath.Join(charmDir, ".jujuignore"), []byte("*.log\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(charmDir, "big.bin"), make([]byte, 10000), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(charmDir, "debug.log"), make([]byte, 50000), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Neither debug.log nor .jujuignore itself is archived.
+	size, err := dir.Size()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(size, jc.DeepEquals, charm.ContentSize{
+		Bytes:            before.Bytes + 10000,
+		Files:            before.Files + 1,
+		LargestFile:      "big.bin",
+		LargestFileBytes: 10000,
+	})
+
+	dir.DisableVersionDetection()
+	archive, err := charm.ReadCharmArchive(archivePath(c, dir))
+	c.Assert(err, jc.ErrorIsNil)
+	archiveSize, err := archive.Size()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(archiveSize.LargestFile, gc.Equals, "big.bin")
+	c.Assert(archiveSize.LargestFileBytes, gc.Equals, int64(10000))
+	c.Assert(archiveSize.Files >= size.Files, jc.IsTrue)
+}
+
+func (s *CharmDirSuite) TestSetMetaAndConfig(c *gc.C) {
+	path := c.MkDir()
+	ch := &charmtest.Charm{
+		Metadata: "# The charm's metadata.\nname: mysql\nsummary: s\ndescription: d\n",
+		Config:   "options:\n  port: {type: int, default: 3306}\n",
+	}
+	c.Assert(ch.WriteDir(path), jc.ErrorIsNil)
+	c.Assert(os.Chmod(filepath.Join(path, "config.yaml"), 0600), jc.ErrorIsNil)
+	dir, err := charm.ReadCharmDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+
+	meta := *dir.Meta()
+	meta.Summary = "A database."
+	c.Assert(dir.SetMeta(&meta), jc.ErrorIsNil)
+	c.Assert(dir.Meta().Summary, gc.Equals, "A database.")
+
+	config := charm.NewConfig()
+	config.Options["port"] = charm.Option{Type: "int", Default: 3307}
+	c.Assert(dir.SetConfig(config), jc.ErrorIsNil)
+
+	// Nothing is written until the charm is saved.
+	reread, err := charm.ReadCharmDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(reread.Meta().Summary, gc.Equals, "s")
+
+	c.Assert(dir.Save(), jc.ErrorIsNil)
+	reread, err = charm.ReadCharmDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(reread.Meta().Summary, gc.Equals, "A database.")
+	c.Check(reread.Config().Options["port"].Default, gc.Equals, int64(3307))
+
+	info, err := os.Stat(filepath.Join(path, "config.yaml"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info.Mode().Perm(), gc.Equals, os.FileMode(0600))
+	entries, err := os.ReadDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, entry := range entries {
+		c.Check(strings.Contains(entry.Name(), ".tmp-"), jc.IsFalse, gc.Commentf("file %q", entry.Name()))
+	}
+}
+
+func (s *CharmDirSuite) TestSetMetaInvalid(c *gc.C) {
+	path := c.MkDir()
+	ch := &charmtest.Charm{Metadata: "name: mysql\nsummary: s\ndescription: d\n"}
+	c.Assert(ch.WriteDir(path), jc.ErrorIsNil)
+	dir, err := charm.ReadCharmDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+
+	meta := *dir.Meta()
+	meta.Provides = map[string]charm.Relation{
+		"juju-info": {Name: "juju-info", Role: charm.RoleProvider, Interface: "juju-info"},
+	}
+	err = dir.SetMeta(&meta)
+	c.Assert(err, gc.NotNil)
+	c.Check(dir.Meta().Provides, gc.HasLen, 0)
+
+	config := charm.NewConfig()
+	config.Options["port"] = charm.Option{Type: "int", Default: "not a number"}
+	err = dir.SetConfig(config)
+	c.Assert(err, gc.ErrorMatches, `config cannot be read back: .*`)
+	c.Check(dir.Config().Options, gc.HasLen, 0)
+
+	// Saving without changes writes nothing.
+	c.Assert(dir.Save(), jc.ErrorIsNil)
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package charmtest helps tests build charm and bundle fixtures. A
+// fixture is declared in memory, as the contents of its files, and
+// materialised into a directory or a zip archive as needed, so that
+// tests need not keep a repository of fixture files on disk. The
+// fixtures in internal/test-charm-repo are still kept on disk, as some
+// hold files, such as symlinked hooks, that cannot be declared here.
+//
+// For example:
+//
+//	ch := &charmtest.Charm{
+//		Metadata: "name: mysql\nsummary: s\ndescription: d\n",
+//		Config:   "options:\n  port: {type: int, default: 3306}\n",
+//		Hooks:    map[string]string{"install": "#!/bin/sh\n"},
+//	}
+//	dir, err := ch.ReadDir(c.MkDir())
+package charmtest
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/charm/v12"
+	"github.com/juju/charm/v12/internal/filetree"
+)
+
+// Charm declares the files of a charm. Empty fields are not written.
+type Charm struct {
+	// Metadata holds the contents of metadata.yaml.
+	Metadata string
+
+	// Manifest holds the contents of manifest.yaml.
+	Manifest string
+
+	// Config holds the contents of config.yaml.
+	Config string
+
+	// Actions holds the contents of actions.yaml.
+	Actions string
+
+	// Revision holds the charm revision, written to the revision
+	// file if it is not zero.
+	Revision int
+
+	// Hooks holds the contents of the charm's hooks, keyed by hook
+	// name. Hooks are written as executables in the hooks directory.
+	Hooks map[string]string
+
+	// Files holds the contents of any other files, keyed by their
+	// slash-separated path relative to the root of the charm.
+	Files map[string]string
+}
+
+// files returns the files of the charm.
+func (ch *Charm) files() fileSet {
+	files := make(fileSet)
+	files.add("metadata.yaml", ch.Metadata, 0644)
+	files.add("manifest.yaml", ch.Manifest, 0644)
+	files.add("config.yaml", ch.Config, 0644)
+	files.add("actions.yaml", ch.Actions, 0644)
+	if ch.Revision != 0 {
+		files.add("revision", strconv.Itoa(ch.Revision), 0644)
+	}
+	for name, content := range ch.Hooks {
+		files["hooks/"+name] = filetree.File{Data: []byte(content), Mode: 0755}
+	}
+	for name, content := range ch.Files {
+		files[name] = filetree.File{Data: []byte(content), Mode: 0644}
+	}
+	return files
+}
+
+// WriteDir writes the charm's files into dir, creating it if needed.
+func (ch *Charm) WriteDir(dir string) error {
+	return ch.files().writeDir(dir)
+}
+
+// WriteArchive writes the charm to w as a zip archive.
+func (ch *Charm) WriteArchive(w io.Writer) error {
+	return ch.files().writeArchive(w)
+}
+
+// ReadDir writes the charm's files into dir, creating it if needed,
+// and reads it back as a charm directory.
+func (ch *Charm) ReadDir(dir string) (*charm.CharmDir, error) {
+	if err := ch.WriteDir(dir); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return charm.ReadCharmDir(dir)
+}
+
+// Archive returns the charm as an archive held in memory.
+func (ch *Charm) Archive() (*charm.CharmArchive, error) {
+	var buf bytes.Buffer
+	if err := ch.WriteArchive(&buf); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return charm.ReadCharmArchiveBytes(buf.Bytes())
+}
+
+// Bundle declares the files of a bundle. Empty fields are not written.
+type Bundle struct {
+	// Data holds the contents of bundle.yaml.
+	Data string
+
+	// ReadMe holds the contents of README.md. Bundles cannot be read
+	// without one, so a placeholder is written if it is empty.
+	ReadMe string
+
+	// Files holds the contents of any other files, such as overlays
+	// or charms used by the bundle, keyed by their slash-separated
+	// path relative to the root of the bundle.
+	Files map[string]string
+}
+
+// files returns the files of the bundle.
+func (b *Bundle) files() fileSet {
+	files := make(fileSet)
+	files.add("bundle.yaml", b.Data, 0644)
+	readMe := b.ReadMe
+	if readMe == "" {
+		readMe = "A bundle.\n"
+	}
+	files.add("README.md", readMe, 0644)
+	for name, content := range b.Files {
+		files[name] = filetree.File{Data: []byte(content), Mode: 0644}
+	}
+	return files
+}
+
+// WriteDir writes the bundle's files into dir, creating it if needed.
+func (b *Bundle) WriteDir(dir string) error {
+	return b.files().writeDir(dir)
+}
+
+// WriteArchive writes the bundle to w as a zip archive.
+func (b *Bundle) WriteArchive(w io.Writer) error {
+	return b.files().writeArchive(w)
+}
+
+// ReadDir writes the bundle's files into dir, creating it if needed,
+// and reads it back as a bundle directory.
+func (b *Bundle) ReadDir(dir string) (*charm.BundleDir, error) {
+	if err := b.WriteDir(dir); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return charm.ReadBundleDir(dir)
+}
+
+// Archive returns the bundle as an archive held in memory.
+func (b *Bundle) Archive() (*charm.BundleArchive, error) {
+	var buf bytes.Buffer
+	if err := b.WriteArchive(&buf); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return charm.ReadBundleArchiveBytes(buf.Bytes())
+}
+
+// fileSet holds the files of a fixture, keyed by their slash-separated
+// path.
+type fileSet map[string]filetree.File
+
+// add adds the named file if it has any content.
+func (fs fileSet) add(name, content string, mode os.FileMode) {
+	if content != "" {
+		fs[name] = filetree.File{Data: []byte(content), Mode: mode}
+	}
+}
+
+// writeDir writes the files into dir.
+func (fs fileSet) writeDir(dir string) error {
+	return errors.Trace(filetree.Write(dir, fs))
+}
+
+// writeArchive writes the files to w as a zip archive.
+func (fs fileSet) writeArchive(w io.Writer) error {
+	zipw := zip.NewWriter(w)
+	for _, name := range filetree.Names(fs) {
+		f := fs[name]
+		h := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		h.SetMode(f.Mode)
+		fw, err := zipw.CreateHeader(h)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(zipw.Close())
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charmtest_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+	"github.com/juju/charm/v12/charmtest"
+)
+
+type CharmTestSuite struct{}
+
+var _ = gc.Suite(&CharmTestSuite{})
+
+var mysqlCharm = &charmtest.Charm{
+	Metadata: "name: mysql\nsummary: s\ndescription: d\n",
+	Config:   "options:\n  port: {type: int, default: 3306}\n",
+	Actions:  "backup:\n  description: Back up the database.\n",
+	Revision: 7,
+	Hooks:    map[string]string{"install": "#!/bin/sh\n"},
+	Files:    map[string]string{"templates/my.cnf": "[mysqld]\n"},
+}
+
+func checkMySQL(c *gc.C, ch charm.Charm) {
+	c.Check(ch.Meta().Name, gc.Equals, "mysql")
+	c.Check(ch.Config().Options["port"].Default, gc.Equals, int64(3306))
+	c.Check(ch.Actions().ActionSpecs, gc.HasLen, 1)
+	c.Check(ch.Revision(), gc.Equals, 7)
+}
+
+func (s *CharmTestSuite) TestCharmReadDir(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "mysql")
+	dir, err := mysqlCharm.ReadDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+	checkMySQL(c, dir)
+	c.Assert(dir.Meta().Hooks()["install"], jc.IsTrue)
+
+	info, err := os.Stat(filepath.Join(path, "hooks", "install"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Mode().Perm(), gc.Equals, os.FileMode(0755))
+	data, err := os.ReadFile(filepath.Join(path, "templates", "my.cnf"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "[mysqld]\n")
+	_, err = os.Stat(filepath.Join(path, "manifest.yaml"))
+	c.Assert(os.IsNotExist(err), jc.IsTrue)
+}
+
+func (s *CharmTestSuite) TestCharmArchive(c *gc.C) {
+	archive, err := mysqlCharm.Archive()
+	c.Assert(err, jc.ErrorIsNil)
+	checkMySQL(c, archive)
+
+	// The archive expands to the same files.
+	path := c.MkDir()
+	err = archive.ExpandTo(path)
+	c.Assert(err, jc.ErrorIsNil)
+	info, err := os.Stat(filepath.Join(path, "hooks", "install"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Mode().Perm()&0100, gc.Not(gc.Equals), os.FileMode(0))
+	dir, err := charm.ReadCharmDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+	checkMySQL(c, dir)
+}
+
+func (s *CharmTestSuite) TestCharmArchiveIsDeterministic(c *gc.C) {
+	var buf0, buf1 bytes.Buffer
+	c.Assert(mysqlCharm.WriteArchive(&buf0), jc.ErrorIsNil)
+	c.Assert(mysqlCharm.WriteArchive(&buf1), jc.ErrorIsNil)
+	c.Assert(buf0.Bytes(), jc.DeepEquals, buf1.Bytes())
+}
+
+var wordpressBundle = &charmtest.Bundle{
+	Data: `
+applications:
+  wordpress:
+    charm: ch:wordpress
+    num_units: 1
+`,
+	Files: map[string]string{"overlays/scale.yaml": "applications:\n  wordpress:\n    num_units: 2\n"},
+}
+
+func (s *CharmTestSuite) TestBundleReadDir(c *gc.C) {
+	path := c.MkDir()
+	dir, err := wordpressBundle.ReadDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dir.Data().Applications["wordpress"].NumUnits, gc.Equals, 1)
+	c.Assert(dir.ReadMe(), gc.Equals, "A bundle.\n")
+	_, err = os.Stat(filepath.Join(path, "overlays", "scale.yaml"))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *CharmTestSuite) TestBundleArchive(c *gc.C) {
+	b := *wordpressBundle
+	b.ReadMe = "WordPress.\n"
+	archive, err := b.Archive()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(archive.Data().Applications["wordpress"].Charm, gc.Equals, "ch:wordpress")
+	c.Assert(archive.ReadMe(), gc.Equals, "WordPress.\n")
+}
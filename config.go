@@ -7,8 +7,12 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/juju/errors"
 	"github.com/juju/schema"
@@ -25,6 +29,39 @@ type Option struct {
 	Type        string      `yaml:"type"`
 	Description string      `yaml:"description,omitempty"`
 	Default     interface{} `yaml:"default,omitempty"`
+
+	// Choices holds the values a string option may take, or is empty
+	// if the option may take any value.
+	Choices []string `yaml:"choices,omitempty"`
+
+	// Minimum and Maximum hold the inclusive bounds of the values an
+	// int or float option may take, or are nil if the option is not
+	// bounded.
+	Minimum *float64 `yaml:"minimum,omitempty"`
+	Maximum *float64 `yaml:"maximum,omitempty"`
+}
+
+// MarshalYAML implements yaml.Marshaler. The fields are written in
+// a fixed order, and the default is only omitted when it is unset, so
+// that zero defaults such as false or 0 survive a round trip.
+func (option Option) MarshalYAML() (interface{}, error) {
+	out := yaml.MapSlice{{Key: "type", Value: option.Type}}
+	if option.Description != "" {
+		out = append(out, yaml.MapItem{Key: "description", Value: option.Description})
+	}
+	if option.Default != nil {
+		out = append(out, yaml.MapItem{Key: "default", Value: option.Default})
+	}
+	if len(option.Choices) > 0 {
+		out = append(out, yaml.MapItem{Key: "choices", Value: option.Choices})
+	}
+	if option.Minimum != nil {
+		out = append(out, yaml.MapItem{Key: "minimum", Value: *option.Minimum})
+	}
+	if option.Maximum != nil {
+		out = append(out, yaml.MapItem{Key: "maximum", Value: *option.Maximum})
+	}
+	return out, nil
 }
 
 // error replaces any supplied non-nil error with a new error describing a
@@ -63,13 +100,27 @@ func (c secretC) Coerce(v interface{}, path []string) (interface{}, error) {
 }
 
 // validate returns an appropriately-typed value for the supplied value, or
-// returns an error if it cannot be converted to the correct type. Nil values
-// are always considered valid.
-func (option Option) validate(name string, value interface{}) (_ interface{}, err error) {
+// returns an error if it cannot be converted to the correct type or is not
+// one of the option's choices or within its bounds. Nil values are always
+// considered valid.
+func (option Option) validate(name string, value interface{}) (interface{}, error) {
+	value, err := option.coerce(name, value)
+	if err != nil || value == nil {
+		return value, err
+	}
+	if err := option.checkValue(name, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// coerce returns an appropriately-typed value for the supplied value, or
+// returns an error if it cannot be converted to the correct type.
+func (option Option) coerce(name string, value interface{}) (_ interface{}, err error) {
 	if value == nil {
 		return nil, nil
 	}
-	if checker := optionTypeCheckers[option.Type]; checker != nil {
+	if checker := optionTypeCheckers()[option.Type]; checker != nil {
 		defer option.error(&err, name, value)
 		if value, err = checker.Coerce(value, nil); err != nil {
 			return nil, err
@@ -79,15 +130,124 @@ func (option Option) validate(name string, value interface{}) (_ interface{}, er
 	return nil, fmt.Errorf("option %q has unknown type %q", name, option.Type)
 }
 
-var optionTypeCheckers = map[string]schema.Checker{
-	"string":  schema.String(),
-	"int":     schema.Int(),
-	"float":   schema.Float(),
-	"boolean": schema.Bool(),
-	"secret":  secretC{},
+// checkValue returns an error if the supplied value, already of the
+// option's type, is not one of the option's choices or is outside its
+// bounds. The empty string is always accepted, as it leaves the option
+// unset.
+func (option Option) checkValue(name string, value interface{}) error {
+	switch value := value.(type) {
+	case int64:
+		return option.checkBounds(name, float64(value), value)
+	case float64:
+		return option.checkBounds(name, value, value)
+	}
+	if len(option.Choices) == 0 {
+		return nil
+	}
+	str, _ := value.(string)
+	if str == "" {
+		return nil
+	}
+	for _, choice := range option.Choices {
+		if str == choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("option %q expected one of %s, got %q", name, quoteStrings(option.Choices), str)
 }
 
-func (option Option) parse(name, str string) (val interface{}, err error) {
+// checkBounds returns an error if the supplied number is outside the
+// option's bounds. The value is the number as it is reported in the
+// error.
+func (option Option) checkBounds(name string, number float64, value interface{}) error {
+	if option.Minimum != nil && number < *option.Minimum {
+		return fmt.Errorf("option %q expected a value of at least %v, got %v", name, *option.Minimum, value)
+	}
+	if option.Maximum != nil && number > *option.Maximum {
+		return fmt.Errorf("option %q expected a value of at most %v, got %v", name, *option.Maximum, value)
+	}
+	return nil
+}
+
+// checkBoundsDeclaration returns an error if the option declares
+// bounds that are not valid for it.
+func (option Option) checkBoundsDeclaration(name string) error {
+	if option.Minimum == nil && option.Maximum == nil {
+		return nil
+	}
+	if option.Type != "int" && option.Type != "float" {
+		return fmt.Errorf("option %q of type %s cannot declare a minimum or maximum", name, option.Type)
+	}
+	for _, bound := range []*float64{option.Minimum, option.Maximum} {
+		if bound != nil && option.Type == "int" && *bound != math.Trunc(*bound) {
+			return fmt.Errorf("option %q of type int declares non-integer bound %v", name, *bound)
+		}
+	}
+	if option.Minimum != nil && option.Maximum != nil && *option.Minimum > *option.Maximum {
+		return fmt.Errorf("option %q declares minimum %v greater than maximum %v", name, *option.Minimum, *option.Maximum)
+	}
+	return nil
+}
+
+// quoteStrings returns the given strings quoted and separated by commas.
+func quoteStrings(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// checkChoices returns an error if the option declares choices that
+// are not valid for it.
+func (option Option) checkChoices(name string) error {
+	if option.Choices == nil {
+		return nil
+	}
+	if option.Type != "string" {
+		return fmt.Errorf("option %q of type %s cannot declare choices", name, option.Type)
+	}
+	if len(option.Choices) == 0 {
+		return fmt.Errorf("option %q declares no choices", name)
+	}
+	seen := make(map[string]bool)
+	for _, choice := range option.Choices {
+		if choice == "" {
+			return fmt.Errorf("option %q declares an empty choice", name)
+		}
+		if seen[choice] {
+			return fmt.Errorf("option %q declares duplicate choice %q", name, choice)
+		}
+		seen[choice] = true
+	}
+	return nil
+}
+
+var optionTypeCheckers = sync.OnceValue(func() map[string]schema.Checker {
+	return map[string]schema.Checker{
+		"string":  schema.String(),
+		"int":     schema.Int(),
+		"float":   schema.Float(),
+		"boolean": schema.Bool(),
+		"secret":  secretC{},
+	}
+})
+
+// parse returns the value of the option parsed from the supplied string, or
+// an error if it cannot be parsed or is not one of the option's choices or
+// within its bounds.
+func (option Option) parse(name, str string) (interface{}, error) {
+	val, err := option.parseValue(name, str)
+	if err != nil {
+		return nil, err
+	}
+	if err := option.checkValue(name, val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func (option Option) parseValue(name, str string) (val interface{}, err error) {
 	switch option.Type {
 	case "string", "secret":
 		return str, nil
@@ -116,6 +276,23 @@ func NewConfig() *Config {
 	return &Config{map[string]Option{}}
 }
 
+// MarshalYAML implements yaml.Marshaler, producing the canonical
+// config.yaml form of the config: the options are sorted by name and
+// the options key is always present, so that the result can be read
+// back with ReadConfig.
+func (c *Config) MarshalYAML() (interface{}, error) {
+	names := make([]string, 0, len(c.Options))
+	for name := range c.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	options := make(yaml.MapSlice, len(names))
+	for i, name := range names {
+		options[i] = yaml.MapItem{Key: name, Value: c.Options[name]}
+	}
+	return yaml.MapSlice{{Key: "options", Value: options}}, nil
+}
+
 // ReadConfig reads a Config in YAML format.
 func ReadConfig(r io.Reader) (*Config, error) {
 	data, err := ioutil.ReadAll(r)
@@ -152,12 +329,20 @@ func ReadConfig(r io.Reader) (*Config, error) {
 		default:
 			return nil, fmt.Errorf("invalid config: option %q has unknown type %q", name, option.Type)
 		}
+		if err := option.checkChoices(name); err != nil {
+			return nil, fmt.Errorf("invalid config: %v", err)
+		}
+		if err := option.checkBoundsDeclaration(name); err != nil {
+			return nil, fmt.Errorf("invalid config: %v", err)
+		}
 		def := option.Default
 		if def == "" && (option.Type == "string" || option.Type == "secret") {
 			// Skip normal validation for compatibility with pyjuju.
-		} else if option.Default, err = option.validate(name, def); err != nil {
+		} else if option.Default, err = option.coerce(name, def); err != nil {
 			option.error(&err, name, def)
 			return nil, fmt.Errorf("invalid config default: %v", err)
+		} else if err = option.checkValue(name, option.Default); err != nil {
+			return nil, fmt.Errorf("invalid config default: %v", err)
 		}
 		config.Options[name] = option
 	}
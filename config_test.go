@@ -488,6 +488,44 @@ options:
 	c.Assert(newCfg, jc.DeepEquals, cfg)
 }
 
+func (s *ConfigSuite) TestYAMLMarshalCanonical(c *gc.C) {
+	cfg := charm.NewConfig()
+	cfg.Options["verbose"] = charm.Option{Type: "boolean", Description: "Log more.", Default: false}
+	cfg.Options["port"] = charm.Option{Type: "int", Default: int64(0)}
+	cfg.Options["name"] = charm.Option{Type: "string", Description: "The name.", Default: ""}
+	cfg.Options["ratio"] = charm.Option{Type: "float", Description: "The ratio."}
+
+	data, err := yaml.Marshal(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, `
+options:
+  name:
+    type: string
+    description: The name.
+    default: ""
+  port:
+    type: int
+    default: 0
+  ratio:
+    type: float
+    description: The ratio.
+  verbose:
+    type: boolean
+    description: Log more.
+    default: false
+`[1:])
+
+	newCfg, err := charm.ReadConfig(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(newCfg, jc.DeepEquals, cfg)
+
+	data, err = yaml.Marshal(charm.NewConfig())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "options: {}\n")
+	_, err = charm.ReadConfig(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
 func (s *ConfigSuite) TestErrorOnInvalidOptionTypes(c *gc.C) {
 	cfg := charm.Config{
 		Options: map[string]charm.Option{"testOption": {Type: "invalid type"}},
@@ -498,3 +536,123 @@ func (s *ConfigSuite) TestErrorOnInvalidOptionTypes(c *gc.C) {
 	_, err = cfg.ParseSettingsYAML([]byte("testKey:\n  testOption: \"some string value\""), "testKey")
 	c.Assert(err, gc.ErrorMatches, "option \"testOption\" has unknown type \"invalid type\"")
 }
+
+func (s *ConfigSuite) TestChoices(c *gc.C) {
+	cfg, err := charm.ReadConfig(strings.NewReader(`
+options:
+  flavour:
+    type: string
+    default: vanilla
+    choices: [vanilla, chocolate]
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.Options["flavour"].Choices, jc.DeepEquals, []string{"vanilla", "chocolate"})
+
+	settings, err := cfg.ValidateSettings(charm.Settings{"flavour": "chocolate"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, charm.Settings{"flavour": "chocolate"})
+	_, err = cfg.ValidateSettings(charm.Settings{"flavour": "mint"})
+	c.Assert(err, gc.ErrorMatches, `option "flavour" expected one of "vanilla", "chocolate", got "mint"`)
+	c.Assert(cfg.FilterSettings(charm.Settings{"flavour": "mint"}), gc.HasLen, 0)
+
+	// The empty string leaves the option unset.
+	settings, err = cfg.ParseSettingsStrings(map[string]string{"flavour": ""})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, charm.Settings{"flavour": ""})
+	_, err = cfg.ParseSettingsStrings(map[string]string{"flavour": "mint"})
+	c.Assert(err, gc.ErrorMatches, `option "flavour" expected one of "vanilla", "chocolate", got "mint"`)
+	_, err = cfg.ParseSettingsYAML([]byte("app:\n  flavour: mint\n"), "app")
+	c.Assert(err, gc.ErrorMatches, `option "flavour" expected one of "vanilla", "chocolate", got "mint"`)
+
+	data, err := yaml.Marshal(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	cfg1, err := charm.ReadConfig(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg1, jc.DeepEquals, cfg)
+}
+
+func (s *ConfigSuite) TestChoicesErrors(c *gc.C) {
+	for i, test := range []struct {
+		option string
+		err    string
+	}{{
+		option: `{type: int, choices: ["1", "2"]}`,
+		err:    `invalid config: option "t" of type int cannot declare choices`,
+	}, {
+		option: `{type: string, choices: []}`,
+		err:    `invalid config: option "t" declares no choices`,
+	}, {
+		option: `{type: string, choices: [a, ""]}`,
+		err:    `invalid config: option "t" declares an empty choice`,
+	}, {
+		option: `{type: string, choices: [a, b, a]}`,
+		err:    `invalid config: option "t" declares duplicate choice "a"`,
+	}, {
+		option: `{type: string, default: c, choices: [a, b]}`,
+		err:    `invalid config default: option "t" expected one of "a", "b", got "c"`,
+	}} {
+		c.Logf("test %d: %s", i, test.option)
+		_, err := charm.ReadConfig(strings.NewReader("options: {t: " + test.option + "}"))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *ConfigSuite) TestBounds(c *gc.C) {
+	cfg, err := charm.ReadConfig(strings.NewReader(`
+options:
+  workers:
+    type: int
+    default: 4
+    minimum: 1
+    maximum: 64
+  ratio:
+    type: float
+    minimum: 0.5
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(*cfg.Options["workers"].Minimum, gc.Equals, 1.0)
+	c.Assert(*cfg.Options["workers"].Maximum, gc.Equals, 64.0)
+	c.Assert(*cfg.Options["ratio"].Minimum, gc.Equals, 0.5)
+	c.Assert(cfg.Options["ratio"].Maximum, gc.IsNil)
+
+	settings, err := cfg.ValidateSettings(charm.Settings{"workers": 64, "ratio": 100.0})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(settings, jc.DeepEquals, charm.Settings{"workers": int64(64), "ratio": 100.0})
+	_, err = cfg.ValidateSettings(charm.Settings{"workers": 0})
+	c.Assert(err, gc.ErrorMatches, `option "workers" expected a value of at least 1, got 0`)
+	_, err = cfg.ValidateSettings(charm.Settings{"workers": 65})
+	c.Assert(err, gc.ErrorMatches, `option "workers" expected a value of at most 64, got 65`)
+	_, err = cfg.ParseSettingsStrings(map[string]string{"ratio": "0.25"})
+	c.Assert(err, gc.ErrorMatches, `option "ratio" expected a value of at least 0.5, got 0.25`)
+	_, err = cfg.ParseSettingsYAML([]byte("app:\n  workers: 100\n"), "app")
+	c.Assert(err, gc.ErrorMatches, `option "workers" expected a value of at most 64, got 100`)
+
+	data, err := yaml.Marshal(cfg)
+	c.Assert(err, jc.ErrorIsNil)
+	cfg1, err := charm.ReadConfig(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg1, jc.DeepEquals, cfg)
+}
+
+func (s *ConfigSuite) TestBoundsErrors(c *gc.C) {
+	for i, test := range []struct {
+		option string
+		err    string
+	}{{
+		option: `{type: string, minimum: 1}`,
+		err:    `invalid config: option "t" of type string cannot declare a minimum or maximum`,
+	}, {
+		option: `{type: int, maximum: 1.5}`,
+		err:    `invalid config: option "t" of type int declares non-integer bound 1.5`,
+	}, {
+		option: `{type: float, minimum: 2, maximum: 1}`,
+		err:    `invalid config: option "t" declares minimum 2 greater than maximum 1`,
+	}, {
+		option: `{type: int, default: 10, maximum: 5}`,
+		err:    `invalid config default: option "t" expected a value of at most 5, got 10`,
+	}} {
+		c.Logf("test %d: %s", i, test.option)
+		_, err := charm.ReadConfig(strings.NewReader("options: {t: " + test.option + "}"))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
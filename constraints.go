@@ -0,0 +1,100 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"sort"
+	"sync"
+)
+
+// Constraints is implemented by the parsed form of a constraints string,
+// as returned by a ConstraintsParser. This package does not know how to
+// interpret constraints itself; a parser, such as one built on juju's
+// core constraints package, may be registered with
+// RegisterConstraintsParser.
+type Constraints interface {
+	// String returns the constraints in their textual form.
+	String() string
+
+	// CheckMachine returns an error if a unit with these constraints
+	// cannot be placed on a machine created with the given machine
+	// constraints, for example because the machine has less memory
+	// than the unit requires.
+	CheckMachine(machine Constraints) error
+}
+
+// ConstraintsParser parses a constraints string.
+type ConstraintsParser func(s string) (Constraints, error)
+
+var (
+	constraintsParserMu sync.RWMutex
+	constraintsParser   ConstraintsParser
+)
+
+// RegisterConstraintsParser registers the parser used by
+// ParseConstraints. When a parser is registered, verifying a bundle
+// checks that the constraints of its applications and machines can be
+// parsed, and that the constraints of applications placed directly on
+// bundle machines are compatible with those of the machines. Passing nil
+// removes the registered parser.
+func RegisterConstraintsParser(parser ConstraintsParser) {
+	constraintsParserMu.Lock()
+	defer constraintsParserMu.Unlock()
+	constraintsParser = parser
+}
+
+// registeredConstraintsParser returns the registered constraints parser,
+// or nil if there is none.
+func registeredConstraintsParser() ConstraintsParser {
+	constraintsParserMu.RLock()
+	defer constraintsParserMu.RUnlock()
+	return constraintsParser
+}
+
+// ParseConstraints parses s, such as the constraints of a bundle
+// application or machine, with the parser registered with
+// RegisterConstraintsParser. It returns nil if s is empty or no parser
+// is registered.
+func ParseConstraints(s string) (Constraints, error) {
+	parse := registeredConstraintsParser()
+	if parse == nil || s == "" {
+		return nil, nil
+	}
+	return parse(s)
+}
+
+// verifyConstraintConflicts checks that the constraints of each
+// application placed directly on a bundle machine are compatible with
+// those of the machine. Containers are not checked, as they do not
+// inherit the constraints of their host machine.
+func (verifier *bundleDataVerifier) verifyConstraintConflicts() {
+	names := make([]string, 0, len(verifier.bd.Applications))
+	for name := range verifier.bd.Applications {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		app := verifier.bd.Applications[name]
+		appCons := verifier.appConstraints[name]
+		if app == nil || appCons == nil {
+			continue
+		}
+		checked := make(map[string]bool)
+		for _, p := range app.To {
+			up, err := ParsePlacement(p)
+			if err != nil || up.ContainerType != "" || up.Machine == "" || up.Machine == "new" || checked[up.Machine] {
+				continue
+			}
+			checked[up.Machine] = true
+			machineCons := verifier.machineConstraints[up.Machine]
+			if machineCons == nil {
+				continue
+			}
+			if err := appCons.CheckMachine(machineCons); err != nil {
+				verifier.addErrorf(CodeInvalidConstraints, "constraints %q in application %q conflict with constraints %q in machine %q: %v",
+					app.Constraints, name, verifier.bd.Machines[up.Machine].Constraints, up.Machine, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type ConstraintsSuite struct{}
+
+var _ = gc.Suite(&ConstraintsSuite{})
+
+func (s *ConstraintsSuite) TearDownTest(c *gc.C) {
+	charm.RegisterConstraintsParser(nil)
+}
+
+// memConstraints is a parsed "mem=<n>" constraint.
+type memConstraints int
+
+func (m memConstraints) String() string {
+	return fmt.Sprintf("mem=%d", int(m))
+}
+
+func (m memConstraints) CheckMachine(machine charm.Constraints) error {
+	if have := machine.(memConstraints); have < m {
+		return fmt.Errorf("machine has %d memory, need %d", have, m)
+	}
+	return nil
+}
+
+func parseMemConstraints(s string) (charm.Constraints, error) {
+	value, ok := strings.CutPrefix(s, "mem=")
+	if !ok {
+		return nil, fmt.Errorf("unknown constraint %q", s)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return memConstraints(n), nil
+}
+
+const constraintsBundle = `
+applications:
+  mysql:
+    charm: ch:mysql
+    num_units: 3
+    constraints: mem=%s
+    to: ["0", "lxd:1", "2"]
+machines:
+  "0":
+    constraints: mem=8
+  "1":
+    constraints: mem=2
+  "2":
+`
+
+func (s *ConstraintsSuite) TestVerifyWithoutParser(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(fmt.Sprintf(constraintsBundle, "16")))
+	c.Assert(err, jc.ErrorIsNil)
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ConstraintsSuite) TestParseConstraints(c *gc.C) {
+	cons, err := charm.ParseConstraints("mem=4")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cons, gc.IsNil)
+
+	charm.RegisterConstraintsParser(parseMemConstraints)
+	cons, err = charm.ParseConstraints("mem=4")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cons, gc.Equals, memConstraints(4))
+	cons, err = charm.ParseConstraints("")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cons, gc.IsNil)
+	_, err = charm.ParseConstraints("cores=2")
+	c.Assert(err, gc.ErrorMatches, `unknown constraint "cores=2"`)
+}
+
+func (s *ConstraintsSuite) TestVerifyDoesNotChangeBundle(c *gc.C) {
+	charm.RegisterConstraintsParser(parseMemConstraints)
+	data := fmt.Sprintf(constraintsBundle, "4")
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	want, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd, jc.DeepEquals, want)
+}
+
+func (s *ConstraintsSuite) TestVerifyConflictingConstraints(c *gc.C) {
+	charm.RegisterConstraintsParser(parseMemConstraints)
+	bd, err := charm.ReadBundleData(strings.NewReader(fmt.Sprintf(constraintsBundle, "16")))
+	c.Assert(err, jc.ErrorIsNil)
+	err = bd.Verify(nil, nil, nil)
+	// Machine 1 is not checked, as the unit is placed in a container.
+	c.Assert(err, gc.ErrorMatches, `constraints "mem=16" in application "mysql" conflict with constraints "mem=8" in machine "0": machine has 8 memory, need 16`)
+}
+
+func (s *ConstraintsSuite) TestVerifyInvalidConstraints(c *gc.C) {
+	charm.RegisterConstraintsParser(parseMemConstraints)
+	bd, err := charm.ReadBundleData(strings.NewReader(fmt.Sprintf(constraintsBundle, "lots")))
+	c.Assert(err, jc.ErrorIsNil)
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, gc.ErrorMatches, `invalid constraints "mem=lots" in application "mysql": .*`)
+}
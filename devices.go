@@ -0,0 +1,105 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+)
+
+// knownDeviceTypes holds the device types that charms commonly request.
+var knownDeviceTypes = set.NewStrings(
+	string(DeviceGPU),
+	string(DeviceNvidiaGPU),
+	string(DeviceAMDGPU),
+	string(DeviceTPU),
+)
+
+// IsKnownDeviceType reports whether t is one of the well-known device
+// types, such as "gpu" or "nvidia.com/gpu".
+func IsKnownDeviceType(t DeviceType) bool {
+	return knownDeviceTypes.Contains(string(t))
+}
+
+// ValidateDeviceType returns an error if t is neither a well-known
+// device type nor a vendor qualified type of the form
+// "<domain>/<name>", such as "example.com/fpga". Vendor qualified types
+// are accepted as is so that charms can request devices this package
+// does not know about.
+func ValidateDeviceType(t DeviceType) error {
+	if IsKnownDeviceType(t) || isVendorDeviceType(t) {
+		return nil
+	}
+	return errors.NewNotValid(nil, fmt.Sprintf(
+		"device type %q not valid, expected one of %s or a vendor qualified type such as \"example.com/device\"",
+		t, strings.Join(knownDeviceTypes.SortedValues(), ", ")))
+}
+
+// isVendorDeviceType reports whether t is of the form "<domain>/<name>"
+// where the domain contains a dot.
+func isVendorDeviceType(t DeviceType) bool {
+	domain, name, ok := strings.Cut(string(t), "/")
+	return ok && name != "" && !strings.Contains(name, "/") &&
+		strings.Contains(domain, ".") && !strings.HasPrefix(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+// DeviceConstraint describes the devices requested for one of an
+// application's device slots, as given in the devices section of a
+// bundle application.
+type DeviceConstraint struct {
+	// Count is the number of devices requested.
+	Count int64
+
+	// Type is the requested device type, such as "gpu" or
+	// "nvidia.com/gpu".
+	Type DeviceType
+
+	// Attributes holds any further attributes the devices must have.
+	Attributes map[string]string
+}
+
+// ParseDeviceConstraint parses a device constraint of the form
+//
+//	[<count>,]<type>[,<key>=<value>;...]
+//
+// for example "2,nvidia.com/gpu" or "gpu,gpu=nvidia-tesla-p100". The
+// count defaults to 1 when omitted.
+func ParseDeviceConstraint(s string) (DeviceConstraint, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) > 3 {
+		return DeviceConstraint{}, errors.NotValidf("device constraint %q with more than 3 fields", s)
+	}
+	cons := DeviceConstraint{Count: 1}
+	if count, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+		if count <= 0 {
+			return DeviceConstraint{}, errors.NotValidf("device count %d in %q", count, s)
+		}
+		cons.Count, fields = count, fields[1:]
+		if len(fields) == 0 {
+			return DeviceConstraint{}, errors.NotValidf("device constraint %q without a type", s)
+		}
+	} else if len(fields) == 3 {
+		return DeviceConstraint{}, errors.NotValidf("device count %q in %q", fields[0], s)
+	}
+	if fields[0] == "" {
+		return DeviceConstraint{}, errors.NotValidf("device constraint %q without a type", s)
+	}
+	cons.Type = DeviceType(fields[0])
+	if len(fields) == 1 {
+		return cons, nil
+	}
+	cons.Attributes = make(map[string]string)
+	for _, attr := range strings.Split(fields[1], ";") {
+		key, value, ok := strings.Cut(attr, "=")
+		if !ok || key == "" || value == "" {
+			return DeviceConstraint{}, errors.NotValidf("device attribute %q in %q", attr, s)
+		}
+		cons.Attributes[key] = value
+	}
+	return cons, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type DeviceConstraintSuite struct{}
+
+var _ = gc.Suite(&DeviceConstraintSuite{})
+
+var deviceConstraintTests = []struct {
+	s      string
+	expect charm.DeviceConstraint
+	err    string
+}{{
+	s:      "gpu",
+	expect: charm.DeviceConstraint{Count: 1, Type: "gpu"},
+}, {
+	s:      "2,nvidia.com/gpu",
+	expect: charm.DeviceConstraint{Count: 2, Type: "nvidia.com/gpu"},
+}, {
+	s: "3,nvidia.com/gpu,gpu=nvidia-tesla-p100;mem=16G",
+	expect: charm.DeviceConstraint{
+		Count:      3,
+		Type:       "nvidia.com/gpu",
+		Attributes: map[string]string{"gpu": "nvidia-tesla-p100", "mem": "16G"},
+	},
+}, {
+	s: "amd.com/gpu,gpu=radeon",
+	expect: charm.DeviceConstraint{
+		Count:      1,
+		Type:       "amd.com/gpu",
+		Attributes: map[string]string{"gpu": "radeon"},
+	},
+}, {
+	s:   "",
+	err: `device constraint "" without a type not valid`,
+}, {
+	s:   "2",
+	err: `device constraint "2" without a type not valid`,
+}, {
+	s:   "0,gpu",
+	err: `device count 0 in "0,gpu" not valid`,
+}, {
+	s:   "two,gpu,gpu=foo",
+	err: `device count "two" in "two,gpu,gpu=foo" not valid`,
+}, {
+	s:   "1,gpu,gpu",
+	err: `device attribute "gpu" in "1,gpu,gpu" not valid`,
+}, {
+	s:   "1,gpu,a=b,c=d",
+	err: `device constraint "1,gpu,a=b,c=d" with more than 3 fields not valid`,
+}}
+
+func (s *DeviceConstraintSuite) TestParseDeviceConstraint(c *gc.C) {
+	for i, t := range deviceConstraintTests {
+		c.Logf("test %d: %q", i, t.s)
+		cons, err := charm.ParseDeviceConstraint(t.s)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(cons, jc.DeepEquals, t.expect)
+	}
+}
+
+func (s *DeviceConstraintSuite) TestValidateDeviceType(c *gc.C) {
+	for _, t := range []charm.DeviceType{
+		charm.DeviceGPU, charm.DeviceNvidiaGPU, charm.DeviceAMDGPU, charm.DeviceTPU,
+		"example.com/fpga", "intel.com/qat",
+	} {
+		c.Check(charm.ValidateDeviceType(t), jc.ErrorIsNil, gc.Commentf("type %q", t))
+	}
+	c.Check(charm.IsKnownDeviceType("example.com/fpga"), jc.IsFalse)
+	for _, t := range []charm.DeviceType{
+		"fpga", "gpuu", "example/fpga", "example.com/", ".com/fpga", "example.com/a/b",
+	} {
+		err := charm.ValidateDeviceType(t)
+		c.Check(err, jc.Satisfies, errors.IsNotValid, gc.Commentf("type %q", t))
+		c.Check(err, gc.ErrorMatches, `device type ".*" not valid, expected one of amd.com/gpu, gpu, nvidia.com/gpu, tpu or a vendor qualified type such as "example.com/device"`)
+	}
+}
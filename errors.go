@@ -0,0 +1,105 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+// URLParseError is returned by ParseURL when a charm or bundle URL
+// cannot be parsed.
+type URLParseError struct {
+	// URL holds the URL that could not be parsed.
+	URL string
+
+	// Reason describes why the URL could not be parsed.
+	Reason string
+
+	cause error
+}
+
+// Error implements error.
+func (e *URLParseError) Error() string {
+	return e.Reason
+}
+
+// Unwrap returns the error that caused the URL to be rejected, so that
+// checks such as errors.Is(err, errors.NotValid) continue to work.
+func (e *URLParseError) Unwrap() error {
+	return e.cause
+}
+
+// PlacementError describes an invalid unit placement directive, either
+// because it cannot be parsed or because it refers to something that the
+// bundle does not define.
+type PlacementError struct {
+	// Placement holds the offending placement directive.
+	Placement string
+
+	// Reason describes the problem with the placement directive.
+	Reason string
+}
+
+// Error implements error.
+func (e *PlacementError) Error() string {
+	return e.Reason
+}
+
+// VerificationErrorCode classifies the problems found by bundle
+// verification. It implements error so that a code can be used as the
+// target of errors.Is:
+//
+//	if errors.Is(err, charm.CodeInvalidPlacement) {
+//		...
+//	}
+type VerificationErrorCode string
+
+const (
+	CodeInvalidBundle      VerificationErrorCode = "invalid-bundle"
+	CodeInvalidSaas        VerificationErrorCode = "invalid-saas"
+	CodeInvalidMachine     VerificationErrorCode = "invalid-machine"
+	CodeInvalidApplication VerificationErrorCode = "invalid-application"
+	CodeInvalidCharm       VerificationErrorCode = "invalid-charm"
+	CodeInvalidConstraints VerificationErrorCode = "invalid-constraints"
+	CodeInvalidStorage     VerificationErrorCode = "invalid-storage"
+	CodeInvalidDevices     VerificationErrorCode = "invalid-devices"
+	CodeInvalidOffer       VerificationErrorCode = "invalid-offer"
+	CodeInvalidResource    VerificationErrorCode = "invalid-resource"
+	CodeInvalidExpose      VerificationErrorCode = "invalid-expose"
+	CodeInvalidPlacement   VerificationErrorCode = "invalid-placement"
+	CodeInvalidRelation    VerificationErrorCode = "invalid-relation"
+	CodeInvalidBinding     VerificationErrorCode = "invalid-binding"
+	CodeInvalidOption      VerificationErrorCode = "invalid-option"
+	CodeOverlayOnlyField   VerificationErrorCode = "overlay-only-field"
+)
+
+// Error implements error.
+func (c VerificationErrorCode) Error() string {
+	return string(c)
+}
+
+// CodedError is an entry of a VerificationError. It associates the
+// problem found with the code classifying it and, when it was found in
+// a multi-document bundle file, with the document holding the problem.
+type CodedError struct {
+	Code     VerificationErrorCode
+	Err      error
+	Document *BundleDocument
+}
+
+// Error implements error.
+func (e *CodedError) Error() string {
+	if e.Document != nil {
+		return e.Document.String() + ": " + e.Err.Error()
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error, so that errors.As can be used to
+// retrieve typed errors such as *PlacementError.
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the code of e.
+func (e *CodedError) Is(target error) bool {
+	code, ok := target.(VerificationErrorCode)
+	return ok && code == e.Code
+}
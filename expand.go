@@ -0,0 +1,256 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/juju/errors"
+	ziputil "github.com/juju/utils/v3/zip"
+)
+
+// ExpandProgress reports the progress of expanding an archive.
+type ExpandProgress struct {
+	// Files holds the number of archive entries extracted so far,
+	// out of TotalFiles.
+	Files, TotalFiles int
+
+	// Bytes holds the number of bytes written to disk so far.
+	// TotalBytes holds the number of bytes the archive declares its
+	// entries to hold, which Bytes reaches once an archive whose
+	// entries match their declared sizes has been expanded.
+	Bytes, TotalBytes int64
+}
+
+// ExpandOption configures the expansion of a charm or bundle archive.
+type ExpandOption func(*expandOptions)
+
+type expandOptions struct {
+	progress func(ExpandProgress)
+	atomic   bool
+}
+
+// WithExpandProgress makes the expansion call progress after each entry
+// of the archive is extracted.
+func WithExpandProgress(progress func(ExpandProgress)) ExpandOption {
+	return func(opts *expandOptions) {
+		opts.progress = progress
+	}
+}
+
+// AtomicExpand makes the expansion extract the archive into a temporary
+// directory alongside the target directory, which is only renamed into
+// place, replacing any existing directory, once the expansion has
+// succeeded and its contents have been synced to disk. The target
+// directory is therefore never left holding a partially expanded
+// archive. If a previous atomic expansion was interrupted while it
+// replaced the target directory, the expansion first restores or
+// removes the directory it was replacing, and it removes the temporary
+// directories left by interrupted expansions. Concurrent atomic
+// expansions into the same directory are not supported.
+func AtomicExpand() ExpandOption {
+	return func(opts *expandOptions) {
+		opts.atomic = true
+	}
+}
+
+// expandArchive expands the archive read by zipr into dir, calling
+// finish to complete the expansion, such as by fixing file modes,
+// before the expansion is reported done.
+func expandArchive(
+	ctx context.Context,
+	zipr *zip.Reader,
+	dir string,
+	options []ExpandOption,
+	finish func(dir string) error,
+) error {
+	var opts expandOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	if !opts.atomic {
+		if err := extractAllContext(ctx, zipr, dir, opts.progress); err != nil {
+			return err
+		}
+		return finish(dir)
+	}
+
+	parent, base := filepath.Split(filepath.Clean(dir))
+	if parent == "" {
+		parent = "."
+	}
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	if err := recoverExpansion(parent, base); err != nil {
+		return errors.Annotate(err, "cannot recover interrupted expansion")
+	}
+	tmpDir, err := os.MkdirTemp(parent, tempDirPrefix(base))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	// MkdirTemp creates the directory with mode 0700; match the mode
+	// of a directory created by a plain expansion.
+	if err := os.Chmod(tmpDir, 0755); err != nil {
+		return errors.Trace(err)
+	}
+	if err := extractAllContext(ctx, zipr, tmpDir, opts.progress); err != nil {
+		return err
+	}
+	if err := finish(tmpDir); err != nil {
+		return err
+	}
+	if err := syncTree(tmpDir); err != nil {
+		return errors.Annotate(err, "cannot sync expanded archive")
+	}
+	return errors.Trace(replaceDir(tmpDir, filepath.Join(parent, base), backupDir(parent, base)))
+}
+
+// tempDirPrefix returns the prefix of the names of the temporary
+// directories into which archives are atomically expanded before being
+// renamed to base.
+func tempDirPrefix(base string) string {
+	return "." + base + ".tmp-"
+}
+
+// backupDir returns the path to which the directory base in parent is
+// moved while it is replaced by an atomic expansion.
+func backupDir(parent, base string) string {
+	return filepath.Join(parent, "."+base+".old")
+}
+
+// recoverExpansion cleans up after an atomic expansion into the
+// directory base in parent that was interrupted. If the expansion was
+// interrupted after the directory was moved to its backup, but before
+// the expanded archive replaced it, the backup is restored; if it was
+// interrupted after the expanded archive replaced the directory, the
+// backup is removed. Any temporary directories left behind are removed.
+func recoverExpansion(parent, base string) error {
+	dir, backup := filepath.Join(parent, base), backupDir(parent, base)
+	if _, err := os.Lstat(backup); err == nil {
+		if _, err := os.Lstat(dir); os.IsNotExist(err) {
+			if err := os.Rename(backup, dir); err != nil {
+				return err
+			}
+			if err := syncDir(parent); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else if err := os.RemoveAll(backup); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	stale, err := filepath.Glob(filepath.Join(parent, tempDirPrefix(base)+"*"))
+	if err != nil {
+		return err
+	}
+	for _, path := range stale {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceDir renames src to dst, replacing any existing dst, which is
+// first moved to backup so that it can be recovered should the process
+// be interrupted before src is in place. The parent directory is synced
+// so that the renames are durable.
+func replaceDir(src, dst, backup string) error {
+	parent := filepath.Dir(dst)
+	if _, err := os.Lstat(dst); os.IsNotExist(err) {
+		if err := os.Rename(src, dst); err != nil {
+			return err
+		}
+		return syncDir(parent)
+	} else if err != nil {
+		return err
+	}
+	if err := os.Rename(dst, backup); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		_ = os.Rename(backup, dst)
+		return err
+	}
+	if err := syncDir(parent); err != nil {
+		return err
+	}
+	return os.RemoveAll(backup)
+}
+
+// syncTree syncs the regular files and directories under root to disk.
+func syncTree(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		return syncPath(path)
+	})
+}
+
+// syncDir syncs the directory at path, so that changes to its entries,
+// such as renames, are durable.
+func syncDir(path string) error {
+	return syncPath(path)
+}
+
+// syncPath syncs the file or directory at path to disk. Windows does
+// not support syncing files opened read-only, nor directories, so it
+// does nothing there.
+func syncPath(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return f.Sync()
+}
+
+// extractAllContext is like ziputil.ExtractAll, except that it checks
+// ctx before extracting each file, and reports its progress to
+// progress, if it is not nil.
+func extractAllContext(ctx context.Context, reader *zip.Reader, targetRoot string, progress func(ExpandProgress)) error {
+	p := ExpandProgress{TotalFiles: len(reader.File)}
+	for _, file := range reader.File {
+		p.TotalBytes += int64(file.UncompressedSize64)
+	}
+	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return errors.Trace(err)
+		}
+		single := &zip.Reader{File: []*zip.File{file}}
+		if err := ziputil.ExtractAll(single, targetRoot); err != nil {
+			return err
+		}
+		p.Files++
+		// Count the bytes that were written, rather than trusting
+		// the size declared by the archive.
+		info, err := os.Lstat(filepath.Join(targetRoot, file.Name))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if info.Mode().IsRegular() || info.Mode()&os.ModeSymlink != 0 {
+			p.Bytes += info.Size()
+		}
+		if progress != nil {
+			progress(p)
+		}
+	}
+	return nil
+}
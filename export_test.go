@@ -10,10 +10,13 @@ var (
 	ValidateValue = validateValue
 
 	ParsePayloadClass         = parsePayloadClass
-	ResourceSchema            = resourceSchema
-	ExtraBindingsSchema       = extraBindingsSchema
+	ResourceSchema            = resourceSchema()
+	ExtraBindingsSchema       = extraBindingsSchema()
 	ValidateMetaExtraBindings = validateMetaExtraBindings
 	ParseResourceMeta         = parseResourceMeta
+	ParseRelations            = parseRelations
+	ParseStorage              = parseStorage
+	ParseDevices              = parseDevices
 
 	UsesGit = usesGit
 )
@@ -21,3 +24,11 @@ var (
 func MissingSeriesError() error {
 	return errMissingSeries
 }
+
+func UnregisterImplicitRelation(name string) {
+	implicitRelationsMu.Lock()
+	defer implicitRelationsMu.Unlock()
+	delete(implicitRelations, name)
+}
+
+var BundleSchemaPaths = bundleSchemaPaths
@@ -6,6 +6,7 @@ package charm
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/juju/collections/set"
 	"github.com/juju/schema"
@@ -14,29 +15,79 @@ import (
 // ExtraBinding represents an extra bindable endpoint that is not a relation.
 type ExtraBinding struct {
 	Name string `bson:"name" json:"Name"`
+
+	// Description optionally documents what the endpoint is used for.
+	Description string `bson:"description,omitempty" json:"Description,omitempty"`
 }
 
 // When specified, the "extra-bindings" section in the metadata.yaml
 // should have the following format:
 //
-// extra-bindings:
-//     "<endpoint-name>":
-//     ...
+//	extra-bindings:
+//	    "<endpoint-name>":
+//	    "<endpoint-name>": "<description>"
+//	    "<endpoint-name>":
+//	        description: "<description>"
+//	    ...
+//
 // Endpoint names are strings and must not match existing relation names from
-// the Provides, Requires, or Peers metadata sections. The values beside each
-// endpoint name must be left out (i.e. "foo": <anything> is invalid).
-var extraBindingsSchema = schema.Map(schema.NonEmptyString("binding name"), schema.Nil(""))
+// the Provides, Requires, or Peers metadata sections. The value beside each
+// endpoint name is optional, and may be either a description or a map
+// holding one.
+var extraBindingsSchema = sync.OnceValue(func() schema.Checker {
+	return schema.Map(schema.NonEmptyString("binding name"), extraBindingValueC{})
+})
+
+// extraBindingValueC checks the value beside an extra binding name,
+// which may be empty, a description, or a map holding a description.
+type extraBindingValueC struct{}
+
+var extraBindingFieldsSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{"description": schema.String()},
+		schema.Defaults{"description": schema.Omit},
+	)
+})
+
+func (c extraBindingValueC) Coerce(v interface{}, path []string) (interface{}, error) {
+	switch v.(type) {
+	case nil, string:
+		return v, nil
+	case map[interface{}]interface{}, map[string]interface{}:
+		return extraBindingFieldsSchema().Coerce(v, path)
+	}
+	prefix := strings.TrimPrefix(strings.Join(path, ""), ".")
+	if prefix != "" {
+		prefix += ": "
+	}
+	return nil, fmt.Errorf("%sexpected empty value, description or map, got %T(%#v)", prefix, v, v)
+}
 
 func parseMetaExtraBindings(data interface{}) (map[string]ExtraBinding, error) {
 	if data == nil {
 		return nil, nil
 	}
 
-	bindingsMap := data.(map[interface{}]interface{})
+	bindingsMap, ok := data.(map[interface{}]interface{})
+	if !ok {
+		return nil, unexpectedType("extra-bindings", "map", data)
+	}
 	result := make(map[string]ExtraBinding)
-	for name, _ := range bindingsMap {
-		stringName := name.(string)
-		result[stringName] = ExtraBinding{Name: stringName}
+	for name, value := range bindingsMap {
+		stringName, ok := name.(string)
+		if !ok {
+			return nil, unexpectedType("extra-bindings", "string key", name)
+		}
+		binding := ExtraBinding{Name: stringName}
+		switch value := value.(type) {
+		case string:
+			binding.Description = value
+		case map[string]interface{}:
+			if desc, ok := value["description"].(string); ok {
+				binding.Description = desc
+			}
+		}
+		result[stringName] = binding
 	}
 
 	return result, nil
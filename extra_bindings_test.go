@@ -36,6 +36,22 @@ func (s *extraBindingsSuite) TestSchemaOkay(c *gc.C) {
 	})
 }
 
+func (s *extraBindingsSuite) TestSchemaDescriptions(c *gc.C) {
+	raw := map[interface{}]interface{}{
+		"foo": "a description",
+		"bar": map[interface{}]interface{}{"description": "another"},
+		"baz": map[interface{}]interface{}{},
+	}
+	v, err := charm.ExtraBindingsSchema.Coerce(raw, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(v, jc.DeepEquals, map[interface{}]interface{}{
+		"foo": "a description",
+		"bar": map[string]interface{}{"description": "another"},
+		"baz": map[string]interface{}{},
+	})
+}
+
 func (s *extraBindingsSuite) TestValidateWithEmptyNonNilMap(c *gc.C) {
 	s.riakMeta.ExtraBindings = map[string]charm.ExtraBinding{}
 	err := charm.ValidateMetaExtraBindings(s.riakMeta)
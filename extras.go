@@ -0,0 +1,156 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// Extras holds the optional artifacts found alongside a charm's
+// metadata. Artifacts the charm does not provide are left as their
+// zero value.
+type Extras struct {
+	// LXDProfile holds the contents of lxd-profile.yaml. It is nil if
+	// the charm has no profile or the profile is empty.
+	LXDProfile *LXDProfile
+
+	// Manifest holds the contents of manifest.yaml, if any.
+	Manifest *Manifest
+
+	// Version holds the version string of the charm, if any.
+	Version string
+
+	// Metering holds the metering requirements declared in
+	// metrics.yaml.
+	Metering MeteringInfo
+}
+
+// ExtrasProvider is implemented by charms that can report all of their
+// optional artifacts at once. Both CharmDir and CharmArchive implement
+// it.
+type ExtrasProvider interface {
+	Extras() Extras
+}
+
+// Extras returns the optional artifacts of the charm.
+func (c *charmBase) Extras() Extras {
+	extras := Extras{
+		Manifest: c.manifest,
+		Version:  c.version,
+		Metering: c.MeteringInfo(),
+	}
+	if c.lxdProfile != nil && !c.lxdProfile.Empty() {
+		extras.LXDProfile = c.lxdProfile
+	}
+	return extras
+}
+
+// CharmExtras returns the optional artifacts of ch. If ch does not
+// implement ExtrasProvider, the artifacts are gathered from its
+// Manifest and Metrics methods and, where implemented, its LXDProfile
+// and Version methods.
+func CharmExtras(ch Charm) Extras {
+	if p, ok := ch.(ExtrasProvider); ok {
+		return p.Extras()
+	}
+	extras := Extras{
+		Manifest: ch.Manifest(),
+		Metering: ch.Metrics().MeteringInfo(),
+	}
+	if p, ok := ch.(LXDProfiler); ok {
+		if profile := p.LXDProfile(); profile != nil && !profile.Empty() {
+			extras.LXDProfile = profile
+		}
+	}
+	extras.Version = VersionOf(ch)
+	return extras
+}
+
+// VersionedCharm is implemented by charms that report the version
+// string recorded when they were built. Both CharmDir and CharmArchive
+// implement it. Manifests need no such interface, as every Charm has a
+// Manifest method.
+type VersionedCharm interface {
+	Version() string
+}
+
+// VersionOf returns the version string of ch, or the empty string if
+// ch does not implement VersionedCharm.
+func VersionOf(ch Charm) string {
+	if v, ok := ch.(VersionedCharm); ok {
+		return v.Version()
+	}
+	return ""
+}
+
+// HookedCharm is implemented by charms that can list the hooks they
+// implement. Both CharmDir and CharmArchive implement it.
+type HookedCharm interface {
+	// LifecycleHooks returns the sorted names of the files in the
+	// charm's hooks directory that implement a hook declared by its
+	// metadata, such as "install" or "db-relation-joined".
+	LifecycleHooks() ([]string, error)
+}
+
+// LifecycleHooksOf returns the hooks implemented by ch. It returns an
+// error satisfying errors.NotSupported if ch does not implement
+// HookedCharm.
+func LifecycleHooksOf(ch Charm) ([]string, error) {
+	h, ok := ch.(HookedCharm)
+	if !ok {
+		return nil, errors.NotSupportedf("listing hooks of %T", ch)
+	}
+	return h.LifecycleHooks()
+}
+
+// LifecycleHooks implements HookedCharm.
+func (dir *CharmDir) LifecycleHooks() ([]string, error) {
+	entries, err := os.ReadDir(dir.join("hooks"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return declaredHooks(dir.meta, names), nil
+}
+
+// LifecycleHooks implements HookedCharm.
+func (a *CharmArchive) LifecycleHooks() ([]string, error) {
+	members, err := a.ArchiveMembers()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var names []string
+	for _, member := range members.Values() {
+		if name, ok := strings.CutPrefix(member, "hooks/"); ok && name != "" && !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return declaredHooks(a.meta, names), nil
+}
+
+// declaredHooks returns the sorted names that are hooks declared by
+// meta.
+func declaredHooks(meta *Meta, names []string) []string {
+	declared := meta.Hooks()
+	var hooks []string
+	for _, name := range names {
+		if declared[name] {
+			hooks = append(hooks, name)
+		}
+	}
+	sort.Strings(hooks)
+	return hooks
+}
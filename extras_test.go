@@ -0,0 +1,94 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type ExtrasSuite struct{}
+
+var _ = gc.Suite(&ExtrasSuite{})
+
+func (s *ExtrasSuite) TestCharmDirExtras(c *gc.C) {
+	dir := readCharmDir(c, "dummy")
+	extras := charm.CharmExtras(dir)
+	c.Assert(extras.LXDProfile, gc.NotNil)
+	c.Assert(extras.LXDProfile, jc.DeepEquals, dir.LXDProfile())
+	c.Assert(extras.Manifest, gc.NotNil)
+	c.Assert(extras.Manifest.Bases, gc.HasLen, 2)
+	c.Assert(extras.Metering.Metered, jc.IsFalse)
+}
+
+func (s *ExtrasSuite) TestCharmArchiveExtras(c *gc.C) {
+	path := archivePath(c, readCharmDir(c, "metered"))
+	archive, err := charm.ReadCharmArchive(path)
+	c.Assert(err, jc.ErrorIsNil)
+	extras := charm.CharmExtras(archive)
+	c.Assert(extras.LXDProfile, gc.IsNil)
+	c.Assert(extras.Manifest, gc.NotNil)
+	c.Assert(extras.Metering.Metered, jc.IsTrue)
+}
+
+func (s *ExtrasSuite) TestCharmDirExtrasVersion(c *gc.C) {
+	dir := readCharmDir(c, "versioned")
+	extras := charm.CharmExtras(dir)
+	c.Assert(extras.Version, gc.Equals, dir.Version())
+	c.Assert(extras.Version, gc.Not(gc.Equals), "")
+	c.Assert(extras.Manifest, gc.IsNil)
+	c.Assert(extras.LXDProfile, gc.IsNil)
+}
+
+func (s *ExtrasSuite) TestCharmExtrasFallback(c *gc.C) {
+	dir := readCharmDir(c, "metered")
+	// Embedding the interface hides the methods beyond those of Charm.
+	ch := struct{ charm.Charm }{dir}
+	extras := charm.CharmExtras(ch)
+	c.Assert(extras, jc.DeepEquals, charm.Extras{
+		Manifest: dir.Manifest(),
+		Metering: dir.MeteringInfo(),
+	})
+}
+
+func (s *ExtrasSuite) TestVersionOf(c *gc.C) {
+	dir := readCharmDir(c, "versioned")
+	c.Assert(charm.VersionOf(dir), gc.Equals, dir.Version())
+	c.Assert(charm.VersionOf(struct{ charm.Charm }{dir}), gc.Equals, "")
+}
+
+var allHooks = []string{
+	"bar-relation-broken", "bar-relation-changed", "bar-relation-departed", "bar-relation-joined",
+	"collect-metrics", "config-changed",
+	"foo-relation-broken", "foo-relation-changed", "foo-relation-departed", "foo-relation-joined",
+	"install", "meter-status-changed",
+	"self-relation-broken", "self-relation-changed", "self-relation-departed", "self-relation-joined",
+	"start", "stop", "upgrade-charm",
+}
+
+func (s *ExtrasSuite) TestLifecycleHooks(c *gc.C) {
+	dir := readCharmDir(c, "all-hooks")
+	hooks, err := charm.LifecycleHooksOf(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	// The otherdata file and the subdir directory are not hooks.
+	c.Assert(hooks, jc.DeepEquals, allHooks)
+
+	archive, err := charm.ReadCharmArchive(archivePath(c, dir))
+	c.Assert(err, jc.ErrorIsNil)
+	hooks, err = charm.LifecycleHooksOf(archive)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hooks, jc.DeepEquals, allHooks)
+
+	hooks, err = charm.LifecycleHooksOf(readCharmDir(c, "wordpress"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hooks, gc.HasLen, 0)
+}
+
+func (s *ExtrasSuite) TestLifecycleHooksNotSupported(c *gc.C) {
+	_, err := charm.LifecycleHooksOf(struct{ charm.Charm }{readCharmDir(c, "dummy")})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
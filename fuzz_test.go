@@ -0,0 +1,67 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	stdtesting "testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/charm/v12"
+)
+
+// addSeedFiles adds the content of the files matching pattern to the
+// seed corpus of f.
+func addSeedFiles(f *stdtesting.F, pattern string) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+}
+
+func FuzzReadMeta(f *stdtesting.F) {
+	addSeedFiles(f, "internal/test-charm-repo/quantal/*/metadata.yaml")
+	f.Add([]byte("name: a\nsummary: b\ndescription: c\nprovides:\n  x: [1]\n"))
+	f.Add([]byte("name: a\nsummary: b\ndescription: c\nextra-bindings:\n  1: {}\n"))
+	f.Fuzz(func(t *stdtesting.T, data []byte) {
+		meta, err := charm.ReadMeta(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		// Anything that parses must also check and marshal
+		// without panicking, and read back once marshalled.
+		_ = meta.Check(charm.FormatV1)
+		_ = meta.Check(charm.FormatV2)
+		out, err := yaml.Marshal(meta)
+		if err != nil {
+			t.Fatalf("cannot marshal metadata: %v", err)
+		}
+		if _, err := charm.ReadMeta(bytes.NewReader(out)); err != nil {
+			t.Fatalf("cannot read marshalled metadata: %v\n%s", err, out)
+		}
+	})
+}
+
+func FuzzReadBundleData(f *stdtesting.F) {
+	addSeedFiles(f, "internal/test-charm-repo/bundle/*/bundle.yaml")
+	f.Add([]byte("applications:\n  a:\n    charm: ch:a\n    to: [lxd:0]\nrelations:\n- [a, b]\n- {provider: a, requirer: b}\n"))
+	f.Fuzz(func(t *stdtesting.T, data []byte) {
+		bd, err := charm.ReadBundleData(bytes.NewReader(data))
+		if err != nil || bd == nil {
+			return
+		}
+		_ = bd.Verify(nil, nil, nil)
+		_ = bd.Normalize()
+	})
+}
@@ -17,9 +17,11 @@ require (
 	github.com/juju/utils/v3 v3.1.0
 	github.com/juju/version/v2 v2.0.1
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826
+	golang.org/x/sys v0.5.0
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c
 	gopkg.in/gobwas/glob.v0 v0.2.3
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -33,5 +35,4 @@ require (
 	github.com/stretchr/testify v1.6.1 // indirect
 	golang.org/x/crypto v0.3.0 // indirect
 	golang.org/x/net v0.7.0 // indirect
-	golang.org/x/sys v0.5.0 // indirect
 )
@@ -0,0 +1,96 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/juju/errors"
+)
+
+// Hash returns the hex-encoded SHA-256 hash of the canonical form of
+// the metadata. Metadata that is equal, however it was formatted in
+// metadata.yaml, has the same hash, so that changes between charm
+// revisions can be detected by comparing hashes.
+func (m *Meta) Hash() (string, error) {
+	return contentHash(m)
+}
+
+// Hash returns the hex-encoded SHA-256 hash of the canonical form of
+// the config. Config that is equal, however it was formatted in
+// config.yaml, has the same hash, so that changes affecting the
+// settings of an application can be detected by comparing hashes.
+func (c *Config) Hash() (string, error) {
+	return contentHash(c)
+}
+
+// Hash returns the hex-encoded SHA-256 hash of the canonical form of
+// the bundle data, as it would be marshalled. Bundles that are equal,
+// however they were formatted, have the same hash; applications
+// declared under the legacy services key hash as if declared under
+// applications.
+func (bd *BundleData) Hash() (string, error) {
+	return contentHash(bd)
+}
+
+// contentHash returns the hex-encoded SHA-256 hash of the canonical
+// JSON form of v, in which object keys are sorted and fields holding
+// null, empty objects or empty arrays are omitted, so that nil and
+// empty values hash alike.
+func contentHash(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.Annotate(err, "cannot marshal content to hash")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return "", errors.Trace(err)
+	}
+	if data, err = json.Marshal(pruneEmpty(doc)); err != nil {
+		return "", errors.Trace(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// pruneEmpty returns v with the fields of its objects that hold null,
+// empty objects or empty arrays removed, recursively. Array elements
+// are retained, as their positions are significant.
+func pruneEmpty(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			value = pruneEmpty(value)
+			if isEmptyJSON(value) {
+				delete(v, key)
+			} else {
+				v[key] = value
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = pruneEmpty(value)
+		}
+	}
+	return v
+}
+
+// isEmptyJSON reports whether v is a decoded JSON null, empty object
+// or empty array.
+func isEmptyJSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
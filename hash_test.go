@@ -0,0 +1,142 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type HashSuite struct{}
+
+var _ = gc.Suite(&HashSuite{})
+
+func (s *HashSuite) metaHash(c *gc.C, data string) string {
+	meta, err := charm.ReadMeta(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	hash, err := meta.Hash()
+	c.Assert(err, jc.ErrorIsNil)
+	return hash
+}
+
+func (s *HashSuite) TestMetaHash(c *gc.C) {
+	hash := s.metaHash(c, `
+name: mysql
+summary: "Database"
+description: A database.
+provides:
+  db: {interface: mysql}
+  info: {interface: juju-info}
+tags: [database]
+`)
+	c.Assert(hash, gc.Matches, "[0-9a-f]{64}")
+
+	// Reordered and reformatted metadata hashes alike.
+	c.Check(s.metaHash(c, `
+tags:
+  - database
+provides:
+  info:
+    interface: juju-info
+  db:
+    interface: mysql
+description: 'A database.'
+summary: Database
+name: mysql
+`), gc.Equals, hash)
+
+	c.Check(s.metaHash(c, `
+name: mysql
+summary: Database
+description: A database.
+provides:
+  db: {interface: pgsql}
+  info: {interface: juju-info}
+tags: [database]
+`), gc.Not(gc.Equals), hash)
+}
+
+func (s *HashSuite) TestMetaHashEmptyValues(c *gc.C) {
+	meta := &charm.Meta{Name: "mysql", Summary: "s", Description: "d"}
+	hash, err := meta.Hash()
+	c.Assert(err, jc.ErrorIsNil)
+
+	meta.Provides = map[string]charm.Relation{}
+	meta.Tags = []string{}
+	other, err := meta.Hash()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(other, gc.Equals, hash)
+}
+
+func (s *HashSuite) configHash(c *gc.C, data string) string {
+	config, err := charm.ReadConfig(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	hash, err := config.Hash()
+	c.Assert(err, jc.ErrorIsNil)
+	return hash
+}
+
+func (s *HashSuite) TestConfigHash(c *gc.C) {
+	hash := s.configHash(c, `
+options:
+  title: {type: string, default: My Title, description: The title.}
+  port: {type: int, default: 8080}
+`)
+	c.Check(s.configHash(c, `
+options:
+  port:
+    default: 8080
+    type: int
+  title:
+    description: "The title."
+    default: "My Title"
+    type: string
+`), gc.Equals, hash)
+	c.Check(s.configHash(c, `
+options:
+  title: {type: string, default: My Title, description: The title.}
+  port: {type: int, default: 8081}
+`), gc.Not(gc.Equals), hash)
+}
+
+func (s *HashSuite) bundleHash(c *gc.C, data string) string {
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	hash, err := bd.Hash()
+	c.Assert(err, jc.ErrorIsNil)
+	return hash
+}
+
+func (s *HashSuite) TestBundleDataHash(c *gc.C) {
+	hash := s.bundleHash(c, `
+applications:
+  wordpress: {charm: wordpress, num_units: 2}
+  mysql: {charm: mysql, num_units: 1}
+relations:
+  - [wordpress:db, mysql:db]
+`)
+	c.Check(s.bundleHash(c, `
+services:
+  mysql:
+    num_units: 1
+    charm: mysql
+  wordpress:
+    num_units: 2
+    charm: wordpress
+relations:
+- - wordpress:db
+  - mysql:db
+`), gc.Equals, hash)
+	c.Check(s.bundleHash(c, `
+applications:
+  wordpress: {charm: wordpress, num_units: 3}
+  mysql: {charm: mysql, num_units: 1}
+relations:
+  - [wordpress:db, mysql:db]
+`), gc.Not(gc.Equals), hash)
+}
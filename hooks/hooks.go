@@ -60,9 +60,11 @@ const (
 	// kinds represent will be prefixed by the workload/container name; for example,
 	// "mycontainer-pebble-ready".
 
-	PebbleChangeUpdated Kind = "pebble-change-updated"
-	PebbleCustomNotice  Kind = "pebble-custom-notice"
-	PebbleReady         Kind = "pebble-ready"
+	PebbleChangeUpdated  Kind = "pebble-change-updated"
+	PebbleCheckFailed    Kind = "pebble-check-failed"
+	PebbleCheckRecovered Kind = "pebble-check-recovered"
+	PebbleCustomNotice   Kind = "pebble-custom-notice"
+	PebbleReady          Kind = "pebble-ready"
 )
 
 var unitHooks = []Kind{
@@ -118,6 +120,8 @@ func StorageHooks() []Kind {
 
 var workloadHooks = []Kind{
 	PebbleChangeUpdated,
+	PebbleCheckFailed,
+	PebbleCheckRecovered,
 	PebbleCustomNotice,
 	PebbleReady,
 }
@@ -150,7 +154,7 @@ func (kind Kind) IsStorage() bool {
 // IsWorkload returns whether the Kind represents a workload hook.
 func (kind Kind) IsWorkload() bool {
 	switch kind {
-	case PebbleChangeUpdated, PebbleCustomNotice, PebbleReady:
+	case PebbleChangeUpdated, PebbleCheckFailed, PebbleCheckRecovered, PebbleCustomNotice, PebbleReady:
 		return true
 	}
 	return false
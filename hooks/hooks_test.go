@@ -47,3 +47,69 @@ func (s *HooksSuite) TestIsSecret(c *gc.C) {
 		c.Assert(h.IsSecret(), jc.IsFalse)
 	}
 }
+
+func (s *HooksSuite) TestMetadata(c *gc.C) {
+	for _, test := range []struct {
+		kinds    []Kind
+		category Category
+		prefixed bool
+	}{
+		{unitHooks, CategoryUnit, false},
+		{relationHooks, CategoryRelation, true},
+		{storageHooks, CategoryStorage, true},
+		{workloadHooks, CategoryWorkload, true},
+		{secretHooks, CategorySecret, false},
+		{[]Kind{Action}, CategoryAction, false},
+	} {
+		for _, kind := range test.kinds {
+			md, ok := Metadata(kind)
+			c.Assert(ok, jc.IsTrue, gc.Commentf("kind %q", kind))
+			c.Check(md.Category, gc.Equals, test.category, gc.Commentf("kind %q", kind))
+			c.Check(md.Prefixed, gc.Equals, test.prefixed, gc.Commentf("kind %q", kind))
+		}
+	}
+
+	md, ok := Metadata(RelationDeparted)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(md.Environment, jc.DeepEquals, []string{
+		"JUJU_RELATION", "JUJU_RELATION_ID", "JUJU_REMOTE_APP", "JUJU_REMOTE_UNIT", "JUJU_DEPARTING_UNIT",
+	})
+	// The returned environment may be modified by the caller.
+	md.Environment[0] = "MODIFIED"
+	md, _ = Metadata(RelationDeparted)
+	c.Assert(md.Environment[0], gc.Equals, "JUJU_RELATION")
+
+	_, ok = Metadata("not-a-hook")
+	c.Assert(ok, jc.IsFalse)
+}
+
+func (s *HooksSuite) TestParseHookName(c *gc.C) {
+	for i, test := range []struct {
+		name   string
+		prefix string
+		kind   Kind
+		err    string
+	}{
+		{name: "install", kind: Install},
+		{name: "secret-rotate", kind: SecretRotate},
+		{name: "db-relation-joined", prefix: "db", kind: RelationJoined},
+		{name: "my-db-relation-broken", prefix: "my-db", kind: RelationBroken},
+		{name: "shared-fs-storage-attached", prefix: "shared-fs", kind: StorageAttached},
+		{name: "mycontainer-pebble-ready", prefix: "mycontainer", kind: PebbleReady},
+		{name: "mycontainer-pebble-check-failed", prefix: "mycontainer", kind: PebbleCheckFailed},
+		{name: "backup-action", prefix: "backup", kind: Action},
+		{name: "relation-joined", err: `hook name "relation-joined" not valid`},
+		{name: "action", err: `hook name "action" not valid`},
+		{name: "backup", err: `hook name "backup" not valid`},
+	} {
+		c.Logf("test %d: %s", i, test.name)
+		prefix, kind, err := ParseHookName(test.name)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(prefix, gc.Equals, test.prefix)
+		c.Check(kind, gc.Equals, test.kind)
+	}
+}
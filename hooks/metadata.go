@@ -0,0 +1,127 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package hooks
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// Category classifies hook kinds by the entity whose change triggers
+// them.
+type Category string
+
+const (
+	// CategoryUnit hooks are about the unit as a whole.
+	CategoryUnit Category = "unit"
+
+	// CategoryRelation hooks are about a relation of the unit.
+	CategoryRelation Category = "relation"
+
+	// CategoryStorage hooks are about a storage instance attached to
+	// the unit.
+	CategoryStorage Category = "storage"
+
+	// CategoryWorkload hooks are about a workload container of the unit.
+	CategoryWorkload Category = "workload"
+
+	// CategorySecret hooks are about a secret owned or consumed by the
+	// unit.
+	CategorySecret Category = "secret"
+
+	// CategoryAction is the category of the Action kind, which
+	// represents the running of an action.
+	CategoryAction Category = "action"
+)
+
+// KindMetadata describes the context in which hooks of a kind run.
+type KindMetadata struct {
+	// Category holds the category of the kind.
+	Category Category
+
+	// Prefixed reports whether the names of hooks of the kind are
+	// prefixed by the name of a relation, storage or container, as
+	// in "db-relation-joined".
+	Prefixed bool
+
+	// Environment holds the environment variables, beyond those set
+	// for every hook, that identify the context of the hook, such as
+	// JUJU_RELATION_ID for relation hooks.
+	Environment []string
+}
+
+var (
+	relationEnvironment = []string{"JUJU_RELATION", "JUJU_RELATION_ID", "JUJU_REMOTE_APP"}
+	remoteEnvironment   = []string{"JUJU_RELATION", "JUJU_RELATION_ID", "JUJU_REMOTE_APP", "JUJU_REMOTE_UNIT"}
+	departEnvironment   = []string{"JUJU_RELATION", "JUJU_RELATION_ID", "JUJU_REMOTE_APP", "JUJU_REMOTE_UNIT", "JUJU_DEPARTING_UNIT"}
+	storageEnvironment  = []string{"JUJU_STORAGE_ID", "JUJU_STORAGE_KIND", "JUJU_STORAGE_LOCATION"}
+	workloadEnvironment = []string{"JUJU_WORKLOAD_NAME"}
+	noticeEnvironment   = []string{"JUJU_WORKLOAD_NAME", "JUJU_NOTICE_ID", "JUJU_NOTICE_TYPE", "JUJU_NOTICE_KEY"}
+	checkEnvironment    = []string{"JUJU_WORKLOAD_NAME", "JUJU_PEBBLE_CHECK_NAME"}
+	secretEnvironment   = []string{"JUJU_SECRET_ID", "JUJU_SECRET_LABEL"}
+	revisionEnvironment = []string{"JUJU_SECRET_ID", "JUJU_SECRET_LABEL", "JUJU_SECRET_REVISION"}
+)
+
+var kindMetadata = map[Kind]KindMetadata{
+	Action: {Category: CategoryAction, Environment: []string{"JUJU_ACTION_NAME", "JUJU_ACTION_UUID"}},
+
+	RelationCreated:  {Category: CategoryRelation, Prefixed: true, Environment: relationEnvironment},
+	RelationJoined:   {Category: CategoryRelation, Prefixed: true, Environment: remoteEnvironment},
+	RelationChanged:  {Category: CategoryRelation, Prefixed: true, Environment: remoteEnvironment},
+	RelationDeparted: {Category: CategoryRelation, Prefixed: true, Environment: departEnvironment},
+	RelationBroken:   {Category: CategoryRelation, Prefixed: true, Environment: relationEnvironment},
+
+	StorageAttached:  {Category: CategoryStorage, Prefixed: true, Environment: storageEnvironment},
+	StorageDetaching: {Category: CategoryStorage, Prefixed: true, Environment: storageEnvironment},
+
+	PebbleChangeUpdated:  {Category: CategoryWorkload, Prefixed: true, Environment: noticeEnvironment},
+	PebbleCheckFailed:    {Category: CategoryWorkload, Prefixed: true, Environment: checkEnvironment},
+	PebbleCheckRecovered: {Category: CategoryWorkload, Prefixed: true, Environment: checkEnvironment},
+	PebbleCustomNotice:   {Category: CategoryWorkload, Prefixed: true, Environment: noticeEnvironment},
+	PebbleReady:          {Category: CategoryWorkload, Prefixed: true, Environment: workloadEnvironment},
+
+	SecretChanged: {Category: CategorySecret, Environment: secretEnvironment},
+	SecretExpired: {Category: CategorySecret, Environment: revisionEnvironment},
+	SecretRemove:  {Category: CategorySecret, Environment: revisionEnvironment},
+	SecretRotate:  {Category: CategorySecret, Environment: secretEnvironment},
+}
+
+func init() {
+	for _, kind := range unitHooks {
+		kindMetadata[kind] = KindMetadata{Category: CategoryUnit}
+	}
+}
+
+// Metadata returns the metadata of the given hook kind, and whether the
+// kind is known.
+func Metadata(kind Kind) (KindMetadata, bool) {
+	md, ok := kindMetadata[kind]
+	if !ok {
+		return KindMetadata{}, false
+	}
+	md.Environment = append([]string(nil), md.Environment...)
+	return md, true
+}
+
+// ParseHookName parses the name of a hook file, such as "install" or
+// "db-relation-joined", and returns its kind. For kinds whose hook names
+// are prefixed by a relation, storage or container name, that name is
+// returned as prefix; otherwise prefix is empty. Action hooks are named
+// after the action, as in "backup-action"; the bare "action" is not
+// accepted.
+func ParseHookName(name string) (prefix string, kind Kind, err error) {
+	if md, ok := kindMetadata[Kind(name)]; ok && !md.Prefixed && md.Category != CategoryAction {
+		return "", Kind(name), nil
+	}
+	for _, kinds := range [][]Kind{relationHooks, storageHooks, workloadHooks, {Action}} {
+		for _, kind := range kinds {
+			prefix, ok := strings.CutSuffix(name, "-"+string(kind))
+			if ok && prefix != "" {
+				return prefix, kind, nil
+			}
+		}
+	}
+	return "", "", errors.NotValidf("hook name %q", name)
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/juju/charm/v12/hooks"
+	"github.com/juju/charm/v12/internal/filetree"
+	"github.com/juju/charm/v12/resource"
+)
+
+// InitOptions holds the options for InitDir.
+type InitOptions struct {
+	// Name holds the name of the charm. It is required.
+	Name string
+
+	// Summary and Description hold the summary and description of the
+	// charm. Placeholders are used if they are empty.
+	Summary     string
+	Description string
+
+	// Format selects the format of the charm: FormatV1 for a machine
+	// charm declaring no bases, or FormatV2 for a charm declaring its
+	// bases in manifest.yaml. FormatUnknown selects FormatV2.
+	Format Format
+
+	// Bases holds the bases a FormatV2 charm supports. If it is empty,
+	// ubuntu@22.04 is used.
+	Bases []Base
+
+	// Container holds the name of the workload container of a FormatV2
+	// sidecar charm. If it is empty, the charm does not declare a
+	// container.
+	Container string
+}
+
+// initHooks holds the hooks given stubs by InitDir.
+var initHooks = []hooks.Kind{
+	hooks.Install,
+	hooks.Start,
+	hooks.Stop,
+	hooks.ConfigChanged,
+	hooks.UpgradeCharm,
+}
+
+// InitDir creates a minimal, valid charm skeleton in the directory at
+// path, which must not exist or be empty, and returns it read back as a
+// charm directory. The skeleton holds metadata.yaml, config.yaml and
+// actions.yaml files with an example option and action, a hooks
+// directory with stub hooks, and a LICENSE placeholder. FormatV2 charms
+// also have a manifest.yaml declaring their bases and, if a container
+// is given, declare the container, its image resource and its
+// pebble-ready hook.
+func InitDir(path string, opts InitOptions) (*CharmDir, error) {
+	if err := ValidateName(opts.Name); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if opts.Format == FormatUnknown {
+		opts.Format = FormatV2
+	}
+	if opts.Format != FormatV1 && opts.Format != FormatV2 {
+		return nil, errors.NotValidf("charm format %v", opts.Format)
+	}
+	if opts.Format == FormatV1 && (len(opts.Bases) > 0 || opts.Container != "") {
+		return nil, errors.NotValidf("bases or container for a format v1 charm")
+	}
+	if entries, err := os.ReadDir(path); err == nil && len(entries) > 0 {
+		return nil, errors.AlreadyExistsf("non-empty directory %q", path)
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Trace(err)
+	}
+
+	files, err := initFiles(opts)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := filetree.Write(path, files); err != nil {
+		return nil, errors.Trace(err)
+	}
+	dir, err := ReadCharmDir(path)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read generated charm")
+	}
+	return dir, nil
+}
+
+// initFiles returns the files of the charm skeleton described by opts,
+// keyed by their slash-separated path.
+func initFiles(opts InitOptions) (map[string]filetree.File, error) {
+	files := make(map[string]filetree.File)
+	addYAML := func(name string, v interface{}) error {
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return errors.Annotatef(err, "cannot marshal %s", name)
+		}
+		files[name] = filetree.File{Data: data, Mode: 0644}
+		return nil
+	}
+
+	meta := &Meta{
+		Name:        opts.Name,
+		Summary:     opts.Summary,
+		Description: opts.Description,
+	}
+	if meta.Summary == "" {
+		meta.Summary = fmt.Sprintf("A one line summary of the %s charm.", opts.Name)
+	}
+	if meta.Description == "" {
+		meta.Description = fmt.Sprintf("A longer description of the %s charm and the software it deploys.\n", opts.Name)
+	}
+	hookNames := make([]string, len(initHooks))
+	for i, hook := range initHooks {
+		hookNames[i] = string(hook)
+	}
+	if opts.Format == FormatV2 {
+		bases := opts.Bases
+		if len(bases) == 0 {
+			bases = []Base{{Name: "ubuntu", Channel: Channel{Track: "22.04", Risk: Stable}}}
+		}
+		manifestBases := make([]yaml.MapSlice, len(bases))
+		for i, base := range bases {
+			if err := base.Validate(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			manifestBases[i] = yaml.MapSlice{
+				{Key: "name", Value: base.Name},
+				{Key: "channel", Value: base.Channel.String()},
+			}
+			if len(base.Architectures) > 0 {
+				manifestBases[i] = append(manifestBases[i], yaml.MapItem{Key: "architectures", Value: base.Architectures})
+			}
+		}
+		if err := addYAML("manifest.yaml", yaml.MapSlice{{Key: "bases", Value: manifestBases}}); err != nil {
+			return nil, err
+		}
+		if opts.Container != "" {
+			image := opts.Container + "-image"
+			meta.Containers = map[string]Container{
+				opts.Container: {Resource: image},
+			}
+			meta.Resources = map[string]resource.Meta{
+				image: {
+					Name:        image,
+					Type:        resource.TypeContainerImage,
+					Description: fmt.Sprintf("The OCI image for the %s container.", opts.Container),
+				},
+			}
+			hookNames = append(hookNames, fmt.Sprintf("%s-%s", opts.Container, hooks.PebbleReady))
+		}
+	}
+	if err := addYAML("metadata.yaml", meta); err != nil {
+		return nil, err
+	}
+
+	config := NewConfig()
+	config.Options["log-level"] = Option{
+		Type:        "string",
+		Description: "The level of detail the charm logs at.",
+		Default:     "info",
+		Choices:     []string{"debug", "info", "warning", "error"},
+	}
+	if err := addYAML("config.yaml", config); err != nil {
+		return nil, err
+	}
+
+	if err := addYAML("actions.yaml", yaml.MapSlice{{
+		Key: "restart",
+		Value: yaml.MapSlice{
+			{Key: "description", Value: "Restart the workload."},
+		},
+	}}); err != nil {
+		return nil, err
+	}
+
+	for _, hook := range hookNames {
+		files["hooks/"+hook] = filetree.File{
+			Data: []byte(fmt.Sprintf("#!/bin/sh\n# The %s hook of the %s charm.\nset -e\n", hook, opts.Name)),
+			Mode: 0755,
+		}
+	}
+	files["LICENSE"] = filetree.File{
+		Data: []byte("Replace this file with the licence under which the charm is distributed.\n"),
+		Mode: 0644,
+	}
+	return files, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type InitDirSuite struct{}
+
+var _ = gc.Suite(&InitDirSuite{})
+
+func (s *InitDirSuite) TestInitDirV1(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "mysql")
+	dir, err := charm.InitDir(path, charm.InitOptions{
+		Name:   "mysql",
+		Format: charm.FormatV1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(charm.MetaFormat(dir), gc.Equals, charm.FormatV1)
+	c.Check(dir.Meta().Name, gc.Equals, "mysql")
+	c.Check(dir.Manifest(), gc.IsNil)
+	c.Check(dir.Config().Options["log-level"].Default, gc.Equals, "info")
+	c.Check(dir.Actions().ActionSpecs, gc.HasLen, 1)
+
+	for _, hook := range []string{"install", "start", "stop", "config-changed", "upgrade-charm"} {
+		info, err := os.Stat(filepath.Join(path, "hooks", hook))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(info.Mode().Perm(), gc.Equals, os.FileMode(0755), gc.Commentf("hook %q", hook))
+	}
+	_, err = os.Stat(filepath.Join(path, "LICENSE"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The skeleton can be archived and read back.
+	var buf bytes.Buffer
+	c.Assert(dir.ArchiveTo(&buf), jc.ErrorIsNil)
+	_, err = charm.ReadCharmArchiveBytes(buf.Bytes())
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *InitDirSuite) TestInitDirV2Sidecar(c *gc.C) {
+	path := c.MkDir()
+	dir, err := charm.InitDir(path, charm.InitOptions{
+		Name:      "snappass",
+		Summary:   "A password sharing service.",
+		Container: "snappass",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(charm.MetaFormat(dir), gc.Equals, charm.FormatV2)
+	c.Check(dir.Meta().Summary, gc.Equals, "A password sharing service.")
+	c.Check(dir.Manifest().Bases, gc.HasLen, 1)
+	c.Check(dir.Manifest().Bases[0].String(), gc.Equals, "ubuntu@22.04/stable")
+	c.Check(dir.Meta().Containers, jc.DeepEquals, map[string]charm.Container{
+		"snappass": {Resource: "snappass-image"},
+	})
+	c.Check(dir.Meta().Resources["snappass-image"].Name, gc.Equals, "snappass-image")
+	_, err = os.Stat(filepath.Join(path, "hooks", "snappass-pebble-ready"))
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *InitDirSuite) TestInitDirErrors(c *gc.C) {
+	_, err := charm.InitDir(c.MkDir(), charm.InitOptions{Name: "Bad_Name"})
+	c.Check(err, gc.ErrorMatches, `name "Bad_Name" not valid`)
+
+	_, err = charm.InitDir(c.MkDir(), charm.InitOptions{Name: "mysql", Format: charm.FormatV1, Container: "mysql"})
+	c.Check(err, gc.ErrorMatches, `bases or container for a format v1 charm not valid`)
+
+	path := c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(path, "README.md"), []byte("hello\n"), 0644), jc.ErrorIsNil)
+	_, err = charm.InitDir(path, charm.InitOptions{Name: "mysql"})
+	c.Check(err, jc.Satisfies, errors.IsAlreadyExists)
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juju/collections/set"
+)
+
+// wellKnownInterfaces holds the names of widely used relation
+// interfaces.
+var wellKnownInterfaces = []string{
+	"cassandra",
+	"elasticsearch",
+	"grafana-dashboard",
+	"grafana-source",
+	"http",
+	"ingress",
+	"juju-info",
+	"kafka",
+	"keystone",
+	"memcache",
+	"metrics-endpoint",
+	"mongodb",
+	"mysql",
+	"mysql-root",
+	"nrpe-external-master",
+	"pgsql",
+	"postgresql_client",
+	"prometheus",
+	"prometheus_scrape",
+	"rabbitmq",
+	"redis",
+	"tls-certificates",
+	"vault-kv",
+	"zookeeper",
+}
+
+// InterfaceRegistry holds a set of known relation interface names. The
+// zero value is an empty registry ready to use.
+type InterfaceRegistry struct {
+	names set.Strings
+}
+
+// NewInterfaceRegistry returns a registry holding the given interface
+// names.
+func NewInterfaceRegistry(names ...string) *InterfaceRegistry {
+	r := &InterfaceRegistry{}
+	for _, name := range names {
+		r.Add(name)
+	}
+	return r
+}
+
+// WellKnownInterfaces returns a new registry holding the names of
+// widely used relation interfaces, such as http, mysql, juju-info and
+// tls-certificates.
+func WellKnownInterfaces() *InterfaceRegistry {
+	return NewInterfaceRegistry(wellKnownInterfaces...)
+}
+
+// Add adds the given interface name to the registry.
+func (r *InterfaceRegistry) Add(name string) {
+	if r.names == nil {
+		r.names = set.NewStrings()
+	}
+	r.names.Add(name)
+}
+
+// Contains reports whether the registry holds the given interface name.
+func (r *InterfaceRegistry) Contains(name string) bool {
+	return r.names.Contains(name)
+}
+
+// Names returns the sorted interface names held in the registry.
+func (r *InterfaceRegistry) Names() []string {
+	return r.names.SortedValues()
+}
+
+// InterfaceWarning describes a relation whose interface is probably a
+// misspelling of a known interface.
+type InterfaceWarning struct {
+	// Relation holds the name of the relation.
+	Relation string
+
+	// Interface holds the interface declared by the relation.
+	Interface string
+
+	// Suggestion holds the known interface the relation probably
+	// meant to use.
+	Suggestion string
+}
+
+// String returns a description of the warning.
+func (w InterfaceWarning) String() string {
+	return fmt.Sprintf("relation %q uses unknown interface %q; did you mean %q?", w.Relation, w.Interface, w.Suggestion)
+}
+
+// CheckInterfaces returns a warning for each relation in meta whose
+// interface is not held in the registry but is a single edit away from
+// one that is, such as "htpp" instead of "http". If registry is nil,
+// the well-known interfaces are used. The warnings are sorted by
+// relation name.
+func CheckInterfaces(meta *Meta, registry *InterfaceRegistry) []InterfaceWarning {
+	if registry == nil {
+		registry = WellKnownInterfaces()
+	}
+	known := registry.Names()
+	var warnings []InterfaceWarning
+	for name, rel := range meta.CombinedRelations() {
+		if rel.Interface == "" || registry.Contains(rel.Interface) {
+			continue
+		}
+		for _, candidate := range known {
+			if levenshtein(rel.Interface, candidate) == 1 {
+				warnings = append(warnings, InterfaceWarning{
+					Relation:   name,
+					Interface:  rel.Interface,
+					Suggestion: candidate,
+				})
+				break
+			}
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].Relation < warnings[j].Relation
+	})
+	return warnings
+}
+
+// levenshtein returns the number of single character insertions,
+// deletions and substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
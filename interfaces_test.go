@@ -0,0 +1,82 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type InterfacesSuite struct{}
+
+var _ = gc.Suite(&InterfacesSuite{})
+
+func (s *InterfacesSuite) TestInterfaceRegistry(c *gc.C) {
+	var r charm.InterfaceRegistry
+	c.Assert(r.Contains("http"), jc.IsFalse)
+	c.Assert(r.Names(), gc.HasLen, 0)
+	r.Add("http")
+	r.Add("mysql")
+	c.Assert(r.Contains("http"), jc.IsTrue)
+	c.Assert(r.Names(), jc.DeepEquals, []string{"http", "mysql"})
+
+	known := charm.WellKnownInterfaces()
+	for _, name := range []string{"http", "mysql", "juju-info", "tls-certificates"} {
+		c.Check(known.Contains(name), jc.IsTrue, gc.Commentf("%s", name))
+	}
+}
+
+func (s *InterfacesSuite) TestCheckInterfaces(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: typo
+summary: s
+description: d
+provides:
+    website:
+        interface: htpp
+    certs:
+        interface: tls-certificate
+requires:
+    db:
+        interface: mysql
+    cache:
+        interface: my-own-cache
+peers:
+    cluster:
+        interface: mysq
+`))
+	c.Assert(err, jc.ErrorIsNil)
+
+	warnings := charm.CheckInterfaces(meta, nil)
+	c.Assert(warnings, jc.DeepEquals, []charm.InterfaceWarning{{
+		Relation:   "certs",
+		Interface:  "tls-certificate",
+		Suggestion: "tls-certificates",
+	}, {
+		Relation:   "cluster",
+		Interface:  "mysq",
+		Suggestion: "mysql",
+	}, {
+		Relation:   "website",
+		Interface:  "htpp",
+		Suggestion: "http",
+	}})
+	c.Assert(warnings[2].String(), gc.Equals, `relation "website" uses unknown interface "htpp"; did you mean "http"?`)
+
+	// A custom registry replaces the well-known interfaces.
+	warnings = charm.CheckInterfaces(meta, charm.NewInterfaceRegistry("my-own-cachex", "mysql"))
+	c.Assert(warnings, jc.DeepEquals, []charm.InterfaceWarning{{
+		Relation:   "cache",
+		Interface:  "my-own-cache",
+		Suggestion: "my-own-cachex",
+	}, {
+		Relation:   "cluster",
+		Interface:  "mysq",
+		Suggestion: "mysql",
+	}})
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package filetree writes trees of files declared in memory to disk.
+package filetree
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/juju/errors"
+)
+
+// File holds the contents and mode of a file.
+type File struct {
+	Data []byte
+	Mode os.FileMode
+}
+
+// Names returns the paths of the given files, sorted so that they are
+// written deterministically.
+func Names(files map[string]File) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Write writes the files, keyed by their slash-separated path, into
+// dir, creating directories as needed. Each file is given exactly its
+// mode, whatever the umask.
+func Write(dir string, files map[string]File) error {
+	for _, name := range Names(files) {
+		f := files[name]
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.Trace(err)
+		}
+		if err := os.WriteFile(path, f.Data, f.Mode); err != nil {
+			return errors.Trace(err)
+		}
+		// The mode given to WriteFile is subject to the umask.
+		if err := os.Chmod(path, f.Mode); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
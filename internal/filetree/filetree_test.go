@@ -0,0 +1,44 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package filetree_test
+
+import (
+	"os"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12/internal/filetree"
+)
+
+type FileTreeSuite struct{}
+
+var _ = gc.Suite(&FileTreeSuite{})
+
+var testFiles = map[string]filetree.File{
+	"hooks/install": {Data: []byte("#!/bin/sh\n"), Mode: 0775},
+	"metadata.yaml": {Data: []byte("name: test\n"), Mode: 0664},
+}
+
+func (s *FileTreeSuite) TestNames(c *gc.C) {
+	c.Assert(filetree.Names(testFiles), jc.DeepEquals, []string{"hooks/install", "metadata.yaml"})
+}
+
+func (s *FileTreeSuite) TestWrite(c *gc.C) {
+	// The group write bits are kept, although the usual umask
+	// clears them.
+	dir := c.MkDir()
+	err := filetree.Write(dir, testFiles)
+	c.Assert(err, jc.ErrorIsNil)
+	for name, f := range testFiles {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		data, err := os.ReadFile(path)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(data, jc.DeepEquals, f.Data)
+		info, err := os.Stat(path)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(info.Mode().Perm(), gc.Equals, f.Mode)
+	}
+}
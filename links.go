@@ -0,0 +1,119 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+)
+
+// Links holds the links shown alongside a charm, declared in the links
+// section of metadata.yaml. Each field but Documentation may be given
+// in metadata.yaml as either a single string or a list of strings.
+type Links struct {
+	// Documentation holds the URL of the charm's documentation.
+	Documentation string `bson:"documentation,omitempty" json:"documentation,omitempty" yaml:"documentation,omitempty"`
+
+	// Website holds the URLs of the websites of the charm or the
+	// software it deploys.
+	Website []string `bson:"website,omitempty" json:"website,omitempty" yaml:"website,omitempty"`
+
+	// Source holds the URLs of the charm's source code.
+	Source []string `bson:"source,omitempty" json:"source,omitempty" yaml:"source,omitempty"`
+
+	// Issues holds the URLs where issues with the charm are reported.
+	Issues []string `bson:"issues,omitempty" json:"issues,omitempty" yaml:"issues,omitempty"`
+
+	// Contact holds the email addresses or URLs of the charm's
+	// maintainers.
+	Contact []string `bson:"contact,omitempty" json:"contact,omitempty" yaml:"contact,omitempty"`
+}
+
+var linksSchema = sync.OnceValue(func() schema.Checker {
+	stringOrList := schema.OneOf(schema.String(), schema.List(schema.String()))
+	return schema.FieldMap(
+		schema.Fields{
+			"documentation": schema.String(),
+			"website":       stringOrList,
+			"source":        stringOrList,
+			"issues":        stringOrList,
+			"contact":       stringOrList,
+		}, schema.Defaults{
+			"documentation": schema.Omit,
+			"website":       schema.Omit,
+			"source":        schema.Omit,
+			"issues":        schema.Omit,
+			"contact":       schema.Omit,
+		},
+	)
+})
+
+// parseLinks parses the coerced links section of metadata.yaml. It
+// returns nil if the section is absent.
+func parseLinks(value interface{}) (*Links, error) {
+	if value == nil {
+		return nil, nil
+	}
+	m, err := coercedMap("links", value)
+	if err != nil {
+		return nil, err
+	}
+	var links Links
+	if links.Documentation, err = optionalField[string](m, "links", "documentation"); err != nil {
+		return nil, err
+	}
+	if links.Documentation != "" {
+		if err := validateLinkURL("links.documentation", links.Documentation); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range []struct {
+		name   string
+		target *[]string
+	}{
+		{"website", &links.Website},
+		{"source", &links.Source},
+		{"issues", &links.Issues},
+		{"contact", &links.Contact},
+	} {
+		path := joinField("links", field.name)
+		if *field.target, err = parseStringOrList(path, m[field.name]); err != nil {
+			return nil, err
+		}
+		for i, link := range *field.target {
+			if field.name == "contact" {
+				if _, err := mail.ParseAddress(link); err == nil {
+					continue
+				}
+			}
+			if err := validateLinkURL(fmt.Sprintf("%s[%d]", path, i), link); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &links, nil
+}
+
+// parseStringOrList returns the strings held in value, which is either
+// a string or a list of strings.
+func parseStringOrList(field string, value interface{}) ([]string, error) {
+	if s, ok := value.(string); ok {
+		return []string{s}, nil
+	}
+	return parseStringList(field, value)
+}
+
+// validateLinkURL checks that link is an absolute http or https URL.
+func validateLinkURL(field, link string) error {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.NotValidf("metadata: %s: URL %q", field, link)
+	}
+	return nil
+}
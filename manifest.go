@@ -6,10 +6,10 @@ package charm
 import (
 	"io"
 	"io/ioutil"
+	"sync"
 
 	"github.com/juju/errors"
 	"github.com/juju/schema"
-	"github.com/juju/utils/v3/arch"
 	"gopkg.in/yaml.v2"
 )
 
@@ -38,7 +38,7 @@ func (m *Manifest) UnmarshalYAML(f func(interface{}) error) error {
 		return err
 	}
 
-	v, err := schema.List(baseSchema).Coerce(raw["bases"], nil)
+	v, err := schema.List(baseSchema()).Coerce(raw["bases"], nil)
 	if err != nil {
 		return errors.Annotatef(err, "coerce")
 	}
@@ -103,16 +103,18 @@ func ReadManifest(r io.Reader) (*Manifest, error) {
 	return manifest, nil
 }
 
-var baseSchema = schema.FieldMap(
-	schema.Fields{
-		"name":          schema.String(),
-		"channel":       schema.String(),
-		"architectures": schema.List(schema.String()),
-	}, schema.Defaults{
-		"name":          schema.Omit,
-		"channel":       schema.Omit,
-		"architectures": schema.Omit,
-	})
+var baseSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"name":          schema.String(),
+			"channel":       schema.String(),
+			"architectures": schema.List(schema.String()),
+		}, schema.Defaults{
+			"name":          schema.Omit,
+			"channel":       schema.Omit,
+			"architectures": schema.Omit,
+		})
+})
 
 func parseArchitectureList(list interface{}) []string {
 	if list == nil {
@@ -121,7 +123,7 @@ func parseArchitectureList(list interface{}) []string {
 	slice := list.([]interface{})
 	result := make([]string, 0, len(slice))
 	for _, elem := range slice {
-		result = append(result, arch.NormaliseArch(elem.(string)))
+		result = append(result, NormaliseArchitecture(elem.(string)))
 	}
 	return result
 }
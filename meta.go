@@ -7,10 +7,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"regexp"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/juju/collections/set"
 	"github.com/juju/errors"
@@ -48,14 +49,51 @@ const (
 	RolePeer     RelationRole = "peer"
 )
 
+// Counterpart returns the role that a relation with role r relates to:
+// providers relate to requirers, requirers to providers and peers to
+// peers. It panics if r is not a known role.
+func (r RelationRole) Counterpart() RelationRole {
+	switch r {
+	case RoleProvider:
+		return RoleRequirer
+	case RoleRequirer:
+		return RoleProvider
+	case RolePeer:
+		return RolePeer
+	}
+	panic(fmt.Errorf("unknown relation role %q", r))
+}
+
+// CanRelateTo reports whether a relation with role r may be established
+// with an endpoint of another application having the other role. Peer
+// relations are only ever established within an application.
+func (r RelationRole) CanRelateTo(other RelationRole) bool {
+	switch r {
+	case RoleProvider, RoleRequirer:
+		return r.Counterpart() == other
+	}
+	return false
+}
+
 // StorageType defines a storage type.
 type StorageType string
 
 const (
 	StorageBlock      StorageType = "block"
 	StorageFilesystem StorageType = "filesystem"
+
+	// StorageTmpfs is memory-backed scratch space, mounted as a
+	// filesystem, whose contents are lost when the unit restarts.
+	StorageTmpfs StorageType = "tmpfs"
 )
 
+// IsFilesystem reports whether storage of the type is mounted as a
+// filesystem, and so may be given a location or mounted into a
+// container.
+func (t StorageType) IsFilesystem() bool {
+	return t == StorageFilesystem || t == StorageTmpfs
+}
+
 // Storage represents a charm's storage requirement.
 type Storage struct {
 	// Name is the name of the store.
@@ -68,7 +106,7 @@ type Storage struct {
 	// Description has no default, and is optional.
 	Description string `bson:"description"`
 
-	// Type is the storage type: filesystem or block-device.
+	// Type is the storage type: filesystem, block-device or tmpfs.
 	//
 	// Type has no default, and must be specified.
 	Type StorageType `bson:"type"`
@@ -111,6 +149,14 @@ type Storage struct {
 	// specific default will be used, typically 1GB for block storage.
 	MinimumSize uint64 `bson:"minimum-size"`
 
+	// MaximumSize is the largest size of store that the charm can make
+	// use of. Like MinimumSize, it requires a unit, one of MGTPEZY, and
+	// is stored as MiB.
+	//
+	// There is no default MaximumSize; if left unspecified, the size of
+	// the store is not bounded.
+	MaximumSize uint64 `bson:"maximum-size,omitempty"`
+
 	// Location is the mount location for filesystem stores. For multi-
 	// stores, the location acts as the parent directory for each mounted
 	// store.
@@ -130,6 +176,13 @@ type Storage struct {
 // DeviceType defines a device type.
 type DeviceType string
 
+const (
+	DeviceGPU       DeviceType = "gpu"
+	DeviceNvidiaGPU DeviceType = "nvidia.com/gpu"
+	DeviceAMDGPU    DeviceType = "amd.com/gpu"
+	DeviceTPU       DeviceType = "tpu"
+)
+
 // Device represents a charm's device requirement (GPU for example).
 type Device struct {
 	// Name is the name of the device.
@@ -143,6 +196,8 @@ type Device struct {
 	// - gpu
 	// - nvidia.com/gpu
 	// - amd.com/gpu
+	// - tpu
+	// along with vendor qualified types such as example.com/fpga.
 	Type DeviceType `bson:"type"`
 
 	// CountMin is the min number of devices that the charm requires.
@@ -197,6 +252,33 @@ type Deployment struct {
 	MinVersion     string         `bson:"min-version"`
 }
 
+// ParseMinVersion returns the minimum Kubernetes cluster version
+// required by the deployment, or version.Zero if it declares none. An
+// error satisfying errors.NotValid is returned if MinVersion is not a
+// version such as "1.18" or "1.18.3".
+func (d *Deployment) ParseMinVersion() (version.Number, error) {
+	if d.MinVersion == "" {
+		return version.Zero, nil
+	}
+	v, err := version.ParseNonStrict(d.MinVersion)
+	if err != nil {
+		return version.Zero, errors.NotValidf("deployment min-version %q", d.MinVersion)
+	}
+	return v, nil
+}
+
+// SupportsClusterVersion reports whether the deployment can be made to
+// a Kubernetes cluster running version v, which is the case if v is
+// no older than the deployment's minimum version. A deployment whose
+// minimum version is not valid supports no cluster version.
+func (d *Deployment) SupportsClusterVersion(v version.Number) bool {
+	minVersion, err := d.ParseMinVersion()
+	if err != nil {
+		return false
+	}
+	return v.Compare(minVersion) >= 0
+}
+
 // Relation represents a single relation defined in the charm
 // metadata.yaml file.
 type Relation struct {
@@ -208,45 +290,149 @@ type Relation struct {
 	Scope     RelationScope `bson:"scope"`
 }
 
+// Counterpart returns the mirror of the relation: a relation with the
+// same name, interface and scope, and the counterpart role. The mirror
+// is neither optional nor limited.
+func (r Relation) Counterpart() Relation {
+	return Relation{
+		Name:      r.Name,
+		Role:      r.Role.Counterpart(),
+		Interface: r.Interface,
+		Scope:     r.Scope,
+	}
+}
+
 // ImplementedBy returns whether the relation is implemented by the supplied charm.
 func (r Relation) ImplementedBy(ch Charm) bool {
+	ok, _ := r.ExplainImplementedBy(ch)
+	return ok
+}
+
+// ExplainImplementedBy is like ImplementedBy, but when the relation is
+// not implemented by the supplied charm it also returns the reason, such
+// as a missing endpoint or a mismatched interface, suitable for
+// including in an error message. The charm's endpoint must have the
+// relation's name, role and interface, must not be container scoped
+// unless the relation is, and must not be limited to fewer relations
+// than the relation's own limit, if it has one.
+func (r Relation) ExplainImplementedBy(ch Charm) (bool, string) {
 	if r.IsImplicit() {
-		return true
+		return true, ""
 	}
-	var m map[string]Relation
-	switch r.Role {
-	case RoleProvider:
-		m = ch.Meta().Provides
-	case RoleRequirer:
-		m = ch.Meta().Requires
-	case RolePeer:
-		m = ch.Meta().Peers
-	default:
+	if _, ok := roleSections[r.Role]; !ok {
 		panic(errors.Errorf("unknown relation role %q", r.Role))
 	}
-	rel, found := m[r.Name]
+	rel, found := ch.Meta().relationsByRole(r.Role)[r.Name]
 	if !found {
-		return false
+		for _, role := range []RelationRole{RoleProvider, RoleRequirer, RolePeer} {
+			if _, ok := ch.Meta().relationsByRole(role)[r.Name]; ok {
+				return false, fmt.Sprintf("relation %q is declared in %s, not %s", r.Name, roleSections[role], roleSections[r.Role])
+			}
+		}
+		return false, fmt.Sprintf("charm does not declare relation %q in %s", r.Name, roleSections[r.Role])
 	}
-	if rel.Interface == r.Interface {
-		switch r.Scope {
-		case ScopeGlobal:
-			return rel.Scope != ScopeContainer
-		case ScopeContainer:
-			return true
-		default:
-			panic(errors.Errorf("unknown relation scope %q", r.Scope))
+	if rel.Interface != r.Interface {
+		return false, fmt.Sprintf("relation %q has interface %q, not %q", r.Name, rel.Interface, r.Interface)
+	}
+	switch r.Scope {
+	case ScopeGlobal:
+		if rel.Scope == ScopeContainer {
+			return false, fmt.Sprintf("relation %q has scope %q, not %q", r.Name, rel.Scope, r.Scope)
 		}
+	case ScopeContainer:
+	default:
+		panic(errors.Errorf("unknown relation scope %q", r.Scope))
 	}
-	return false
+	if r.Limit > 0 && rel.Limit > 0 && rel.Limit < r.Limit {
+		return false, fmt.Sprintf("relation %q is limited to %d relations, not %d", r.Name, rel.Limit, r.Limit)
+	}
+	return true, ""
+}
+
+// relationsByRole returns the relations declared by the charm with the
+// given role, or nil if the role is unknown.
+func (m Meta) relationsByRole(role RelationRole) map[string]Relation {
+	switch role {
+	case RoleProvider:
+		return m.Provides
+	case RoleRequirer:
+		return m.Requires
+	case RolePeer:
+		return m.Peers
+	}
+	return nil
 }
 
 // IsImplicit returns whether the relation is supplied by juju itself,
-// rather than by a charm.
+// rather than by a charm. The juju-info relation is always implicit;
+// further implicit relations may be added with RegisterImplicitRelation.
 func (r Relation) IsImplicit() bool {
-	return (r.Name == "juju-info" &&
-		r.Interface == "juju-info" &&
-		r.Role == RoleProvider)
+	implicit, ok := implicitRelation(r.Name)
+	return ok && r.Interface == implicit.Interface && r.Role == implicit.Role
+}
+
+var (
+	implicitRelationsMu sync.RWMutex
+	implicitRelations   = map[string]Relation{
+		"juju-info": {
+			Name:      "juju-info",
+			Role:      RoleProvider,
+			Interface: "juju-info",
+			Scope:     ScopeGlobal,
+		},
+	}
+)
+
+// RegisterImplicitRelation registers a relation that juju provides for
+// every application without it being declared by the charm, in addition
+// to juju-info. Registered relations are honoured by IsImplicit,
+// ImplementedBy and bundle relation verification and inference.
+//
+// Implicit relations must be provided relations, and their names must
+// use the reserved "juju-" prefix so that they cannot clash with the
+// relations declared by charms.
+func RegisterImplicitRelation(r Relation) error {
+	if r.Role != RoleProvider {
+		return errors.NotValidf("implicit relation %q with role %q", r.Name, r.Role)
+	}
+	if !strings.HasPrefix(r.Name, "juju-") {
+		return errors.NotValidf("implicit relation name %q without the \"juju-\" prefix", r.Name)
+	}
+	if r.Interface == "" {
+		return errors.NotValidf("implicit relation %q without an interface", r.Name)
+	}
+	if r.Scope == "" {
+		r.Scope = ScopeGlobal
+	}
+	implicitRelationsMu.Lock()
+	defer implicitRelationsMu.Unlock()
+	if _, ok := implicitRelations[r.Name]; ok {
+		return errors.AlreadyExistsf("implicit relation %q", r.Name)
+	}
+	implicitRelations[r.Name] = r
+	return nil
+}
+
+// ImplicitRelations returns the relations juju provides for every
+// application, sorted by name.
+func ImplicitRelations() []Relation {
+	implicitRelationsMu.RLock()
+	defer implicitRelationsMu.RUnlock()
+	rels := make([]Relation, 0, len(implicitRelations))
+	for _, r := range implicitRelations {
+		rels = append(rels, r)
+	}
+	sort.Slice(rels, func(i, j int) bool {
+		return rels[i].Name < rels[j].Name
+	})
+	return rels
+}
+
+func implicitRelation(name string) (Relation, bool) {
+	implicitRelationsMu.RLock()
+	defer implicitRelationsMu.RUnlock()
+	r, ok := implicitRelations[name]
+	return r, ok
 }
 
 // RunAs defines which user to run a certain process as.
@@ -291,6 +477,25 @@ type Meta struct {
 	Containers map[string]Container    `bson:"containers,omitempty" json:"containers,omitempty" yaml:"containers,omitempty"`
 	Assumes    *assumes.ExpressionTree `bson:"assumes,omitempty" json:"assumes,omitempty" yaml:"assumes,omitempty"`
 	CharmUser  RunAs                   `bson:"charm-user,omitempty" json:"charm-user,omitempty" yaml:"charm-user,omitempty"`
+
+	// Links holds the links to the charm's documentation, website,
+	// source and issue tracker, and to its maintainers.
+	Links *Links `bson:"links,omitempty" json:"links,omitempty" yaml:"links,omitempty"`
+
+	// Secrets holds the secrets the charm manages or consumes, keyed
+	// by secret name.
+	Secrets map[string]Secret `bson:"secrets,omitempty" json:"secrets,omitempty" yaml:"secrets,omitempty"`
+
+	// UnknownFields holds the top-level fields of metadata.yaml that
+	// are not recognised, keyed by field name. It is only populated
+	// when ReadMeta is called with WithUnknownFields.
+	UnknownFields map[string]interface{} `bson:"-" json:"-" yaml:"-"`
+
+	// Translations holds the summary and description of the charm in
+	// other languages, keyed by language. It is populated from the
+	// charm's metadata.<lang>.yaml files when a charm directory or
+	// archive is read; see Localized.
+	Translations map[string]MetaTranslation `bson:"translations,omitempty" json:"Translations,omitempty" yaml:"-"`
 }
 
 // Container specifies the possible systems it supports and mounts it wants.
@@ -299,6 +504,17 @@ type Container struct {
 	Mounts   []Mount `bson:"mounts,omitempty" json:"mounts,omitempty" yaml:"mounts,omitempty"`
 	Uid      int     `bson:"uid,omitempty" json:"uid,omitempty" yaml:"uid,omitempty"`
 	Gid      int     `bson:"gid,omitempty" json:"gid,omitempty" yaml:"gid,omitempty"`
+
+	// Command overrides the entrypoint of the container image, and
+	// Args the arguments passed to it, so that the workload can be
+	// started without a custom pebble layer.
+	Command []string `bson:"command,omitempty" json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string `bson:"args,omitempty" json:"args,omitempty" yaml:"args,omitempty"`
+
+	// Env holds environment variables set for the container's
+	// workload, keyed by variable name. Numbers and booleans given
+	// as values are converted to strings.
+	Env map[string]string `bson:"env,omitempty" json:"env,omitempty" yaml:"env,omitempty"`
 }
 
 // Mount allows a container to mount a storage filesystem from the storage top-level directive.
@@ -326,9 +542,10 @@ func generateStorageHooks(storageName string, allHooks map[string]bool) {
 	}
 }
 
-// Hooks returns a map of all possible valid hooks, taking relations
-// into account. It's a map to enable fast lookups, and the value is
-// always true.
+// Hooks returns a map of all possible valid hooks, taking relations,
+// storage and containers into account. It's a map to enable fast
+// lookups, and the value is always true. Use HooksFor to include the
+// charm's action hooks.
 func (m Meta) Hooks() map[string]bool {
 	allHooks := make(map[string]bool)
 	// Unit hooks
@@ -352,26 +569,125 @@ func (m Meta) Hooks() map[string]bool {
 	for storageName := range m.Storage {
 		generateStorageHooks(storageName, allHooks)
 	}
-	for containerName := range m.Containers {
-		generateContainerHooks(containerName, allHooks)
+	for hookName := range m.ContainersHooks() {
+		allHooks[hookName] = true
 	}
 	return allHooks
 }
 
+// HooksFor is like Hooks, but also includes an "<action>-action" hook
+// for each of the given actions, so that every hook the charm may run
+// is derived in one place. The actions may be nil.
+func (m Meta) HooksFor(actions *Actions) map[string]bool {
+	allHooks := m.Hooks()
+	if actions != nil {
+		for name := range actions.ActionSpecs {
+			allHooks[fmt.Sprintf("%s-%s", name, hooks.Action)] = true
+		}
+	}
+	return allHooks
+}
+
+// ContainersHooks returns a map of the workload hooks triggered by pebble
+// for each container declared by the charm, such as
+// "mycontainer-pebble-ready". The value is always true.
+func (m Meta) ContainersHooks() map[string]bool {
+	containerHooks := make(map[string]bool)
+	for containerName := range m.Containers {
+		generateContainerHooks(containerName, containerHooks)
+	}
+	return containerHooks
+}
+
+// The parse functions read metadata that has been coerced by the charm
+// schema, so values should already have the types they expect. As
+// metadata is often taken from untrusted uploads, they check the types
+// regardless, so that a mismatch between schema and parser results in
+// an error rather than a panic.
+
+// unexpectedType returns the error reported when a coerced value does
+// not have the expected type.
+func unexpectedType(field, want string, v interface{}) error {
+	return errors.Errorf("metadata: %s: expected %s, got %T(%#v)", field, want, v, v)
+}
+
+func coercedString(field string, v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", unexpectedType(field, "string", v)
+	}
+	return s, nil
+}
+
+func coercedBool(field string, v interface{}) (bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, unexpectedType(field, "bool", v)
+	}
+	return b, nil
+}
+
+func coercedInt(field string, v interface{}) (int64, error) {
+	i, ok := v.(int64)
+	if !ok {
+		return 0, unexpectedType(field, "int", v)
+	}
+	return i, nil
+}
+
+func coercedMap(field string, v interface{}) (map[string]interface{}, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, unexpectedType(field, "map", v)
+	}
+	return m, nil
+}
+
+func coercedList(field string, v interface{}) ([]interface{}, error) {
+	l, ok := v.([]interface{})
+	if !ok {
+		return nil, unexpectedType(field, "list", v)
+	}
+	return l, nil
+}
+
+// optionalField returns the value of the named field in m, or the zero
+// value if the field is absent. An error is returned if the field holds
+// a value of another type.
+func optionalField[T any](m map[string]interface{}, path, name string) (T, error) {
+	var zero T
+	v := m[name]
+	if v == nil {
+		return zero, nil
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, unexpectedType(joinField(path, name), fmt.Sprintf("%T", zero), v)
+	}
+	return t, nil
+}
+
 // Used for parsing Categories and Tags.
-func parseStringList(list interface{}) []string {
+func parseStringList(field string, list interface{}) ([]string, error) {
 	if list == nil {
-		return nil
+		return nil, nil
+	}
+	slice, err := coercedList(field, list)
+	if err != nil {
+		return nil, err
 	}
-	slice := list.([]interface{})
 	result := make([]string, 0, len(slice))
-	for _, elem := range slice {
-		result = append(result, elem.(string))
+	for i, elem := range slice {
+		s, err := coercedString(fmt.Sprintf("%s[%d]", field, i), elem)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
 	}
-	return result
+	return result, nil
 }
 
-var validTermName = regexp.MustCompile(`^[a-z](-?[a-z0-9]+)+$`)
+var validTermName = lazyRegexp(`^[a-z](-?[a-z0-9]+)+$`)
 
 // TermsId represents a single term id. The term can either be owned
 // or "public" (meaning there is no owner).
@@ -384,17 +700,25 @@ type TermsId struct {
 	Revision int
 }
 
-// Validate returns an error if the Term contains invalid data.
-func (t *TermsId) Validate() error {
-	if t.Tenant != "" && t.Tenant != "cs" {
-		if !validTermName.MatchString(t.Tenant) {
+// Validate returns an error if the Term contains invalid data, or has
+// a tenant not accepted by the given options.
+func (t *TermsId) Validate(options ...TermOption) error {
+	var opts termOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	if t.Tenant != "" {
+		if !validTermName().MatchString(t.Tenant) {
 			return errors.Errorf("wrong term tenant format %q", t.Tenant)
 		}
+		if opts.tenants != nil && !opts.tenants.Contains(t.Tenant) {
+			return errors.Errorf("term tenant %q not accepted", t.Tenant)
+		}
 	}
 	if t.Owner != "" && !names.IsValidUser(t.Owner) {
 		return errors.Errorf("wrong owner format %q", t.Owner)
 	}
-	if !validTermName.MatchString(t.Name) {
+	if !validTermName().MatchString(t.Name) {
 		return errors.Errorf("wrong term name format %q", t.Name)
 	}
 	if t.Revision < 0 {
@@ -430,6 +754,42 @@ func (t *TermsId) String() string {
 	return string(id)
 }
 
+// MarshalYAML implements yaml.Marshaler, writing the term in canonical
+// form.
+func (t TermsId) MarshalYAML() (interface{}, error) {
+	return t.String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, reading the term as
+// ParseTerm does.
+func (t *TermsId) UnmarshalYAML(f func(interface{}) error) error {
+	var s string
+	if err := f(&s); err != nil {
+		return err
+	}
+	term, err := ParseTerm(s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	*t = *term
+	return nil
+}
+
+// TermOption configures the behaviour of ParseTerm and TermsId.Validate.
+type TermOption func(*termOptions)
+
+type termOptions struct {
+	tenants set.Strings
+}
+
+// WithTermTenants makes ParseTerm and TermsId.Validate accept only
+// terms without a tenant or with one of the given tenants. By default any tenant is accepted.
+func WithTermTenants(tenants ...string) TermOption {
+	return func(opts *termOptions) {
+		opts.tenants = set.NewStrings(tenants...)
+	}
+}
+
 // ParseTerm takes a termID as a string and parses it into a Term.
 // A complete term is in the form:
 // tenant:owner/name/revision
@@ -440,7 +800,9 @@ func (t *TermsId) String() string {
 // owner/name/27 # Revision 27
 // name/283 # Revision 283
 // cs:owner/name # Tenant cs
-func ParseTerm(s string) (*TermsId, error) {
+// The owner may be any valid user name, including one qualified by its
+// domain such as "bob@external".
+func ParseTerm(s string, options ...TermOption) (*TermsId, error) {
 	tenant := ""
 	termid := s
 	if t := strings.SplitN(s, ":", 2); len(t) == 2 {
@@ -485,28 +847,136 @@ func ParseTerm(s string) (*TermsId, error) {
 	default:
 		return nil, errors.Errorf("unknown term id format %q", s)
 	}
-	if err := term.Validate(); err != nil {
+	if err := term.Validate(options...); err != nil {
 		return nil, errors.Trace(err)
 	}
 	return &term, nil
 }
 
+// ReadMetaOption configures the behaviour of ReadMeta.
+type ReadMetaOption func(*readMetaOptions)
+
+type readMetaOptions struct {
+	recordUnknown bool
+	warnUnknown   func(field string)
+	checkPayload  bool
+	payloadTypes  []string
+	source        string
+	termOptions   []TermOption
+}
+
+// WithMetaSource gives the name of the source of the metadata, such as
+// the path of the metadata.yaml file, which is recorded in the
+// *YAMLPositionError returned for a problem that can be located.
+func WithMetaSource(name string) ReadMetaOption {
+	return func(opts *readMetaOptions) {
+		opts.source = name
+	}
+}
+
+// WithUnknownFields makes ReadMeta record the top-level fields it does
+// not recognise in Meta.UnknownFields, rather than silently dropping
+// them, so that typos such as "requiers" can be reported.
+func WithUnknownFields() ReadMetaOption {
+	return func(opts *readMetaOptions) {
+		opts.recordUnknown = true
+	}
+}
+
+// WithUnknownFieldWarnings makes ReadMeta call warn with the name of
+// each top-level field it does not recognise, in sorted order.
+func WithUnknownFieldWarnings(warn func(field string)) ReadMetaOption {
+	return func(opts *readMetaOptions) {
+		opts.warnUnknown = warn
+	}
+}
+
+// WithPayloadTypes makes ReadMeta reject payload classes whose type is
+// not one of the given types, or of those returned by
+// DefaultPayloadTypes if none are given. Without it, payload classes of
+// any type are accepted.
+func WithPayloadTypes(types ...string) ReadMetaOption {
+	return func(opts *readMetaOptions) {
+		opts.checkPayload = true
+		opts.payloadTypes = types
+	}
+}
+
+// WithMetaTermOptions makes ReadMeta check that the terms of the charm
+// parse with the given options, as Meta.CheckTerms does.
+func WithMetaTermOptions(options ...TermOption) ReadMetaOption {
+	return func(opts *readMetaOptions) {
+		opts.termOptions = options
+	}
+}
+
 // ReadMeta reads the content of a metadata.yaml file and returns
 // its representation.
-// The data has verified as unambiguous, but not validated.
-func ReadMeta(r io.Reader) (*Meta, error) {
+// The data has verified as unambiguous, but not validated, except that
+// payload classes must not share a name with a container, and must have
+// an accepted type if WithPayloadTypes is given, and terms must parse if
+// WithMetaTermOptions is given.
+func ReadMeta(r io.Reader, options ...ReadMetaOption) (*Meta, error) {
 	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
+	var opts readMetaOptions
+	for _, option := range options {
+		option(&opts)
+	}
 	var meta Meta
 	err = yaml.Unmarshal(data, &meta)
 	if err != nil {
-		return nil, err
+		return nil, yamlPositionError(opts.source, data, "metadata: ", err)
+	}
+	if err := validatePayloadClasses(&meta, opts.checkPayload, opts.payloadTypes); err != nil {
+		return nil, errors.Annotate(err, "metadata")
+	}
+	if opts.termOptions != nil {
+		if err := meta.CheckTerms(opts.termOptions...); err != nil {
+			return nil, errors.Annotate(err, "metadata")
+		}
+	}
+	if opts.recordUnknown || opts.warnUnknown != nil {
+		unknown, err := unknownMetaFields(data)
+		if err != nil {
+			return nil, err
+		}
+		if opts.recordUnknown && len(unknown) > 0 {
+			meta.UnknownFields = unknown
+		}
+		if opts.warnUnknown != nil {
+			fields := make([]string, 0, len(unknown))
+			for field := range unknown {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				opts.warnUnknown(field)
+			}
+		}
 	}
 	return &meta, nil
 }
 
+// unknownMetaFields returns the top-level fields of the metadata.yaml
+// content in data that are not part of the charm schema.
+func unknownMetaFields(data []byte) (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	known := charmSchemaFields()
+	unknown := make(map[string]interface{})
+	for field, value := range raw {
+		if _, ok := known[field]; !ok {
+			unknown[field] = value
+		}
+	}
+	return unknown, nil
+}
+
 // UnmarshalYAML
 func (meta *Meta) UnmarshalYAML(f func(interface{}) error) error {
 	raw := make(map[interface{}]interface{})
@@ -518,13 +988,19 @@ func (meta *Meta) UnmarshalYAML(f func(interface{}) error) error {
 	if err := ensureUnambiguousFormat(raw); err != nil {
 		return err
 	}
+	if err := ensureNoNullKeys("", raw); err != nil {
+		return errors.New("metadata: " + err.Error())
+	}
 
-	v, err := charmSchema.Coerce(raw, nil)
+	v, err := charmSchema().Coerce(raw, nil)
 	if err != nil {
 		return errors.New("metadata: " + err.Error())
 	}
 
-	m := v.(map[string]interface{})
+	m, err := coercedMap("", v)
+	if err != nil {
+		return err
+	}
 	meta1, err := parseMeta(m)
 	if err != nil {
 		return err
@@ -550,35 +1026,61 @@ func parseMeta(m map[string]interface{}) (*Meta, error) {
 	var meta Meta
 	var err error
 
-	meta.Name = m["name"].(string)
-	// Schema decodes as int64, but the int range should be good
-	// enough for revisions.
-	meta.Summary = m["summary"].(string)
-	meta.Description = m["description"].(string)
-	meta.Provides = parseRelations(m["provides"], RoleProvider)
-	meta.Requires = parseRelations(m["requires"], RoleRequirer)
-	meta.Peers = parseRelations(m["peers"], RolePeer)
+	if meta.Name, err = coercedString("name", m["name"]); err != nil {
+		return nil, err
+	}
+	if meta.Summary, err = coercedString("summary", m["summary"]); err != nil {
+		return nil, err
+	}
+	if meta.Description, err = coercedString("description", m["description"]); err != nil {
+		return nil, err
+	}
+	if meta.Provides, err = parseRelations(m["provides"], RoleProvider); err != nil {
+		return nil, err
+	}
+	if meta.Requires, err = parseRelations(m["requires"], RoleRequirer); err != nil {
+		return nil, err
+	}
+	if meta.Peers, err = parseRelations(m["peers"], RolePeer); err != nil {
+		return nil, err
+	}
 	if meta.ExtraBindings, err = parseMetaExtraBindings(m["extra-bindings"]); err != nil {
 		return nil, err
 	}
-	meta.Categories = parseStringList(m["categories"])
-	meta.Tags = parseStringList(m["tags"])
+	if meta.Categories, err = parseStringList("categories", m["categories"]); err != nil {
+		return nil, err
+	}
+	if meta.Tags, err = parseStringList("tags", m["tags"]); err != nil {
+		return nil, err
+	}
 	if subordinate := m["subordinate"]; subordinate != nil {
-		meta.Subordinate = subordinate.(bool)
+		if meta.Subordinate, err = coercedBool("subordinate", subordinate); err != nil {
+			return nil, err
+		}
+	}
+	if meta.Series, err = parseStringList("series", m["series"]); err != nil {
+		return nil, err
+	}
+	if meta.Storage, err = parseStorage(m["storage"]); err != nil {
+		return nil, err
+	}
+	if meta.Devices, err = parseDevices(m["devices"]); err != nil {
+		return nil, err
 	}
-	meta.Series = parseStringList(m["series"])
-	meta.Storage = parseStorage(m["storage"])
-	meta.Devices = parseDevices(m["devices"])
 	meta.Deployment, err = parseDeployment(m["deployment"], meta.Series, meta.Storage)
 	if err != nil {
 		return nil, err
 	}
-	meta.PayloadClasses = parsePayloadClasses(m["payloads"])
+	if meta.PayloadClasses, err = parsePayloadClasses(m); err != nil {
+		return nil, err
+	}
 	meta.MinJujuVersion, err = parseMinJujuVersion(m["min-juju-version"])
 	if err != nil {
 		return nil, err
 	}
-	meta.Terms = parseStringList(m["terms"])
+	if meta.Terms, err = parseStringList("terms", m["terms"]); err != nil {
+		return nil, err
+	}
 
 	meta.Resources, err = parseMetaResources(m["resources"])
 	if err != nil {
@@ -594,6 +1096,12 @@ func parseMeta(m map[string]interface{}) (*Meta, error) {
 	if err != nil {
 		return nil, errors.Annotatef(err, "parsing charm-user")
 	}
+	if meta.Links, err = parseLinks(m["links"]); err != nil {
+		return nil, err
+	}
+	if meta.Secrets, err = parseSecrets(m["secrets"]); err != nil {
+		return nil, err
+	}
 	return &meta, nil
 }
 
@@ -618,14 +1126,18 @@ func (m Meta) MarshalYAML() (interface{}, error) {
 		Tags           []string                         `yaml:"tags,omitempty"`
 		Subordinate    bool                             `yaml:"subordinate,omitempty"`
 		Series         []string                         `yaml:"series,omitempty"`
-		Storage        map[string]Storage               `yaml:"storage,omitempty"`
-		Devices        map[string]Device                `yaml:"devices,omitempty"`
-		Deployment     *Deployment                      `yaml:"deployment,omitempty"`
+		Storage        map[string]marshaledStorage      `yaml:"storage,omitempty"`
+		Devices        map[string]marshaledDevice       `yaml:"devices,omitempty"`
+		Deployment     *marshaledDeployment             `yaml:"deployment,omitempty"`
+		Payloads       map[string]marshaledPayloadClass `yaml:"payloads,omitempty"`
 		Terms          []string                         `yaml:"terms,omitempty"`
 		MinJujuVersion string                           `yaml:"min-juju-version,omitempty"`
 		Resources      map[string]marshaledResourceMeta `yaml:"resources,omitempty"`
 		Containers     map[string]marshaledContainer    `yaml:"containers,omitempty"`
 		Assumes        *assumes.ExpressionTree          `yaml:"assumes,omitempty"`
+		CharmUser      RunAs                            `yaml:"charm-user,omitempty"`
+		Links          *Links                           `yaml:"links,omitempty"`
+		Secrets        map[string]Secret                `yaml:"secrets,omitempty"`
 	}{
 		Name:           m.Name,
 		Summary:        m.Summary,
@@ -638,21 +1150,133 @@ func (m Meta) MarshalYAML() (interface{}, error) {
 		Tags:           m.Tags,
 		Subordinate:    m.Subordinate,
 		Series:         m.Series,
-		Storage:        m.Storage,
-		Devices:        m.Devices,
-		Deployment:     m.Deployment,
+		Storage:        marshaledStores(m.Storage),
+		Devices:        marshaledDevices(m.Devices),
+		Deployment:     (*marshaledDeployment)(m.Deployment),
+		Payloads:       marshaledPayloadClasses(m.PayloadClasses),
 		Terms:          m.Terms,
 		MinJujuVersion: minver,
 		Resources:      marshaledResources(m.Resources),
 		Containers:     marshaledContainers(m.Containers),
 		Assumes:        m.Assumes,
+		CharmUser:      m.CharmUser,
+		Links:          m.Links,
+		Secrets:        m.Secrets,
+	}, nil
+}
+
+type marshaledStorage Storage
+
+func marshaledStores(stores map[string]Storage) map[string]marshaledStorage {
+	marshaled := make(map[string]marshaledStorage)
+	for name, store := range stores {
+		marshaled[name] = marshaledStorage(store)
+	}
+	return marshaled
+}
+
+func (s marshaledStorage) MarshalYAML() (interface{}, error) {
+	ms := struct {
+		Type        StorageType `yaml:"type"`
+		Description string      `yaml:"description,omitempty"`
+		Shared      bool        `yaml:"shared,omitempty"`
+		ReadOnly    bool        `yaml:"read-only,omitempty"`
+		Multiple    *struct {
+			Range string `yaml:"range"`
+		} `yaml:"multiple,omitempty"`
+		MinimumSize string   `yaml:"minimum-size,omitempty"`
+		MaximumSize string   `yaml:"maximum-size,omitempty"`
+		Location    string   `yaml:"location,omitempty"`
+		Properties  []string `yaml:"properties,omitempty"`
+	}{
+		Type:        s.Type,
+		Description: s.Description,
+		Shared:      s.Shared,
+		ReadOnly:    s.ReadOnly,
+		Location:    s.Location,
+		Properties:  s.Properties,
+	}
+	// See storageCountC for the accepted forms of the range.
+	if s.CountMin != 1 || s.CountMax != 1 {
+		ms.Multiple = &struct {
+			Range string `yaml:"range"`
+		}{}
+		switch {
+		case s.CountMax < 0:
+			ms.Multiple.Range = fmt.Sprintf("%d+", s.CountMin)
+		case s.CountMin == s.CountMax:
+			ms.Multiple.Range = fmt.Sprint(s.CountMin)
+		default:
+			ms.Multiple.Range = fmt.Sprintf("%d-%d", s.CountMin, s.CountMax)
+		}
+	}
+	if s.MinimumSize > 0 {
+		ms.MinimumSize = fmt.Sprintf("%dM", s.MinimumSize)
+	}
+	if s.MaximumSize > 0 {
+		ms.MaximumSize = fmt.Sprintf("%dM", s.MaximumSize)
+	}
+	return ms, nil
+}
+
+type marshaledDevice Device
+
+func marshaledDevices(devices map[string]Device) map[string]marshaledDevice {
+	marshaled := make(map[string]marshaledDevice)
+	for name, device := range devices {
+		marshaled[name] = marshaledDevice(device)
+	}
+	return marshaled
+}
+
+func (d marshaledDevice) MarshalYAML() (interface{}, error) {
+	return struct {
+		Description string     `yaml:"description,omitempty"`
+		Type        DeviceType `yaml:"type"`
+		CountMin    int64      `yaml:"countmin"`
+		CountMax    int64      `yaml:"countmax"`
+	}{
+		Description: d.Description,
+		Type:        d.Type,
+		CountMin:    d.CountMin,
+		CountMax:    d.CountMax,
 	}, nil
 }
 
+type marshaledDeployment Deployment
+
+func (d marshaledDeployment) MarshalYAML() (interface{}, error) {
+	return struct {
+		DeploymentType DeploymentType `yaml:"type,omitempty"`
+		DeploymentMode DeploymentMode `yaml:"mode,omitempty"`
+		ServiceType    ServiceType    `yaml:"service,omitempty"`
+		MinVersion     string         `yaml:"min-version,omitempty"`
+	}{
+		DeploymentType: d.DeploymentType,
+		DeploymentMode: d.DeploymentMode,
+		ServiceType:    d.ServiceType,
+		MinVersion:     d.MinVersion,
+	}, nil
+}
+
+type marshaledPayloadClass struct {
+	Type string `yaml:"type"`
+}
+
+func marshaledPayloadClasses(classes map[string]PayloadClass) map[string]marshaledPayloadClass {
+	marshaled := make(map[string]marshaledPayloadClass)
+	for name, class := range classes {
+		marshaled[name] = marshaledPayloadClass{Type: class.Type}
+	}
+	return marshaled
+}
+
 type marshaledResourceMeta struct {
 	Path        string `yaml:"filename"` // TODO(ericsnow) Change to "path"?
 	Type        string `yaml:"type,omitempty"`
 	Description string `yaml:"description,omitempty"`
+	URL         string `yaml:"url,omitempty"`
+	SHA256      string `yaml:"sha256,omitempty"`
 }
 
 func marshaledResources(rs map[string]resource.Meta) map[string]marshaledResourceMeta {
@@ -661,6 +1285,8 @@ func marshaledResources(rs map[string]resource.Meta) map[string]marshaledResourc
 		r1 := marshaledResourceMeta{
 			Path:        r.Path,
 			Description: r.Description,
+			URL:         r.URL,
+			SHA256:      r.SHA256,
 		}
 		if r.Type != resource.TypeFile {
 			r1.Type = r.Type.String()
@@ -708,7 +1334,15 @@ func (r marshaledRelation) MarshalYAML() (interface{}, error) {
 func marshaledExtraBindings(bindings map[string]ExtraBinding) map[string]interface{} {
 	marshaled := make(map[string]interface{})
 	for _, binding := range bindings {
-		marshaled[binding.Name] = nil
+		// Bindings without a description marshal as nulls, which
+		// older readers of the metadata require.
+		if binding.Description == "" {
+			marshaled[binding.Name] = nil
+			continue
+		}
+		marshaled[binding.Name] = map[string]string{
+			"description": binding.Description,
+		}
 	}
 	return marshaled
 }
@@ -725,11 +1359,21 @@ func marshaledContainers(c map[string]Container) map[string]marshaledContainer {
 
 func (c marshaledContainer) MarshalYAML() (interface{}, error) {
 	mc := struct {
-		Resource string  `yaml:"resource,omitempty"`
-		Mounts   []Mount `yaml:"mounts,omitempty"`
+		Resource string            `yaml:"resource,omitempty"`
+		Mounts   []Mount           `yaml:"mounts,omitempty"`
+		Uid      int               `yaml:"uid,omitempty"`
+		Gid      int               `yaml:"gid,omitempty"`
+		Command  []string          `yaml:"command,omitempty"`
+		Args     []string          `yaml:"args,omitempty"`
+		Env      map[string]string `yaml:"env,omitempty"`
 	}{
 		Resource: c.Resource,
 		Mounts:   c.Mounts,
+		Uid:      c.Uid,
+		Gid:      c.Gid,
+		Command:  c.Command,
+		Args:     c.Args,
+		Env:      c.Env,
 	}
 	return mc, nil
 }
@@ -744,8 +1388,25 @@ const (
 	FormatV2      Format = iota
 )
 
+// CheckTerms checks that each of the terms of the charm parses with
+// the given options.
+func (m Meta) CheckTerms(options ...TermOption) error {
+	for _, term := range m.Terms {
+		if _, err := ParseTerm(term, options...); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Check checks that the metadata is well-formed.
 func (m Meta) Check(format Format, reasons ...FormatSelectionReason) error {
+	// Illegal combinations of fields are reported first, as they would
+	// otherwise show up as less helpful format errors.
+	if err := m.checkCombinations(); err != nil {
+		return errors.Trace(err)
+	}
+
 	switch format {
 	case FormatV1:
 		err := m.checkV1(reasons)
@@ -774,12 +1435,12 @@ func (m Meta) Check(format Format, reasons ...FormatSelectionReason) error {
 			// Container-scoped require relations on subordinates are allowed
 			// to use the otherwise-reserved juju-* namespace.
 			if !m.Subordinate || role != RoleRequirer || rel.Scope != ScopeContainer {
-				if reserved, _ := reservedName(m.Name, name); reserved {
+				if reserved, _ := IsReservedEndpointName(m.Name, name); reserved {
 					return errors.Errorf("charm %q using a reserved relation name: %q", m.Name, name)
 				}
 			}
 			if role != RoleRequirer {
-				if reserved, _ := reservedName(m.Name, rel.Interface); reserved {
+				if reserved, _ := IsReservedEndpointName(m.Name, rel.Interface); reserved {
 					return errors.Errorf("charm %q relation %q using a reserved interface: %q", m.Name, name, rel.Interface)
 				}
 			}
@@ -803,6 +1464,11 @@ func (m Meta) Check(format Format, reasons ...FormatSelectionReason) error {
 	if err := validateMetaExtraBindings(m); err != nil {
 		return errors.Errorf("charm %q has invalid extra bindings: %v", m.Name, err)
 	}
+	for name := range m.ExtraBindings {
+		if reserved, _ := IsReservedExtraBindingName(m.Name, name); reserved {
+			return errors.Errorf("charm %q using a reserved extra binding name: %q", m.Name, name)
+		}
+	}
 
 	// Subordinate charms must have at least one relation that
 	// has container scope, otherwise they can't relate to the
@@ -830,18 +1496,36 @@ func (m Meta) Check(format Format, reasons ...FormatSelectionReason) error {
 
 	names = make(map[string]bool)
 	for name, store := range m.Storage {
-		if store.Location != "" && store.Type != StorageFilesystem {
+		if store.Location != "" && !store.Type.IsFilesystem() {
 			return errors.Errorf(`charm %q storage %q: location may not be specified for "type: %s"`, m.Name, name, store.Type)
 		}
 		if store.Type == "" {
 			return errors.Errorf("charm %q storage %q: type must be specified", m.Name, name)
 		}
+		if reserved, reason := IsReservedStorageName(m.Name, name); reserved {
+			return errors.Errorf("charm %q storage %q: %s", m.Name, name, reason)
+		}
 		if store.CountMin < 0 {
 			return errors.Errorf("charm %q storage %q: invalid minimum count %d", m.Name, name, store.CountMin)
 		}
 		if store.CountMax == 0 || store.CountMax < -1 {
 			return errors.Errorf("charm %q storage %q: invalid maximum count %d", m.Name, name, store.CountMax)
 		}
+		if store.CountMax != -1 && store.CountMin > store.CountMax {
+			return errors.Errorf(
+				"charm %q storage %q: maximum count %d can not be smaller than minimum count %d",
+				m.Name, name, store.CountMax, store.CountMin)
+		}
+		if store.MaximumSize > 0 && store.MaximumSize < store.MinimumSize {
+			return errors.Errorf(
+				"charm %q storage %q: maximum size %dM can not be smaller than minimum size %dM",
+				m.Name, name, store.MaximumSize, store.MinimumSize)
+		}
+		if store.Type == StorageTmpfs {
+			if err := checkTmpfsStorage(store); err != nil {
+				return errors.Errorf("charm %q storage %q: %v", m.Name, name, err)
+			}
+		}
 		if names[name] {
 			return errors.Errorf("charm %q storage %q: duplicated storage name", m.Name, name)
 		}
@@ -853,6 +1537,9 @@ func (m Meta) Check(format Format, reasons ...FormatSelectionReason) error {
 		if device.Type == "" {
 			return errors.Errorf("charm %q device %q: type must be specified", m.Name, name)
 		}
+		if err := ValidateDeviceType(device.Type); err != nil {
+			return errors.Errorf("charm %q device %q: %v", m.Name, name, err)
+		}
 		if device.CountMax >= 0 && device.CountMin >= 0 && device.CountMin > device.CountMax {
 			return errors.Errorf(
 				"charm %q device %q: maximum count %d can not be smaller than minimum count %d",
@@ -864,6 +1551,10 @@ func (m Meta) Check(format Format, reasons ...FormatSelectionReason) error {
 		names[name] = true
 	}
 
+	if err := m.checkContainers(); err != nil {
+		return errors.Trace(err)
+	}
+
 	for name, payloadClass := range m.PayloadClasses {
 		if payloadClass.Name != name {
 			return errors.Errorf("mismatch on payload class name (%q != %q)", payloadClass.Name, name)
@@ -877,15 +1568,102 @@ func (m Meta) Check(format Format, reasons ...FormatSelectionReason) error {
 		return err
 	}
 
-	for _, term := range m.Terms {
-		if _, terr := ParseTerm(term); terr != nil {
-			return errors.Trace(terr)
+	if err := m.CheckTerms(); err != nil {
+		return errors.Trace(err)
+	}
+
+	if m.Deployment != nil {
+		if _, err := m.Deployment.ParseMinVersion(); err != nil {
+			return errors.Trace(err)
 		}
 	}
 
 	return nil
 }
 
+// checkCombinations checks for fields that are valid on their own but
+// may not be used together, reporting all such combinations at once.
+// Sidecar charms, which declare containers, cannot be subordinates or
+// use the older pod spec deployment settings, and subordinates, which
+// run alongside their principal, cannot request devices.
+func (m Meta) checkCombinations() error {
+	var problems []string
+	if m.Subordinate && len(m.Containers) > 0 {
+		problems = append(problems, "subordinate charms cannot declare containers")
+	}
+	if m.Subordinate && len(m.Devices) > 0 {
+		problems = append(problems, "subordinate charms cannot declare devices")
+	}
+	if m.Deployment != nil && len(m.Containers) > 0 {
+		problems = append(problems, "charms declaring containers cannot declare deployment")
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.NewNotValid(nil, fmt.Sprintf("charm %q: %s", m.Name, strings.Join(problems, "; ")))
+}
+
+// checkTmpfsStorage checks the rules specific to tmpfs storage, which
+// is held in memory and so must be bounded, and is local to each unit.
+func checkTmpfsStorage(store Storage) error {
+	switch {
+	case store.Shared:
+		return errors.New("tmpfs storage may not be shared")
+	case store.CountMax == -1:
+		return errors.New("tmpfs storage must have a bounded maximum count")
+	case store.MaximumSize == 0:
+		return errors.New("tmpfs storage must specify a maximum size")
+	}
+	return nil
+}
+
+// checkContainers checks that the resources and storage referenced by
+// the charm's containers exist, that only filesystem storage is mounted,
+// and that no two mounts in a container share a location. A mount may
+// not use the location of a different storage in the charm container
+// either, as the workload would then see the wrong filesystem at that
+// path.
+func (m Meta) checkContainers() error {
+	storageLocations := make(map[string]string)
+	for name, store := range m.Storage {
+		if store.Location != "" {
+			storageLocations[path.Clean(store.Location)] = name
+		}
+	}
+	for name, container := range m.Containers {
+		if container.Resource != "" {
+			r, ok := m.Resources[container.Resource]
+			if !ok {
+				return errors.Errorf("charm %q container %q: referenced resource %q not found", m.Name, name, container.Resource)
+			}
+			if r.Type != resource.TypeContainerImage {
+				return errors.Errorf("charm %q container %q: referenced resource %q is not a %s",
+					m.Name, name, container.Resource, resource.TypeContainerImage)
+			}
+		}
+		locations := make(map[string]bool)
+		for _, mount := range container.Mounts {
+			store, ok := m.Storage[mount.Storage]
+			if !ok {
+				return errors.Errorf("charm %q container %q: mount references unknown storage %q", m.Name, name, mount.Storage)
+			}
+			if !store.Type.IsFilesystem() {
+				return errors.Errorf("charm %q container %q: cannot mount storage %q of type %s", m.Name, name, mount.Storage, store.Type)
+			}
+			location := path.Clean(mount.Location)
+			if locations[location] {
+				return errors.Errorf("charm %q container %q: duplicate mount location %q", m.Name, name, mount.Location)
+			}
+			locations[location] = true
+			if other, ok := storageLocations[location]; ok && other != mount.Storage {
+				return errors.Errorf("charm %q container %q: mount location %q of storage %q collides with the location of storage %q",
+					m.Name, name, mount.Location, mount.Storage, other)
+			}
+		}
+	}
+	return nil
+}
+
 func (m Meta) checkV1(reasons []FormatSelectionReason) error {
 	if m.Assumes != nil {
 		return errors.NotValidf("assumes in metadata v1")
@@ -922,43 +1700,59 @@ func hasReason(reasons []FormatSelectionReason, reason FormatSelectionReason) bo
 	return set.NewStrings(reasons...).Contains(reason)
 }
 
-func reservedName(charmName, endpointName string) (reserved bool, reason string) {
-	if strings.HasPrefix(charmName, "juju-") {
-		return false, ""
-	}
-	if endpointName == "juju" {
-		return true, `"juju" is a reserved name`
-	}
-	if strings.HasPrefix(endpointName, "juju-") {
-		return true, `the "juju-" prefix is reserved`
-	}
-	return false, ""
-}
-
-func parseRelations(relations interface{}, role RelationRole) map[string]Relation {
+func parseRelations(relations interface{}, role RelationRole) (map[string]Relation, error) {
 	if relations == nil {
-		return nil
+		return nil, nil
+	}
+	section := roleSections[role]
+	relationsMap, err := coercedMap(section, relations)
+	if err != nil {
+		return nil, err
 	}
 	result := make(map[string]Relation)
-	for name, rel := range relations.(map[string]interface{}) {
-		relMap := rel.(map[string]interface{})
+	for name, rel := range relationsMap {
+		field := section + "." + name
+		relMap, err := coercedMap(field, rel)
+		if err != nil {
+			return nil, err
+		}
 		relation := Relation{
-			Name:      name,
-			Role:      role,
-			Interface: relMap["interface"].(string),
-			Optional:  relMap["optional"].(bool),
+			Name: name,
+			Role: role,
+		}
+		if relation.Interface, err = coercedString(field+".interface", relMap["interface"]); err != nil {
+			return nil, err
+		}
+		if relation.Optional, err = coercedBool(field+".optional", relMap["optional"]); err != nil {
+			return nil, err
 		}
 		if scope := relMap["scope"]; scope != nil {
-			relation.Scope = RelationScope(scope.(string))
+			s, err := coercedString(field+".scope", scope)
+			if err != nil {
+				return nil, err
+			}
+			relation.Scope = RelationScope(s)
 		}
-		if relMap["limit"] != nil {
+		if limit := relMap["limit"]; limit != nil {
 			// Schema defaults to int64, but we know
 			// the int range should be more than enough.
-			relation.Limit = int(relMap["limit"].(int64))
+			l, err := coercedInt(field+".limit", limit)
+			if err != nil {
+				return nil, err
+			}
+			relation.Limit = int(l)
 		}
 		result[name] = relation
 	}
-	return result
+	return result, nil
+}
+
+// roleSections holds the metadata section declaring relations of each
+// role.
+var roleSections = map[RelationRole]string{
+	RoleProvider: "provides",
+	RoleRequirer: "requires",
+	RolePeer:     "peers",
 }
 
 // CombinedRelations returns all defined relations, regardless of their type in
@@ -1026,90 +1820,164 @@ func (c ifaceExpC) Coerce(v interface{}, path []string) (newv interface{}, err e
 	if err != nil {
 		return
 	}
-	m := v.(map[string]interface{})
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("%s: expected map, got %T(%#v)", strings.Join(path[1:], ""), v, v)
+	}
 	if _, ok := m["limit"]; !ok {
 		m["limit"] = c.limit
 	}
-	return ifaceSchema.Coerce(m, path)
+	return ifaceSchema().Coerce(m, path)
 }
 
-var ifaceSchema = schema.FieldMap(
-	schema.Fields{
-		"interface": schema.String(),
-		"limit":     schema.OneOf(schema.Const(nil), schema.Int()),
-		"scope":     schema.OneOf(schema.Const(string(ScopeGlobal)), schema.Const(string(ScopeContainer))),
-		"optional":  schema.Bool(),
-	},
-	schema.Defaults{
-		"scope":    string(ScopeGlobal),
-		"optional": false,
-	},
-)
-
-func parseStorage(stores interface{}) map[string]Storage {
+var ifaceSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"interface": schema.String(),
+			"limit":     schema.OneOf(schema.Const(nil), schema.Int()),
+			"scope":     schema.OneOf(schema.Const(string(ScopeGlobal)), schema.Const(string(ScopeContainer))),
+			"optional":  schema.Bool(),
+		},
+		schema.Defaults{
+			"scope":    string(ScopeGlobal),
+			"optional": false,
+		},
+	)
+})
+
+func parseStorage(stores interface{}) (map[string]Storage, error) {
 	if stores == nil {
-		return nil
+		return nil, nil
+	}
+	storesMap, err := coercedMap("storage", stores)
+	if err != nil {
+		return nil, err
 	}
 	result := make(map[string]Storage)
-	for name, store := range stores.(map[string]interface{}) {
-		storeMap := store.(map[string]interface{})
+	for name, store := range storesMap {
+		field := "storage." + name
+		storeMap, err := coercedMap(field, store)
+		if err != nil {
+			return nil, err
+		}
+		storageType, err := coercedString(field+".type", storeMap["type"])
+		if err != nil {
+			return nil, err
+		}
 		store := Storage{
 			Name:     name,
-			Type:     StorageType(storeMap["type"].(string)),
-			Shared:   storeMap["shared"].(bool),
-			ReadOnly: storeMap["read-only"].(bool),
+			Type:     StorageType(storageType),
 			CountMin: 1,
 			CountMax: 1,
 		}
-		if desc, ok := storeMap["description"].(string); ok {
-			store.Description = desc
+		if store.Shared, err = coercedBool(field+".shared", storeMap["shared"]); err != nil {
+			return nil, err
+		}
+		if store.ReadOnly, err = coercedBool(field+".read-only", storeMap["read-only"]); err != nil {
+			return nil, err
+		}
+		if store.Description, err = optionalField[string](storeMap, field, "description"); err != nil {
+			return nil, err
+		}
+		multiple, err := optionalField[map[string]interface{}](storeMap, field, "multiple")
+		if err != nil {
+			return nil, err
 		}
-		if multiple, ok := storeMap["multiple"].(map[string]interface{}); ok {
-			if r, ok := multiple["range"].([2]int); ok {
+		if multiple != nil {
+			r, err := optionalField[[2]int](multiple, field+".multiple", "range")
+			if err != nil {
+				return nil, err
+			}
+			if multiple["range"] != nil {
 				store.CountMin, store.CountMax = r[0], r[1]
 			}
 		}
-		if minSize, ok := storeMap["minimum-size"].(uint64); ok {
-			store.MinimumSize = minSize
+		// A count is shorthand for a multiple range of that fixed size.
+		if storeMap["count"] != nil {
+			if multiple != nil {
+				return nil, errors.Errorf("storage %q: count and multiple may not both be specified", name)
+			}
+			count, err := optionalField[int64](storeMap, field, "count")
+			if err != nil {
+				return nil, err
+			}
+			store.CountMin, store.CountMax = int(count), int(count)
 		}
-		if loc, ok := storeMap["location"].(string); ok {
-			store.Location = loc
+		if store.MinimumSize, err = optionalField[uint64](storeMap, field, "minimum-size"); err != nil {
+			return nil, err
 		}
-		if properties, ok := storeMap["properties"].([]interface{}); ok {
-			for _, p := range properties {
-				store.Properties = append(store.Properties, p.(string))
+		if store.MaximumSize, err = optionalField[uint64](storeMap, field, "maximum-size"); err != nil {
+			return nil, err
+		}
+		if store.Location, err = optionalField[string](storeMap, field, "location"); err != nil {
+			return nil, err
+		}
+		if properties := storeMap["properties"]; properties != nil {
+			if store.Properties, err = parseStringList(field+".properties", properties); err != nil {
+				return nil, err
 			}
 		}
 		result[name] = store
 	}
-	return result
+	return result, nil
 }
 
-func parseDevices(devices interface{}) map[string]Device {
+func parseDevices(devices interface{}) (map[string]Device, error) {
 	if devices == nil {
-		return nil
+		return nil, nil
+	}
+	devicesMap, err := coercedMap("devices", devices)
+	if err != nil {
+		return nil, err
 	}
 	result := make(map[string]Device)
-	for name, device := range devices.(map[string]interface{}) {
-		deviceMap := device.(map[string]interface{})
+	for name, device := range devicesMap {
+		field := "devices." + name
+		deviceMap, err := coercedMap(field, device)
+		if err != nil {
+			return nil, err
+		}
+		deviceType, err := coercedString(field+".type", deviceMap["type"])
+		if err != nil {
+			return nil, err
+		}
 		device := Device{
 			Name:     name,
-			Type:     DeviceType(deviceMap["type"].(string)),
+			Type:     DeviceType(deviceType),
 			CountMin: 1,
 			CountMax: 1,
 		}
-		if desc, ok := deviceMap["description"].(string); ok {
-			device.Description = desc
+		if device.Description, err = optionalField[string](deviceMap, field, "description"); err != nil {
+			return nil, err
 		}
-		if countmin, ok := deviceMap["countmin"].(int64); ok {
-			device.CountMin = countmin
+		for _, count := range []struct {
+			name string
+			dest *int64
+		}{
+			{"countmin", &device.CountMin},
+			{"countmax", &device.CountMax},
+		} {
+			if deviceMap[count.name] == nil {
+				continue
+			}
+			if *count.dest, err = optionalField[int64](deviceMap, field, count.name); err != nil {
+				return nil, err
+			}
 		}
-		if countmax, ok := deviceMap["countmax"].(int64); ok {
-			device.CountMax = countmax
+		// A count is shorthand for equal minimum and maximum counts.
+		if deviceMap["count"] != nil {
+			if deviceMap["countmin"] != nil || deviceMap["countmax"] != nil {
+				return nil, errors.Errorf("device %q: count may not be specified with countmin or countmax", name)
+			}
+			count, err := optionalField[int64](deviceMap, field, "count")
+			if err != nil {
+				return nil, err
+			}
+			device.CountMin, device.CountMax = count, count
 		}
 		result[name] = device
 	}
-	return result
+	return result, nil
 }
 
 func parseDeployment(deployment interface{}, charmSeries []string, storage map[string]Storage) (*Deployment, error) {
@@ -1122,7 +1990,10 @@ func parseDeployment(deployment interface{}, charmSeries []string, storage map[s
 	if charmSeries[0] != kubernetes {
 		return nil, errors.Errorf("charms with deployment metadata only supported for %q", kubernetes)
 	}
-	deploymentMap := deployment.(map[string]interface{})
+	deploymentMap, err := coercedMap("deployment", deployment)
+	if err != nil {
+		return nil, err
+	}
 	var result Deployment
 	if deploymentType, ok := deploymentMap["type"].(string); ok {
 		result.DeploymentType = DeploymentType(deploymentType)
@@ -1135,6 +2006,9 @@ func parseDeployment(deployment interface{}, charmSeries []string, storage map[s
 	}
 	if minVersion, ok := deploymentMap["min-version"].(string); ok {
 		result.MinVersion = minVersion
+		if _, err := result.ParseMinVersion(); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 	if result.ServiceType != "" {
 		osForSeries, err := series.GetOSFromSeries(charmSeries[0])
@@ -1161,13 +2035,23 @@ func parseContainers(input interface{}, resources map[string]resource.Meta, stor
 	if input == nil {
 		return nil, nil
 	}
+	containersMap, err := coercedMap("containers", input)
+	if err != nil {
+		return nil, err
+	}
 	containers := map[string]Container{}
-	for name, v := range input.(map[string]interface{}) {
-		containerMap := v.(map[string]interface{})
+	for name, v := range containersMap {
+		field := "containers." + name
+		containerMap, err := coercedMap(field, v)
+		if err != nil {
+			return nil, err
+		}
 		container := Container{}
 
 		if value, ok := containerMap["resource"]; ok {
-			container.Resource = value.(string)
+			if container.Resource, err = coercedString(field+".resource", value); err != nil {
+				return nil, err
+			}
 		}
 		if container.Resource != "" {
 			if r, ok := resources[container.Resource]; !ok {
@@ -1179,26 +2063,49 @@ func parseContainers(input interface{}, resources map[string]resource.Meta, stor
 			}
 		}
 
-		container.Mounts, err = parseMounts(containerMap["mounts"], storage)
+		container.Mounts, err = parseMounts(field+".mounts", containerMap["mounts"], storage)
 		if err != nil {
 			return nil, errors.Annotatef(err, "container %q", name)
 		}
 
 		if value, ok := containerMap["uid"]; ok {
-			container.Uid = int(value.(int64))
+			uid, err := coercedInt(field+".uid", value)
+			if err != nil {
+				return nil, err
+			}
+			container.Uid = int(uid)
 			if container.Uid >= 1000 && container.Uid < 10000 {
 				return nil, errors.Errorf("container %q has invalid uid %d: uid cannot be in reserved range 1000-9999",
 					name, container.Uid)
 			}
 		}
 		if value, ok := containerMap["gid"]; ok {
-			container.Gid = int(value.(int64))
+			gid, err := coercedInt(field+".gid", value)
+			if err != nil {
+				return nil, err
+			}
+			container.Gid = int(gid)
 			if container.Gid >= 1000 && container.Gid < 10000 {
 				return nil, errors.Errorf("container %q has invalid gid %d: gid cannot be in reserved range 1000-9999",
 					name, container.Gid)
 			}
 		}
 
+		if container.Command, err = parseStringList(field+".command", containerMap["command"]); err != nil {
+			return nil, err
+		}
+		for _, arg := range container.Command {
+			if arg == "" {
+				return nil, errors.NotValidf("container %q with empty command element", name)
+			}
+		}
+		if container.Args, err = parseStringList(field+".args", containerMap["args"]); err != nil {
+			return nil, err
+		}
+		if container.Env, err = parseContainerEnv(field+".env", containerMap["env"]); err != nil {
+			return nil, errors.Annotatef(err, "container %q", name)
+		}
+
 		containers[name] = container
 	}
 	if len(containers) == 0 {
@@ -1207,19 +2114,51 @@ func parseContainers(input interface{}, resources map[string]resource.Meta, stor
 	return containers, nil
 }
 
-func parseMounts(input interface{}, storage map[string]Storage) ([]Mount, error) {
+// validEnvName matches the names of environment variables that may be
+// set for a container.
+var validEnvName = lazyRegexp(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func parseContainerEnv(field string, input interface{}) (map[string]string, error) {
+	if input == nil {
+		return nil, nil
+	}
+	envMap, err := coercedMap(field, input)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(envMap))
+	for name, v := range envMap {
+		if !validEnvName().MatchString(name) {
+			return nil, errors.NotValidf("environment variable name %q", name)
+		}
+		if env[name], err = coercedString(field+"."+name, v); err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
+func parseMounts(field string, input interface{}, storage map[string]Storage) ([]Mount, error) {
 	if input == nil {
 		return nil, nil
 	}
+	mountsList, err := coercedList(field, input)
+	if err != nil {
+		return nil, err
+	}
 	mounts := []Mount(nil)
-	for _, v := range input.([]interface{}) {
+	for i, v := range mountsList {
 		mount := Mount{}
-		mountMap := v.(map[string]interface{})
-		if value, ok := mountMap["storage"].(string); ok {
-			mount.Storage = value
+		mountField := fmt.Sprintf("%s[%d]", field, i)
+		mountMap, err := coercedMap(mountField, v)
+		if err != nil {
+			return nil, err
 		}
-		if value, ok := mountMap["location"].(string); ok {
-			mount.Location = value
+		if mount.Storage, err = optionalField[string](mountMap, mountField, "storage"); err != nil {
+			return nil, err
+		}
+		if mount.Location, err = optionalField[string](mountMap, mountField, "location"); err != nil {
+			return nil, err
 		}
 		if mount.Storage == "" {
 			return nil, errors.Errorf("storage must be specifed on mount")
@@ -1239,7 +2178,11 @@ func parseMinJujuVersion(value any) (version.Number, error) {
 	if value == nil {
 		return version.Zero, nil
 	}
-	ver, err := version.Parse(value.(string))
+	s, err := coercedString("min-juju-version", value)
+	if err != nil {
+		return version.Zero, err
+	}
+	ver, err := version.Parse(s)
 	if err != nil {
 		return version.Zero, errors.Annotate(err, "invalid min-juju-version")
 	}
@@ -1250,7 +2193,11 @@ func parseCharmUser(value any) (RunAs, error) {
 	if value == nil {
 		return RunAsDefault, nil
 	}
-	v := RunAs(value.(string))
+	s, err := coercedString("charm-user", value)
+	if err != nil {
+		return RunAsDefault, err
+	}
+	v := RunAs(s)
 	switch v {
 	case RunAsRoot, RunAsSudoer, RunAsNonRoot:
 		return v, nil
@@ -1260,45 +2207,74 @@ func parseCharmUser(value any) (RunAs, error) {
 	}
 }
 
-var storageSchema = schema.FieldMap(
-	schema.Fields{
-		"type":      schema.OneOf(schema.Const(string(StorageBlock)), schema.Const(string(StorageFilesystem))),
-		"shared":    schema.Bool(),
-		"read-only": schema.Bool(),
-		"multiple": schema.FieldMap(
-			schema.Fields{
-				"range": storageCountC{}, // m, m-n, m+, m-
-			},
-			schema.Defaults{},
-		),
-		"minimum-size": storageSizeC{},
-		"location":     schema.String(),
-		"description":  schema.String(),
-		"properties":   schema.List(propertiesC{}),
-	},
-	schema.Defaults{
-		"shared":       false,
-		"read-only":    false,
-		"multiple":     schema.Omit,
-		"location":     schema.Omit,
-		"description":  schema.Omit,
-		"properties":   schema.Omit,
-		"minimum-size": schema.Omit,
-	},
-)
-
-var deviceSchema = schema.FieldMap(
-	schema.Fields{
-		"description": schema.String(),
-		"type":        schema.String(),
-		"countmin":    deviceCountC{},
-		"countmax":    deviceCountC{},
-	}, schema.Defaults{
-		"description": schema.Omit,
-		"countmin":    schema.Omit,
-		"countmax":    schema.Omit,
-	},
-)
+var storageSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"type": schema.OneOf(
+				schema.Const(string(StorageBlock)),
+				schema.Const(string(StorageFilesystem)),
+				schema.Const(string(StorageTmpfs)),
+			),
+			"shared":    schema.Bool(),
+			"read-only": schema.Bool(),
+			"multiple": schema.FieldMap(
+				schema.Fields{
+					"range": storageCountC{}, // m, m-n, m+, m-
+				},
+				schema.Defaults{},
+			),
+			"count":        fixedCountC{},
+			"minimum-size": storageSizeC{},
+			"maximum-size": storageSizeC{},
+			"location":     schema.String(),
+			"description":  schema.String(),
+			"properties":   schema.List(propertiesC{}),
+		},
+		schema.Defaults{
+			"shared":       false,
+			"read-only":    false,
+			"multiple":     schema.Omit,
+			"location":     schema.Omit,
+			"description":  schema.Omit,
+			"properties":   schema.Omit,
+			"count":        schema.Omit,
+			"minimum-size": schema.Omit,
+			"maximum-size": schema.Omit,
+		},
+	)
+})
+
+var deviceSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"description": schema.String(),
+			"type":        schema.String(),
+			"count":       fixedCountC{},
+			"countmin":    deviceCountC{},
+			"countmax":    deviceCountC{},
+		}, schema.Defaults{
+			"description": schema.Omit,
+			"count":       schema.Omit,
+			"countmin":    schema.Omit,
+			"countmax":    schema.Omit,
+		},
+	)
+})
+
+// envValueC checks the value of a container environment variable. As
+// values such as "PORT: 8080" are read as numbers, any scalar is
+// accepted and converted to a string.
+type envValueC struct{}
+
+func (c envValueC) Coerce(v interface{}, path []string) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	}
+	return schema.String().Coerce(v, path)
+}
 
 type deviceCountC struct{}
 
@@ -1312,12 +2288,12 @@ func (c deviceCountC) Coerce(v interface{}, path []string) (interface{}, error)
 			return m, nil
 		}
 	}
-	return 0, errors.Errorf("invalid device count %d", s)
+	return 0, errors.Errorf("%s: invalid device count %d", strings.Join(path[1:], ""), s)
 }
 
 type storageCountC struct{}
 
-var storageCountRE = regexp.MustCompile("^([0-9]+)([-+]|-[0-9]+)$")
+var storageCountRE = lazyRegexp("^([0-9]+)([-+]|-[0-9]+)$")
 
 func (c storageCountC) Coerce(v interface{}, path []string) (newv interface{}, err error) {
 	s, err := schema.OneOf(schema.Int(), stringC).Coerce(v, path)
@@ -1332,7 +2308,8 @@ func (c storageCountC) Coerce(v interface{}, path []string) (newv interface{}, e
 		}
 		return [2]int{int(m), int(m)}, nil
 	}
-	match := storageCountRE.FindStringSubmatch(s.(string))
+	str, _ := s.(string)
+	match := storageCountRE().FindStringSubmatch(str)
 	if match == nil {
 		return nil, errors.Errorf("%s: value %q does not match 'm', 'm-n', or 'm+'", strings.Join(path[1:], ""), s)
 	}
@@ -1353,6 +2330,21 @@ func (c storageCountC) Coerce(v interface{}, path []string) (newv interface{}, e
 	return [2]int{m, n}, nil
 }
 
+// fixedCountC checks the count of a store or device, which must
+// be a positive integer.
+type fixedCountC struct{}
+
+func (c fixedCountC) Coerce(v interface{}, path []string) (newv interface{}, err error) {
+	s, err := schema.Int().Coerce(v, path)
+	if err != nil {
+		return nil, err
+	}
+	if m, _ := s.(int64); m <= 0 {
+		return nil, errors.Errorf("%s: invalid count %v", strings.Join(path[1:], ""), m)
+	}
+	return s, nil
+}
+
 type storageSizeC struct{}
 
 func (c storageSizeC) Coerce(v interface{}, path []string) (newv interface{}, err error) {
@@ -1360,7 +2352,8 @@ func (c storageSizeC) Coerce(v interface{}, path []string) (newv interface{}, er
 	if err != nil {
 		return nil, err
 	}
-	return utils.ParseSize(s.(string))
+	str, _ := s.(string)
+	return utils.ParseSize(str)
 }
 
 type propertiesC struct{}
@@ -1369,103 +2362,167 @@ func (c propertiesC) Coerce(v interface{}, path []string) (newv interface{}, err
 	return schema.OneOf(schema.Const("transient")).Coerce(v, path)
 }
 
-var deploymentSchema = schema.FieldMap(
-	schema.Fields{
-		"type": schema.OneOf(
-			schema.Const(string(DeploymentStateful)),
-			schema.Const(string(DeploymentStateless)),
-			schema.Const(string(DeploymentDaemon)),
-		),
-		"mode": schema.OneOf(
-			schema.Const(string(ModeOperator)),
-			schema.Const(string(ModeWorkload)),
-		),
-		"service": schema.OneOf(
-			schema.Const(string(ServiceCluster)),
-			schema.Const(string(ServiceLoadBalancer)),
-			schema.Const(string(ServiceExternal)),
-			schema.Const(string(ServiceOmit)),
-		),
-		"min-version": schema.String(),
-	}, schema.Defaults{
-		"type":        schema.Omit,
-		"mode":        string(ModeWorkload),
-		"service":     schema.Omit,
-		"min-version": schema.Omit,
-	},
-)
-
-var containerSchema = schema.FieldMap(
-	schema.Fields{
-		"resource": schema.String(),
-		"mounts":   schema.List(mountSchema),
-		"uid":      schema.Int(),
-		"gid":      schema.Int(),
-	}, schema.Defaults{
-		"resource": schema.Omit,
-		"mounts":   schema.Omit,
-		"uid":      schema.Omit,
-		"gid":      schema.Omit,
-	})
-
-var mountSchema = schema.FieldMap(
-	schema.Fields{
-		"storage":  schema.String(),
-		"location": schema.String(),
-	}, schema.Defaults{
-		"storage":  schema.Omit,
-		"location": schema.Omit,
-	})
-
-var charmSchema = schema.FieldMap(
-	schema.Fields{
+var deploymentSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"type": schema.OneOf(
+				schema.Const(string(DeploymentStateful)),
+				schema.Const(string(DeploymentStateless)),
+				schema.Const(string(DeploymentDaemon)),
+			),
+			"mode": schema.OneOf(
+				schema.Const(string(ModeOperator)),
+				schema.Const(string(ModeWorkload)),
+			),
+			"service": schema.OneOf(
+				schema.Const(string(ServiceCluster)),
+				schema.Const(string(ServiceLoadBalancer)),
+				schema.Const(string(ServiceExternal)),
+				schema.Const(string(ServiceOmit)),
+			),
+			"min-version": schema.String(),
+		}, schema.Defaults{
+			"type":        schema.Omit,
+			"mode":        string(ModeWorkload),
+			"service":     schema.Omit,
+			"min-version": schema.Omit,
+		},
+	)
+})
+
+var containerSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"resource": schema.String(),
+			"mounts":   schema.List(mountSchema()),
+			"uid":      schema.Int(),
+			"gid":      schema.Int(),
+			"command":  schema.List(schema.String()),
+			"args":     schema.List(schema.String()),
+			"env":      schema.StringMap(envValueC{}),
+		}, schema.Defaults{
+			"resource": schema.Omit,
+			"mounts":   schema.Omit,
+			"uid":      schema.Omit,
+			"gid":      schema.Omit,
+			"command":  schema.Omit,
+			"args":     schema.Omit,
+			"env":      schema.Omit,
+		})
+})
+
+var mountSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"storage":  schema.String(),
+			"location": schema.String(),
+		}, schema.Defaults{
+			"storage":  schema.Omit,
+			"location": schema.Omit,
+		})
+})
+
+// charmSchema is built on first use rather than at package initialisation,
+// as are the schemas it is composed from.
+var charmSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		charmSchemaFields(),
+		schema.Defaults{
+			"provides":         schema.Omit,
+			"requires":         schema.Omit,
+			"peers":            schema.Omit,
+			"extra-bindings":   schema.Omit,
+			"revision":         schema.Omit,
+			"format":           schema.Omit,
+			"subordinate":      schema.Omit,
+			"categories":       schema.Omit,
+			"tags":             schema.Omit,
+			"series":           schema.Omit,
+			"storage":          schema.Omit,
+			"devices":          schema.Omit,
+			"deployment":       schema.Omit,
+			"payloads":         schema.Omit,
+			"workloads":        schema.Omit,
+			"processes":        schema.Omit,
+			"resources":        schema.Omit,
+			"terms":            schema.Omit,
+			"min-juju-version": schema.Omit,
+			"assumes":          schema.Omit,
+			"containers":       schema.Omit,
+			"charm-user":       schema.Omit,
+			"links":            schema.Omit,
+			"secrets":          schema.Omit,
+		},
+	)
+})
+
+// charmSchemaFields holds the top-level fields of metadata.yaml.
+var charmSchemaFields = sync.OnceValue(func() schema.Fields {
+	return schema.Fields{
 		"name":             schema.String(),
 		"summary":          schema.String(),
 		"description":      schema.String(),
 		"peers":            schema.StringMap(ifaceExpander(nil)),
 		"provides":         schema.StringMap(ifaceExpander(nil)),
 		"requires":         schema.StringMap(ifaceExpander(nil)),
-		"extra-bindings":   extraBindingsSchema,
+		"extra-bindings":   extraBindingsSchema(),
 		"revision":         schema.Int(), // Obsolete
 		"format":           schema.Int(), // Obsolete
 		"subordinate":      schema.Bool(),
 		"categories":       schema.List(schema.String()),
 		"tags":             schema.List(schema.String()),
 		"series":           schema.List(schema.String()),
-		"storage":          schema.StringMap(storageSchema),
-		"devices":          schema.StringMap(deviceSchema),
-		"deployment":       deploymentSchema,
-		"payloads":         schema.StringMap(payloadClassSchema),
-		"resources":        schema.StringMap(resourceSchema),
+		"storage":          schema.StringMap(storageSchema()),
+		"devices":          schema.StringMap(deviceSchema()),
+		"deployment":       deploymentSchema(),
+		"payloads":         schema.StringMap(payloadClassSchema()),
+		"workloads":        schema.StringMap(legacyPayloadClassSchema()), // Deprecated: use payloads
+		"processes":        schema.StringMap(legacyPayloadClassSchema()), // Deprecated: use payloads
+		"resources":        schema.StringMap(resourceSchema()),
 		"terms":            schema.List(schema.String()),
 		"min-juju-version": schema.String(),
 		"assumes":          schema.List(schema.Any()),
-		"containers":       schema.StringMap(containerSchema),
+		"containers":       schema.StringMap(containerSchema()),
 		"charm-user":       schema.String(),
-	},
-	schema.Defaults{
-		"provides":         schema.Omit,
-		"requires":         schema.Omit,
-		"peers":            schema.Omit,
-		"extra-bindings":   schema.Omit,
-		"revision":         schema.Omit,
-		"format":           schema.Omit,
-		"subordinate":      schema.Omit,
-		"categories":       schema.Omit,
-		"tags":             schema.Omit,
-		"series":           schema.Omit,
-		"storage":          schema.Omit,
-		"devices":          schema.Omit,
-		"deployment":       schema.Omit,
-		"payloads":         schema.Omit,
-		"resources":        schema.Omit,
-		"terms":            schema.Omit,
-		"min-juju-version": schema.Omit,
-		"assumes":          schema.Omit,
-		"containers":       schema.Omit,
-		"charm-user":       schema.Omit,
-	},
-)
+		"links":            linksSchema(),
+		"secrets":          schema.StringMap(secretSchema()),
+	}
+})
+
+// ensureNoNullKeys returns an error if any map held in v has a null
+// key. The schema checkers panic when they come across one, so the raw
+// data is checked before it is coerced.
+func ensureNoNullKeys(path string, v interface{}) error {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		for key, value := range v {
+			if key == nil {
+				if path == "" {
+					return errors.New("unexpected null key")
+				}
+				return errors.Errorf("%s: unexpected null key", path)
+			}
+			if err := ensureNoNullKeys(joinField(path, fmt.Sprint(key)), value); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			if err := ensureNoNullKeys(fmt.Sprintf("%s[%d]", path, i), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// joinField returns the path of the named field within path.
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
 
 // ensureUnambiguousFormat returns an error if the raw data contains
 // both metadata v1 and v2 contents. However is it unable to definitively
@@ -11,7 +11,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	stdtesting "testing"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version/v2"
 	gc "gopkg.in/check.v1"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/juju/charm/v12"
 	"github.com/juju/charm/v12/assumes"
+	"github.com/juju/charm/v12/hooks"
 	"github.com/juju/charm/v12/resource"
 )
 
@@ -56,6 +59,100 @@ func (s *MetaSuite) TestReadMetaVersion2(c *gc.C) {
 	c.Assert(meta.Terms, gc.HasLen, 0)
 }
 
+func (s *MetaSuite) TestReadMetaNullKey(c *gc.C) {
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+requires:
+    db:
+        ~: mysql
+`))
+	c.Assert(err, gc.ErrorMatches, `metadata: requires.db: unexpected null key`)
+}
+
+func (s *MetaSuite) TestReadMetaUnexpectedTypes(c *gc.C) {
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+extra-bindings: [a, b]
+`))
+	c.Assert(err, gc.ErrorMatches, `metadata: extra-bindings: expected map, got .*`)
+}
+
+func (s *MetaSuite) TestParseErrorPaths(c *gc.C) {
+	// The parsers are normally given the output of the schema, but
+	// must not panic when given values of another type.
+	_, err := charm.ParseRelations(map[string]interface{}{
+		"db": map[string]interface{}{"interface": 1},
+	}, charm.RoleRequirer)
+	c.Check(err, gc.ErrorMatches, `metadata: requires.db.interface: expected string, got int\(1\)`)
+
+	_, err = charm.ParseStorage(map[string]interface{}{
+		"store0": map[string]interface{}{
+			"type":       "block",
+			"shared":     false,
+			"read-only":  false,
+			"properties": []interface{}{"transient", 1},
+		},
+	})
+	c.Check(err, gc.ErrorMatches, `metadata: storage.store0.properties\[1\]: expected string, got int\(1\)`)
+
+	_, err = charm.ParseStorage(map[string]interface{}{
+		"store0": map[string]interface{}{
+			"type":         "block",
+			"shared":       false,
+			"read-only":    false,
+			"minimum-size": "1G",
+		},
+	})
+	c.Check(err, gc.ErrorMatches, `metadata: storage.store0.minimum-size: expected uint64, got string\("1G"\)`)
+
+	_, err = charm.ParseDevices(map[string]interface{}{
+		"gpu0": map[string]interface{}{"type": "gpu", "countmax": "2"},
+	})
+	c.Check(err, gc.ErrorMatches, `metadata: devices.gpu0.countmax: expected int64, got string\("2"\)`)
+
+	_, err = charm.ParseDevices([]interface{}{"gpu0"})
+	c.Check(err, gc.ErrorMatches, `metadata: devices: expected map, got \[\]interface \{\}\(.*\)`)
+}
+
+func (s *MetaSuite) TestReadMetaUnknownFields(c *gc.C) {
+	data := `
+name: typo
+summary: b
+description: c
+requiers:
+    db: mysql
+extra:
+    nested: true
+`
+	meta, err := charm.ReadMeta(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.UnknownFields, gc.IsNil)
+	c.Assert(meta.Requires, gc.HasLen, 0)
+
+	var warnings []string
+	meta, err = charm.ReadMeta(strings.NewReader(data),
+		charm.WithUnknownFields(),
+		charm.WithUnknownFieldWarnings(func(field string) {
+			warnings = append(warnings, field)
+		}),
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.UnknownFields, jc.DeepEquals, map[string]interface{}{
+		"requiers": map[interface{}]interface{}{"db": "mysql"},
+		"extra":    map[interface{}]interface{}{"nested": true},
+	})
+	c.Assert(warnings, jc.DeepEquals, []string{"extra", "requiers"})
+
+	// Known fields are never reported.
+	meta, err = charm.ReadMeta(repoMeta(c, "dummy"), charm.WithUnknownFields())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.UnknownFields, gc.IsNil)
+}
+
 func (s *MetaSuite) TestValidTermFormat(c *gc.C) {
 	valid := []string{
 		"foobar",
@@ -271,6 +368,74 @@ func (s *MetaSuite) TestParseTerms(c *gc.C) {
 	}
 }
 
+func (s *MetaSuite) TestParseTermTenants(c *gc.C) {
+	term, err := charm.ParseTerm("jaas:owner/term/1", charm.WithTermTenants("cs", "jaas"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(term, jc.DeepEquals, &charm.TermsId{"jaas", "owner", "term", 1})
+
+	term, err = charm.ParseTerm("term/1", charm.WithTermTenants("cs"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(term, jc.DeepEquals, &charm.TermsId{"", "", "term", 1})
+
+	_, err = charm.ParseTerm("other:term", charm.WithTermTenants("cs", "jaas"))
+	c.Assert(err, gc.ErrorMatches, `term tenant "other" not accepted`)
+
+	// With no tenants, no tenant is accepted.
+	_, err = charm.ParseTerm("cs:term", charm.WithTermTenants())
+	c.Assert(err, gc.ErrorMatches, `term tenant "cs" not accepted`)
+}
+
+func (s *MetaSuite) TestTermValidateTenants(c *gc.C) {
+	term := &charm.TermsId{Tenant: "cs", Name: "term"}
+	c.Assert(term.Validate(), jc.ErrorIsNil)
+	c.Assert(term.Validate(charm.WithTermTenants("jaas")), gc.ErrorMatches, `term tenant "cs" not accepted`)
+
+	term = &charm.TermsId{Tenant: "C$", Name: "term"}
+	c.Assert(term.Validate(), gc.ErrorMatches, `wrong term tenant format "C\$"`)
+}
+
+func (s *MetaSuite) TestReadMetaTermOptions(c *gc.C) {
+	const metaYAML = `
+name: terms
+summary: s
+description: d
+terms: ["jaas:term", "term/2"]
+`
+	meta, err := charm.ReadMeta(strings.NewReader(metaYAML), charm.WithMetaTermOptions(charm.WithTermTenants("jaas")))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.Terms, gc.HasLen, 2)
+	c.Assert(meta.CheckTerms(charm.WithTermTenants("jaas")), jc.ErrorIsNil)
+
+	_, err = charm.ReadMeta(strings.NewReader(metaYAML), charm.WithMetaTermOptions(charm.WithTermTenants("cs")))
+	c.Assert(err, gc.ErrorMatches, `metadata: term tenant "jaas" not accepted`)
+}
+
+func (s *MetaSuite) TestParseTermOwner(c *gc.C) {
+	term, err := charm.ParseTerm("bob@external/term/2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(term, jc.DeepEquals, &charm.TermsId{"", "bob@external", "term", 2})
+
+	_, err = charm.ParseTerm("bob@/term/2")
+	c.Assert(err, gc.ErrorMatches, `wrong owner format "bob@"`)
+}
+
+func (s *MetaSuite) TestTermYAMLRoundTrip(c *gc.C) {
+	var terms []charm.TermsId
+	err := yaml.Unmarshal([]byte(`["cs:owner/term/007", "term", "owner/term"]`), &terms)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(terms, jc.DeepEquals, []charm.TermsId{
+		{"cs", "owner", "term", 7},
+		{"", "", "term", 0},
+		{"", "owner", "term", 0},
+	})
+	data, err := yaml.Marshal(terms)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, "- cs:owner/term/7\n- term\n- owner/term\n")
+
+	err = yaml.Unmarshal([]byte(`["1term"]`), &terms)
+	c.Assert(err, gc.ErrorMatches, `wrong term name format "1term"`)
+}
+
 func (s *MetaSuite) TestReadCategory(c *gc.C) {
 	meta, err := charm.ReadMeta(repoMeta(c, "category"))
 	c.Assert(err, gc.IsNil)
@@ -323,6 +488,57 @@ func (s *MetaSuite) TestCheckSubordinateWithoutContainerRelation(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "subordinate charm \"dummy\" lacks \"requires\" relation with container scope")
 }
 
+func (s *MetaSuite) TestCheckIllegalCombinations(c *gc.C) {
+	for i, test := range []struct {
+		about  string
+		meta   charm.Meta
+		format charm.Format
+		err    string
+	}{{
+		about: "subordinate with containers",
+		meta: charm.Meta{
+			Subordinate: true,
+			Containers:  map[string]charm.Container{"web": {}},
+		},
+		format: charm.FormatV2,
+		err:    `charm "x": subordinate charms cannot declare containers`,
+	}, {
+		about: "subordinate with devices",
+		meta: charm.Meta{
+			Subordinate: true,
+			Devices:     map[string]charm.Device{"gpu": {Type: "gpu"}},
+		},
+		format: charm.FormatV1,
+		err:    `charm "x": subordinate charms cannot declare devices`,
+	}, {
+		about: "containers with deployment",
+		meta: charm.Meta{
+			Containers: map[string]charm.Container{"web": {}},
+			Deployment: &charm.Deployment{DeploymentType: charm.DeploymentStateless},
+		},
+		format: charm.FormatV2,
+		err:    `charm "x": charms declaring containers cannot declare deployment`,
+	}, {
+		about: "all at once",
+		meta: charm.Meta{
+			Subordinate: true,
+			Containers:  map[string]charm.Container{"web": {}},
+			Devices:     map[string]charm.Device{"gpu": {Type: "gpu"}},
+			Deployment:  &charm.Deployment{DeploymentType: charm.DeploymentStateless},
+		},
+		format: charm.FormatV2,
+		err: `charm "x": subordinate charms cannot declare containers; ` +
+			`subordinate charms cannot declare devices; ` +
+			`charms declaring containers cannot declare deployment`,
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		test.meta.Name = "x"
+		err := test.meta.Check(test.format, charm.SelectionManifest, charm.SelectionBases)
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
 func (s *MetaSuite) TestScopeConstraint(c *gc.C) {
 	meta, err := charm.ReadMeta(repoMeta(c, "logging"))
 	c.Assert(err, gc.IsNil)
@@ -548,6 +764,49 @@ requires:
   innocuous: juju-info`, "")
 }
 
+func (s *MetaSuite) TestCheckReservedNames(c *gc.C) {
+	check := func(yaml, e string) {
+		meta, err := charm.ReadMeta(strings.NewReader(yaml))
+		c.Assert(err, jc.ErrorIsNil)
+		err = meta.Check(charm.FormatV1)
+		if e != "" {
+			c.Check(err, gc.ErrorMatches, e)
+		} else {
+			c.Check(err, jc.ErrorIsNil)
+		}
+	}
+	check("name: a\nsummary: b\ndescription: c\nextra-bindings:\n  juju-admin:\n",
+		`charm "a" using a reserved extra binding name: "juju-admin"`)
+	check("name: a\nsummary: b\ndescription: c\nstorage:\n  juju:\n    type: filesystem\n",
+		`charm "a" storage "juju": "juju" is a reserved name`)
+	// Charms in the juju-* namespace may use reserved names.
+	check("name: juju-a\nsummary: b\ndescription: c\nstorage:\n  juju-data:\n    type: filesystem\n", "")
+}
+
+func (s *MetaSuite) TestIsReservedName(c *gc.C) {
+	for i, test := range []struct {
+		check    func(charmName, name string) (bool, string)
+		charm    string
+		name     string
+		reserved bool
+		reason   string
+	}{
+		{charm.IsReservedEndpointName, "a", "db", false, ""},
+		{charm.IsReservedEndpointName, "a", "juju", true, `"juju" is a reserved name`},
+		{charm.IsReservedEndpointName, "a", "juju-info", true, `the "juju-" prefix is reserved`},
+		{charm.IsReservedEndpointName, "juju-a", "juju-info", false, ""},
+		{charm.IsReservedExtraBindingName, "a", "juju-admin", true, `the "juju-" prefix is reserved`},
+		{charm.IsReservedExtraBindingName, "a", "admin", false, ""},
+		{charm.IsReservedStorageName, "a", "juju", true, `"juju" is a reserved name`},
+		{charm.IsReservedStorageName, "a", "jujudata", false, ""},
+	} {
+		c.Logf("test %d: %s %s", i, test.charm, test.name)
+		reserved, reason := test.check(test.charm, test.name)
+		c.Check(reserved, gc.Equals, test.reserved)
+		c.Check(reason, gc.Equals, test.reason)
+	}
+}
+
 // dummyMetadata contains a minimally valid charm metadata.yaml
 // for testing valid and invalid series.
 const dummyMetadata = "name: a\nsummary: b\ndescription: c"
@@ -771,6 +1030,59 @@ func (s *MetaSuite) TestMetaHooks(c *gc.C) {
 	c.Assert(hooks, jc.DeepEquals, expectedHooks)
 }
 
+func (s *MetaSuite) TestMetaContainersHooks(c *gc.C) {
+	meta := charm.Meta{
+		Containers: map[string]charm.Container{
+			"web": {},
+		},
+	}
+	expectedHooks := map[string]bool{
+		"web-pebble-change-updated":  true,
+		"web-pebble-check-failed":    true,
+		"web-pebble-check-recovered": true,
+		"web-pebble-custom-notice":   true,
+		"web-pebble-ready":           true,
+	}
+	c.Assert(meta.ContainersHooks(), jc.DeepEquals, expectedHooks)
+
+	hooks := meta.Hooks()
+	for hook := range expectedHooks {
+		c.Assert(hooks[hook], jc.IsTrue, gc.Commentf("hook %q", hook))
+	}
+}
+
+func (s *MetaSuite) TestMetaHooksFor(c *gc.C) {
+	meta := charm.Meta{
+		Provides:   map[string]charm.Relation{"website": {Name: "website", Role: charm.RoleProvider, Interface: "http"}},
+		Storage:    map[string]charm.Storage{"data": {Name: "data", Type: charm.StorageFilesystem}},
+		Containers: map[string]charm.Container{"web": {}},
+		Secrets: map[string]charm.Secret{
+			"password": {Name: "password", Owner: charm.SecretOwnerApplication, RotatePolicy: charm.RotateDaily},
+			"token":    {Name: "token", Owner: charm.SecretOwnerConsumer},
+		},
+	}
+	actions := &charm.Actions{ActionSpecs: map[string]charm.ActionSpec{
+		"backup":  {Description: "Take a backup."},
+		"restore": {Description: "Restore a backup."},
+	}}
+	allHooks := meta.HooksFor(actions)
+	for _, hook := range []string{
+		"install", "website-relation-joined", "data-storage-attached",
+		"web-pebble-check-failed", "secret-rotate", "secret-changed",
+		"backup-action", "restore-action",
+	} {
+		c.Check(allHooks[hook], jc.IsTrue, gc.Commentf("hook %q", hook))
+	}
+	c.Check(allHooks["action"], jc.IsFalse)
+	c.Check(meta.HooksFor(nil), jc.DeepEquals, meta.Hooks())
+
+	// Every hook can be parsed back into its kind.
+	for hook := range allHooks {
+		_, _, err := hooks.ParseHookName(hook)
+		c.Check(err, jc.ErrorIsNil, gc.Commentf("hook %q", hook))
+	}
+}
+
 func (s *MetaSuite) TestCodecRoundTripEmpty(c *gc.C) {
 	for _, codec := range codecs {
 		c.Logf("codec %s", codec.Name)
@@ -974,6 +1286,123 @@ func (s *MetaSuite) TestImplementedBy(c *gc.C) {
 	}
 }
 
+var explainImplementedByTests = []struct {
+	relation charm.Relation
+	reason   string
+}{{
+	relation: charm.Relation{Name: "pro", Role: charm.RoleProvider, Interface: "ifce-pro", Scope: charm.ScopeGlobal, Limit: 2},
+}, {
+	relation: charm.Relation{Name: "juju-info", Role: charm.RoleProvider, Interface: "juju-info", Scope: charm.ScopeGlobal},
+}, {
+	relation: charm.Relation{Name: "blah", Role: charm.RoleProvider, Interface: "ifce-pro", Scope: charm.ScopeGlobal},
+	reason:   `charm does not declare relation "blah" in provides`,
+}, {
+	relation: charm.Relation{Name: "pro", Role: charm.RoleRequirer, Interface: "ifce-pro", Scope: charm.ScopeGlobal},
+	reason:   `relation "pro" is declared in provides, not requires`,
+}, {
+	relation: charm.Relation{Name: "req", Role: charm.RoleRequirer, Interface: "blah", Scope: charm.ScopeGlobal},
+	reason:   `relation "req" has interface "ifce-req", not "blah"`,
+}, {
+	relation: charm.Relation{Name: "info", Role: charm.RoleRequirer, Interface: "juju-info", Scope: charm.ScopeGlobal},
+	reason:   `relation "info" has scope "container", not "global"`,
+}, {
+	relation: charm.Relation{Name: "pro", Role: charm.RoleProvider, Interface: "ifce-pro", Scope: charm.ScopeGlobal, Limit: 3},
+	reason:   `relation "pro" is limited to 2 relations, not 3`,
+}}
+
+func (s *MetaSuite) TestExplainImplementedBy(c *gc.C) {
+	for i, t := range explainImplementedByTests {
+		c.Logf("test %d: %+v", i, t.relation)
+		ok, reason := t.relation.ExplainImplementedBy(&dummyCharm{})
+		c.Check(ok, gc.Equals, t.reason == "")
+		c.Check(reason, gc.Equals, t.reason)
+		c.Check(t.relation.ImplementedBy(&dummyCharm{}), gc.Equals, ok)
+	}
+}
+
+func (s *MetaSuite) TestRelationRoleCounterpart(c *gc.C) {
+	c.Assert(charm.RoleProvider.Counterpart(), gc.Equals, charm.RoleRequirer)
+	c.Assert(charm.RoleRequirer.Counterpart(), gc.Equals, charm.RoleProvider)
+	c.Assert(charm.RolePeer.Counterpart(), gc.Equals, charm.RolePeer)
+	c.Assert(func() { charm.RelationRole("boss").Counterpart() }, gc.PanicMatches, `unknown relation role "boss"`)
+
+	c.Assert(charm.RoleProvider.CanRelateTo(charm.RoleRequirer), jc.IsTrue)
+	c.Assert(charm.RoleRequirer.CanRelateTo(charm.RoleProvider), jc.IsTrue)
+	c.Assert(charm.RoleProvider.CanRelateTo(charm.RoleProvider), jc.IsFalse)
+	c.Assert(charm.RoleRequirer.CanRelateTo(charm.RolePeer), jc.IsFalse)
+	c.Assert(charm.RolePeer.CanRelateTo(charm.RolePeer), jc.IsFalse)
+	c.Assert(charm.RelationRole("boss").CanRelateTo(charm.RoleProvider), jc.IsFalse)
+}
+
+func (s *MetaSuite) TestRelationCounterpart(c *gc.C) {
+	rel := charm.Relation{
+		Name:      "db",
+		Role:      charm.RoleRequirer,
+		Interface: "mysql",
+		Optional:  true,
+		Limit:     1,
+		Scope:     charm.ScopeContainer,
+	}
+	c.Assert(rel.Counterpart(), jc.DeepEquals, charm.Relation{
+		Name:      "db",
+		Role:      charm.RoleProvider,
+		Interface: "mysql",
+		Scope:     charm.ScopeContainer,
+	})
+	c.Assert(rel.Counterpart().Counterpart().Role, gc.Equals, rel.Role)
+}
+
+func (s *MetaSuite) TestRegisterImplicitRelation(c *gc.C) {
+	dashboard := charm.Relation{
+		Name:      "juju-dashboard",
+		Role:      charm.RoleProvider,
+		Interface: "juju-dashboard",
+	}
+	err := charm.RegisterImplicitRelation(dashboard)
+	c.Assert(err, jc.ErrorIsNil)
+	defer charm.UnregisterImplicitRelation("juju-dashboard")
+
+	dashboard.Scope = charm.ScopeGlobal
+	c.Assert(charm.ImplicitRelations(), jc.DeepEquals, []charm.Relation{dashboard, {
+		Name:      "juju-info",
+		Role:      charm.RoleProvider,
+		Interface: "juju-info",
+		Scope:     charm.ScopeGlobal,
+	}})
+	c.Assert(dashboard.IsImplicit(), jc.IsTrue)
+	c.Assert(dashboard.ImplementedBy(&dummyCharm{}), jc.IsTrue)
+
+	// A relation of the same name with another interface is not implicit.
+	other := dashboard
+	other.Interface = "http"
+	c.Assert(other.IsImplicit(), jc.IsFalse)
+
+	err = charm.RegisterImplicitRelation(dashboard)
+	c.Assert(err, jc.ErrorIs, errors.AlreadyExists)
+	err = charm.RegisterImplicitRelation(charm.Relation{Name: "juju-info", Role: charm.RoleProvider, Interface: "juju-info"})
+	c.Assert(err, jc.ErrorIs, errors.AlreadyExists)
+}
+
+func (s *MetaSuite) TestRegisterImplicitRelationErrors(c *gc.C) {
+	for i, t := range []struct {
+		rel charm.Relation
+		err string
+	}{{
+		rel: charm.Relation{Name: "juju-debug", Role: charm.RoleRequirer, Interface: "juju-debug"},
+		err: `implicit relation "juju-debug" with role "requirer" not valid`,
+	}, {
+		rel: charm.Relation{Name: "debug", Role: charm.RoleProvider, Interface: "juju-debug"},
+		err: `implicit relation name "debug" without the "juju-" prefix not valid`,
+	}, {
+		rel: charm.Relation{Name: "juju-debug", Role: charm.RoleProvider},
+		err: `implicit relation "juju-debug" without an interface not valid`,
+	}} {
+		c.Logf("test %d", i)
+		err := charm.RegisterImplicitRelation(t.rel)
+		c.Check(err, gc.ErrorMatches, t.err)
+	}
+}
+
 var metaYAMLMarshalTests = []struct {
 	about string
 	yaml  string
@@ -1013,6 +1442,8 @@ peers:
 extra-bindings:
     extraBar:
     extraFoo1:
+    extraDocumented:
+        description: traffic from the admin network
 categories: [c1, c1]
 tags: [t1, t2]
 series:
@@ -1043,6 +1474,90 @@ assumes:
   - table
   - lazy-suzan
 `,
+}, {
+	about: "charm with storage, devices and payloads",
+	yaml: `
+name: storage-devices
+description: d
+summary: s
+storage:
+    single:
+        type: filesystem
+        location: /srv/data
+    multi:
+        type: block
+        description: lots
+        shared: true
+        read-only: true
+        multiple:
+            range: 2-5
+        minimum-size: 10G
+        maximum-size: 1T
+        properties: [transient]
+    unbounded:
+        type: block
+        multiple:
+            range: 1+
+    fixed:
+        type: filesystem
+        multiple:
+            range: 3
+devices:
+    gpu:
+        type: nvidia.com/gpu
+        description: a gpu
+        countmin: 0
+        countmax: 2
+    default:
+        type: gpu
+payloads:
+    monitor:
+        type: docker
+terms: [term1, term2]
+min-juju-version: 2.9.0
+`,
+}, {
+	about: "v2 charm with containers",
+	yaml: `
+name: containers
+description: d
+summary: s
+resources:
+    image:
+        type: oci-image
+storage:
+    data:
+        type: filesystem
+containers:
+    app:
+        resource: image
+        mounts:
+            - storage: data
+              location: /data
+        uid: 10000
+        gid: 0
+        command: [/bin/app]
+        args: [--serve]
+        env:
+            APP_MODE: production
+    sidecar:
+        uid: 0
+charm-user: non-root
+assumes:
+- k8s-api
+`,
+}, {
+	about: "kubernetes charm with deployment",
+	yaml: `
+name: k8s
+description: d
+summary: s
+series: [kubernetes]
+deployment:
+    type: stateful
+    service: loadbalancer
+    min-version: "1.15"
+`,
 }}
 
 func (s *MetaSuite) TestYAMLMarshal(c *gc.C) {
@@ -1058,6 +1573,44 @@ func (s *MetaSuite) TestYAMLMarshal(c *gc.C) {
 	}
 }
 
+func (s *MetaSuite) TestYAMLMarshalCanonical(c *gc.C) {
+	ch, err := charm.ReadMeta(strings.NewReader(`
+summary: s
+name: canonical
+storage:
+    data:
+        type: filesystem
+        multiple:
+            range: 1-
+description: d
+devices:
+    gpu:
+        type: gpu
+requires:
+    db: mysql
+`))
+	c.Assert(err, gc.IsNil)
+	gotYAML, err := yaml.Marshal(ch)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(gotYAML), gc.Equals, `
+name: canonical
+summary: s
+description: d
+requires:
+  db: mysql
+storage:
+  data:
+    type: filesystem
+    multiple:
+      range: 1+
+devices:
+  gpu:
+    type: gpu
+    countmin: 1
+    countmax: 1
+`[1:])
+}
+
 func (s *MetaSuite) TestYAMLMarshalSimpleRelationOrExtraBinding(c *gc.C) {
 	// Check that a simple relation / extra-binding gets marshaled as a string.
 	chYAML := `
@@ -1148,6 +1701,26 @@ devices:
 	}, gc.Commentf("meta: %+v", meta))
 }
 
+func (s *MetaSuite) TestDevicesCountShorthand(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+devices:
+    accelerator:
+        type: tpu
+        count: 4
+`))
+	c.Assert(err, gc.IsNil)
+	c.Assert(meta.Devices["accelerator"], jc.DeepEquals, charm.Device{
+		Name:     "accelerator",
+		Type:     charm.DeviceTPU,
+		CountMin: 4,
+		CountMax: 4,
+	})
+	c.Assert(meta.Check(charm.FormatV1), jc.ErrorIsNil)
+}
+
 func (s *MetaSuite) TestDevicesDefaultLimitAndRequest(c *gc.C) {
 	meta, err := charm.ReadMeta(strings.NewReader(`
 name: a
@@ -1220,11 +1793,48 @@ deployment:
 		desc: "missing series",
 		yaml: "        service: cluster",
 		err:  `charm with deployment metadata must declare at least one series`,
+	}, {
+		desc: "invalid min-version",
+		yaml: "        min-version: \"1.18+x\"\nseries:\n        - kubernetes",
+		err:  `deployment min-version "1.18\+x" not valid`,
 	}}
 
 	testErrors(c, prefix, tests)
 }
 
+func (s *MetaSuite) TestDeploymentSupportsClusterVersion(c *gc.C) {
+	d := &charm.Deployment{MinVersion: "1.18"}
+	minVersion, err := d.ParseMinVersion()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(minVersion, gc.Equals, version.MustParse("1.18.0"))
+	c.Check(d.SupportsClusterVersion(version.MustParse("1.18.0")), jc.IsTrue)
+	c.Check(d.SupportsClusterVersion(version.MustParse("1.21.3")), jc.IsTrue)
+	c.Check(d.SupportsClusterVersion(version.MustParse("1.17.9")), jc.IsFalse)
+
+	// Any cluster version is supported without a minimum.
+	d = &charm.Deployment{}
+	minVersion, err = d.ParseMinVersion()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(minVersion, gc.Equals, version.Zero)
+	c.Check(d.SupportsClusterVersion(version.MustParse("1.0.0")), jc.IsTrue)
+
+	// No cluster version is supported with an invalid minimum.
+	d = &charm.Deployment{MinVersion: "latest"}
+	_, err = d.ParseMinVersion()
+	c.Assert(err, jc.ErrorIs, errors.NotValid)
+	c.Check(d.SupportsClusterVersion(version.MustParse("9.9.9")), jc.IsFalse)
+}
+
+func (s *MetaSuite) TestCheckDeploymentMinVersion(c *gc.C) {
+	meta := charm.Meta{
+		Name:       "a",
+		Series:     []string{"kubernetes"},
+		Deployment: &charm.Deployment{MinVersion: "1.18+x"},
+	}
+	err := meta.Check(charm.FormatV1)
+	c.Assert(err, gc.ErrorMatches, `deployment min-version "1.18\+x" not valid`)
+}
+
 type testErrorPayload struct {
 	desc string
 	yaml string
@@ -1267,11 +1877,19 @@ devices:
 	}, {
 		desc: "countmax has to be greater than 0",
 		yaml: "        countmax: -1\n        description: a big gpu device\n        type: gpu",
-		err:  "metadata: invalid device count -1",
+		err:  "metadata: devices.bad-nvidia-gpu.countmax: invalid device count -1",
 	}, {
 		desc: "countmin has to be greater than 0",
 		yaml: "        countmin: -1\n        description: a big gpu device\n        type: gpu",
-		err:  "metadata: invalid device count -1",
+		err:  "metadata: devices.bad-nvidia-gpu.countmin: invalid device count -1",
+	}, {
+		desc: "count must be positive",
+		yaml: "        count: 0\n        type: gpu",
+		err:  "metadata: devices.bad-nvidia-gpu.count: invalid count 0",
+	}, {
+		desc: "count is exclusive with countmin and countmax",
+		yaml: "        count: 2\n        countmax: 3\n        type: gpu",
+		err:  `device "bad-nvidia-gpu": count may not be specified with countmin or countmax`,
 	}}
 
 	testErrors(c, prefix, tests)
@@ -1291,6 +1909,10 @@ devices:
 		desc: "countmax can not be smaller than countmin",
 		yaml: "        countmin: 2\n        countmax: 1\n        description: a big gpu device\n        type: gpu",
 		err:  "charm \"a\" device \"bad-nvidia-gpu\": maximum count 1 can not be smaller than minimum count 2",
+	}, {
+		desc: "unknown device types are rejected",
+		yaml: "        type: gpuu",
+		err:  `charm "a" device "bad-nvidia-gpu": device type "gpuu" not valid, expected one of amd.com/gpu, gpu, nvidia.com/gpu, tpu or a vendor qualified type such as "example.com/device"`,
 	}}
 
 	testCheckErrors(c, prefix, tests)
@@ -1365,6 +1987,22 @@ storage:
 		desc: "minimum size must have valid suffix",
 		yaml: "  type: block\n  minimum-size: 10Q",
 		err:  `metadata: invalid multiplier suffix "Q", expected one of MGTPEZY`,
+	}, {
+		desc: "maximum size must have valid suffix",
+		yaml: "  type: block\n  maximum-size: 10Q",
+		err:  `metadata: invalid multiplier suffix "Q", expected one of MGTPEZY`,
+	}, {
+		desc: "count must be an integer",
+		yaml: "  type: filesystem\n  count: 1+",
+		err:  `metadata: storage.store-bad.count: expected int, got string\("1\+"\)`,
+	}, {
+		desc: "count must be positive",
+		yaml: "  type: filesystem\n  count: 0",
+		err:  `metadata: storage.store-bad.count: invalid count 0`,
+	}, {
+		desc: "count and multiple are exclusive",
+		yaml: "  type: filesystem\n  count: 2\n  multiple:\n    range: 1-3",
+		err:  `storage "store-bad": count and multiple may not both be specified`,
 	}, {
 		desc: "properties must contain valid values",
 		yaml: "  type: block\n  properties: [transient, foo]",
@@ -1387,6 +2025,26 @@ storage:
 		desc: "location cannot be specified for block type storage",
 		yaml: "  type: block\n  location: /dev/sdc",
 		err:  `charm "a" storage "store-bad": location may not be specified for "type: block"`,
+	}, {
+		desc: "maximum count cannot be smaller than minimum count",
+		yaml: "  type: filesystem\n  multiple:\n    range: 3-2",
+		err:  `charm "a" storage "store-bad": maximum count 2 can not be smaller than minimum count 3`,
+	}, {
+		desc: "maximum size cannot be smaller than minimum size",
+		yaml: "  type: block\n  minimum-size: 2G\n  maximum-size: 1G",
+		err:  `charm "a" storage "store-bad": maximum size 1024M can not be smaller than minimum size 2048M`,
+	}, {
+		desc: "tmpfs storage cannot be shared",
+		yaml: "  type: tmpfs\n  shared: true\n  maximum-size: 1G",
+		err:  `charm "a" storage "store-bad": tmpfs storage may not be shared`,
+	}, {
+		desc: "tmpfs storage must have a bounded count",
+		yaml: "  type: tmpfs\n  maximum-size: 1G\n  multiple:\n    range: 1+",
+		err:  `charm "a" storage "store-bad": tmpfs storage must have a bounded maximum count`,
+	}, {
+		desc: "tmpfs storage must have a maximum size",
+		yaml: "  type: tmpfs\n  minimum-size: 1G",
+		err:  `charm "a" storage "store-bad": tmpfs storage must specify a maximum size`,
 	}}
 
 	testCheckErrors(c, prefix, tests)
@@ -1418,7 +2076,7 @@ storage:
 	testStorageCount("1-", 1, -1)
 }
 
-func (s *MetaSuite) TestStorageLocation(c *gc.C) {
+func (s *MetaSuite) TestStorageCountShorthand(c *gc.C) {
 	meta, err := charm.ReadMeta(strings.NewReader(`
 name: a
 summary: b
@@ -1426,15 +2084,16 @@ description: c
 storage:
     store0:
         type: filesystem
-        location: /var/lib/things
+        count: 3
 `))
 	c.Assert(err, gc.IsNil)
 	store := meta.Storage["store0"]
-	c.Assert(store, gc.NotNil)
-	c.Assert(store.Location, gc.Equals, "/var/lib/things")
+	c.Assert(store.CountMin, gc.Equals, 3)
+	c.Assert(store.CountMax, gc.Equals, 3)
+	c.Assert(meta.Check(charm.FormatV1), jc.ErrorIsNil)
 }
 
-func (s *MetaSuite) TestStorageMinimumSize(c *gc.C) {
+func (s *MetaSuite) TestStorageLocation(c *gc.C) {
 	meta, err := charm.ReadMeta(strings.NewReader(`
 name: a
 summary: b
@@ -1442,15 +2101,81 @@ description: c
 storage:
     store0:
         type: filesystem
-        minimum-size: 10G
+        location: /var/lib/things
 `))
 	c.Assert(err, gc.IsNil)
 	store := meta.Storage["store0"]
 	c.Assert(store, gc.NotNil)
-	c.Assert(store.MinimumSize, gc.Equals, uint64(10*1024))
+	c.Assert(store.Location, gc.Equals, "/var/lib/things")
 }
 
-func (s *MetaSuite) TestStorageProperties(c *gc.C) {
+func (s *MetaSuite) TestStorageTmpfs(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+containers:
+  foo:
+    resource: test-os
+    mounts:
+      - storage: scratch
+        location: /tmp/scratch
+resources:
+  test-os:
+    type: oci-image
+storage:
+    scratch:
+        type: tmpfs
+        location: /var/scratch
+        maximum-size: 512M
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	store := meta.Storage["scratch"]
+	c.Assert(store.Type, gc.Equals, charm.StorageTmpfs)
+	c.Assert(store.Type.IsFilesystem(), jc.IsTrue)
+	c.Assert(store.MaximumSize, gc.Equals, uint64(512))
+	err = meta.Check(charm.FormatV2, charm.SelectionManifest, charm.SelectionBases)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(charm.StorageFilesystem.IsFilesystem(), jc.IsTrue)
+	c.Assert(charm.StorageBlock.IsFilesystem(), jc.IsFalse)
+}
+
+func (s *MetaSuite) TestStorageMinimumSize(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+storage:
+    store0:
+        type: filesystem
+        minimum-size: 10G
+`))
+	c.Assert(err, gc.IsNil)
+	store := meta.Storage["store0"]
+	c.Assert(store, gc.NotNil)
+	c.Assert(store.MinimumSize, gc.Equals, uint64(10*1024))
+}
+
+func (s *MetaSuite) TestStorageMaximumSize(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+storage:
+    store0:
+        type: filesystem
+        minimum-size: 1G
+        maximum-size: 10G
+`))
+	c.Assert(err, gc.IsNil)
+	store := meta.Storage["store0"]
+	c.Assert(store.MinimumSize, gc.Equals, uint64(1024))
+	c.Assert(store.MaximumSize, gc.Equals, uint64(10*1024))
+	c.Assert(meta.Check(charm.FormatV1), jc.ErrorIsNil)
+}
+
+func (s *MetaSuite) TestStorageProperties(c *gc.C) {
 	meta, err := charm.ReadMeta(strings.NewReader(`
 name: a
 summary: b
@@ -1490,6 +2215,47 @@ extra-bindings:
 	})
 }
 
+func (s *MetaSuite) TestExtraBindingsDescription(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+extra-bindings:
+    plain:
+    short: the short form
+    long:
+        description: the long form
+`))
+	c.Assert(err, gc.IsNil)
+	c.Assert(meta.ExtraBindings, jc.DeepEquals, map[string]charm.ExtraBinding{
+		"plain": {Name: "plain"},
+		"short": {Name: "short", Description: "the short form"},
+		"long":  {Name: "long", Description: "the long form"},
+	})
+
+	data, err := yaml.Marshal(meta)
+	c.Assert(err, jc.ErrorIsNil)
+	var raw map[string]interface{}
+	c.Assert(yaml.Unmarshal(data, &raw), jc.ErrorIsNil)
+	c.Assert(raw["extra-bindings"], jc.DeepEquals, map[interface{}]interface{}{
+		"plain": nil,
+		"short": map[interface{}]interface{}{"description": "the short form"},
+		"long":  map[interface{}]interface{}{"description": "the long form"},
+	})
+}
+
+func (s *MetaSuite) TestExtraBindingsDescriptionFieldError(c *gc.C) {
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+extra-bindings:
+    foo:
+        description: [not, a, string]
+`))
+	c.Assert(err, gc.ErrorMatches, `metadata: extra-bindings.foo.description: expected string, got .*`)
+}
+
 func (s *MetaSuite) TestExtraBindingsEmptyMapError(c *gc.C) {
 	meta, err := charm.ReadMeta(strings.NewReader(`
 name: a
@@ -1509,7 +2275,7 @@ description: c
 extra-bindings:
     foo: 42
 `))
-	c.Assert(err, gc.ErrorMatches, `metadata: extra-bindings.foo: expected empty value, got int\(42\)`)
+	c.Assert(err, gc.ErrorMatches, `metadata: extra-bindings.foo: expected empty value, description or map, got int\(42\)`)
 	c.Assert(meta, gc.IsNil)
 }
 
@@ -1550,6 +2316,153 @@ payloads:
 	})
 }
 
+func (s *MetaSuite) TestPayloadClassesUnknownType(c *gc.C) {
+	const metaYAML = `
+name: a
+summary: b
+description: c
+payloads:
+    monitor:
+        type: lxd
+`
+	// Payload types are only checked when asked for.
+	meta, err := charm.ReadMeta(strings.NewReader(metaYAML))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.PayloadClasses["monitor"].Type, gc.Equals, "lxd")
+
+	_, err = charm.ReadMeta(strings.NewReader(metaYAML), charm.WithPayloadTypes())
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `metadata: payload class "monitor" type "lxd" not valid, expected one of containerd, docker, kvm, rkt`)
+
+	meta, err = charm.ReadMeta(strings.NewReader(metaYAML), charm.WithPayloadTypes("docker", "lxd"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.PayloadClasses["monitor"].Type, gc.Equals, "lxd")
+}
+
+func (s *MetaSuite) TestReadCharmAcceptsAnyPayloadType(c *gc.C) {
+	path := cloneDir(c, charmDirPath(c, "dummy"))
+	err := os.WriteFile(filepath.Join(path, "metadata.yaml"), []byte(`
+name: dummy
+summary: b
+description: c
+payloads:
+    monitor:
+        type: lxd
+`), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	ch, err := charm.ReadCharm(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ch.Meta().PayloadClasses["monitor"].Type, gc.Equals, "lxd")
+}
+
+func (s *MetaSuite) TestPayloadClassesLegacyFields(c *gc.C) {
+	for _, field := range []string{"workloads", "processes"} {
+		c.Logf("field %s", field)
+		meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+` + field + `:
+    monitor:
+        type: docker
+`))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(meta.PayloadClasses, jc.DeepEquals, map[string]charm.PayloadClass{
+			"monitor": {Name: "monitor", Type: "docker"},
+		})
+
+		// Legacy fields are written back as payloads.
+		data, err := yaml.Marshal(meta)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(string(data), jc.Contains, "payloads:")
+		c.Check(string(data), gc.Not(jc.Contains), field+":")
+	}
+
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+payloads:
+    monitor:
+        type: docker
+workloads:
+    other:
+        type: docker
+`))
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `payload classes declared in both payloads and workloads`)
+}
+
+func (s *MetaSuite) TestPayloadClassesLegacyProcesses(c *gc.C) {
+	// Workload processes, as declared by charms written for Juju 1.25.
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+processes:
+    web:
+        description: The web server
+        image: nginx/nginx
+        command: nginx -g "daemon off;"
+        ports:
+            - 80:8080
+            - 443:8081
+        volumes:
+            - /var/www/html:/usr/share/nginx/html:ro
+        env:
+            IMPORTANT: "YES"
+    worker:
+        type: docker
+        type-options:
+            publish_all: true
+        image: myworker
+        command: run-worker
+        ports: ["8000:website"]
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.PayloadClasses, jc.DeepEquals, map[string]charm.PayloadClass{
+		"worker": {Name: "worker", Type: "docker"},
+	})
+
+	// Processes without a type are not payload classes, so they do not
+	// conflict with those declared by payloads.
+	meta, err = charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+payloads:
+    monitor:
+        type: docker
+processes:
+    web:
+        image: nginx/nginx
+        ports: ["80:8080"]
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.PayloadClasses, jc.DeepEquals, map[string]charm.PayloadClass{
+		"monitor": {Name: "monitor", Type: "docker"},
+	})
+}
+
+func (s *MetaSuite) TestPayloadClassesConflictWithContainers(c *gc.C) {
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+payloads:
+    app:
+        type: docker
+containers:
+    app:
+        resource: image
+resources:
+    image:
+        type: oci-image
+`))
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `metadata: payload class "app" conflicts with container of the same name`)
+}
+
 func (s *MetaSuite) TestResources(c *gc.C) {
 	meta, err := charm.ReadMeta(strings.NewReader(`
 name: a
@@ -1589,6 +2502,42 @@ resources:
 	})
 }
 
+func (s *MetaSuite) TestDownloadResources(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+resources:
+    model:
+        type: download
+        filename: model.bin
+        url: https://example.com/model.bin
+        sha256: e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(meta.Resources, jc.DeepEquals, map[string]resource.Meta{
+		"model": {
+			Name:   "model",
+			Type:   resource.TypeDownload,
+			Path:   "model.bin",
+			URL:    "https://example.com/model.bin",
+			SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+	})
+
+	_, err = charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+resources:
+    model:
+        type: download
+        filename: model.bin
+        url: https://example.com/model.bin
+`))
+	c.Assert(err, gc.ErrorMatches, `.*download resource missing sha256`)
+}
+
 func (s *MetaSuite) TestParseResourceMetaOkay(c *gc.C) {
 	name := "my-resource"
 	data := map[string]interface{}{
@@ -1735,6 +2684,14 @@ containers:
         location: /b/
     uid: 10
     gid: 10
+    command: [/bin/server]
+    args: [--port, "8080"]
+    env:
+      LOG_LEVEL: debug
+      _private: "1"
+      PORT: 8080
+      VERBOSE: true
+      RATIO: 0.5
 resources:
   test-os:
     type: oci-image
@@ -1750,12 +2707,57 @@ storage:
 				Storage:  "a",
 				Location: "/b/",
 			}},
-			Uid: 10,
-			Gid: 10,
+			Uid:     10,
+			Gid:     10,
+			Command: []string{"/bin/server"},
+			Args:    []string{"--port", "8080"},
+			Env: map[string]string{
+				"LOG_LEVEL": "debug",
+				"_private":  "1",
+				"PORT":      "8080",
+				"VERBOSE":   "true",
+				"RATIO":     "0.5",
+			},
 		},
 	})
 }
 
+func (s *MetaSuite) TestContainerOverridesErrors(c *gc.C) {
+	for i, test := range []struct {
+		container string
+		err       string
+	}{{
+		container: `env: {1ST: a}`,
+		err:       `parsing containers: container "foo": environment variable name "1ST" not valid`,
+	}, {
+		container: `env: {MY-VAR: a}`,
+		err:       `parsing containers: container "foo": environment variable name "MY-VAR" not valid`,
+	}, {
+		container: `env: {PORT: [8080]}`,
+		err:       `metadata: containers.foo.env.PORT: expected string, got .*`,
+	}, {
+		container: `command: [""]`,
+		err:       `parsing containers: container "foo" with empty command element not valid`,
+	}, {
+		container: `args: run`,
+		err:       `metadata: containers.foo.args: expected list, got string\("run"\)`,
+	}} {
+		c.Logf("test %d: %s", i, test.container)
+		_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+containers:
+  foo:
+    ` + test.container + `
+`))
+		c.Check(err, gc.ErrorMatches, test.err)
+		if strings.HasPrefix(test.err, "parsing containers: ") {
+			c.Check(err, jc.Satisfies, errors.IsNotValid)
+		}
+	}
+}
+
 func (s *MetaSuite) TestInvalidUid(c *gc.C) {
 	_, err := charm.ReadMeta(strings.NewReader(`
 name: a
@@ -1889,6 +2891,90 @@ storage:
 	c.Assert(err, gc.ErrorMatches, `parsing containers: container "foo": storage "b" not valid`)
 }
 
+func (s *MetaSuite) TestCheckContainerMounts(c *gc.C) {
+	for i, test := range []struct {
+		about  string
+		mounts string
+		expect string
+	}{{
+		about: "valid mounts",
+		mounts: `
+      - storage: a
+        location: /a/
+      - storage: b
+        location: /srv/b`,
+	}, {
+		about: "same location as its own storage",
+		mounts: `
+      - storage: b
+        location: /var/lib/b`,
+	}, {
+		about: "duplicate location",
+		mounts: `
+      - storage: a
+        location: /srv/data
+      - storage: b
+        location: /srv/data/`,
+		expect: `charm "a" container "foo": duplicate mount location "/srv/data/"`,
+	}, {
+		about: "location of another storage",
+		mounts: `
+      - storage: a
+        location: /var/lib/b`,
+		expect: `charm "a" container "foo": mount location "/var/lib/b" of storage "a" collides with the location of storage "b"`,
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+containers:
+  foo:
+    resource: test-os
+    mounts:` + test.mounts + `
+resources:
+  test-os:
+    type: oci-image
+storage:
+  a:
+    type: filesystem
+  b:
+    type: filesystem
+    location: /var/lib/b
+`))
+		c.Assert(err, jc.ErrorIsNil)
+		err = meta.Check(charm.FormatV2, charm.SelectionManifest, charm.SelectionBases)
+		if test.expect == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.expect)
+		}
+	}
+}
+
+func (s *MetaSuite) TestCheckContainerReferences(c *gc.C) {
+	meta := charm.Meta{
+		Name: "a",
+		Containers: map[string]charm.Container{
+			"foo": {Resource: "test-os"},
+		},
+	}
+	err := meta.Check(charm.FormatV2, charm.SelectionManifest, charm.SelectionBases)
+	c.Assert(err, gc.ErrorMatches, `charm "a" container "foo": referenced resource "test-os" not found`)
+
+	meta.Containers["foo"] = charm.Container{
+		Mounts: []charm.Mount{{Storage: "data", Location: "/data"}},
+	}
+	err = meta.Check(charm.FormatV2, charm.SelectionManifest, charm.SelectionBases)
+	c.Assert(err, gc.ErrorMatches, `charm "a" container "foo": mount references unknown storage "data"`)
+
+	meta.Storage = map[string]charm.Storage{
+		"data": {Name: "data", Type: charm.StorageBlock, CountMin: 1, CountMax: 1},
+	}
+	err = meta.Check(charm.FormatV2, charm.SelectionManifest, charm.SelectionBases)
+	c.Assert(err, gc.ErrorMatches, `charm "a" container "foo": cannot mount storage "data" of type block`)
+}
+
 func (s *MetaSuite) TestFormatV1AndV2Mixing(c *gc.C) {
 	_, err := charm.ReadMeta(strings.NewReader(`
 name: a
@@ -1945,7 +3031,7 @@ func (c *dummyCharm) Revision() int {
 func (c *dummyCharm) Meta() *charm.Meta {
 	return &charm.Meta{
 		Provides: map[string]charm.Relation{
-			"pro": {Interface: "ifce-pro", Scope: charm.ScopeGlobal},
+			"pro": {Interface: "ifce-pro", Scope: charm.ScopeGlobal, Limit: 2},
 		},
 		Requires: map[string]charm.Relation{
 			"req":  {Interface: "ifce-req", Scope: charm.ScopeGlobal},
@@ -2095,3 +3181,182 @@ charm-user: barry
 `))
 	c.Assert(err, gc.ErrorMatches, `parsing charm-user: invalid charm-user "barry" expected one of root, sudoer or non-root`)
 }
+
+func (s *MetaSuite) TestLinks(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+links:
+  documentation: https://discourse.charmhub.io/t/a-docs/123
+  website: https://example.com
+  source:
+  - https://github.com/example/a-operator
+  - https://git.launchpad.net/a
+  issues: https://github.com/example/a-operator/issues
+  contact:
+  - Jane Doe <jane@example.com>
+  - https://matrix.to/#/#charm-a:example.com
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.Links, jc.DeepEquals, &charm.Links{
+		Documentation: "https://discourse.charmhub.io/t/a-docs/123",
+		Website:       []string{"https://example.com"},
+		Source:        []string{"https://github.com/example/a-operator", "https://git.launchpad.net/a"},
+		Issues:        []string{"https://github.com/example/a-operator/issues"},
+		Contact:       []string{"Jane Doe <jane@example.com>", "https://matrix.to/#/#charm-a:example.com"},
+	})
+
+	data, err := yaml.Marshal(meta)
+	c.Assert(err, jc.ErrorIsNil)
+	roundTripped, err := charm.ReadMeta(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(roundTripped.Links, jc.DeepEquals, meta.Links)
+
+	meta, err = charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.Links, gc.IsNil)
+}
+
+func (s *MetaSuite) TestLinksInvalid(c *gc.C) {
+	for i, test := range []struct {
+		links string
+		err   string
+	}{{
+		links: "documentation: docs.example.com",
+		err:   `metadata: links.documentation: URL "docs.example.com" not valid`,
+	}, {
+		links: "website: [https://example.com, ftp://example.com]",
+		err:   `metadata: links.website\[1\]: URL "ftp://example.com" not valid`,
+	}, {
+		links: "contact: [nobody]",
+		err:   `metadata: links.contact\[0\]: URL "nobody" not valid`,
+	}, {
+		links: "source: {a: b}",
+		err:   `metadata: links.source: .*`,
+	}} {
+		c.Logf("test %d: %s", i, test.links)
+		_, err := charm.ReadMeta(strings.NewReader("name: a\nsummary: b\ndescription: c\nlinks:\n  " + test.links + "\n"))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *MetaSuite) TestSecrets(c *gc.C) {
+	meta, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+secrets:
+  admin-password:
+    description: The administrator password.
+    rotate-policy: monthly
+  session-key:
+    owner: unit
+    rotate-policy: never
+  upstream-token:
+    owner: consumer
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.Secrets, jc.DeepEquals, map[string]charm.Secret{
+		"admin-password": {
+			Name:         "admin-password",
+			Description:  "The administrator password.",
+			RotatePolicy: charm.RotateMonthly,
+			Owner:        charm.SecretOwnerApplication,
+		},
+		"session-key": {
+			Name:         "session-key",
+			RotatePolicy: charm.RotateNever,
+			Owner:        charm.SecretOwnerUnit,
+		},
+		"upstream-token": {
+			Name:  "upstream-token",
+			Owner: charm.SecretOwnerConsumer,
+		},
+	})
+
+	// Juju runs the same secret hooks whichever secrets are declared.
+	hooks := meta.Hooks()
+	c.Check(hooks["secret-rotate"], jc.IsTrue)
+	c.Check(hooks["secret-changed"], jc.IsTrue)
+	c.Check(hooks["admin-password-secret-rotate"], jc.IsFalse)
+	c.Check(hooks["upstream-token-secret-changed"], jc.IsFalse)
+
+	data, err := yaml.Marshal(meta)
+	c.Assert(err, jc.ErrorIsNil)
+	roundTripped, err := charm.ReadMeta(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(roundTripped.Secrets, jc.DeepEquals, meta.Secrets)
+}
+
+func (s *MetaSuite) TestSecretsInvalid(c *gc.C) {
+	for i, test := range []struct {
+		secrets string
+		err     string
+	}{{
+		secrets: "Bad_Name: {}",
+		err:     `.*secret name "Bad_Name" not valid`,
+	}, {
+		secrets: "token: {owner: model}",
+		err:     `.*secrets.token: owner "model" \(expected one of application, consumer, unit\) not valid`,
+	}, {
+		secrets: "token: {rotate-policy: fortnightly}",
+		err:     `.*secrets.token: rotate-policy "fortnightly" \(expected one of daily, hourly, monthly, never, quarterly, weekly, yearly\) not valid`,
+	}, {
+		secrets: "token: {owner: consumer, rotate-policy: daily}",
+		err:     `.*secrets.token: rotate-policy for a consumed secret not valid`,
+	}} {
+		c.Logf("test %d: %s", i, test.secrets)
+		_, err := charm.ReadMeta(strings.NewReader("name: a\nsummary: b\ndescription: c\nsecrets:\n  " + test.secrets + "\n"))
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+const benchmarkMeta = `
+name: benchmark
+summary: a charm used for benchmarking
+description: a charm used for benchmarking
+provides:
+  website: http
+  admin:
+    interface: http
+    limit: 1
+requires:
+  db: mysql
+peers:
+  ring: ring
+storage:
+  data:
+    type: filesystem
+    location: /srv/data
+    multiple:
+      range: 1-3
+devices:
+  gpu:
+    type: nvidia.com/gpu
+    countmin: 1
+resources:
+  blob:
+    type: file
+    filename: blob.tgz
+extra-bindings:
+  public:
+tags: [database]
+series: [jammy]
+terms: [some-term]
+`
+
+// BenchmarkReadMeta measures the cost of parsing metadata. Running it with
+// -benchtime=1x measures the cold-start cost, which includes building the
+// lazily constructed schemas and regular expressions.
+func BenchmarkReadMeta(b *stdtesting.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := charm.ReadMeta(strings.NewReader(benchmarkMeta)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies the changes found by MetaDiff.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "changed"
+)
+
+// Change describes a single difference between two revisions of a
+// charm's metadata.
+type Change struct {
+	// Section holds the part of the metadata that changed, such as
+	// "relation", "storage" or "min-juju-version".
+	Section string
+
+	// Name holds the name of the relation, storage, device or other
+	// named item that changed within the section. It is empty for
+	// changes to the charm as a whole.
+	Name string
+
+	// Field holds the field of the item that changed, such as
+	// "interface" for a relation. It is empty if the item as a whole
+	// was added or removed, or if the section has no fields.
+	Field string
+
+	// Kind classifies the change.
+	Kind ChangeKind
+
+	// Message describes the change.
+	Message string
+}
+
+// String returns the description of the change.
+func (c Change) String() string {
+	return c.Message
+}
+
+// Changes holds the differences between two revisions of a charm's
+// metadata, as returned by MetaDiff.
+type Changes []Change
+
+// String returns the descriptions of the changes, one per line.
+func (c Changes) String() string {
+	lines := make([]string, len(c))
+	for i, change := range c {
+		lines[i] = change.Message
+	}
+	return strings.Join(lines, "\n")
+}
+
+// metaDiffSections holds the order in which sections are reported.
+var metaDiffSections = []string{
+	"name", "summary", "description", "subordinate", "min-juju-version",
+	"charm-user", "assumes", "deployment", "links", "series", "categories", "tags",
+	"terms", "relation", "extra-binding", "storage", "device", "resource",
+	"container", "payload-class", "secret",
+}
+
+// MetaDiff compares two revisions of a charm's metadata and returns the
+// changes made in going from a to b: relations, storage, devices and
+// other named items added, removed or changed, and changes to fields of
+// the charm as a whole, such as its minimum juju version. The changes
+// are sorted by section and then by name. A nil Meta is treated as
+// empty.
+func MetaDiff(a, b *Meta) Changes {
+	if a == nil {
+		a = &Meta{}
+	}
+	if b == nil {
+		b = &Meta{}
+	}
+	d := &metaDiffer{}
+
+	d.value("name", a.Name, b.Name)
+	d.value("summary", a.Summary, b.Summary)
+	d.value("description", a.Description, b.Description)
+	d.value("subordinate", a.Subordinate, b.Subordinate)
+	d.value("min-juju-version", a.MinJujuVersion, b.MinJujuVersion)
+	d.value("charm-user", a.CharmUser, b.CharmUser)
+	if !reflect.DeepEqual(a.Assumes, b.Assumes) {
+		d.add(Change{Section: "assumes", Kind: ChangeModified, Message: "assumes changed"})
+	}
+	d.item("deployment", "", a.Deployment, b.Deployment)
+	d.item("links", "", a.Links, b.Links)
+
+	d.list("series", a.Series, b.Series)
+	d.list("categories", a.Categories, b.Categories)
+	d.list("tags", a.Tags, b.Tags)
+	d.list("terms", a.Terms, b.Terms)
+
+	d.items("relation", a.CombinedRelations(), b.CombinedRelations())
+	d.items("extra-binding", a.ExtraBindings, b.ExtraBindings)
+	d.items("storage", a.Storage, b.Storage)
+	d.items("device", a.Devices, b.Devices)
+	d.items("resource", a.Resources, b.Resources)
+	d.items("container", a.Containers, b.Containers)
+	d.items("payload-class", a.PayloadClasses, b.PayloadClasses)
+	d.items("secret", a.Secrets, b.Secrets)
+
+	return d.sorted()
+}
+
+type metaDiffer struct {
+	changes Changes
+}
+
+func (d *metaDiffer) add(change Change) {
+	d.changes = append(d.changes, change)
+}
+
+// value records a change to a field of the charm as a whole.
+func (d *metaDiffer) value(section string, a, b interface{}) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	d.add(Change{
+		Section: section,
+		Kind:    ChangeModified,
+		Message: fmt.Sprintf("%s changed from %s to %s", section, diffValue(a), diffValue(b)),
+	})
+}
+
+// list records the entries added to and removed from a list.
+func (d *metaDiffer) list(section string, a, b []string) {
+	inA := make(map[string]bool)
+	for _, v := range a {
+		inA[v] = true
+	}
+	inB := make(map[string]bool)
+	for _, v := range b {
+		inB[v] = true
+	}
+	for _, v := range a {
+		if !inB[v] {
+			d.add(Change{Section: section, Name: v, Kind: ChangeRemoved, Message: fmt.Sprintf("%s %q removed", section, v)})
+		}
+	}
+	for _, v := range b {
+		if !inA[v] {
+			d.add(Change{Section: section, Name: v, Kind: ChangeAdded, Message: fmt.Sprintf("%s %q added", section, v)})
+		}
+	}
+}
+
+// items records the differences between two maps of named items, such
+// as map[string]Relation.
+func (d *metaDiffer) items(section string, a, b interface{}) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for _, key := range va.MapKeys() {
+		name := key.String()
+		if nv := vb.MapIndex(key); nv.IsValid() {
+			d.item(section, name, va.MapIndex(key).Interface(), nv.Interface())
+		} else {
+			d.add(Change{Section: section, Name: name, Kind: ChangeRemoved, Message: fmt.Sprintf("%s %q removed", section, name)})
+		}
+	}
+	for _, key := range vb.MapKeys() {
+		if !va.MapIndex(key).IsValid() {
+			name := key.String()
+			d.add(Change{Section: section, Name: name, Kind: ChangeAdded, Message: fmt.Sprintf("%s %q added", section, name)})
+		}
+	}
+}
+
+// item records the changes to the fields of a single item, which is a
+// struct or a pointer to a struct. The name is empty for items that
+// stand for a section as a whole, such as the deployment.
+func (d *metaDiffer) item(section, name string, a, b interface{}) {
+	label := section
+	if name != "" {
+		label = fmt.Sprintf("%s %q", section, name)
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == reflect.Pointer {
+		switch {
+		case va.IsNil() && vb.IsNil():
+			return
+		case va.IsNil():
+			d.add(Change{Section: section, Name: name, Kind: ChangeAdded, Message: label + " added"})
+			return
+		case vb.IsNil():
+			d.add(Change{Section: section, Name: name, Kind: ChangeRemoved, Message: label + " removed"})
+			return
+		}
+		va, vb = va.Elem(), vb.Elem()
+	}
+	t := va.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Name == "Name" {
+			continue
+		}
+		fa, fb := va.Field(i).Interface(), vb.Field(i).Interface()
+		if reflect.DeepEqual(fa, fb) {
+			continue
+		}
+		fieldName := diffFieldName(field)
+		d.add(Change{
+			Section: section,
+			Name:    name,
+			Field:   fieldName,
+			Kind:    ChangeModified,
+			Message: fmt.Sprintf("%s %s changed from %s to %s", label, fieldName, diffValue(fa), diffValue(fb)),
+		})
+	}
+}
+
+func (d *metaDiffer) sorted() Changes {
+	order := make(map[string]int)
+	for i, section := range metaDiffSections {
+		order[section] = i
+	}
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		ci, cj := d.changes[i], d.changes[j]
+		if ci.Section != cj.Section {
+			return order[ci.Section] < order[cj.Section]
+		}
+		if ci.Name != cj.Name {
+			return ci.Name < cj.Name
+		}
+		return ci.Field < cj.Field
+	})
+	return d.changes
+}
+
+// diffFieldName returns the name used to report changes to the given
+// struct field: its serialised name where it has one, or its Go name in
+// lower case.
+func diffFieldName(field reflect.StructField) string {
+	for _, key := range []string{"yaml", "bson"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return strings.ToLower(field.Name)
+}
+
+// diffValue formats a value for a change message.
+func diffValue(v interface{}) string {
+	switch v := v.(type) {
+	case fmt.Stringer:
+		return fmt.Sprintf("%q", v.String())
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.String {
+		return fmt.Sprintf("%q", rv.String())
+	}
+	return fmt.Sprintf("%v", v)
+}
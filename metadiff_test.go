@@ -0,0 +1,101 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type MetaDiffSuite struct{}
+
+var _ = gc.Suite(&MetaDiffSuite{})
+
+func readDiffMeta(c *gc.C, data string) *charm.Meta {
+	meta, err := charm.ReadMeta(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	return meta
+}
+
+func (s *MetaDiffSuite) TestNoChanges(c *gc.C) {
+	meta := readCharmDir(c, "dummy").Meta()
+	c.Assert(charm.MetaDiff(meta, meta), gc.HasLen, 0)
+}
+
+func (s *MetaDiffSuite) TestChanges(c *gc.C) {
+	a := readDiffMeta(c, `
+name: db
+summary: a database
+description: stores data
+min-juju-version: 2.9.0
+series: [focal]
+tags: [database]
+provides:
+  db: mysql
+requires:
+  logging: syslog
+storage:
+  data:
+    type: filesystem
+    minimum-size: 1G
+`)
+	b := readDiffMeta(c, `
+name: db
+summary: a database
+description: stores data
+min-juju-version: 3.1.0
+series: [focal, jammy]
+tags: [database]
+provides:
+  db: pgsql
+  metrics:
+    interface: prometheus
+storage:
+  data:
+    type: filesystem
+    minimum-size: 2G
+    shared: true
+`)
+	changes := charm.MetaDiff(a, b)
+	c.Assert(changes.String(), gc.Equals, strings.Join([]string{
+		`min-juju-version changed from "2.9.0" to "3.1.0"`,
+		`series "jammy" added`,
+		`relation "db" interface changed from "mysql" to "pgsql"`,
+		`relation "logging" removed`,
+		`relation "metrics" added`,
+		`storage "data" minimum-size changed from 1024 to 2048`,
+		`storage "data" shared changed from false to true`,
+	}, "\n"))
+	c.Assert(changes[2], jc.DeepEquals, charm.Change{
+		Section: "relation",
+		Name:    "db",
+		Field:   "interface",
+		Kind:    charm.ChangeModified,
+		Message: `relation "db" interface changed from "mysql" to "pgsql"`,
+	})
+	c.Assert(changes[3].Kind, gc.Equals, charm.ChangeRemoved)
+	c.Assert(changes[4].Kind, gc.Equals, charm.ChangeAdded)
+}
+
+func (s *MetaDiffSuite) TestNilMeta(c *gc.C) {
+	b := readDiffMeta(c, `
+name: a
+summary: b
+description: c
+devices:
+  gpu:
+    type: gpu
+`)
+	changes := charm.MetaDiff(nil, b)
+	c.Assert(changes.String(), gc.Equals, strings.Join([]string{
+		`name changed from "" to "a"`,
+		`summary changed from "" to "b"`,
+		`description changed from "" to "c"`,
+		`device "gpu" added`,
+	}, "\n"))
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -123,3 +124,44 @@ func (m Metrics) ValidateMetric(name, value string) error {
 func (m Metrics) PlanRequired() bool {
 	return m.Plan != nil && m.Plan.Required
 }
+
+// MeteringInfo summarises the metering requirements of a charm, as
+// declared in its metrics.yaml file.
+type MeteringInfo struct {
+	// Metered reports whether the charm is metered, which is the
+	// case whenever it has a metrics.yaml file, even an empty one.
+	Metered bool
+
+	// PlanRequired reports whether the charm can only be deployed
+	// with a plan.
+	PlanRequired bool
+
+	// Metrics holds the sorted names of the charm-defined metrics.
+	Metrics []string
+
+	// BuiltinMetrics holds the sorted names of the builtin metrics
+	// the charm has opted into.
+	BuiltinMetrics []string
+}
+
+// MeteringInfo returns the metering requirements described by m. It
+// may be called on a nil *Metrics, as returned for charms without a
+// metrics.yaml file, which are not metered.
+func (m *Metrics) MeteringInfo() MeteringInfo {
+	var info MeteringInfo
+	if m == nil {
+		return info
+	}
+	for name := range m.Metrics {
+		if IsBuiltinMetric(name) {
+			info.BuiltinMetrics = append(info.BuiltinMetrics, name)
+		} else {
+			info.Metrics = append(info.Metrics, name)
+		}
+	}
+	sort.Strings(info.Metrics)
+	sort.Strings(info.BuiltinMetrics)
+	info.Metered = true
+	info.PlanRequired = m.PlanRequired()
+	return info
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/charm/v12"
@@ -270,5 +271,12 @@ metrics:
 		metrics, err := charm.ReadMetrics(strings.NewReader(test.input))
 		c.Assert(err, gc.IsNil)
 		c.Assert(metrics.PlanRequired(), gc.Equals, test.planRequired)
+		c.Assert(metrics.MeteringInfo().PlanRequired, gc.Equals, test.planRequired)
+		c.Assert(metrics.MeteringInfo().Metered, jc.IsTrue)
 	}
 }
+
+func (s *MetricsSuite) TestMeteringInfoNoMetrics(c *gc.C) {
+	var metrics *charm.Metrics
+	c.Assert(metrics.MeteringInfo(), jc.DeepEquals, charm.MeteringInfo{})
+}
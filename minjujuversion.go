@@ -0,0 +1,116 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/version/v2"
+)
+
+// JujuVersionRequirement records a feature used by a charm and the
+// minimum Juju version supporting it.
+type JujuVersionRequirement struct {
+	// Feature describes the feature, such as "containers".
+	Feature string
+
+	// Version holds the first Juju version supporting the feature.
+	Version version.Number
+}
+
+// String returns a description of the requirement.
+func (r JujuVersionRequirement) String() string {
+	return fmt.Sprintf("%s requires juju %s", r.Feature, r.Version)
+}
+
+var (
+	containersJujuVersion      = version.MustParse("2.9.0")
+	manifestJujuVersion        = version.MustParse("2.9.0")
+	assumesJujuVersion         = version.MustParse("2.9.23")
+	actionExecutionJujuVersion = version.MustParse("3.0.0")
+	secretsJujuVersion         = version.MustParse("3.1.0")
+	secretConfigJujuVersion    = version.MustParse("3.3.0")
+)
+
+// ComputeMinJujuVersion infers the minimum Juju version able to deploy
+// a charm from the features used by its metadata, manifest, actions and
+// config, any of which may be nil. It returns the inferred version,
+// which is version.Zero if no feature needs a particular version,
+// together with the requirements it was derived from, sorted by
+// descending version and then by feature.
+//
+// If the metadata declares a min-juju-version lower than the inferred
+// version, the returned error satisfies errors.NotValid and names the
+// features responsible; the version and requirements are still
+// returned.
+func ComputeMinJujuVersion(meta *Meta, manifest *Manifest, actions *Actions, config *Config) (version.Number, []JujuVersionRequirement, error) {
+	var reqs []JujuVersionRequirement
+	require := func(feature string, v version.Number) {
+		reqs = append(reqs, JujuVersionRequirement{Feature: feature, Version: v})
+	}
+	if meta != nil {
+		if len(meta.Containers) > 0 {
+			require("containers", containersJujuVersion)
+		}
+		if meta.Assumes != nil {
+			require("assumes", assumesJujuVersion)
+		}
+		if len(meta.Secrets) > 0 {
+			require("secrets", secretsJujuVersion)
+		}
+	}
+	if manifest != nil && len(manifest.Bases) > 0 {
+		require("manifest bases", manifestJujuVersion)
+	}
+	if config != nil {
+		names := make([]string, 0, len(config.Options))
+		for name, option := range config.Options {
+			if option.Type == "secret" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			require(fmt.Sprintf("secret config option %q", name), secretConfigJujuVersion)
+		}
+	}
+	if actions != nil {
+		names := make([]string, 0, len(actions.ActionSpecs))
+		for name, spec := range actions.ActionSpecs {
+			if spec.Parallel || spec.ExecutionGroup != "" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			require(fmt.Sprintf("parallel execution of action %q", name), actionExecutionJujuVersion)
+		}
+	}
+
+	sort.SliceStable(reqs, func(i, j int) bool {
+		if c := reqs[i].Version.Compare(reqs[j].Version); c != 0 {
+			return c > 0
+		}
+		return reqs[i].Feature < reqs[j].Feature
+	})
+	if len(reqs) == 0 {
+		return version.Zero, nil, nil
+	}
+	minVersion := reqs[0].Version
+	if meta != nil && meta.MinJujuVersion != version.Zero && meta.MinJujuVersion.Compare(minVersion) < 0 {
+		var understated []string
+		for _, req := range reqs {
+			if meta.MinJujuVersion.Compare(req.Version) < 0 {
+				understated = append(understated, req.String())
+			}
+		}
+		return minVersion, reqs, errors.NewNotValid(nil, fmt.Sprintf(
+			"min-juju-version %s is lower than the %s required: %s",
+			meta.MinJujuVersion, minVersion, strings.Join(understated, "; ")))
+	}
+	return minVersion, reqs, nil
+}
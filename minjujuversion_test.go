@@ -0,0 +1,77 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/version/v2"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type MinJujuVersionSuite struct{}
+
+var _ = gc.Suite(&MinJujuVersionSuite{})
+
+func (s *MinJujuVersionSuite) TestNoFeatures(c *gc.C) {
+	v, reqs, err := charm.ComputeMinJujuVersion(&charm.Meta{Name: "plain"}, nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(v, gc.Equals, version.Zero)
+	c.Assert(reqs, gc.HasLen, 0)
+}
+
+func (s *MinJujuVersionSuite) TestFeatures(c *gc.C) {
+	meta := &charm.Meta{
+		Name:       "app",
+		Containers: map[string]charm.Container{"web": {Resource: "web-image"}},
+		Secrets:    map[string]charm.Secret{"password": {Name: "password", Owner: charm.SecretOwnerApplication}},
+	}
+	actions := &charm.Actions{ActionSpecs: map[string]charm.ActionSpec{
+		"backup":  {Parallel: true},
+		"restore": {},
+	}}
+	config := &charm.Config{Options: map[string]charm.Option{
+		"api-key": {Type: "secret"},
+		"port":    {Type: "int"},
+	}}
+	v, reqs, err := charm.ComputeMinJujuVersion(meta, nil, actions, config)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(v, gc.Equals, version.MustParse("3.3.0"))
+	c.Assert(reqs, jc.DeepEquals, []charm.JujuVersionRequirement{
+		{Feature: `secret config option "api-key"`, Version: version.MustParse("3.3.0")},
+		{Feature: "secrets", Version: version.MustParse("3.1.0")},
+		{Feature: `parallel execution of action "backup"`, Version: version.MustParse("3.0.0")},
+		{Feature: "containers", Version: version.MustParse("2.9.0")},
+	})
+	c.Assert(reqs[0].String(), gc.Equals, `secret config option "api-key" requires juju 3.3.0`)
+}
+
+func (s *MinJujuVersionSuite) TestManifest(c *gc.C) {
+	manifest := &charm.Manifest{Bases: []charm.Base{{Name: "ubuntu"}}}
+	v, _, err := charm.ComputeMinJujuVersion(nil, manifest, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(v, gc.Equals, version.MustParse("2.9.0"))
+}
+
+func (s *MinJujuVersionSuite) TestUnderstatedMinJujuVersion(c *gc.C) {
+	meta := &charm.Meta{
+		Name:           "app",
+		MinJujuVersion: version.MustParse("2.9.10"),
+		Containers:     map[string]charm.Container{"web": {Resource: "web-image"}},
+	}
+	config := &charm.Config{Options: map[string]charm.Option{
+		"api-key": {Type: "secret"},
+	}}
+	v, reqs, err := charm.ComputeMinJujuVersion(meta, nil, nil, config)
+	c.Assert(err, gc.ErrorMatches, `min-juju-version 2.9.10 is lower than the 3.3.0 required: secret config option "api-key" requires juju 3.3.0`)
+	c.Assert(errors.Is(err, errors.NotValid), jc.IsTrue)
+	c.Assert(v, gc.Equals, version.MustParse("3.3.0"))
+	c.Assert(reqs, gc.HasLen, 2)
+
+	meta.MinJujuVersion = version.MustParse("3.4.0")
+	_, _, err = charm.ComputeMinJujuVersion(meta, nil, nil, config)
+	c.Assert(err, jc.ErrorIsNil)
+}
@@ -4,7 +4,6 @@ package charm
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/juju/errors"
@@ -66,7 +65,7 @@ func (u *OfferURL) HasEndpoint() bool {
 }
 
 // modelApplicationRegexp parses urls of the form controller:user/model.application[:relname]
-var modelApplicationRegexp = regexp.MustCompile(`(/?((?P<user>[^/]+)/)?(?P<model>[^.]*)(\.(?P<application>[^:]*(:.*)?))?)?`)
+var modelApplicationRegexp = lazyRegexp(`(/?((?P<user>[^/]+)/)?(?P<model>[^.]*)(\.(?P<application>[^:]*(:.*)?))?)?`)
 
 // IsValidOfferURL ensures that a URL string is a valid OfferURL.
 func IsValidOfferURL(urlStr string) bool {
@@ -106,7 +105,7 @@ func ParseOfferURLParts(urlStr string) (*OfferURLParts, error) {
 	return parseOfferURLParts(urlStr, true)
 }
 
-var endpointRegexp = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+var endpointRegexp = lazyRegexp(`^[a-zA-Z0-9]+$`)
 
 func maybeParseSource(urlStr string) (source, rest string) {
 	parts := strings.Split(urlStr, ":")
@@ -114,7 +113,7 @@ func maybeParseSource(urlStr string) (source, rest string) {
 	case 3:
 		return parts[0], parts[1] + ":" + parts[2]
 	case 2:
-		if endpointRegexp.MatchString(parts[1]) {
+		if endpointRegexp().MatchString(parts[1]) {
 			return "", urlStr
 		}
 		return parts[0], parts[1]
@@ -127,12 +126,12 @@ func parseOfferURLParts(urlStr string, allowIncomplete bool) (*OfferURLParts, er
 	source, urlParts := maybeParseSource(urlStr)
 
 	valid := !strings.HasPrefix(urlStr, ":")
-	valid = valid && modelApplicationRegexp.MatchString(urlParts)
+	valid = valid && modelApplicationRegexp().MatchString(urlParts)
 	if valid {
 		result.Source = source
-		result.User = modelApplicationRegexp.ReplaceAllString(urlParts, "$user")
-		result.ModelName = modelApplicationRegexp.ReplaceAllString(urlParts, "$model")
-		result.ApplicationName = modelApplicationRegexp.ReplaceAllString(urlParts, "$application")
+		result.User = modelApplicationRegexp().ReplaceAllString(urlParts, "$user")
+		result.ModelName = modelApplicationRegexp().ReplaceAllString(urlParts, "$model")
+		result.ApplicationName = modelApplicationRegexp().ReplaceAllString(urlParts, "$application")
 	}
 	if !valid || strings.Contains(result.ModelName, "/") || strings.Contains(result.ApplicationName, "/") {
 		// TODO(wallyworld) - update error message when we support multi-controller and JAAS CMR
@@ -112,11 +112,14 @@ func VerifyNoOverlayFieldsPresent(bd *BundleData) error {
 				if isOverlayField(structField) {
 					errList = append(
 						errList,
-						fmt.Errorf(
-							"%s.%s can only appear in an overlay section",
-							strings.Join(pathStack, "."),
-							yamlName(structField),
-						),
+						&CodedError{
+							Code: CodeOverlayOnlyField,
+							Err: fmt.Errorf(
+								"%s.%s can only appear in an overlay section",
+								strings.Join(pathStack, "."),
+								yamlName(structField),
+							),
+						},
 					)
 					foundOverlay = true
 				}
@@ -421,7 +424,7 @@ func ReadAndMergeBundleData(sources ...BundleDataSource) (*BundleData, error) {
 	// Treat the first part as the base bundle
 	base := allParts[0]
 	if err := VerifyNoOverlayFieldsPresent(base.Data); err != nil {
-		return nil, errors.Trace(err)
+		return nil, errors.Trace(withDocument(err, base.Document))
 	}
 
 	// Merge parts and resolve include directives
@@ -590,6 +593,7 @@ func applyOverlay(base, overlay *BundleDataPart) error {
 
 	// Append any additional relations.
 	base.Data.Relations = append(base.Data.Relations, overlay.Data.Relations...)
+	base.Data.RelationSpecs = append(base.Data.RelationSpecs, overlay.Data.RelationSpecs...)
 
 	// Override machine definitions.
 	if machines := overlay.Data.Machines; machines != nil {
@@ -5,17 +5,36 @@ package charm
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
 	"github.com/juju/names/v5"
 	"github.com/juju/schema"
 )
 
-var payloadClassSchema = schema.FieldMap(
-	schema.Fields{
-		"type": schema.String(),
-	},
-	schema.Defaults{},
-)
+// defaultPayloadTypes holds the payload types accepted by
+// PayloadClass.ValidateType, and by ReadMeta when WithPayloadTypes is
+// given no types.
+var defaultPayloadTypes = []string{"containerd", "docker", "kvm", "rkt"}
+
+// DefaultPayloadTypes returns the payload types accepted by
+// PayloadClass.ValidateType, and by ReadMeta when WithPayloadTypes is
+// given no types.
+func DefaultPayloadTypes() []string {
+	return append([]string(nil), defaultPayloadTypes...)
+}
+
+var payloadClassSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"type": schema.String(),
+		},
+		schema.Defaults{},
+	)
+})
 
 // PayloadClass holds the information about a payload class, as stored
 // in a charm's metadata.
@@ -27,33 +46,117 @@ type PayloadClass struct {
 	Type string
 }
 
-func parsePayloadClasses(data interface{}) map[string]PayloadClass {
+// legacyPayloadFields holds the deprecated metadata.yaml fields that
+// declared workload processes before they were replaced by payloads.
+// ReadMeta reads the entries of these fields that declare a type as
+// payload classes, which are always written back as payloads.
+var legacyPayloadFields = []string{"workloads", "processes"}
+
+// legacyPayloadClassSchema checks an entry of a legacy payload field,
+// which describes a process and need not declare a type. Only the
+// type is used; the other fields are accepted as they were before.
+var legacyPayloadClassSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"type":         schema.String(),
+			"description":  schema.Any(),
+			"type-options": schema.Any(),
+			"command":      schema.Any(),
+			"image":        schema.Any(),
+			"ports":        schema.Any(),
+			"volumes":      schema.Any(),
+			"env":          schema.Any(),
+		},
+		schema.Defaults{
+			"type":         schema.Omit,
+			"description":  schema.Omit,
+			"type-options": schema.Omit,
+			"command":      schema.Omit,
+			"image":        schema.Omit,
+			"ports":        schema.Omit,
+			"volumes":      schema.Omit,
+			"env":          schema.Omit,
+		},
+	)
+})
+
+// parsePayloadClasses returns the payload classes declared in the given
+// metadata by the payloads field or, failing that, by the entries of a
+// legacy field that declare a type. Entries of legacy fields without a
+// type describe processes rather than payloads, and are ignored. It is
+// an error to declare payload classes in more than one field.
+func parsePayloadClasses(m map[string]interface{}) (map[string]PayloadClass, error) {
+	field := "payloads"
+	result, err := parsePayloadClassesField(field, m[field], false)
+	if err != nil {
+		return nil, err
+	}
+	for _, legacy := range legacyPayloadFields {
+		classes, err := parsePayloadClassesField(legacy, m[legacy], true)
+		if err != nil {
+			return nil, err
+		}
+		if len(classes) == 0 {
+			continue
+		}
+		if result != nil {
+			return nil, errors.NewNotValid(nil, fmt.Sprintf("payload classes declared in both %s and %s", field, legacy))
+		}
+		field, result = legacy, classes
+	}
+	return result, nil
+}
+
+func parsePayloadClassesField(field string, data interface{}, legacy bool) (map[string]PayloadClass, error) {
 	if data == nil {
-		return nil
+		return nil, nil
+	}
+	classes, err := coercedMap(field, data)
+	if err != nil {
+		return nil, err
 	}
 
-	result := make(map[string]PayloadClass)
-	for name, val := range data.(map[string]interface{}) {
-		result[name] = parsePayloadClass(name, val)
+	var result map[string]PayloadClass
+	if !legacy {
+		result = make(map[string]PayloadClass)
+	}
+	for name, val := range classes {
+		pc, err := parsePayloadClass(name, val)
+		if err != nil {
+			return nil, err
+		}
+		if legacy && pc.Type == "" {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]PayloadClass)
+		}
+		result[name] = pc
 	}
 
-	return result
+	return result, nil
 }
 
-func parsePayloadClass(name string, data interface{}) PayloadClass {
+func parsePayloadClass(name string, data interface{}) (PayloadClass, error) {
 	payloadClass := PayloadClass{
 		Name: name,
 	}
 	if data == nil {
-		return payloadClass
+		return payloadClass, nil
+	}
+	field := "payloads." + name
+	pcMap, err := coercedMap(field, data)
+	if err != nil {
+		return PayloadClass{}, err
 	}
-	pcMap := data.(map[string]interface{})
 
 	if val := pcMap["type"]; val != nil {
-		payloadClass.Type = val.(string)
+		if payloadClass.Type, err = coercedString(field+".type", val); err != nil {
+			return PayloadClass{}, err
+		}
 	}
 
-	return payloadClass
+	return payloadClass, nil
 }
 
 // Validate checks the payload class to ensure its data is valid.
@@ -71,3 +174,41 @@ func (pc PayloadClass) Validate() error {
 
 	return nil
 }
+
+// ValidateType checks that the payload class has one of the allowed
+// types. If no types are given, DefaultPayloadTypes is used.
+func (pc PayloadClass) ValidateType(allowed ...string) error {
+	if len(allowed) == 0 {
+		allowed = defaultPayloadTypes
+	}
+	allowedSet := set.NewStrings(allowed...)
+	if allowedSet.Contains(pc.Type) {
+		return nil
+	}
+	return errors.NewNotValid(nil, fmt.Sprintf("payload class %q type %q not valid, expected one of %s",
+		pc.Name, pc.Type, strings.Join(allowedSet.SortedValues(), ", ")))
+}
+
+// validatePayloadClasses checks the payload classes declared by meta
+// do not conflict with the containers it declares, as both describe the
+// workloads run by the charm. If checkTypes is true, it also checks
+// that the payload classes have allowed types, as by ValidateType.
+func validatePayloadClasses(meta *Meta, checkTypes bool, allowed []string) error {
+	classNames := make([]string, 0, len(meta.PayloadClasses))
+	for name := range meta.PayloadClasses {
+		classNames = append(classNames, name)
+	}
+	sort.Strings(classNames)
+	for _, name := range classNames {
+		pc := meta.PayloadClasses[name]
+		if checkTypes && pc.Type != "" {
+			if err := pc.ValidateType(allowed...); err != nil {
+				return err
+			}
+		}
+		if _, ok := meta.Containers[name]; ok {
+			return errors.NewNotValid(nil, fmt.Sprintf("payload class %q conflicts with container of the same name", name))
+		}
+	}
+	return nil
+}
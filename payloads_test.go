@@ -19,7 +19,8 @@ func (s *payloadClassSuite) TestParsePayloadClassOkay(c *gc.C) {
 	data := map[string]interface{}{
 		"type": "docker",
 	}
-	payloadClass := charm.ParsePayloadClass(name, data)
+	payloadClass, err := charm.ParsePayloadClass(name, data)
+	c.Assert(err, jc.ErrorIsNil)
 
 	c.Check(payloadClass, jc.DeepEquals, charm.PayloadClass{
 		Name: "my-payload",
@@ -32,7 +33,8 @@ func (s *payloadClassSuite) TestParsePayloadClassMissingName(c *gc.C) {
 	data := map[string]interface{}{
 		"type": "docker",
 	}
-	payloadClass := charm.ParsePayloadClass(name, data)
+	payloadClass, err := charm.ParsePayloadClass(name, data)
+	c.Assert(err, jc.ErrorIsNil)
 
 	c.Check(payloadClass, jc.DeepEquals, charm.PayloadClass{
 		Name: "",
@@ -43,7 +45,8 @@ func (s *payloadClassSuite) TestParsePayloadClassMissingName(c *gc.C) {
 func (s *payloadClassSuite) TestParsePayloadClassEmpty(c *gc.C) {
 	name := "my-payload"
 	var data map[string]interface{}
-	payloadClass := charm.ParsePayloadClass(name, data)
+	payloadClass, err := charm.ParsePayloadClass(name, data)
+	c.Assert(err, jc.ErrorIsNil)
 
 	c.Check(payloadClass, jc.DeepEquals, charm.PayloadClass{
 		Name: "my-payload",
@@ -94,3 +97,16 @@ func (s *payloadClassSuite) TestValidateMissingType(c *gc.C) {
 
 	c.Check(err, gc.ErrorMatches, `payload class missing type`)
 }
+
+func (s *payloadClassSuite) TestValidateType(c *gc.C) {
+	for _, t := range charm.DefaultPayloadTypes() {
+		payloadClass := charm.PayloadClass{Name: "my-payload", Type: t}
+		c.Check(payloadClass.ValidateType(), jc.ErrorIsNil)
+	}
+	payloadClass := charm.PayloadClass{Name: "my-payload", Type: "lxd"}
+	c.Check(payloadClass.ValidateType(), gc.ErrorMatches,
+		`payload class "my-payload" type "lxd" not valid, expected one of containerd, docker, kvm, rkt`)
+	c.Check(payloadClass.ValidateType("lxd"), jc.ErrorIsNil)
+	c.Check(charm.PayloadClass{Name: "my-payload", Type: "docker"}.ValidateType("lxd"), gc.ErrorMatches,
+		`payload class "my-payload" type "docker" not valid, expected one of lxd`)
+}
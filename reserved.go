@@ -0,0 +1,47 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import "strings"
+
+// IsReservedEndpointName reports whether endpoint, the name of a relation
+// or interface declared by the named charm, is reserved for use by juju,
+// and if so, why. The name "juju" and names with the "juju-" prefix are
+// reserved, except in charms whose own name has that prefix.
+//
+// Meta.Check applies this rule to the names of all relations and to the
+// interfaces of provided and peer relations. As an exception, container
+// scoped require relations of subordinate charms may use a reserved
+// name, so that they can relate to juju-info.
+func IsReservedEndpointName(charmName, endpoint string) (reserved bool, reason string) {
+	return reservedName(charmName, endpoint)
+}
+
+// IsReservedExtraBindingName reports whether name, the name of an extra
+// binding declared by the named charm, is reserved for use by juju, and
+// if so, why. Extra bindings share the namespace of relation endpoints,
+// so the rule is that of IsReservedEndpointName.
+func IsReservedExtraBindingName(charmName, name string) (reserved bool, reason string) {
+	return reservedName(charmName, name)
+}
+
+// IsReservedStorageName reports whether name, the name of storage
+// declared by the named charm, is reserved for use by juju, and if so,
+// why. The rule is that of IsReservedEndpointName.
+func IsReservedStorageName(charmName, name string) (reserved bool, reason string) {
+	return reservedName(charmName, name)
+}
+
+func reservedName(charmName, name string) (reserved bool, reason string) {
+	if strings.HasPrefix(charmName, "juju-") {
+		return false, ""
+	}
+	if name == "juju" {
+		return true, `"juju" is a reserved name`
+	}
+	if strings.HasPrefix(name, "juju-") {
+		return true, `the "juju-" prefix is reserved`
+	}
+	return false, ""
+}
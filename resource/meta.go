@@ -4,7 +4,11 @@
 package resource
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/url"
 	"strings"
 
 	"github.com/juju/errors"
@@ -32,6 +36,15 @@ type Meta struct {
 
 	// Description holds optional user-facing info for the resource.
 	Description string
+
+	// URL is the location from which a download resource is fetched.
+	// It is only set for resources of type TypeDownload.
+	URL string
+
+	// SHA256 holds the hex-encoded SHA-256 checksum of the content at
+	// URL, against which the downloaded data is verified. It is only
+	// set for resources of type TypeDownload.
+	SHA256 string
 }
 
 // Validate checks the resource metadata to ensure the data is valid.
@@ -49,11 +62,11 @@ func (meta Meta) Validate() error {
 		return errors.NewNotValid(nil, msg)
 	}
 
-	if meta.Type == TypeFile && meta.Path == "" {
+	if (meta.Type == TypeFile || meta.Type == TypeDownload) && meta.Path == "" {
 		// TODO(ericsnow) change "filename" to "path"
 		return errors.NewNotValid(nil, "resource missing filename")
 	}
-	if meta.Type == TypeFile {
+	if meta.Type == TypeFile || meta.Type == TypeDownload {
 		if strings.Contains(meta.Path, "/") {
 			msg := fmt.Sprintf(`filename cannot contain "/" (got %q)`, meta.Path)
 			return errors.NewNotValid(nil, msg)
@@ -61,5 +74,50 @@ func (meta Meta) Validate() error {
 		// TODO(ericsnow) Constrain Path to alphanumeric?
 	}
 
+	if meta.Type == TypeDownload {
+		if err := meta.validateDownload(); err != nil {
+			return errors.Trace(err)
+		}
+	} else if meta.URL != "" || meta.SHA256 != "" {
+		msg := fmt.Sprintf("url and sha256 are only valid for %s resources", TypeDownload)
+		return errors.NewNotValid(nil, msg)
+	}
+
+	return nil
+}
+
+// validateDownload checks the fields specific to download resources.
+func (meta Meta) validateDownload() error {
+	if meta.URL == "" {
+		return errors.NewNotValid(nil, "download resource missing url")
+	}
+	u, err := url.Parse(meta.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		msg := fmt.Sprintf("download resource url %q must be an absolute http or https URL", meta.URL)
+		return errors.NewNotValid(nil, msg)
+	}
+	if meta.SHA256 == "" {
+		return errors.NewNotValid(nil, "download resource missing sha256")
+	}
+	if sum, err := hex.DecodeString(meta.SHA256); err != nil || len(sum) != sha256.Size {
+		msg := fmt.Sprintf("download resource sha256 %q is not a hex-encoded SHA-256 checksum", meta.SHA256)
+		return errors.NewNotValid(nil, msg)
+	}
+	return nil
+}
+
+// VerifyDownload reads the data downloaded for a download resource
+// and checks that it matches the resource's SHA256 checksum.
+func (meta Meta) VerifyDownload(r io.Reader) error {
+	if meta.Type != TypeDownload {
+		return errors.NotValidf("verifying %s resource %q", meta.Type, meta.Name)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return errors.Trace(err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, meta.SHA256) {
+		return errors.Errorf("resource %q checksum mismatch: expected sha256 %s, got %s", meta.Name, meta.SHA256, got)
+	}
 	return nil
 }
@@ -4,6 +4,8 @@
 package resource_test
 
 import (
+	"strings"
+
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -116,3 +118,95 @@ func (s *MetaSuite) TestValidateMissingComment(c *gc.C) {
 
 	c.Check(err, jc.ErrorIsNil)
 }
+
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func (s *MetaSuite) TestValidateDownload(c *gc.C) {
+	res := resource.Meta{
+		Name:   "my-resource",
+		Type:   resource.TypeDownload,
+		Path:   "model.bin",
+		URL:    "https://example.com/model.bin",
+		SHA256: emptySHA256,
+	}
+	err := res.Validate()
+
+	c.Check(err, jc.ErrorIsNil)
+}
+
+func (s *MetaSuite) TestValidateDownloadInvalid(c *gc.C) {
+	valid := resource.Meta{
+		Name:   "my-resource",
+		Type:   resource.TypeDownload,
+		Path:   "model.bin",
+		URL:    "https://example.com/model.bin",
+		SHA256: emptySHA256,
+	}
+	for i, test := range []struct {
+		about  string
+		modify func(*resource.Meta)
+		err    string
+	}{{
+		about:  "missing url",
+		modify: func(m *resource.Meta) { m.URL = "" },
+		err:    `download resource missing url`,
+	}, {
+		about:  "relative url",
+		modify: func(m *resource.Meta) { m.URL = "model.bin" },
+		err:    `download resource url "model.bin" must be an absolute http or https URL`,
+	}, {
+		about:  "unsupported scheme",
+		modify: func(m *resource.Meta) { m.URL = "ftp://example.com/model.bin" },
+		err:    `download resource url "ftp://example.com/model.bin" must be an absolute http or https URL`,
+	}, {
+		about:  "missing sha256",
+		modify: func(m *resource.Meta) { m.SHA256 = "" },
+		err:    `download resource missing sha256`,
+	}, {
+		about:  "short sha256",
+		modify: func(m *resource.Meta) { m.SHA256 = "e3b0c442" },
+		err:    `download resource sha256 "e3b0c442" is not a hex-encoded SHA-256 checksum`,
+	}, {
+		about:  "missing filename",
+		modify: func(m *resource.Meta) { m.Path = "" },
+		err:    `resource missing filename`,
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		res := valid
+		test.modify(&res)
+		err := res.Validate()
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *MetaSuite) TestValidateURLOnlyForDownload(c *gc.C) {
+	res := resource.Meta{
+		Name: "my-resource",
+		Type: resource.TypeFile,
+		Path: "filename.tgz",
+		URL:  "https://example.com/filename.tgz",
+	}
+	err := res.Validate()
+
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, `url and sha256 are only valid for download resources`)
+}
+
+func (s *MetaSuite) TestVerifyDownload(c *gc.C) {
+	res := resource.Meta{
+		Name:   "my-resource",
+		Type:   resource.TypeDownload,
+		Path:   "model.bin",
+		URL:    "https://example.com/model.bin",
+		SHA256: emptySHA256,
+	}
+	c.Check(res.VerifyDownload(strings.NewReader("")), jc.ErrorIsNil)
+
+	err := res.VerifyDownload(strings.NewReader("tampered"))
+	c.Check(err, gc.ErrorMatches, `resource "my-resource" checksum mismatch: expected sha256 `+emptySHA256+`, got [0-9a-f]{64}`)
+
+	res.Type = resource.TypeFile
+	err = res.VerifyDownload(strings.NewReader(""))
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -12,11 +12,13 @@ const (
 	typeUnknown Type = iota
 	TypeFile
 	TypeContainerImage
+	TypeDownload
 )
 
 var types = map[Type]string{
 	TypeFile:           "file",
 	TypeContainerImage: "oci-image",
+	TypeDownload:       "download",
 }
 
 // Type enumerates the recognized resource types.
@@ -19,6 +19,7 @@ func (s *TypeSuite) TestParseTypeOkay(c *gc.C) {
 	for resourceType, expected := range map[string]resource.Type{
 		"file":      resource.TypeFile,
 		"oci-image": resource.TypeContainerImage,
+		"download":  resource.TypeDownload,
 	} {
 		rt, err := resource.ParseType(resourceType)
 		c.Assert(err, jc.ErrorIsNil)
@@ -47,6 +48,7 @@ func (s *TypeSuite) TestTypeStringSupported(c *gc.C) {
 	supported := map[resource.Type]string{
 		resource.TypeFile:           "file",
 		resource.TypeContainerImage: "oci-image",
+		resource.TypeDownload:       "download",
 	}
 	for rt, expected := range supported {
 		str := rt.String()
@@ -66,6 +68,7 @@ func (s *TypeSuite) TestTypeValidateSupported(c *gc.C) {
 	supported := []resource.Type{
 		resource.TypeFile,
 		resource.TypeContainerImage,
+		resource.TypeDownload,
 	}
 	for _, rt := range supported {
 		err := rt.Validate()
@@ -5,6 +5,7 @@ package charm
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/juju/errors"
 	"github.com/juju/schema"
@@ -12,18 +13,24 @@ import (
 	"github.com/juju/charm/v12/resource"
 )
 
-var resourceSchema = schema.FieldMap(
-	schema.Fields{
-		"type":        schema.String(),
-		"filename":    schema.String(), // TODO(ericsnow) Change to "path"?
-		"description": schema.String(),
-	},
-	schema.Defaults{
-		"type":        resource.TypeFile.String(),
-		"filename":    "",
-		"description": "",
-	},
-)
+var resourceSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"type":        schema.String(),
+			"filename":    schema.String(), // TODO(ericsnow) Change to "path"?
+			"description": schema.String(),
+			"url":         schema.String(),
+			"sha256":      schema.String(),
+		},
+		schema.Defaults{
+			"type":        resource.TypeFile.String(),
+			"filename":    "",
+			"description": "",
+			"url":         schema.Omit,
+			"sha256":      schema.Omit,
+		},
+	)
+})
 
 func parseMetaResources(data interface{}) (map[string]resource.Meta, error) {
 	if data == nil {
@@ -36,6 +43,14 @@ func parseMetaResources(data interface{}) (map[string]resource.Meta, error) {
 		if err != nil {
 			return nil, err
 		}
+		// A download resource is only useful if its checksum can be
+		// verified when it is fetched, so check it when it is read
+		// rather than waiting for the metadata to be checked.
+		if meta.Type == resource.TypeDownload {
+			if err := meta.Validate(); err != nil {
+				return nil, errors.Annotatef(err, "resource %q", name)
+			}
+		}
 		result[name] = meta
 	}
 
@@ -55,7 +70,7 @@ func validateMetaResources(resources map[string]resource.Meta) error {
 }
 
 // parseResourceMeta parses the provided data into a Meta, assuming
-// that the data has first been checked with resourceSchema.
+// that the data has first been checked with resourceSchema().
 func parseResourceMeta(name string, data interface{}) (resource.Meta, error) {
 	meta := resource.Meta{
 		Name: name,
@@ -82,5 +97,13 @@ func parseResourceMeta(name string, data interface{}) (resource.Meta, error) {
 		meta.Description = val.(string)
 	}
 
+	if val := rMap["url"]; val != nil {
+		meta.URL = val.(string)
+	}
+
+	if val := rMap["sha256"]; val != nil {
+		meta.SHA256 = val.(string)
+	}
+
 	return meta, nil
 }
@@ -0,0 +1,109 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+)
+
+// ScanResult holds the outcome of reading one charm directory found by
+// ScanCharmDirs.
+type ScanResult struct {
+	// Path holds the path to the charm directory.
+	Path string
+
+	// Dir holds the charm read from Path. It is nil if Err is set.
+	Dir *CharmDir
+
+	// Err holds any error encountered reading the charm.
+	Err error
+}
+
+// ScanCharmDirs walks the directory tree rooted at root and reads every
+// charm directory found in it, using up to the given number of workers
+// concurrently. A directory is taken to be a charm if IsCharmDir reports
+// so; directories inside a charm and hidden directories are not
+// searched.
+//
+// An error reading one charm does not stop the scan; it is reported in
+// the result for that charm. The results are sorted by path. An error is
+// returned only if the tree cannot be walked or ctx is done before the
+// scan completes.
+func ScanCharmDirs(ctx context.Context, root string, workers int) ([]ScanResult, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	paths := make(chan string)
+	results := make(chan ScanResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				dir, err := ReadCharmDirContext(ctx, path)
+				select {
+				case results <- ScanResult{Path: path, Dir: dir, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	var found []ScanResult
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for result := range results {
+			found = append(found, result)
+		}
+	}()
+
+	walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !IsCharmDir(path) {
+			return nil
+		}
+		select {
+		case paths <- path:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return filepath.SkipDir
+	})
+	close(paths)
+	wg.Wait()
+	close(results)
+	<-collected
+
+	if walkErr != nil {
+		return nil, errors.Annotatef(walkErr, "scanning %q", root)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Annotatef(err, "scanning %q", root)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Path < found[j].Path
+	})
+	return found, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/v3/fs"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type ScanSuite struct{}
+
+var _ = gc.Suite(&ScanSuite{})
+
+func (s *ScanSuite) makeTree(c *gc.C) string {
+	root := c.MkDir()
+	for _, p := range []struct{ name, dest string }{
+		{"dummy", "a/dummy"},
+		{"mysql", "b/c/mysql"},
+		{"wordpress", "wordpress"},
+		// Hidden directories are not searched.
+		{"varnish", ".hidden/varnish"},
+		// Nor are directories inside charms.
+		{"logging", "wordpress/nested/logging"},
+	} {
+		dest := filepath.Join(root, p.dest)
+		err := os.MkdirAll(filepath.Dir(dest), 0755)
+		c.Assert(err, jc.ErrorIsNil)
+		err = fs.Copy(charmDirPath(c, p.name), dest)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	broken := filepath.Join(root, "broken")
+	err := os.Mkdir(broken, 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	err = os.WriteFile(filepath.Join(broken, "metadata.yaml"), []byte("name: [\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	return root
+}
+
+func (s *ScanSuite) TestScanCharmDirs(c *gc.C) {
+	root := s.makeTree(c)
+	for _, workers := range []int{0, 1, 4} {
+		c.Logf("workers %d", workers)
+		results, err := charm.ScanCharmDirs(context.Background(), root, workers)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(results, gc.HasLen, 4)
+
+		c.Check(results[0].Path, gc.Equals, filepath.Join(root, "a/dummy"))
+		c.Check(results[0].Err, jc.ErrorIsNil)
+		c.Check(results[0].Dir.Meta().Name, gc.Equals, "dummy")
+
+		c.Check(results[1].Path, gc.Equals, filepath.Join(root, "b/c/mysql"))
+		c.Check(results[1].Err, jc.ErrorIsNil)
+		c.Check(results[1].Dir.Meta().Name, gc.Equals, "mysql")
+
+		c.Check(results[2].Path, gc.Equals, filepath.Join(root, "broken"))
+		c.Check(results[2].Err, gc.ErrorMatches, `parsing "metadata.yaml" file: .*`)
+		c.Check(results[2].Dir, gc.IsNil)
+
+		c.Check(results[3].Path, gc.Equals, filepath.Join(root, "wordpress"))
+		c.Check(results[3].Err, jc.ErrorIsNil)
+		c.Check(results[3].Dir.Meta().Name, gc.Equals, "wordpress")
+	}
+}
+
+func (s *ScanSuite) TestScanCharmDirsCancelled(c *gc.C) {
+	root := s.makeTree(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := charm.ScanCharmDirs(ctx, root, 2)
+	c.Assert(err, gc.ErrorMatches, `scanning ".*": context canceled`)
+}
+
+func (s *ScanSuite) TestScanCharmDirsMissingRoot(c *gc.C) {
+	_, err := charm.ScanCharmDirs(context.Background(), filepath.Join(c.MkDir(), "missing"), 2)
+	c.Assert(err, gc.ErrorMatches, `scanning ".*": .* no such file or directory`)
+}
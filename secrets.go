@@ -0,0 +1,160 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+)
+
+// SecretOwner describes who owns a secret declared by a charm.
+type SecretOwner string
+
+const (
+	// SecretOwnerApplication secrets are owned by the application and
+	// managed by its leader.
+	SecretOwnerApplication SecretOwner = "application"
+
+	// SecretOwnerUnit secrets are owned by each unit of the
+	// application.
+	SecretOwnerUnit SecretOwner = "unit"
+
+	// SecretOwnerConsumer secrets are owned by another application
+	// and consumed by this one.
+	SecretOwnerConsumer SecretOwner = "consumer"
+)
+
+// validSecretOwners holds the owners a secret may declare.
+var validSecretOwners = []SecretOwner{SecretOwnerApplication, SecretOwnerUnit, SecretOwnerConsumer}
+
+// SecretRotatePolicy describes how often an owned secret is rotated.
+type SecretRotatePolicy string
+
+// These are the rotate policies a secret may declare.
+const (
+	RotateNever     SecretRotatePolicy = "never"
+	RotateHourly    SecretRotatePolicy = "hourly"
+	RotateDaily     SecretRotatePolicy = "daily"
+	RotateWeekly    SecretRotatePolicy = "weekly"
+	RotateMonthly   SecretRotatePolicy = "monthly"
+	RotateQuarterly SecretRotatePolicy = "quarterly"
+	RotateYearly    SecretRotatePolicy = "yearly"
+)
+
+// validSecretRotatePolicies holds the rotate policies a secret may
+// declare.
+var validSecretRotatePolicies = []SecretRotatePolicy{
+	RotateNever, RotateHourly, RotateDaily, RotateWeekly, RotateMonthly, RotateQuarterly, RotateYearly,
+}
+
+// Secret describes a secret the charm manages or consumes, declared in
+// the secrets section of metadata.yaml.
+type Secret struct {
+	// Name holds the name of the secret, which is its key in the
+	// secrets section.
+	Name string `bson:"name" json:"name" yaml:"-"`
+
+	// Description holds an optional description of the secret.
+	Description string `bson:"description,omitempty" json:"description,omitempty" yaml:"description,omitempty"`
+
+	// RotatePolicy holds how often the secret is rotated. It may only
+	// be set for secrets the charm owns; empty means the secret is
+	// never rotated.
+	RotatePolicy SecretRotatePolicy `bson:"rotate-policy,omitempty" json:"rotate-policy,omitempty" yaml:"rotate-policy,omitempty"`
+
+	// Owner holds who owns the secret. It defaults to
+	// SecretOwnerApplication.
+	Owner SecretOwner `bson:"owner" json:"owner" yaml:"owner"`
+}
+
+// Rotates reports whether the secret is owned by the charm and rotated
+// periodically.
+func (s Secret) Rotates() bool {
+	return s.Owner != SecretOwnerConsumer && s.RotatePolicy != "" && s.RotatePolicy != RotateNever
+}
+
+// validSecretName matches the names of secrets.
+var validSecretName = regexp.MustCompile(`^[a-z][a-z0-9]*(?:-[a-z0-9]+)*$`)
+
+var secretSchema = sync.OnceValue(func() schema.Checker {
+	return schema.FieldMap(
+		schema.Fields{
+			"description":   schema.String(),
+			"rotate-policy": schema.String(),
+			"owner":         schema.String(),
+		}, schema.Defaults{
+			"description":   schema.Omit,
+			"rotate-policy": schema.Omit,
+			"owner":         string(SecretOwnerApplication),
+		},
+	)
+})
+
+// parseSecrets parses the coerced secrets section of metadata.yaml. It
+// returns nil if the section is absent.
+func parseSecrets(value interface{}) (map[string]Secret, error) {
+	if value == nil {
+		return nil, nil
+	}
+	m, err := coercedMap("secrets", value)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make(map[string]Secret, len(m))
+	for name, v := range m {
+		path := joinField("secrets", name)
+		if !validSecretName.MatchString(name) {
+			return nil, errors.NotValidf("metadata: secret name %q", name)
+		}
+		fields, err := coercedMap(path, v)
+		if err != nil {
+			return nil, err
+		}
+		secret := Secret{Name: name}
+		if secret.Description, err = optionalField[string](fields, path, "description"); err != nil {
+			return nil, err
+		}
+		owner, err := optionalField[string](fields, path, "owner")
+		if err != nil {
+			return nil, err
+		}
+		secret.Owner = SecretOwner(owner)
+		if !slices.Contains(validSecretOwners, secret.Owner) {
+			return nil, errors.NotValidf("metadata: %s: owner %q (expected one of %s)",
+				path, owner, joinValues(validSecretOwners))
+		}
+		policy, err := optionalField[string](fields, path, "rotate-policy")
+		if err != nil {
+			return nil, err
+		}
+		secret.RotatePolicy = SecretRotatePolicy(policy)
+		if policy != "" {
+			if !slices.Contains(validSecretRotatePolicies, secret.RotatePolicy) {
+				return nil, errors.NotValidf("metadata: %s: rotate-policy %q (expected one of %s)",
+					path, policy, joinValues(validSecretRotatePolicies))
+			}
+			if secret.Owner == SecretOwnerConsumer {
+				return nil, errors.NotValidf("metadata: %s: rotate-policy for a consumed secret", path)
+			}
+		}
+		secrets[name] = secret
+	}
+	return secrets, nil
+}
+
+// joinValues returns the given values sorted and separated by commas.
+func joinValues[T ~string](values []T) string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = string(v)
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, ", ")
+}
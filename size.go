@@ -0,0 +1,105 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// ContentSize describes the size of the files making up a charm.
+type ContentSize struct {
+	// Bytes holds the total uncompressed size of the files.
+	Bytes int64
+
+	// Files holds the number of files, including symlinks but not
+	// directories.
+	Files int
+
+	// LargestFile holds the slash-separated path of the largest file
+	// within the charm.
+	LargestFile string
+
+	// LargestFileBytes holds the uncompressed size of the largest file.
+	LargestFileBytes int64
+}
+
+// add accounts for the file with the given path and size.
+func (s *ContentSize) add(path string, size int64) {
+	s.Bytes += size
+	s.Files++
+	if size > s.LargestFileBytes || s.LargestFile == "" {
+		s.LargestFile, s.LargestFileBytes = path, size
+	}
+}
+
+// Size returns the size of the files that would be written by
+// ArchiveTo, without reading their content. Files excluded by the
+// charm's .jujuignore file are not counted, nor are the revision and
+// version files that ArchiveTo adds. A symlink counts as a file whose
+// size is the length of its target.
+func (dir *CharmDir) Size() (ContentSize, error) {
+	ignoreRules, err := dir.buildIgnoreRules()
+	if err != nil {
+		return ContentSize{}, errors.Trace(err)
+	}
+	root, err := resolveSymlinkedRoot(dir.Path)
+	if err != nil {
+		return ContentSize{}, errors.Trace(err)
+	}
+	var size ContentSize
+	err = filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relpath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		relpath = filepath.ToSlash(relpath)
+		if ignoreRules.Match(relpath, fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case fi.IsDir():
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			size.add(relpath, int64(len(target)))
+		default:
+			size.add(relpath, fi.Size())
+		}
+		return nil
+	})
+	if err != nil {
+		return ContentSize{}, errors.Trace(err)
+	}
+	return size, nil
+}
+
+// Size returns the size of the files held in the archive, as recorded
+// in its directory, without decompressing them.
+func (a *CharmArchive) Size() (ContentSize, error) {
+	zipr, err := a.zopen.openZip()
+	if err != nil {
+		return ContentSize{}, errors.Trace(err)
+	}
+	defer zipr.Close()
+	var size ContentSize
+	for _, f := range zipr.File {
+		if strings.HasSuffix(f.Name, "/") || f.FileInfo().IsDir() {
+			continue
+		}
+		size.add(f.Name, int64(f.UncompressedSize64))
+	}
+	return size, nil
+}
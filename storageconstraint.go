@@ -0,0 +1,119 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/v3"
+)
+
+// validStoragePoolName matches the names Juju accepts for storage pools.
+var validStoragePoolName = regexp.MustCompile(`^[a-zA-Z]+[-?a-zA-Z0-9]*$`)
+
+// StorageConstraint describes the storage requested for one of an
+// application's stores, as given in the storage section of a bundle
+// application.
+type StorageConstraint struct {
+	// Pool names the storage pool from which the storage is
+	// provisioned. It is empty if the default pool is to be used.
+	Pool string
+
+	// Count is the number of storage instances requested.
+	Count uint64
+
+	// Size is the requested size of each storage instance in
+	// megabytes, or zero if no size is given.
+	Size uint64
+}
+
+// ParseStorageConstraint parses a storage constraint of the form
+//
+//	[<pool>][,<count>][,<size>]
+//
+// for example "ebs,3,10G", "10G" or "ebs". The pool, if given, must
+// come first; the count and size may follow in either order. The count
+// defaults to 1 when omitted, and a size without a suffix is in
+// megabytes. Errors name the component that could not be parsed.
+func ParseStorageConstraint(s string) (StorageConstraint, error) {
+	if s == "" {
+		return StorageConstraint{}, errors.NotValidf("empty storage constraint")
+	}
+	cons := StorageConstraint{Count: 1}
+	var haveCount, haveSize bool
+	for i, field := range strings.Split(s, ",") {
+		switch {
+		case field == "":
+			return StorageConstraint{}, errors.NotValidf("empty field %d in storage constraint %q", i+1, s)
+		case isStorageCount(field):
+			if haveCount {
+				return StorageConstraint{}, errors.NotValidf("storage constraint %q with more than one count", s)
+			}
+			count, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return StorageConstraint{}, errors.NotValidf("storage count %q in %q", field, s)
+			}
+			cons.Count, haveCount = count, true
+		case field[0] >= '0' && field[0] <= '9':
+			if haveSize {
+				return StorageConstraint{}, errors.NotValidf("storage constraint %q with more than one size", s)
+			}
+			size, err := utils.ParseSize(field)
+			if err != nil {
+				return StorageConstraint{}, errors.NotValidf("storage size %q in %q", field, s)
+			}
+			cons.Size, haveSize = size, true
+		case i == 0:
+			if !validStoragePoolName.MatchString(field) {
+				return StorageConstraint{}, errors.NotValidf("storage pool name %q in %q", field, s)
+			}
+			cons.Pool = field
+		default:
+			return StorageConstraint{}, errors.NotValidf("storage constraint %q with pool %q not given first", s, field)
+		}
+	}
+	return cons, nil
+}
+
+// isStorageCount reports whether field consists only of digits, and so
+// is a count rather than a size.
+func isStorageCount(field string) bool {
+	return strings.Trim(field, "0123456789") == ""
+}
+
+// ValidateStorageConstraint returns an error if s cannot be parsed by
+// ParseStorageConstraint. It may be given to BundleData.Verify to check
+// the storage constraints of a bundle's applications.
+func ValidateStorageConstraint(s string) error {
+	_, err := ParseStorageConstraint(s)
+	return err
+}
+
+// ParseStorage returns the storage constraints of the application,
+// keyed by store name, as parsed by ParseStorageConstraint. It returns
+// nil if the application declares no storage, and an error naming the
+// store if any of the constraints cannot be parsed.
+func (app *ApplicationSpec) ParseStorage() (map[string]StorageConstraint, error) {
+	if len(app.Storage) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(app.Storage))
+	for name := range app.Storage {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make(map[string]StorageConstraint, len(names))
+	for _, name := range names {
+		cons, err := ParseStorageConstraint(app.Storage[name])
+		if err != nil {
+			return nil, errors.Annotatef(err, "storage %q", name)
+		}
+		result[name] = cons
+	}
+	return result, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type StorageConstraintSuite struct{}
+
+var _ = gc.Suite(&StorageConstraintSuite{})
+
+var storageConstraintTests = []struct {
+	s      string
+	expect charm.StorageConstraint
+	err    string
+}{{
+	s:      "10G",
+	expect: charm.StorageConstraint{Count: 1, Size: 10 * 1024},
+}, {
+	s:      "ebs",
+	expect: charm.StorageConstraint{Pool: "ebs", Count: 1},
+}, {
+	s:      "ebs-ssd,3,10G",
+	expect: charm.StorageConstraint{Pool: "ebs-ssd", Count: 3, Size: 10 * 1024},
+}, {
+	s:      "10G,3",
+	expect: charm.StorageConstraint{Count: 3, Size: 10 * 1024},
+}, {
+	s:      "rootfs,0",
+	expect: charm.StorageConstraint{Pool: "rootfs", Count: 0},
+}, {
+	s:   "",
+	err: `empty storage constraint not valid`,
+}, {
+	s:   "ebs,,10G",
+	err: `empty field 2 in storage constraint "ebs,,10G" not valid`,
+}, {
+	s:   "ebs,3,4",
+	err: `storage constraint "ebs,3,4" with more than one count not valid`,
+}, {
+	s:   "1G,2G",
+	err: `storage constraint "1G,2G" with more than one size not valid`,
+}, {
+	s:   "ebs,10Q",
+	err: `storage size "10Q" in "ebs,10Q" not valid`,
+}, {
+	s:   "bad pool,10G",
+	err: `storage pool name "bad pool" in "bad pool,10G" not valid`,
+}, {
+	s:   "10G,ebs",
+	err: `storage constraint "10G,ebs" with pool "ebs" not given first not valid`,
+}}
+
+func (s *StorageConstraintSuite) TestParseStorageConstraint(c *gc.C) {
+	for i, t := range storageConstraintTests {
+		c.Logf("test %d: %q", i, t.s)
+		cons, err := charm.ParseStorageConstraint(t.s)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+			continue
+		}
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(cons, jc.DeepEquals, t.expect)
+	}
+}
+
+func (s *StorageConstraintSuite) TestParseStorage(c *gc.C) {
+	bd, err := charm.ReadBundleData(strings.NewReader(`
+applications:
+    ceph:
+        charm: ceph
+        storage:
+            osd-devices: ebs,3,10G
+            journal: 1G
+    bad:
+        charm: bad
+        storage:
+            osd-devices: ebs,3,10G
+            journal: bad pool
+    none:
+        charm: none
+`))
+	c.Assert(err, jc.ErrorIsNil)
+
+	storage, err := bd.Applications["ceph"].ParseStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(storage, jc.DeepEquals, map[string]charm.StorageConstraint{
+		"osd-devices": {Pool: "ebs", Count: 3, Size: 10 * 1024},
+		"journal":     {Count: 1, Size: 1024},
+	})
+
+	_, err = bd.Applications["bad"].ParseStorage()
+	c.Check(err, gc.ErrorMatches, `storage "journal": storage pool name "bad pool" in "bad pool" not valid`)
+
+	storage, err = bd.Applications["none"].ParseStorage()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(storage, gc.IsNil)
+}
+
+func (s *StorageConstraintSuite) TestVerifyStorage(c *gc.C) {
+	const data = `
+applications:
+    ceph:
+        charm: ceph
+        storage:
+            osd-devices: ebs,3,10G
+            journal: bad pool
+`
+	bd, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Without a storage verifier, storage constraints are not checked.
+	err = bd.Verify(nil, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = bd.Verify(nil, charm.ValidateStorageConstraint, nil)
+	c.Assert(err, gc.ErrorMatches, `invalid storage "journal" in application "ceph": storage pool name "bad pool" in "bad pool" not valid`)
+
+	// Verification does not change the bundle.
+	want, err := charm.ReadBundleData(strings.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd, jc.DeepEquals, want)
+}
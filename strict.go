@@ -0,0 +1,105 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ReadMetaStrict is like ReadMeta, except that it returns an error if
+// any mapping in the metadata.yaml content holds the same key more than
+// once, rather than silently using the last value.
+func ReadMetaStrict(r io.Reader, options ...ReadMetaOption) (*Meta, error) {
+	data, err := readStrict(r)
+	if err != nil {
+		return nil, errors.Annotate(err, "metadata")
+	}
+	return ReadMeta(bytes.NewReader(data), options...)
+}
+
+// ReadConfigStrict is like ReadConfig, except that it returns an error
+// if any mapping in the config.yaml content holds the same key more than
+// once, such as an option defined twice.
+func ReadConfigStrict(r io.Reader) (*Config, error) {
+	data, err := readStrict(r)
+	if err != nil {
+		return nil, errors.Annotate(err, "invalid config")
+	}
+	return ReadConfig(bytes.NewReader(data))
+}
+
+// ReadBundleDataStrict is like ReadBundleData, except that it returns an
+// error if any mapping in any of the bundle documents holds the same key
+// more than once, such as an application defined twice.
+func ReadBundleDataStrict(r io.Reader, options ...ReadBundleDataOption) (*BundleData, error) {
+	data, err := readStrict(r)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot unmarshal bundle contents")
+	}
+	return ReadBundleData(bytes.NewReader(data), options...)
+}
+
+// readStrict reads all the YAML documents from r and checks that none of
+// them holds duplicate mapping keys. It returns the data read.
+func readStrict(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		// Decoding into a MapSlice keeps every key, including
+		// duplicates, for this mapping and all those nested in it.
+		var doc yaml.MapSlice
+		if err := dec.Decode(&doc); err == io.EOF {
+			return data, nil
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := checkDuplicateKeys(doc, nil); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+}
+
+// checkDuplicateKeys returns an error describing the first duplicate
+// mapping key found in v, which is found at the given path.
+func checkDuplicateKeys(v interface{}, path []string) error {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		seen := make(map[interface{}]bool)
+		for _, item := range v {
+			key := item.Key
+			if _, ok := key.(yaml.MapSlice); ok {
+				// Complex keys are not comparable; they are
+				// not used by any of the charm formats.
+				key = fmt.Sprint(key)
+			}
+			if seen[key] {
+				if len(path) == 0 {
+					return errors.Errorf("duplicate key %q", fmt.Sprint(item.Key))
+				}
+				return errors.Errorf("duplicate key %q in %q", fmt.Sprint(item.Key), strings.Join(path, "."))
+			}
+			seen[key] = true
+			if err := checkDuplicateKeys(item.Value, append(path, fmt.Sprint(item.Key))); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			if err := checkDuplicateKeys(elem, append(path, fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type StrictSuite struct{}
+
+var _ = gc.Suite(&StrictSuite{})
+
+func (s *StrictSuite) TestReadMetaStrict(c *gc.C) {
+	meta, err := charm.ReadMetaStrict(repoMeta(c, "dummy"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(meta.Name, gc.Equals, "dummy")
+
+	_, err = charm.ReadMetaStrict(strings.NewReader(`
+name: dup
+summary: b
+description: c
+summary: d
+`))
+	c.Assert(err, gc.ErrorMatches, `metadata: duplicate key "summary"`)
+
+	_, err = charm.ReadMetaStrict(strings.NewReader(`
+name: dup
+summary: b
+description: c
+requires:
+    db:
+        interface: mysql
+    db:
+        interface: pgsql
+`))
+	c.Assert(err, gc.ErrorMatches, `metadata: duplicate key "db" in "requires"`)
+}
+
+func (s *StrictSuite) TestReadConfigStrict(c *gc.C) {
+	config, err := charm.ReadConfigStrict(strings.NewReader(`
+options:
+    title: {default: My Title, description: title, type: string}
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(config.Options, gc.HasLen, 1)
+
+	_, err = charm.ReadConfigStrict(strings.NewReader(`
+options:
+    title: {default: My Title, description: title, type: string}
+    title: {default: 3, description: title, type: int}
+`))
+	c.Assert(err, gc.ErrorMatches, `invalid config: duplicate key "title" in "options"`)
+}
+
+func (s *StrictSuite) TestReadBundleDataStrict(c *gc.C) {
+	bd, err := charm.ReadBundleDataStrict(strings.NewReader(mediawikiBundle))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(bd.Applications, gc.HasLen, 2)
+
+	_, err = charm.ReadBundleDataStrict(strings.NewReader(`
+applications:
+    mysql:
+        charm: ch:mysql
+    mysql:
+        charm: ch:mariadb
+`))
+	c.Assert(err, gc.ErrorMatches, `cannot unmarshal bundle contents: duplicate key "mysql" in "applications"`)
+
+	// Duplicates are detected in overlay documents and in lists too.
+	_, err = charm.ReadBundleDataStrict(strings.NewReader(`
+applications:
+    mysql:
+        charm: ch:mysql
+--- # overlay
+applications:
+    mysql:
+        offers:
+            db:
+                endpoints: [server]
+                acl:
+                    admin: admin
+                    admin: consume
+`))
+	c.Assert(err, gc.ErrorMatches, `cannot unmarshal bundle contents: duplicate key "admin" in "applications.mysql.offers.db.acl"`)
+
+	// Options are passed on to ReadBundleData.
+	_, err = charm.ReadBundleDataStrict(strings.NewReader(`
+applications:
+    mysql:
+        charm: ch:mysql
+        optons: {}
+`), charm.WithStrictApplications())
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// ReadCharmTarball returns a CharmArchive for the charm held in the
+// gzipped tarball read from r. The charm is converted to the zip format
+// in memory, so make sure it fits before using this.
+func ReadCharmTarball(r io.Reader) (*CharmArchive, error) {
+	var buf bytes.Buffer
+	if err := ConvertTarballToArchive(r, &buf); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return ReadCharmArchiveBytes(buf.Bytes())
+}
+
+// ConvertTarballToArchive reads a charm from the gzipped tarball in r
+// and writes it to w as a zip archive, in the format expected by
+// ReadCharmArchive. Entries are rejected if they would be expanded
+// outside of the charm directory, and only directories, regular files
+// and symlinks are accepted.
+func ConvertTarballToArchive(r io.Reader, w io.Writer) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Annotate(err, "reading charm tarball")
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	zipw := zip.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Annotate(err, "reading charm tarball")
+		}
+		if err := addTarEntry(zipw, tr, hdr); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(zipw.Close())
+}
+
+// addTarEntry adds the tar entry described by hdr, with content read
+// from tr, to zipw.
+func addTarEntry(zipw *zip.Writer, tr *tar.Reader, hdr *tar.Header) error {
+	switch hdr.Typeflag {
+	case tar.TypeXGlobalHeader:
+		return nil
+	case tar.TypeLink:
+		return errors.Errorf("file is a hard link: %q", hdr.Name)
+	}
+	mode := hdr.FileInfo().Mode()
+	if err := checkFileType(hdr.Name, mode); err != nil {
+		return err
+	}
+	name := path.Clean(hdr.Name)
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return errors.Errorf("file %q is outside of the charm", hdr.Name)
+	}
+	if name == "." {
+		// The root of the charm itself.
+		return nil
+	}
+
+	method := zip.Deflate
+	if mode.IsDir() {
+		name += "/"
+		method = zip.Store
+	}
+	perm := os.FileMode(0644)
+	if mode&os.ModeSymlink != 0 {
+		if err := checkSymlinkTarget("", name, hdr.Linkname); err != nil {
+			return err
+		}
+		method = zip.Store
+		perm = 0777
+	} else if mode&0100 != 0 {
+		perm = 0755
+	}
+	h := &zip.FileHeader{
+		Name:   name,
+		Method: method,
+	}
+	h.SetMode(mode&^0777 | perm)
+
+	fw, err := zipw.CreateHeader(h)
+	if err != nil || mode.IsDir() {
+		return errors.Trace(err)
+	}
+	if mode&os.ModeSymlink != 0 {
+		_, err = fw.Write([]byte(hdr.Linkname))
+	} else {
+		_, err = io.Copy(fw, tr)
+	}
+	return errors.Trace(err)
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type TarballSuite struct{}
+
+var _ = gc.Suite(&TarballSuite{})
+
+// charmTarball returns the content of the charm directory at path as a
+// gzipped tarball, with entry names prefixed by "./".
+func charmTarball(c *gc.C, path string) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	err := filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		var link string
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = "./" + filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if fi.Mode().IsRegular() {
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			_, err = tw.Write(data)
+			return err
+		}
+		return nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tw.Close(), jc.ErrorIsNil)
+	c.Assert(gzw.Close(), jc.ErrorIsNil)
+	return buf.Bytes()
+}
+
+// tarballOf returns a gzipped tarball holding the given entries.
+func tarballOf(c *gc.C, hdrs ...*tar.Header) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for _, hdr := range hdrs {
+		c.Assert(tw.WriteHeader(hdr), jc.ErrorIsNil)
+	}
+	c.Assert(tw.Close(), jc.ErrorIsNil)
+	c.Assert(gzw.Close(), jc.ErrorIsNil)
+	return buf.Bytes()
+}
+
+func (s *TarballSuite) TestReadCharmTarball(c *gc.C) {
+	dir := readCharmDir(c, "dummy")
+	archive, err := charm.ReadCharmTarball(bytes.NewReader(charmTarball(c, dir.Path)))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(archive.Meta(), jc.DeepEquals, dir.Meta())
+	c.Assert(archive.Config(), jc.DeepEquals, dir.Config())
+	c.Assert(archive.Actions(), jc.DeepEquals, dir.Actions())
+	c.Assert(archive.Revision(), gc.Equals, dir.Revision())
+}
+
+func (s *TarballSuite) TestConvertTarballToArchive(c *gc.C) {
+	dir := readCharmDir(c, "dummy")
+	var buf bytes.Buffer
+	err := charm.ConvertTarballToArchive(bytes.NewReader(charmTarball(c, dir.Path)), &buf)
+	c.Assert(err, jc.ErrorIsNil)
+
+	archive, err := charm.ReadCharmArchiveBytes(buf.Bytes())
+	c.Assert(err, jc.ErrorIsNil)
+	members, err := archive.ArchiveMembers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(members.Contains("metadata.yaml"), jc.IsTrue)
+	c.Assert(members.Contains("hooks/install"), jc.IsTrue)
+
+	target := c.MkDir()
+	err = archive.ExpandTo(target)
+	c.Assert(err, jc.ErrorIsNil)
+	info, err := os.Stat(filepath.Join(target, "hooks", "install"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Mode()&0100, gc.Not(gc.Equals), os.FileMode(0))
+}
+
+func (s *TarballSuite) TestConvertTarballErrors(c *gc.C) {
+	for i, test := range []struct {
+		about  string
+		data   []byte
+		expect string
+	}{{
+		about:  "not gzipped",
+		data:   []byte("metadata.yaml"),
+		expect: "reading charm tarball: .*",
+	}, {
+		about: "relative path outside the charm",
+		data: tarballOf(c, &tar.Header{
+			Name: "../metadata.yaml", Typeflag: tar.TypeReg, Mode: 0644,
+		}),
+		expect: `file "../metadata.yaml" is outside of the charm`,
+	}, {
+		about: "absolute path",
+		data: tarballOf(c, &tar.Header{
+			Name: "/etc/passwd", Typeflag: tar.TypeReg, Mode: 0644,
+		}),
+		expect: `file "/etc/passwd" is outside of the charm`,
+	}, {
+		about: "symlink outside the charm",
+		data: tarballOf(c, &tar.Header{
+			Name: "hooks/install", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd", Mode: 0777,
+		}),
+		expect: `symlink "hooks/install" links out of charm: "../../etc/passwd"`,
+	}, {
+		about: "named pipe",
+		data: tarballOf(c, &tar.Header{
+			Name: "fifo", Typeflag: tar.TypeFifo, Mode: 0644,
+		}),
+		expect: `file is a named pipe: "fifo"`,
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		err := charm.ConvertTarballToArchive(bytes.NewReader(test.data), &bytes.Buffer{})
+		c.Check(err, gc.ErrorMatches, test.expect)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/collections/set"
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// MetaTranslation holds a charm's summary and description translated
+// into a single language, as read from a metadata.<lang>.yaml file in
+// the charm, for example metadata.fr.yaml or metadata.pt-BR.yaml.
+type MetaTranslation struct {
+	Summary     string `bson:"summary,omitempty" json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string `bson:"description,omitempty" json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// metaTranslationFileRE matches the names of translation files, holding
+// the language in its first group. The language is a BCP 47 tag made of
+// a language subtag, optionally followed by a script subtag and a region
+// subtag, such as "fr", "pt-BR" or "zh-Hant-TW". As BCP 47 tags are case
+// insensitive, and underscores are commonly used as separators, both
+// are accepted.
+var metaTranslationFileRE = lazyRegexp(`(?i)^metadata\.([a-z]{2,3}(?:[-_][a-z]{4})?(?:[-_](?:[a-z]{2}|[0-9]{3}))?)\.yaml$`)
+
+// backupFileSuffixes holds the suffixes commonly given to backup and
+// editor copies of metadata.yaml, such as metadata.bak.yaml, which look
+// like language subtags but are not treated as translations.
+var backupFileSuffixes = set.NewStrings("bak", "new", "old", "sav", "swp", "tmp")
+
+// metaTranslationLanguage returns the canonical language tag of the
+// translation held in the named file, and whether the file holds a
+// translation at all.
+func metaTranslationLanguage(name string) (string, bool) {
+	m := metaTranslationFileRE().FindStringSubmatch(name)
+	if m == nil || backupFileSuffixes.Contains(strings.ToLower(m[1])) {
+		return "", false
+	}
+	return canonicalLanguageTag(m[1]), true
+}
+
+// canonicalLanguageTag returns the BCP 47 language tag lang in its
+// canonical form, with subtags separated by hyphens, the language in
+// lower case, the script in title case and the region in upper case, as
+// in "zh-Hant-TW".
+func canonicalLanguageTag(lang string) string {
+	subtags := strings.FieldsFunc(lang, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+	for i, subtag := range subtags {
+		switch {
+		case i == 0:
+			subtags[i] = strings.ToLower(subtag)
+		case len(subtag) == 4:
+			subtags[i] = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		default:
+			subtags[i] = strings.ToUpper(subtag)
+		}
+	}
+	return strings.Join(subtags, "-")
+}
+
+// ReadMetaTranslation reads the content of a metadata.<lang>.yaml file.
+// Fields other than summary and description are rejected.
+func ReadMetaTranslation(r io.Reader) (MetaTranslation, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return MetaTranslation{}, errors.Trace(err)
+	}
+	var t MetaTranslation
+	if err := yaml.UnmarshalStrict(data, &t); err != nil {
+		return MetaTranslation{}, errors.Trace(err)
+	}
+	return t, nil
+}
+
+// ReadMetaTranslations reads every metadata.<lang>.yaml file in the
+// charm directory at dir and returns the translations keyed by their
+// canonical language tag, such as "pt-BR" for metadata.pt_br.yaml.
+// Files whose names do not hold a language tag, such as a backup copy
+// of metadata.yaml named metadata.bak.yaml, are ignored; an error is
+// returned if a translation file cannot be parsed. It returns nil if
+// there are no translations.
+func ReadMetaTranslations(dir string) (map[string]MetaTranslation, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var translations map[string]MetaTranslation
+	for _, entry := range entries {
+		lang, ok := metaTranslationLanguage(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.Annotatef(err, "reading %q file", entry.Name())
+		}
+		t, err := ReadMetaTranslation(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, errors.Annotatef(err, "parsing %q file", entry.Name())
+		}
+		if translations == nil {
+			translations = make(map[string]MetaTranslation)
+		}
+		translations[lang] = t
+	}
+	return translations, nil
+}
+
+// Localized returns the summary and description of the charm in the
+// given language, such as "fr" or "pt-BR". Language tags are compared
+// case insensitively, so "pt_br" finds the same translation. If there
+// is no translation for the language, the translation for its base
+// language ("pt" for "pt-BR") is used. Any text not translated is
+// taken from the charm's own summary and description.
+func (m *Meta) Localized(lang string) MetaTranslation {
+	result := MetaTranslation{
+		Summary:     m.Summary,
+		Description: m.Description,
+	}
+	lang = canonicalLanguageTag(lang)
+	t, ok := m.Translations[lang]
+	if !ok {
+		base, _, _ := strings.Cut(lang, "-")
+		t = m.Translations[base]
+	}
+	if t.Summary != "" {
+		result.Summary = t.Summary
+	}
+	if t.Description != "" {
+		result.Description = t.Description
+	}
+	return result
+}
+
+// readArchiveMetaTranslations reads the translations held in the
+// metadata.<lang>.yaml files at the root of a charm archive, as
+// ReadMetaTranslations does for a charm directory.
+func readArchiveMetaTranslations(zipr *zipReadCloser) (map[string]MetaTranslation, error) {
+	var translations map[string]MetaTranslation
+	for _, fh := range zipr.File {
+		lang, ok := metaTranslationLanguage(fh.Name)
+		if !ok {
+			continue
+		}
+		reader, err := fh.Open()
+		if err != nil {
+			return nil, errors.Annotatef(err, "opening %q file", fh.Name)
+		}
+		t, err := ReadMetaTranslation(reader)
+		_ = reader.Close()
+		if err != nil {
+			return nil, errors.Annotatef(err, "parsing %q file", fh.Name)
+		}
+		if translations == nil {
+			translations = make(map[string]MetaTranslation)
+		}
+		translations[lang] = t
+	}
+	return translations, nil
+}
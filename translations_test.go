@@ -0,0 +1,145 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type TranslationsSuite struct{}
+
+var _ = gc.Suite(&TranslationsSuite{})
+
+func writeTranslations(c *gc.C, dir string) {
+	files := map[string]string{
+		"metadata.fr.yaml":    "summary: Un résumé\ndescription: Une description\n",
+		"metadata.pt-BR.yaml": "summary: Um resumo\n",
+		"metadata.yaml.orig":  "not a translation",
+		"metadata.bak.yaml":   "name: dummy\nsummary: A summary\n",
+		"metadata.v2.yaml":    "summary: Not a language\n",
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+var expectTranslations = map[string]charm.MetaTranslation{
+	"fr":    {Summary: "Un résumé", Description: "Une description"},
+	"pt-BR": {Summary: "Um resumo"},
+}
+
+func (s *TranslationsSuite) TestReadMetaTranslations(c *gc.C) {
+	dir := cloneDir(c, charmDirPath(c, "dummy"))
+	writeTranslations(c, dir)
+
+	translations, err := charm.ReadMetaTranslations(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(translations, jc.DeepEquals, expectTranslations)
+}
+
+func (s *TranslationsSuite) TestReadMetaTranslationsNone(c *gc.C) {
+	translations, err := charm.ReadMetaTranslations(charmDirPath(c, "dummy"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(translations, gc.IsNil)
+}
+
+func (s *TranslationsSuite) TestReadMetaTranslationUnknownField(c *gc.C) {
+	_, err := charm.ReadMetaTranslation(strings.NewReader("sumary: Zusammenfassung\n"))
+	c.Assert(err, gc.ErrorMatches, `(?s).*field sumary not found.*`)
+}
+
+func (s *TranslationsSuite) TestReadMetaTranslationsInvalid(c *gc.C) {
+	path := cloneDir(c, charmDirPath(c, "dummy"))
+	err := os.WriteFile(filepath.Join(path, "metadata.de.yaml"), []byte("sumary: Zusammenfassung\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = charm.ReadMetaTranslations(path)
+	c.Assert(err, gc.ErrorMatches, `(?s)parsing "metadata.de.yaml" file: .*field sumary not found.*`)
+	_, err = charm.ReadCharmDir(path)
+	c.Assert(err, gc.ErrorMatches, `(?s)parsing "metadata.de.yaml" file: .*field sumary not found.*`)
+}
+
+func (s *TranslationsSuite) TestReadMetaTranslationsLanguages(c *gc.C) {
+	dir := c.MkDir()
+	for _, name := range []string{
+		"metadata.de.yaml",
+		"metadata.zh-Hant-TW.yaml",
+		"metadata.es_419.yaml",
+		"metadata.en-gb.yaml",
+		"metadata.SR_latn.yaml",
+		"metadata.de.bak.yaml",
+		"metadata.bak.yaml",
+		"metadata.old.yaml",
+		"metadata.fr-FR-old.yaml",
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte("summary: "+name+"\n"), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	translations, err := charm.ReadMetaTranslations(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(translations, jc.DeepEquals, map[string]charm.MetaTranslation{
+		"de":         {Summary: "metadata.de.yaml"},
+		"zh-Hant-TW": {Summary: "metadata.zh-Hant-TW.yaml"},
+		"es-419":     {Summary: "metadata.es_419.yaml"},
+		"en-GB":      {Summary: "metadata.en-gb.yaml"},
+		"sr-Latn":    {Summary: "metadata.SR_latn.yaml"},
+	})
+}
+
+func (s *TranslationsSuite) TestCharmDirAndArchiveTranslations(c *gc.C) {
+	path := cloneDir(c, charmDirPath(c, "dummy"))
+	writeTranslations(c, path)
+
+	dir, err := charm.ReadCharmDir(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(dir.Meta().Translations, jc.DeepEquals, expectTranslations)
+
+	archive, err := charm.ReadCharmArchive(archivePath(c, dir))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(archive.Meta().Translations, jc.DeepEquals, expectTranslations)
+}
+
+func (s *TranslationsSuite) TestLocalized(c *gc.C) {
+	meta := &charm.Meta{
+		Summary:      "A summary",
+		Description:  "A description",
+		Translations: expectTranslations,
+	}
+	for i, test := range []struct {
+		lang   string
+		expect charm.MetaTranslation
+	}{{
+		lang:   "fr",
+		expect: charm.MetaTranslation{Summary: "Un résumé", Description: "Une description"},
+	}, {
+		lang:   "fr-CA",
+		expect: charm.MetaTranslation{Summary: "Un résumé", Description: "Une description"},
+	}, {
+		lang:   "pt-BR",
+		expect: charm.MetaTranslation{Summary: "Um resumo", Description: "A description"},
+	}, {
+		lang:   "pt_br",
+		expect: charm.MetaTranslation{Summary: "Um resumo", Description: "A description"},
+	}, {
+		lang:   "FR-ca",
+		expect: charm.MetaTranslation{Summary: "Un résumé", Description: "Une description"},
+	}, {
+		lang:   "pt",
+		expect: charm.MetaTranslation{Summary: "A summary", Description: "A description"},
+	}, {
+		lang:   "",
+		expect: charm.MetaTranslation{Summary: "A summary", Description: "A description"},
+	}} {
+		c.Logf("test %d: %q", i, test.lang)
+		c.Check(meta.Localized(test.lang), jc.DeepEquals, test.expect)
+	}
+}
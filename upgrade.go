@@ -0,0 +1,120 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// IncompatibilityKind classifies the breaking changes found by
+// CheckUpgrade.
+type IncompatibilityKind string
+
+const (
+	RelationRemoved          IncompatibilityKind = "relation-removed"
+	RelationRoleChanged      IncompatibilityKind = "relation-role-changed"
+	RelationInterfaceChanged IncompatibilityKind = "relation-interface-changed"
+	StorageRemoved           IncompatibilityKind = "storage-removed"
+	StorageTypeChanged       IncompatibilityKind = "storage-type-changed"
+	OptionRemoved            IncompatibilityKind = "option-removed"
+	OptionTypeChanged        IncompatibilityKind = "option-type-changed"
+	SubordinateChanged       IncompatibilityKind = "subordinate-changed"
+)
+
+// Incompatibility describes a change between two revisions of a charm
+// that may break applications upgrading from one to the other.
+type Incompatibility struct {
+	// Kind classifies the change.
+	Kind IncompatibilityKind
+
+	// Name holds the name of the relation, storage or config option
+	// affected by the change. It is empty for changes affecting the
+	// charm as a whole.
+	Name string
+
+	// Message describes the change.
+	Message string
+}
+
+// String returns the description of the incompatibility.
+func (i Incompatibility) String() string {
+	return i.Message
+}
+
+// CheckUpgrade compares two revisions of a charm and returns the
+// changes which may break applications upgrading from oldCharm to
+// newCharm:
+// relations that are removed or whose role or interface changes,
+// storage that is removed or whose type changes, config options that
+// are removed or whose type changes, and changes to whether the charm
+// is subordinate. The result is sorted by kind and then by name, and is
+// empty if the upgrade is safe as far as these checks can tell.
+func CheckUpgrade(oldCharm, newCharm Charm) []Incompatibility {
+	var result []Incompatibility
+	add := func(kind IncompatibilityKind, name, f string, a ...interface{}) {
+		result = append(result, Incompatibility{
+			Kind:    kind,
+			Name:    name,
+			Message: fmt.Sprintf(f, a...),
+		})
+	}
+
+	oldMeta, newMeta := oldCharm.Meta(), newCharm.Meta()
+	if oldMeta.Subordinate != newMeta.Subordinate {
+		if newMeta.Subordinate {
+			add(SubordinateChanged, "", "charm changed from principal to subordinate")
+		} else {
+			add(SubordinateChanged, "", "charm changed from subordinate to principal")
+		}
+	}
+
+	newRelations := newMeta.CombinedRelations()
+	for name, oldRel := range oldMeta.CombinedRelations() {
+		newRel, ok := newRelations[name]
+		switch {
+		case !ok:
+			add(RelationRemoved, name, "relation %q removed", name)
+		case newRel.Role != oldRel.Role:
+			add(RelationRoleChanged, name, "relation %q changed role from %q to %q", name, oldRel.Role, newRel.Role)
+		case newRel.Interface != oldRel.Interface:
+			add(RelationInterfaceChanged, name, "relation %q changed interface from %q to %q", name, oldRel.Interface, newRel.Interface)
+		}
+	}
+
+	for name, oldStore := range oldMeta.Storage {
+		newStore, ok := newMeta.Storage[name]
+		switch {
+		case !ok:
+			add(StorageRemoved, name, "storage %q removed", name)
+		case newStore.Type != oldStore.Type:
+			add(StorageTypeChanged, name, "storage %q changed type from %q to %q", name, oldStore.Type, newStore.Type)
+		}
+	}
+
+	var oldOptions, newOptions map[string]Option
+	if config := oldCharm.Config(); config != nil {
+		oldOptions = config.Options
+	}
+	if config := newCharm.Config(); config != nil {
+		newOptions = config.Options
+	}
+	for name, oldOption := range oldOptions {
+		newOption, ok := newOptions[name]
+		switch {
+		case !ok:
+			add(OptionRemoved, name, "config option %q removed", name)
+		case newOption.Type != oldOption.Type:
+			add(OptionTypeChanged, name, "config option %q changed type from %q to %q", name, oldOption.Type, newOption.Type)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
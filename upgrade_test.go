@@ -0,0 +1,106 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type UpgradeSuite struct{}
+
+var _ = gc.Suite(&UpgradeSuite{})
+
+func upgradeTestCharm() testCharmImpl {
+	return testCharmImpl{
+		meta: &charm.Meta{
+			Name: "wordpress",
+			Provides: map[string]charm.Relation{
+				"url": {Name: "url", Role: charm.RoleProvider, Interface: "http", Scope: charm.ScopeGlobal},
+			},
+			Requires: map[string]charm.Relation{
+				"db":    {Name: "db", Role: charm.RoleRequirer, Interface: "mysql", Scope: charm.ScopeGlobal},
+				"cache": {Name: "cache", Role: charm.RoleRequirer, Interface: "memcache", Scope: charm.ScopeGlobal},
+			},
+			Storage: map[string]charm.Storage{
+				"data": {Name: "data", Type: charm.StorageFilesystem},
+				"logs": {Name: "logs", Type: charm.StorageFilesystem},
+			},
+		},
+		config: &charm.Config{
+			Options: map[string]charm.Option{
+				"title":   {Type: "string"},
+				"workers": {Type: "int"},
+			},
+		},
+	}
+}
+
+func (s *UpgradeSuite) TestCheckUpgradeCompatible(c *gc.C) {
+	oldCharm := upgradeTestCharm()
+	newCharm := upgradeTestCharm()
+	// Additions are not breaking changes.
+	newCharm.meta.Provides["admin"] = charm.Relation{Name: "admin", Role: charm.RoleProvider, Interface: "http"}
+	newCharm.config.Options["debug"] = charm.Option{Type: "boolean"}
+	c.Assert(charm.CheckUpgrade(oldCharm, newCharm), gc.HasLen, 0)
+}
+
+func (s *UpgradeSuite) TestCheckUpgradeIncompatible(c *gc.C) {
+	oldCharm := upgradeTestCharm()
+	newCharm := upgradeTestCharm()
+	newCharm.meta.Subordinate = true
+	delete(newCharm.meta.Requires, "cache")
+	newCharm.meta.Requires["db"] = charm.Relation{Name: "db", Role: charm.RoleRequirer, Interface: "pgsql"}
+	delete(newCharm.meta.Provides, "url")
+	newCharm.meta.Requires["url"] = charm.Relation{Name: "url", Role: charm.RoleRequirer, Interface: "http"}
+	delete(newCharm.meta.Storage, "logs")
+	newCharm.meta.Storage["data"] = charm.Storage{Name: "data", Type: charm.StorageBlock}
+	delete(newCharm.config.Options, "title")
+	newCharm.config.Options["workers"] = charm.Option{Type: "string"}
+
+	c.Assert(charm.CheckUpgrade(oldCharm, newCharm), jc.DeepEquals, []charm.Incompatibility{{
+		Kind:    charm.OptionRemoved,
+		Name:    "title",
+		Message: `config option "title" removed`,
+	}, {
+		Kind:    charm.OptionTypeChanged,
+		Name:    "workers",
+		Message: `config option "workers" changed type from "int" to "string"`,
+	}, {
+		Kind:    charm.RelationInterfaceChanged,
+		Name:    "db",
+		Message: `relation "db" changed interface from "mysql" to "pgsql"`,
+	}, {
+		Kind:    charm.RelationRemoved,
+		Name:    "cache",
+		Message: `relation "cache" removed`,
+	}, {
+		Kind:    charm.RelationRoleChanged,
+		Name:    "url",
+		Message: `relation "url" changed role from "provider" to "requirer"`,
+	}, {
+		Kind:    charm.StorageRemoved,
+		Name:    "logs",
+		Message: `storage "logs" removed`,
+	}, {
+		Kind:    charm.StorageTypeChanged,
+		Name:    "data",
+		Message: `storage "data" changed type from "filesystem" to "block"`,
+	}, {
+		Kind:    charm.SubordinateChanged,
+		Message: "charm changed from principal to subordinate",
+	}})
+}
+
+func (s *UpgradeSuite) TestCheckUpgradeNoConfig(c *gc.C) {
+	oldCharm := upgradeTestCharm()
+	newCharm := upgradeTestCharm()
+	newCharm.config = nil
+	result := charm.CheckUpgrade(oldCharm, newCharm)
+	c.Assert(result, gc.HasLen, 2)
+	c.Assert(result[0].Kind, gc.Equals, charm.OptionRemoved)
+	c.Assert(result[0].String(), gc.Equals, `config option "title" removed`)
+}
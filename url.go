@@ -4,10 +4,16 @@
 package charm
 
 import (
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	gourl "net/url"
-	"regexp"
+	"os"
 	"strconv"
 	"strings"
 
@@ -61,10 +67,14 @@ type URL struct {
 	Architecture string // "amd64" or "" if unset for charmstore (v1) URLs.
 }
 
+// digestAlgorithm is the algorithm of the content digests by which
+// charm URLs may be pinned.
+const digestAlgorithm = "sha256"
+
 var (
-	validArch   = regexp.MustCompile("^[a-z]+([a-z0-9]+)?$")
-	validSeries = regexp.MustCompile("^[a-z]+([a-z0-9]+)?$")
-	validName   = regexp.MustCompile("^[a-z][a-z0-9]*(-[a-z0-9]*[a-z][a-z0-9]*)*$")
+	validArch   = lazyRegexp("^[a-z]+([a-z0-9]+)?$")
+	validSeries = lazyRegexp("^[a-z]+([a-z0-9]+)?$")
+	validName   = lazyRegexp("^[a-z][a-z0-9]*(-[a-z0-9]*[a-z][a-z0-9]*)*$")
 )
 
 // ValidateSchema returns an error if the schema is invalid.
@@ -84,7 +94,7 @@ func ValidateSchema(schema string) error {
 // IsValidSeries reports whether series is a valid series in charm or bundle
 // URLs.
 func IsValidSeries(series string) bool {
-	return validSeries.MatchString(series)
+	return validSeries().MatchString(series)
 }
 
 // ValidateSeries returns an error if the given series is invalid.
@@ -98,7 +108,57 @@ func ValidateSeries(series string) error {
 // IsValidArchitecture reports whether the architecture is a valid architecture
 // in charm or bundle URLs.
 func IsValidArchitecture(architecture string) bool {
-	return validArch.MatchString(architecture) && arch.IsSupportedArch(architecture)
+	return validArch().MatchString(architecture) && arch.IsSupportedArch(architecture)
+}
+
+// architectureAliases maps the names by which architectures are commonly
+// reported, such as by uname, onto their Juju names.
+var architectureAliases = map[string]string{
+	"x86_64":   arch.AMD64,
+	"x64":      arch.AMD64,
+	"aarch64":  arch.ARM64,
+	"arm64v8":  arch.ARM64,
+	"i686":     arch.I386,
+	"i586":     arch.I386,
+	"i486":     arch.I386,
+	"x86":      arch.I386,
+	"arm":      arch.ARM,
+	"armv8l":   arch.ARM,
+	"armv7l":   arch.ARM,
+	"armv7":    arch.ARM,
+	"armv6l":   arch.ARM,
+	"ppc64le":  arch.PPC64EL,
+	"ppc64":    arch.PPC64EL,
+	"risc":     arch.RISCV64,
+	"risc-v64": arch.RISCV64,
+}
+
+// NormaliseArchitecture returns the Juju name of the given architecture,
+// mapping aliases such as "x86_64" and "aarch64" onto "amd64" and
+// "arm64". Names are matched case-insensitively. Any name that
+// arch.NormaliseArch maps onto a Juju name is mapped the same way; other
+// names are returned lower-cased, and may then be rejected by
+// ValidateArchitecture.
+func NormaliseArchitecture(architecture string) string {
+	architecture = strings.TrimSpace(architecture)
+	lower := strings.ToLower(architecture)
+	if canonical, ok := architectureAliases[lower]; ok {
+		return canonical
+	}
+	if canonical := arch.NormaliseArch(architecture); arch.IsSupportedArch(canonical) {
+		return canonical
+	}
+	return lower
+}
+
+// ParseArchitecture returns the Juju name of the given architecture, as
+// returned by NormaliseArchitecture, or an error if it is not valid.
+func ParseArchitecture(architecture string) (string, error) {
+	canonical := NormaliseArchitecture(architecture)
+	if !IsValidArchitecture(canonical) {
+		return "", errors.NotValidf("architecture name %q", architecture)
+	}
+	return canonical, nil
 }
 
 // ValidateArchitecture returns an error if the given architecture is invalid.
@@ -111,7 +171,7 @@ func ValidateArchitecture(arch string) error {
 
 // IsValidName reports whether name is a valid charm or bundle name.
 func IsValidName(name string) bool {
-	return validName.MatchString(name)
+	return validName().MatchString(name)
 }
 
 // ValidateName returns an error if the given name is invalid.
@@ -122,6 +182,190 @@ func ValidateName(name string) error {
 	return errors.NotValidf("name %q", name)
 }
 
+// IsLocal reports whether the URL refers to a local charm or bundle.
+func (u *URL) IsLocal() bool {
+	return Local.Matches(u.Schema)
+}
+
+// IsCharmHub reports whether the URL refers to a charm or bundle in
+// the charmhub repository.
+func (u *URL) IsCharmHub() bool {
+	return CharmHub.Matches(u.Schema)
+}
+
+// Validate returns an error if the URL is not one that ParseURL could
+// have returned: the schema, name, revision, series and architecture
+// must all be valid, and local URLs cannot specify an architecture.
+func (u *URL) Validate() error {
+	if err := ValidateSchema(u.Schema); err != nil {
+		return errors.Trace(err)
+	}
+	if err := ValidateName(u.Name); err != nil {
+		return errors.Trace(err)
+	}
+	if u.Revision < -1 {
+		return errors.NotValidf("revision %d", u.Revision)
+	}
+	if u.Series != "" {
+		if err := ValidateSeries(u.Series); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if u.Architecture != "" {
+		if u.IsLocal() {
+			return errors.NotValidf("local URL with architecture %q", u.Architecture)
+		}
+		if err := ValidateArchitecture(u.Architecture); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// ValidateDigest returns an error if the given content digest is not of
+// the form "sha256:<hex>", with the hex-encoded SHA-256 checksum given
+// in lower case.
+func ValidateDigest(digest string) error {
+	algorithm, sum, ok := strings.Cut(digest, ":")
+	if !ok || algorithm != digestAlgorithm || len(sum) != 2*sha256.Size || strings.ToLower(sum) != sum {
+		return errors.NotValidf("digest %q", digest)
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return errors.NotValidf("digest %q", digest)
+	}
+	return nil
+}
+
+// Digest returns the content digest of the data read from r, in the
+// form used by pinned charm URLs.
+func Digest(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", errors.Trace(err)
+	}
+	return digestAlgorithm + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ArchiveDigest returns the content digest of the charm or bundle
+// archive at path, in the form used by pinned charm URLs.
+func ArchiveDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer f.Close()
+	digest, err := Digest(f)
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot read archive %q", path)
+	}
+	return digest, nil
+}
+
+// PinnedURL is a charm URL pinned to the content digest of its
+// archive. It is written as the URL followed by "@" and the digest:
+//
+//	ch:wordpress@sha256:<hex>
+//
+// The digest is held outside URL, so that URL values, and the
+// charm URLs stored with them, are unaffected by pinning.
+type PinnedURL struct {
+	URL    *URL
+	Digest string // "sha256:<hex>".
+}
+
+// MustParsePinnedURL works like ParsePinnedURL, but panics in case of
+// errors.
+func MustParsePinnedURL(url string) *PinnedURL {
+	u, err := ParsePinnedURL(url)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// ParsePinnedURL parses a charmhub URL pinned to the content digest of
+// its archive by appending "@sha256:<hex>". The URL part is parsed as
+// ParseURL does.
+func ParsePinnedURL(url string) (*PinnedURL, error) {
+	i := strings.LastIndex(url, "@")
+	if i < 0 {
+		return nil, errors.NotValidf("pinned URL %q without digest", url)
+	}
+	curl, err := ParseURL(url[:i])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	pinned := &PinnedURL{URL: curl, Digest: url[i+1:]}
+	if err := pinned.Validate(); err != nil {
+		return nil, errors.Annotatef(err, "cannot parse URL %q", url)
+	}
+	return pinned, nil
+}
+
+// Validate returns an error if the pinned URL is not one that
+// ParsePinnedURL could have returned.
+func (u *PinnedURL) Validate() error {
+	if u.URL == nil {
+		return errors.NotValidf("pinned URL without URL")
+	}
+	if err := u.URL.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	if u.URL.IsLocal() {
+		return errors.NotValidf("local URL with digest %q", u.Digest)
+	}
+	return errors.Trace(ValidateDigest(u.Digest))
+}
+
+// String returns the string representation of the pinned URL.
+func (u *PinnedURL) String() string {
+	return u.URL.String() + "@" + u.Digest
+}
+
+// VerifyDigest reads the archive content from r and checks that it
+// matches the digest to which the URL is pinned.
+func (u *PinnedURL) VerifyDigest(r io.Reader) error {
+	digest, err := Digest(r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if digest != u.Digest {
+		return errors.Errorf("charm %q digest mismatch: expected %s, got %s", u.String(), u.Digest, digest)
+	}
+	return nil
+}
+
+// VerifyArchive checks that the content of the archive at path matches
+// the digest to which the URL is pinned.
+func (u *PinnedURL) VerifyArchive(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer f.Close()
+	return errors.Trace(u.VerifyDigest(f))
+}
+
+// MarshalText implements encoding.TextMarshaler by
+// returning u.String()
+func (u *PinnedURL) MarshalText() ([]byte, error) {
+	if u == nil {
+		return nil, nil
+	}
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler by
+// parsing the data with ParsePinnedURL.
+func (u *PinnedURL) UnmarshalText(data []byte) error {
+	url, err := ParsePinnedURL(string(data))
+	if err != nil {
+		return err
+	}
+	*u = *url
+	return nil
+}
+
 // WithRevision returns a URL equivalent to url but with Revision set
 // to revision.
 func (u *URL) WithRevision(revision int) *URL {
@@ -156,10 +400,23 @@ func MustParseURL(url string) *URL {
 }
 
 // ParseURL parses the provided charm URL string into its respective
-// structure.
+// structure. If the URL cannot be parsed, the error is a *URLParseError.
 //
-// A missing schema is assumed to be 'ch'.
+// A missing schema is assumed to be 'ch'. Use ParsePinnedURL for URLs
+// pinned to a content digest.
 func ParseURL(url string) (*URL, error) {
+	curl, err := parseURL(url)
+	if err != nil {
+		return nil, &URLParseError{
+			URL:    url,
+			Reason: err.Error(),
+			cause:  err,
+		}
+	}
+	return curl, nil
+}
+
+func parseURL(url string) (*URL, error) {
 	u, err := gourl.Parse(url)
 	if err != nil {
 		return nil, errors.Errorf("cannot parse charm or bundle URL: %q", url)
@@ -327,7 +584,7 @@ func (u *URL) MarshalText() ([]byte, error) {
 	return []byte(u.FullPath()), nil
 }
 
-// UnmarshalText implements encoding.TestUnmarshaler by
+// UnmarshalText implements encoding.TextUnmarshaler by
 // parsing the data with ParseURL.
 func (u *URL) UnmarshalText(data []byte) error {
 	url, err := ParseURL(string(data))
@@ -338,6 +595,48 @@ func (u *URL) UnmarshalText(data []byte) error {
 	return nil
 }
 
+// Value implements driver.Valuer so that a URL can be stored directly
+// in a SQL database. The URL is stored as the text returned by
+// u.FullPath(), and a nil URL is stored as NULL.
+func (u *URL) Value() (driver.Value, error) {
+	if u == nil {
+		return nil, nil
+	}
+	return u.FullPath(), nil
+}
+
+// Scan implements sql.Scanner by parsing the text read from a SQL
+// database with ParseURL. A NULL value leaves u as the zero URL.
+func (u *URL) Scan(src interface{}) error {
+	var s string
+	switch src := src.(type) {
+	case nil:
+		*u = URL{}
+		return nil
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	default:
+		return errors.Errorf("cannot scan %T into charm URL", src)
+	}
+	url, err := ParseURL(s)
+	if err != nil {
+		return err
+	}
+	*u = *url
+	return nil
+}
+
+var (
+	_ encoding.TextMarshaler   = (*URL)(nil)
+	_ encoding.TextUnmarshaler = (*URL)(nil)
+	_ driver.Valuer            = (*URL)(nil)
+	_ sql.Scanner              = (*URL)(nil)
+	_ encoding.TextMarshaler   = (*PinnedURL)(nil)
+	_ encoding.TextUnmarshaler = (*PinnedURL)(nil)
+)
+
 // Quote translates a charm url string into one which can be safely used
 // in a file path.  ASCII letters, ASCII digits, dot and dash stay the
 // same; other characters are translated to their hex representation
@@ -400,19 +699,19 @@ func parseCharmhubURL(url *gourl.URL) (*URL, error) {
 	var nameRev string
 	switch len(parts) {
 	case 3:
-		r.Architecture, r.Series, nameRev = parts[0], parts[1], parts[2]
-
-		if err := ValidateArchitecture(r.Architecture); err != nil {
+		architecture, err := ParseArchitecture(parts[0])
+		if err != nil {
 			return nil, errors.Annotatef(err, "in URL %q", url)
 		}
+		r.Architecture, r.Series, nameRev = architecture, parts[1], parts[2]
 	case 2:
 		// Since both the architecture and series are optional,
 		// the first part can be either architecture or series.
 		// To differentiate between them, we go ahead and try to
 		// validate the first part as an architecture to decide.
 
-		if err := ValidateArchitecture(parts[0]); err == nil {
-			r.Architecture, nameRev = parts[0], parts[1]
+		if architecture, err := ParseArchitecture(parts[0]); err == nil {
+			r.Architecture, nameRev = architecture, parts[1]
 		} else {
 			r.Series, nameRev = parts[0], parts[1]
 		}
@@ -6,10 +6,15 @@ package charm_test
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/juju/errors"
 	"github.com/juju/mgo/v3/bson"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/v3/arch"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/yaml.v2"
 
@@ -91,6 +96,14 @@ var urlTests = []struct {
 	s:     "ch:arm64/name",
 	url:   &charm.URL{"ch", "name", -1, "", "arm64"},
 	exact: "ch:arm64/name",
+}, {
+	s:     "ch:x86_64/focal/name-2",
+	url:   &charm.URL{"ch", "name", 2, "focal", "amd64"},
+	exact: "ch:amd64/focal/name-2",
+}, {
+	s:     "ch:aarch64/name",
+	url:   &charm.URL{"ch", "name", -1, "", "arm64"},
+	exact: "ch:arm64/name",
 }, {
 	s:   "ch:~user/name",
 	err: `charmhub charm or bundle URL with user name: "ch:~user/name" not valid`,
@@ -205,6 +218,51 @@ func (s *URLSuite) TestValidCheckers(c *gc.C) {
 	}
 }
 
+func (s *URLSuite) TestNormaliseArchitecture(c *gc.C) {
+	for in, out := range map[string]string{
+		"amd64":   "amd64",
+		"x86_64":  "amd64",
+		"X86_64":  "amd64",
+		"aarch64": "arm64",
+		"i686":    "i386",
+		"armv7l":  "armhf",
+		"ppc64le": "ppc64el",
+		"s390x":   "s390x",
+		"arm":     "armhf",
+		"armv8l":  "armhf",
+		"purple":  "purple",
+	} {
+		c.Check(charm.NormaliseArchitecture(in), gc.Equals, out, gc.Commentf("%q", in))
+	}
+}
+
+func (s *URLSuite) TestNormaliseArchitectureCompatible(c *gc.C) {
+	// Every name mapped onto a Juju name by arch.NormaliseArch, which
+	// NormaliseArchitecture replaced, is still mapped the same way.
+	for _, in := range []string{
+		"amd64", "x86_64", " amd64 ", "amd64\n",
+		"386", "i386", "i486", "i586", "i686", "i786", "i886", "i986",
+		"arm", "armhf", "armv6l", "armv7", "armv7l", "armv8l", "armv8b",
+		"arm64", "aarch64",
+		"ppc64", "ppc64el", "ppc64le",
+		"s390x",
+		"riscv64", "risc", "risc-v64", "risc-V64",
+	} {
+		old := arch.NormaliseArch(in)
+		c.Assert(arch.IsSupportedArch(old), jc.IsTrue, gc.Commentf("%q", in))
+		c.Check(charm.NormaliseArchitecture(in), gc.Equals, old, gc.Commentf("%q", in))
+	}
+}
+
+func (s *URLSuite) TestParseArchitecture(c *gc.C) {
+	a, err := charm.ParseArchitecture("x86_64")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(a, gc.Equals, "amd64")
+
+	_, err = charm.ParseArchitecture("purple")
+	c.Check(err, gc.ErrorMatches, `architecture name "purple" not valid`)
+}
+
 func (s *URLSuite) TestMustParseURL(c *gc.C) {
 	url := charm.MustParseURL("ch:series/name")
 	c.Assert(url, gc.DeepEquals, &charm.URL{"ch", "name", -1, "series", ""})
@@ -212,6 +270,15 @@ func (s *URLSuite) TestMustParseURL(c *gc.C) {
 	c.Assert(f, gc.PanicMatches, "cannot parse URL \"local:@@/name\": series name \"@@\" not valid")
 }
 
+func (s *URLSuite) TestParseURLError(c *gc.C) {
+	_, err := charm.ParseURL("local:@@/name")
+	var parseErr *charm.URLParseError
+	c.Assert(errors.As(err, &parseErr), jc.IsTrue)
+	c.Assert(parseErr.URL, gc.Equals, "local:@@/name")
+	c.Assert(parseErr.Reason, gc.Equals, `cannot parse URL "local:@@/name": series name "@@" not valid`)
+	c.Assert(err, jc.ErrorIs, errors.NotValid)
+}
+
 func (s *URLSuite) TestWithRevision(c *gc.C) {
 	url := charm.MustParseURL("ch:series/name")
 	other := url.WithRevision(1)
@@ -223,6 +290,150 @@ func (s *URLSuite) TestWithRevision(c *gc.C) {
 	c.Assert(other.WithRevision(1), gc.DeepEquals, other)
 }
 
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+var pinnedURLTests = []struct {
+	s   string
+	err string
+	url *charm.PinnedURL
+}{{
+	s:   "ch:mysql@" + testDigest,
+	url: &charm.PinnedURL{URL: &charm.URL{"ch", "mysql", -1, "", ""}, Digest: testDigest},
+}, {
+	s:   "ch:amd64/jammy/mysql-3@" + testDigest,
+	url: &charm.PinnedURL{URL: &charm.URL{"ch", "mysql", 3, "jammy", "amd64"}, Digest: testDigest},
+}, {
+	s:   "mysql@" + testDigest,
+	url: &charm.PinnedURL{URL: &charm.URL{"ch", "mysql", -1, "", ""}, Digest: testDigest},
+}, {
+	s:   "ch:mysql",
+	err: `pinned URL "ch:mysql" without digest not valid`,
+}, {
+	s:   "ch:mysql@md5:0123456789abcdef",
+	err: `cannot parse URL "ch:mysql@md5:0123456789abcdef": digest "md5:0123456789abcdef" not valid`,
+}, {
+	s:   "ch:mysql@sha256:0123",
+	err: `cannot parse URL "ch:mysql@sha256:0123": digest "sha256:0123" not valid`,
+}, {
+	s:   "local:mysql@" + testDigest,
+	err: `cannot parse URL "local:mysql@sha256:[0-9a-f]+": local URL with digest "sha256:[0-9a-f]+" not valid`,
+}}
+
+func (s *URLSuite) TestParsePinnedURL(c *gc.C) {
+	for i, t := range pinnedURLTests {
+		c.Logf("test %d: %q", i, t.s)
+		url, err := charm.ParsePinnedURL(t.s)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(url, jc.DeepEquals, t.url)
+		c.Check(url.String(), gc.Equals, "ch:"+strings.TrimPrefix(t.s, "ch:"))
+	}
+}
+
+func (s *URLSuite) TestParseURLWithDigest(c *gc.C) {
+	// Pinned URLs are not charm URLs.
+	_, err := charm.ParseURL("ch:mysql@" + testDigest)
+	c.Assert(err, gc.NotNil)
+}
+
+func (s *URLSuite) TestValidateDigest(c *gc.C) {
+	c.Assert(charm.ValidateDigest(testDigest), jc.ErrorIsNil)
+	for _, digest := range []string{
+		"",
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		"sha512:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		"sha256:0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF",
+		"sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdeg",
+	} {
+		err := charm.ValidateDigest(digest)
+		c.Check(err, gc.ErrorMatches, fmt.Sprintf(`digest %q not valid`, digest))
+		c.Check(err, jc.ErrorIs, errors.NotValid)
+	}
+}
+
+func (s *URLSuite) TestVerifyDigest(c *gc.C) {
+	content := "archive content"
+	digest, err := charm.Digest(strings.NewReader(content))
+	c.Assert(err, jc.ErrorIsNil)
+	// echo -n "archive content" | sha256sum
+	c.Assert(digest, gc.Equals, "sha256:fa868b2818c90263b5c2c8e056180232a6f3c34547ca49b7f3ca10599a52db3d")
+
+	url := charm.MustParsePinnedURL("ch:mysql@" + digest)
+	c.Assert(url.VerifyDigest(strings.NewReader(content)), jc.ErrorIsNil)
+
+	err = url.VerifyDigest(strings.NewReader("other content"))
+	c.Assert(err, gc.ErrorMatches, `charm "ch:mysql@sha256:[0-9a-f]+" digest mismatch: expected sha256:[0-9a-f]+, got sha256:[0-9a-f]+`)
+}
+
+func (s *URLSuite) TestVerifyArchive(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "mysql.charm")
+	err := os.WriteFile(path, []byte("archive content"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	digest, err := charm.ArchiveDigest(path)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(charm.MustParsePinnedURL("ch:mysql@"+digest).VerifyArchive(path), jc.ErrorIsNil)
+	c.Assert(charm.MustParsePinnedURL("ch:mysql@"+testDigest).VerifyArchive(path), gc.ErrorMatches, `charm .* digest mismatch: .*`)
+
+	_, err = charm.ArchiveDigest(filepath.Join(c.MkDir(), "missing.charm"))
+	c.Assert(err, jc.ErrorIs, os.ErrNotExist)
+}
+
+func (s *URLSuite) TestSchemaPredicates(c *gc.C) {
+	url := charm.MustParseURL("ch:amd64/focal/wordpress-3")
+	c.Assert(url.IsCharmHub(), jc.IsTrue)
+	c.Assert(url.IsLocal(), jc.IsFalse)
+
+	url = charm.MustParseURL("local:focal/wordpress-3")
+	c.Assert(url.IsCharmHub(), jc.IsFalse)
+	c.Assert(url.IsLocal(), jc.IsTrue)
+}
+
+var validateURLTests = []struct {
+	url charm.URL
+	err string
+}{{
+	url: charm.URL{Schema: "ch", Name: "wordpress", Revision: -1},
+}, {
+	url: charm.URL{Schema: "ch", Name: "wordpress", Revision: 3, Series: "focal", Architecture: "amd64"},
+}, {
+	url: charm.URL{Schema: "local", Name: "wordpress", Revision: 0, Series: "focal"},
+}, {
+	url: charm.URL{Schema: "cs", Name: "wordpress", Revision: -1},
+	err: `schema "cs" not valid`,
+}, {
+	url: charm.URL{Schema: "ch", Name: "Word_press", Revision: -1},
+	err: `name "Word_press" not valid`,
+}, {
+	url: charm.URL{Schema: "ch", Name: "wordpress", Revision: -2},
+	err: `revision -2 not valid`,
+}, {
+	url: charm.URL{Schema: "ch", Name: "wordpress", Revision: -1, Series: "@@"},
+	err: `series name "@@" not valid`,
+}, {
+	url: charm.URL{Schema: "ch", Name: "wordpress", Revision: -1, Architecture: "z80"},
+	err: `architecture name "z80" not valid`,
+}, {
+	url: charm.URL{Schema: "local", Name: "wordpress", Revision: -1, Architecture: "amd64"},
+	err: `local URL with architecture "amd64" not valid`,
+}}
+
+func (s *URLSuite) TestValidate(c *gc.C) {
+	for i, t := range validateURLTests {
+		c.Logf("test %d: %#v", i, t.url)
+		err := t.url.Validate()
+		if t.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+			continue
+		}
+		c.Check(err, gc.ErrorMatches, t.err)
+		c.Check(err, jc.ErrorIs, errors.NotValid)
+	}
+}
+
 var codecs = []struct {
 	Name      string
 	Marshal   func(interface{}) ([]byte, error)
@@ -274,6 +485,23 @@ func (s *URLSuite) TestURLCodecs(c *gc.C) {
 	}
 }
 
+func (s *URLSuite) TestPinnedURLCodecs(c *gc.C) {
+	url := charm.MustParsePinnedURL("ch:mysql-3@" + testDigest)
+	// Pinned URLs are encoded as text, which bson does not support.
+	for i, codec := range codecs[1:] {
+		c.Logf("codec %d: %v", i, codec.Name)
+		type doc struct {
+			URL *charm.PinnedURL
+		}
+		data, err := codec.Marshal(doc{url})
+		c.Assert(err, jc.ErrorIsNil)
+		var v doc
+		err = codec.Unmarshal(data, &v)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(v.URL, jc.DeepEquals, url)
+	}
+}
+
 func (s *URLSuite) TestJSONGarbage(c *gc.C) {
 	// unmarshalling json gibberish
 	for _, value := range []string{":{", `"ch:{}+<"`, `"ch:~_~/f00^^&^/baaaar$%-?"`} {
@@ -282,6 +510,52 @@ func (s *URLSuite) TestJSONGarbage(c *gc.C) {
 	}
 }
 
+func (s *URLSuite) TestURLMapKeys(c *gc.C) {
+	m := map[*charm.URL]int{
+		charm.MustParseURL("ch:amd64/jammy/mysql-2"): 1,
+	}
+	data, err := json.Marshal(m)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, `{"ch:amd64/jammy/mysql-2":1}`)
+
+	var out map[charm.URL]int
+	err = json.Unmarshal(data, &out)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(out, jc.DeepEquals, map[charm.URL]int{
+		*charm.MustParseURL("ch:amd64/jammy/mysql-2"): 1,
+	})
+}
+
+func (s *URLSuite) TestSQLValueScan(c *gc.C) {
+	url := charm.MustParseURL("ch:amd64/jammy/mysql-2")
+	value, err := url.Value()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(value, gc.Equals, "ch:amd64/jammy/mysql-2")
+
+	value, err = (*charm.URL)(nil).Value()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(value, gc.IsNil)
+
+	for i, src := range []interface{}{"ch:amd64/jammy/mysql-2", []byte("ch:amd64/jammy/mysql-2")} {
+		c.Logf("test %d: %T", i, src)
+		var scanned charm.URL
+		err = scanned.Scan(src)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(&scanned, jc.DeepEquals, url)
+	}
+
+	scanned := *url
+	err = scanned.Scan(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(scanned, jc.DeepEquals, charm.URL{})
+
+	err = scanned.Scan(42)
+	c.Assert(err, gc.ErrorMatches, `cannot scan int into charm URL`)
+
+	err = scanned.Scan("ch:~_~/f00^^&^/baaaar$%-?")
+	c.Assert(err, gc.NotNil)
+}
+
 type QuoteSuite struct{}
 
 var _ = gc.Suite(&QuoteSuite{})
@@ -0,0 +1,220 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// URLPattern matches charm URLs. It has the same form as a charm URL,
+// except that the name may contain "*" wildcards, each matching any
+// sequence of characters:
+//
+//	ch:mysql*
+//	ch:amd64/jammy/*-k8s
+//
+// Any part of the URL that the pattern leaves unset matches all
+// values, so "ch:mysql" matches "ch:mysql-3" and "ch:amd64/focal/mysql"
+// alike.
+type URLPattern struct {
+	Schema       string
+	Name         string // May contain "*" wildcards.
+	Revision     int    // -1 to match any revision.
+	Series       string // "" to match any series.
+	Architecture string // "" to match any architecture.
+}
+
+// ParseURLPattern parses the given charm URL pattern. The syntax is
+// that accepted by ParseURL, with wildcards allowed in the name.
+func ParseURLPattern(pattern string) (*URLPattern, error) {
+	nameStart := strings.LastIndexAny(pattern, ":/") + 1
+	if strings.Contains(pattern[:nameStart], "*") {
+		return nil, errors.NotValidf("charm URL pattern %q with wildcard outside the name", pattern)
+	}
+	// Wildcards can't be parsed as part of a charm name, so stand in a
+	// valid name character for each one while validating the rest of
+	// the pattern.
+	curl, err := ParseURL(strings.Replace(pattern, "*", "x", -1))
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot parse charm URL pattern %q", pattern)
+	}
+	name := pattern[nameStart:]
+	if curl.Revision >= 0 {
+		name = strings.TrimSuffix(name, fmt.Sprintf("-%d", curl.Revision))
+	}
+	return &URLPattern{
+		Schema:       curl.Schema,
+		Name:         name,
+		Revision:     curl.Revision,
+		Series:       curl.Series,
+		Architecture: curl.Architecture,
+	}, nil
+}
+
+// MustParseURLPattern works like ParseURLPattern, but panics in case
+// of errors.
+func MustParseURLPattern(pattern string) *URLPattern {
+	p, err := ParseURLPattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match reports whether the given URL matches the pattern.
+func (p *URLPattern) Match(u *URL) bool {
+	if p.Schema != u.Schema {
+		return false
+	}
+	if p.Revision >= 0 && p.Revision != u.Revision {
+		return false
+	}
+	if p.Series != "" && p.Series != u.Series {
+		return false
+	}
+	if p.Architecture != "" && p.Architecture != u.Architecture {
+		return false
+	}
+	// Charm names can't contain any of the other path.Match
+	// metacharacters, so only "*" has a special meaning here.
+	ok, _ := path.Match(p.Name, u.Name)
+	return ok
+}
+
+// String returns the string representation of the pattern.
+func (p *URLPattern) String() string {
+	u := URL(*p)
+	return u.String()
+}
+
+// URLSet holds a set of charm URL patterns, such as an allow or deny
+// list of charms. Plain charm URLs are patterns too, matching only
+// themselves, except for the parts they leave unset.
+//
+// The zero value is an empty set, ready to use.
+type URLSet struct {
+	patterns map[string]*URLPattern
+}
+
+// NewURLSet returns a set holding the given patterns.
+func NewURLSet(patterns ...string) (*URLSet, error) {
+	var s URLSet
+	for _, p := range patterns {
+		if err := s.Add(p); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return &s, nil
+}
+
+// Add adds the given pattern to the set.
+func (s *URLSet) Add(pattern string) error {
+	p, err := ParseURLPattern(pattern)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if s.patterns == nil {
+		s.patterns = make(map[string]*URLPattern)
+	}
+	s.patterns[p.String()] = p
+	return nil
+}
+
+// AddURL adds the given charm URL to the set.
+func (s *URLSet) AddURL(u *URL) {
+	p := URLPattern(*u)
+	if s.patterns == nil {
+		s.patterns = make(map[string]*URLPattern)
+	}
+	s.patterns[p.String()] = &p
+}
+
+// Remove removes the given pattern from the set, if present.
+func (s *URLSet) Remove(pattern string) {
+	if p, err := ParseURLPattern(pattern); err == nil {
+		delete(s.patterns, p.String())
+	}
+}
+
+// Contains reports whether the given pattern is a member of the set.
+// Unlike Match, no wildcard matching is involved: "ch:mysql-1" is not
+// contained in a set holding only "ch:mysql*".
+func (s *URLSet) Contains(pattern string) bool {
+	p, err := ParseURLPattern(pattern)
+	if err != nil {
+		return false
+	}
+	_, ok := s.patterns[p.String()]
+	return ok
+}
+
+// Match reports whether any pattern in the set matches the given URL.
+func (s *URLSet) Match(u *URL) bool {
+	for _, p := range s.patterns {
+		if p.Match(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of patterns in the set.
+func (s *URLSet) Len() int {
+	return len(s.patterns)
+}
+
+// Values returns the patterns in the set in canonical order: sorted
+// by schema, name, series, architecture and then revision, with the
+// unset parts first.
+func (s *URLSet) Values() []*URLPattern {
+	values := make([]*URLPattern, 0, len(s.patterns))
+	for _, p := range s.patterns {
+		values = append(values, p)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return lessURLPattern(values[i], values[j])
+	})
+	return values
+}
+
+// Strings returns the string form of the patterns in the set, in the
+// order returned by Values.
+func (s *URLSet) Strings() []string {
+	values := s.Values()
+	strs := make([]string, len(values))
+	for i, p := range values {
+		strs[i] = p.String()
+	}
+	return strs
+}
+
+// SortURLs sorts the given charm URLs in the canonical order used by
+// URLSet.Values.
+func SortURLs(urls []*URL) {
+	sort.Slice(urls, func(i, j int) bool {
+		pi, pj := URLPattern(*urls[i]), URLPattern(*urls[j])
+		return lessURLPattern(&pi, &pj)
+	})
+}
+
+func lessURLPattern(p0, p1 *URLPattern) bool {
+	if p0.Schema != p1.Schema {
+		return p0.Schema < p1.Schema
+	}
+	if p0.Name != p1.Name {
+		return p0.Name < p1.Name
+	}
+	if p0.Series != p1.Series {
+		return p0.Series < p1.Series
+	}
+	if p0.Architecture != p1.Architecture {
+		return p0.Architecture < p1.Architecture
+	}
+	return p0.Revision < p1.Revision
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type URLSetSuite struct{}
+
+var _ = gc.Suite(&URLSetSuite{})
+
+var urlPatternTests = []struct {
+	pattern string
+	expect  *charm.URLPattern
+	err     string
+}{{
+	pattern: "ch:mysql*",
+	expect:  &charm.URLPattern{Schema: "ch", Name: "mysql*", Revision: -1},
+}, {
+	pattern: "*",
+	expect:  &charm.URLPattern{Schema: "ch", Name: "*", Revision: -1},
+}, {
+	pattern: "ch:amd64/jammy/*-k8s-3",
+	expect:  &charm.URLPattern{Schema: "ch", Name: "*-k8s", Revision: 3, Series: "jammy", Architecture: "amd64"},
+}, {
+	pattern: "local:focal/my*",
+	expect:  &charm.URLPattern{Schema: "local", Name: "my*", Revision: -1, Series: "focal"},
+}, {
+	pattern: "ch:wordpress",
+	expect:  &charm.URLPattern{Schema: "ch", Name: "wordpress", Revision: -1},
+}, {
+	pattern: "ch:amd64/*/mysql",
+	err:     `charm URL pattern "ch:amd64/\*/mysql" with wildcard outside the name not valid`,
+}, {
+	pattern: "cs:mysql*",
+	err:     `cannot parse charm URL pattern "cs:mysql\*": cannot parse URL "cs:mysqlx": schema "cs" not valid`,
+}}
+
+func (s *URLSetSuite) TestParseURLPattern(c *gc.C) {
+	for i, t := range urlPatternTests {
+		c.Logf("test %d: %q", i, t.pattern)
+		p, err := charm.ParseURLPattern(t.pattern)
+		if t.err != "" {
+			c.Check(err, gc.ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(p, jc.DeepEquals, t.expect)
+		c.Check(p.String(), gc.Equals, charm.MustParseURLPattern(p.String()).String())
+	}
+}
+
+var urlPatternMatchTests = []struct {
+	pattern string
+	url     string
+	expect  bool
+}{
+	{"ch:mysql*", "ch:mysql", true},
+	{"ch:mysql*", "ch:mysql-k8s-12", true},
+	{"ch:mysql*", "ch:amd64/focal/mysql-router", true},
+	{"ch:mysql*", "ch:postgresql", false},
+	{"ch:mysql*", "local:mysql", false},
+	{"ch:*-k8s", "ch:mysql-k8s", true},
+	{"ch:*-k8s", "ch:mysql", false},
+	{"ch:mysql", "ch:jammy/mysql-3", true},
+	{"ch:mysql", "ch:mysql-router", false},
+	{"ch:jammy/mysql", "ch:focal/mysql", false},
+	{"ch:arm64/mysql", "ch:amd64/mysql", false},
+	{"ch:mysql-3", "ch:mysql-3", true},
+	{"ch:mysql-3", "ch:mysql-4", false},
+}
+
+func (s *URLSetSuite) TestURLPatternMatch(c *gc.C) {
+	for i, t := range urlPatternMatchTests {
+		c.Logf("test %d: %q %q", i, t.pattern, t.url)
+		p := charm.MustParseURLPattern(t.pattern)
+		c.Check(p.Match(charm.MustParseURL(t.url)), gc.Equals, t.expect)
+	}
+}
+
+func (s *URLSetSuite) TestURLSet(c *gc.C) {
+	set, err := charm.NewURLSet("ch:mysql*", "ch:jammy/wordpress", "local:dummy")
+	c.Assert(err, jc.ErrorIsNil)
+	set.AddURL(charm.MustParseURL("ch:amd64/ubuntu-3"))
+	c.Assert(set.Len(), gc.Equals, 4)
+
+	c.Assert(set.Contains("ch:mysql*"), jc.IsTrue)
+	c.Assert(set.Contains("mysql*"), jc.IsTrue)
+	c.Assert(set.Contains("ch:mysql"), jc.IsFalse)
+
+	c.Assert(set.Match(charm.MustParseURL("ch:mysql-router-2")), jc.IsTrue)
+	c.Assert(set.Match(charm.MustParseURL("ch:jammy/wordpress-7")), jc.IsTrue)
+	c.Assert(set.Match(charm.MustParseURL("ch:focal/wordpress")), jc.IsFalse)
+	c.Assert(set.Match(charm.MustParseURL("ch:dummy")), jc.IsFalse)
+
+	set.Remove("ch:mysql*")
+	c.Assert(set.Len(), gc.Equals, 3)
+	c.Assert(set.Match(charm.MustParseURL("ch:mysql")), jc.IsFalse)
+
+	_, err = charm.NewURLSet("ch:amd64/*/mysql")
+	c.Assert(err, gc.ErrorMatches, `charm URL pattern .* with wildcard outside the name not valid`)
+}
+
+func (s *URLSetSuite) TestURLSetZeroValue(c *gc.C) {
+	var set charm.URLSet
+	c.Assert(set.Len(), gc.Equals, 0)
+	c.Assert(set.Match(charm.MustParseURL("ch:mysql")), jc.IsFalse)
+	err := set.Add("ch:mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(set.Strings(), jc.DeepEquals, []string{"ch:mysql"})
+}
+
+func (s *URLSetSuite) TestURLSetCanonicalOrder(c *gc.C) {
+	set, err := charm.NewURLSet(
+		"local:zebra",
+		"ch:mysql-2",
+		"ch:jammy/mysql",
+		"ch:mysql",
+		"ch:mysql-10",
+		"ch:arm64/mysql",
+		"ch:apache*",
+		"ch:mysql",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(set.Strings(), jc.DeepEquals, []string{
+		"ch:apache*",
+		"ch:mysql",
+		"ch:mysql-2",
+		"ch:mysql-10",
+		"ch:arm64/mysql",
+		"ch:jammy/mysql",
+		"local:zebra",
+	})
+}
+
+func (s *URLSetSuite) TestSortURLs(c *gc.C) {
+	urls := []*charm.URL{
+		charm.MustParseURL("local:dummy"),
+		charm.MustParseURL("ch:wordpress-10"),
+		charm.MustParseURL("ch:wordpress-9"),
+		charm.MustParseURL("ch:mysql"),
+	}
+	charm.SortURLs(urls)
+	strs := make([]string, len(urls))
+	for i, u := range urls {
+		strs[i] = u.String()
+	}
+	c.Assert(strs, jc.DeepEquals, []string{"ch:mysql", "ch:wordpress-9", "ch:wordpress-10", "local:dummy"})
+}
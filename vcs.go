@@ -0,0 +1,236 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// VersionProvider generates the version string for a charm directory,
+// typically from the version control system holding it.
+type VersionProvider interface {
+	// Type returns the kind of version the provider generates, such
+	// as "git".
+	Type() string
+
+	// Version returns the version string for the charm directory at
+	// path. It returns false if the provider does not apply to the
+	// directory, in which case the next provider is tried.
+	Version(path string) (string, bool, error)
+}
+
+// GitVersionProvider returns a provider that runs "git describe" for
+// charms kept in a git repository.
+func GitVersionProvider() VersionProvider {
+	return &vcsCMD{
+		vcsType:       "git",
+		args:          []string{"describe", "--dirty", "--always"},
+		usesTypeCheck: usesGit,
+	}
+}
+
+// HgVersionProvider returns a provider that runs "hg id" for charms
+// kept in a Mercurial repository.
+func HgVersionProvider() VersionProvider {
+	return &vcsCMD{
+		vcsType:       "hg",
+		args:          []string{"id", "-n"},
+		usesTypeCheck: usesHg,
+	}
+}
+
+// BzrVersionProvider returns a provider that runs "bzr version-info"
+// for charms kept in a Bazaar branch.
+func BzrVersionProvider() VersionProvider {
+	return &vcsCMD{
+		vcsType:       "bzr",
+		args:          []string{"version-info"},
+		usesTypeCheck: usesBzr,
+	}
+}
+
+// GitReaderVersionProvider returns a provider that reads the commit
+// checked out in a charm's git repository directly, without running
+// git. The version is the abbreviated commit hash, so unlike
+// GitVersionProvider it includes neither tags nor a dirty marker.
+func GitReaderVersionProvider() VersionProvider {
+	return gitReader{}
+}
+
+// VersionFileProvider returns a provider that reads the version from
+// the charm's version file.
+func VersionFileProvider() VersionProvider {
+	return versionFile{}
+}
+
+// DefaultVersionProviders returns the providers used when none are
+// specified: git, Mercurial and Bazaar, then the version file. Where
+// git is not installed, GitReaderVersionProvider can be used in place
+// of GitVersionProvider.
+func DefaultVersionProviders() []VersionProvider {
+	return []VersionProvider{
+		GitVersionProvider(),
+		HgVersionProvider(),
+		BzrVersionProvider(),
+		VersionFileProvider(),
+	}
+}
+
+// DetectVersion returns the version string for the charm directory at
+// path and the type of the provider that generated it, trying each of
+// the given providers in turn until one applies. If no providers are
+// given, DefaultVersionProviders is used. If none applies, the version
+// and type are empty.
+func DetectVersion(path string, providers ...VersionProvider) (string, string, error) {
+	if len(providers) == 0 {
+		providers = DefaultVersionProviders()
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", errors.Annotatef(err, "failed resolving relative path %q", path)
+	}
+	for _, p := range providers {
+		version, ok, err := p.Version(absPath)
+		if err != nil {
+			return "", p.Type(), err
+		}
+		if ok {
+			return version, p.Type(), nil
+		}
+	}
+	return "", "", nil
+}
+
+// gitHashAbbrev holds the length of the abbreviated commit hashes
+// generated by gitReader, matching git's default.
+const gitHashAbbrev = 7
+
+type gitReader struct{}
+
+// Type implements VersionProvider.
+func (gitReader) Type() string {
+	return "git"
+}
+
+// Version implements VersionProvider.
+func (gitReader) Version(path string) (string, bool, error) {
+	gitDir, ok := findGitDir(path)
+	if !ok {
+		return "", false, nil
+	}
+	hash, err := readGitHead(gitDir)
+	if err != nil {
+		return "", true, errors.Annotatef(err, "reading git HEAD in %q", gitDir)
+	}
+	if len(hash) > gitHashAbbrev {
+		hash = hash[:gitHashAbbrev]
+	}
+	return hash, true, nil
+}
+
+// findGitDir returns the git directory of the repository holding path,
+// searching parent directories as git does.
+func findGitDir(path string) (string, bool) {
+	for dir := path; ; {
+		candidate := filepath.Join(dir, ".git")
+		if info, err := os.Stat(candidate); err == nil {
+			if info.IsDir() {
+				return candidate, true
+			}
+			// Worktrees and submodules use a .git file pointing
+			// at the real git directory.
+			if data, err := os.ReadFile(candidate); err == nil {
+				line := strings.TrimSpace(string(data))
+				if target := strings.TrimPrefix(line, "gitdir: "); target != line {
+					if !filepath.IsAbs(target) {
+						target = filepath.Join(dir, target)
+					}
+					return target, true
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// readGitHead returns the commit hash checked out in the given git
+// directory.
+func readGitHead(gitDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	head := strings.TrimSpace(string(data))
+	ref := strings.TrimPrefix(head, "ref: ")
+	if ref == head {
+		// A detached HEAD holds the hash itself.
+		return head, nil
+	}
+	// Linked worktrees keep shared refs in the common directory.
+	dirs := []string{gitDir}
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		dirs = append(dirs, common)
+	}
+	for _, dir := range dirs {
+		if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+		if hash, ok := readPackedRef(filepath.Join(dir, "packed-refs"), ref); ok {
+			return hash, nil
+		}
+	}
+	return "", errors.NotFoundf("git ref %q", ref)
+}
+
+// readPackedRef returns the hash for ref from the given packed-refs
+// file.
+func readPackedRef(path, ref string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], true
+		}
+	}
+	return "", false
+}
+
+type versionFile struct{}
+
+// Type implements VersionProvider.
+func (versionFile) Type() string {
+	return versionFileVersionType
+}
+
+// Version implements VersionProvider.
+func (versionFile) Version(path string) (string, bool, error) {
+	file, err := os.Open(filepath.Join(path, "version"))
+	if err != nil {
+		return "", false, nil
+	}
+	defer file.Close()
+	version, err := ReadVersion(file)
+	if err != nil {
+		return "", true, err
+	}
+	return version, true, nil
+}
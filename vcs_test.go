@@ -0,0 +1,158 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type VersionProviderSuite struct{}
+
+var _ = gc.Suite(&VersionProviderSuite{})
+
+type fakeVersionProvider struct {
+	vcsType string
+	version string
+	ok      bool
+	err     error
+}
+
+func (p fakeVersionProvider) Type() string {
+	return p.vcsType
+}
+
+func (p fakeVersionProvider) Version(path string) (string, bool, error) {
+	return p.version, p.ok, p.err
+}
+
+const gitHash = "0123456789abcdef0123456789abcdef01234567"
+
+func writeFiles(c *gc.C, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		c.Assert(err, jc.ErrorIsNil)
+		err = os.WriteFile(path, []byte(content), 0644)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *VersionProviderSuite) TestDetectVersionOrder(c *gc.C) {
+	version, vcsType, err := charm.DetectVersion(c.MkDir(),
+		fakeVersionProvider{vcsType: "a"},
+		fakeVersionProvider{vcsType: "b", version: "v2", ok: true},
+		fakeVersionProvider{vcsType: "c", version: "v3", ok: true},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, "v2")
+	c.Assert(vcsType, gc.Equals, "b")
+
+	_, vcsType, err = charm.DetectVersion(c.MkDir(),
+		fakeVersionProvider{vcsType: "a", err: errors.New("boom")},
+		fakeVersionProvider{vcsType: "b", version: "v2", ok: true},
+	)
+	c.Assert(err, gc.ErrorMatches, "boom")
+	c.Assert(vcsType, gc.Equals, "a")
+
+	version, vcsType, err = charm.DetectVersion(c.MkDir(), fakeVersionProvider{vcsType: "a"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, "")
+	c.Assert(vcsType, gc.Equals, "")
+}
+
+func (s *VersionProviderSuite) TestVersionFileProvider(c *gc.C) {
+	dir := c.MkDir()
+	_, ok, err := charm.VersionFileProvider().Version(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsFalse)
+
+	writeFiles(c, dir, map[string]string{"version": "revision-id: 1.2.3\n"})
+	version, ok, err := charm.VersionFileProvider().Version(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(version, gc.Equals, "1.2.3")
+}
+
+func (s *VersionProviderSuite) TestGitReaderVersionProvider(c *gc.C) {
+	provider := charm.GitReaderVersionProvider()
+	c.Assert(provider.Type(), gc.Equals, "git")
+
+	// Loose ref, with the charm in a subdirectory of the repository.
+	repo := c.MkDir()
+	writeFiles(c, repo, map[string]string{
+		".git/HEAD":            "ref: refs/heads/main\n",
+		".git/refs/heads/main": gitHash + "\n",
+		"charms/dummy/x":       "",
+	})
+	version, ok, err := provider.Version(filepath.Join(repo, "charms", "dummy"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(version, gc.Equals, "0123456")
+
+	// Packed ref.
+	repo = c.MkDir()
+	writeFiles(c, repo, map[string]string{
+		".git/HEAD":        "ref: refs/heads/main\n",
+		".git/packed-refs": "# pack-refs with: peeled fully-peeled sorted\n" + gitHash + " refs/heads/main\n",
+	})
+	version, ok, err = provider.Version(repo)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(version, gc.Equals, "0123456")
+
+	// Detached HEAD in a linked worktree.
+	repo = c.MkDir()
+	writeFiles(c, repo, map[string]string{
+		"main/.git/worktrees/wt/HEAD": "fedcba9876543210fedcba9876543210fedcba98\n",
+		"wt/.git":                     "gitdir: ../main/.git/worktrees/wt\n",
+	})
+	version, ok, err = provider.Version(filepath.Join(repo, "wt"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(version, gc.Equals, "fedcba9")
+
+	// Missing ref.
+	repo = c.MkDir()
+	writeFiles(c, repo, map[string]string{".git/HEAD": "ref: refs/heads/main\n"})
+	_, ok, err = provider.Version(repo)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(err, gc.ErrorMatches, `reading git HEAD in ".*": git ref "refs/heads/main" not found`)
+}
+
+func (s *CharmDirSuite) TestArchiveToWithVersionProviders(c *gc.C) {
+	dir, err := charm.ReadCharmDir(cloneDir(c, charmDirPath(c, "dummy")))
+	c.Assert(err, jc.ErrorIsNil)
+	dir.SetVersionProviders(fakeVersionProvider{vcsType: "fake", version: "fake-1", ok: true})
+
+	var buf bytes.Buffer
+	err = dir.ArchiveTo(&buf)
+	c.Assert(err, jc.ErrorIsNil)
+	archive, err := charm.ReadCharmArchiveBytes(buf.Bytes())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(archive.Version(), gc.Equals, "fake-1")
+}
+
+func (s *CharmDirSuite) TestArchiveToWithVersionDetectionDisabled(c *gc.C) {
+	charmDir := cloneDir(c, charmDirPath(c, "dummy"))
+	writeFiles(c, charmDir, map[string]string{"version": "1.2.3"})
+	dir, err := charm.ReadCharmDir(charmDir)
+	c.Assert(err, jc.ErrorIsNil)
+	dir.SetVersionProviders(fakeVersionProvider{vcsType: "fake", version: "fake-1", ok: true})
+	dir.DisableVersionDetection()
+
+	var buf bytes.Buffer
+	err = dir.ArchiveTo(&buf)
+	c.Assert(err, jc.ErrorIsNil)
+	archive, err := charm.ReadCharmArchiveBytes(buf.Bytes())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(archive.Version(), gc.Equals, "1.2.3")
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// YAMLPositionError describes a problem found at a particular position
+// in a YAML source such as a metadata.yaml or bundle.yaml file. Its
+// message is that of the error describing the problem, so that the
+// position is only reported by callers that ask for it.
+type YAMLPositionError struct {
+	// File holds the name of the source holding the problem, or is
+	// empty if the source has no name.
+	File string
+
+	// Line holds the line, starting at one, of the problem.
+	Line int
+
+	// Column holds the column, starting at one, of the problem, or
+	// zero if it is not known.
+	Column int
+
+	// Err holds the error describing the problem.
+	Err error
+}
+
+// Error implements error.
+func (e *YAMLPositionError) Error() string {
+	return e.Err.Error()
+}
+
+// Position returns the position of the problem in the conventional
+// file:line:column form, leaving out the column if it is not known.
+func (e *YAMLPositionError) Position() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+}
+
+// Unwrap returns the error describing the problem.
+func (e *YAMLPositionError) Unwrap() error {
+	return e.Err
+}
+
+// yamlSyntaxErrorPattern matches the syntax errors returned by the YAML
+// parser, which report the line but not the column of the problem.
+var yamlSyntaxErrorPattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlPositionError returns err as a *YAMLPositionError locating the
+// problem in file, whose contents are data, if it can be located. The
+// problem can be located if err is a YAML syntax error, or if its
+// message, once prefix is removed, starts with the dotted path of a
+// field in data, such as "storage.data.type: unexpected value", in which
+// case the deepest field on the path that exists in data is reported.
+// Otherwise err is returned unchanged.
+func yamlPositionError(file string, data []byte, prefix string, err error) error {
+	if perr, ok := yamlSyntaxError(file, err).(*YAMLPositionError); ok {
+		return perr
+	}
+	rest, ok := strings.CutPrefix(err.Error(), prefix)
+	if !ok {
+		return err
+	}
+	path, _, ok := strings.Cut(rest, ": ")
+	if !ok || strings.ContainsAny(path, " \t\"") {
+		return err
+	}
+	return yamlFieldError(file, data, path, err)
+}
+
+// yamlSyntaxError returns err as a *YAMLPositionError if it is a syntax
+// error returned by the YAML parser for file, and unchanged otherwise.
+func yamlSyntaxError(file string, err error) error {
+	m := yamlSyntaxErrorPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &YAMLPositionError{File: file, Line: line, Err: err}
+}
+
+// yamlFieldError returns err as a *YAMLPositionError locating it at the
+// deepest field on the given dotted path that exists in data, the
+// contents of file. If no field on the path exists, err is returned
+// unchanged.
+func yamlFieldError(file string, data []byte, path string, err error) error {
+	node := yamlNodeAt(data, path)
+	if node == nil {
+		return err
+	}
+	return &YAMLPositionError{
+		File:   file,
+		Line:   node.Line,
+		Column: node.Column,
+		Err:    err,
+	}
+}
+
+// yamlIndexPattern matches the sequence indexes of a field path, as in
+// "storage.data.properties[1]".
+var yamlIndexPattern = regexp.MustCompile(`^\[(\d+)\]`)
+
+// yamlNodeAt returns the node of the YAML document in data found by
+// following the given dotted field path as far as it exists in the
+// document. The key node is returned for fields of a mapping. It
+// returns nil if the document cannot be parsed or if not even the first
+// field on the path exists.
+func yamlNodeAt(data []byte, path string) *yamlv3.Node {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	var (
+		found *yamlv3.Node
+		node  = doc.Content[0]
+	)
+	for path != "" {
+		if node.Kind == yamlv3.AliasNode {
+			node = node.Alias
+		}
+		if node.Kind != yamlv3.MappingNode {
+			break
+		}
+		// Field names may themselves hold dots, so prefer the longest
+		// key that matches the start of the path.
+		var key, value *yamlv3.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := node.Content[i].Value
+			if !strings.HasPrefix(path, k) || (key != nil && len(k) <= len(key.Value)) {
+				continue
+			}
+			if next := path[len(k):]; next == "" || next[0] == '.' || next[0] == '[' {
+				key, value = node.Content[i], node.Content[i+1]
+			}
+		}
+		if key == nil {
+			break
+		}
+		found, node = key, value
+		path = path[len(key.Value):]
+		for {
+			m := yamlIndexPattern.FindStringSubmatch(path)
+			if m == nil {
+				break
+			}
+			index, _ := strconv.Atoi(m[1])
+			if node.Kind != yamlv3.SequenceNode || index >= len(node.Content) {
+				return found
+			}
+			found, node = node.Content[index], node.Content[index]
+			path = path[len(m[0]):]
+		}
+		path = strings.TrimPrefix(path, ".")
+	}
+	return found
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/charm/v12"
+)
+
+type YAMLPositionSuite struct{}
+
+var _ = gc.Suite(&YAMLPositionSuite{})
+
+func (s *YAMLPositionSuite) TestMetaSchemaError(c *gc.C) {
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+storage:
+  data:
+    type: floppy
+`), charm.WithMetaSource("charm/metadata.yaml"))
+	c.Assert(err, gc.ErrorMatches, `metadata: storage.data.type: unexpected value "floppy"`)
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsTrue)
+	c.Assert(perr.File, gc.Equals, "charm/metadata.yaml")
+	c.Assert(perr.Line, gc.Equals, 7)
+	c.Assert(perr.Column, gc.Equals, 5)
+	c.Assert(perr.Position(), gc.Equals, "charm/metadata.yaml:7:5")
+}
+
+func (s *YAMLPositionSuite) TestMetaWithoutSource(c *gc.C) {
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+storage:
+  data:
+    type: floppy
+`))
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsTrue)
+	c.Assert(perr.Position(), gc.Equals, ":7:5")
+}
+
+func (s *YAMLPositionSuite) TestCharmDirMetaError(c *gc.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "metadata.yaml")
+	err := os.WriteFile(path, []byte("name: a\nsummary: b\ndescription: c\nrequires:\n  db: ~\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = charm.ReadCharmDir(dir)
+	c.Assert(err, gc.ErrorMatches, `parsing "metadata.yaml" file: metadata: requires.db: expected map, got nothing`)
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsTrue)
+	c.Assert(perr.Position(), gc.Equals, path+":5:3")
+}
+
+func (s *YAMLPositionSuite) TestMetaMissingField(c *gc.C) {
+	// The deepest field on the path that exists is reported.
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+storage:
+  data:
+    description: no type
+`))
+	c.Assert(err, gc.ErrorMatches, `metadata: storage.data.type: unexpected value <nil>`)
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsTrue)
+	c.Assert(perr.Position(), gc.Equals, ":6:3")
+}
+
+func (s *YAMLPositionSuite) TestMetaSyntaxError(c *gc.C) {
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+  description: c
+`))
+	c.Assert(err, gc.ErrorMatches, `yaml: line 4: mapping values are not allowed in this context`)
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsTrue)
+	c.Assert(perr.Line, gc.Equals, 4)
+	c.Assert(perr.Column, gc.Equals, 0)
+}
+
+func (s *YAMLPositionSuite) TestMetaErrorWithoutPath(c *gc.C) {
+	_, err := charm.ReadMeta(strings.NewReader(`
+name: a
+summary: b
+description: c
+deployment:
+  service: cluster
+series:
+  - xenial
+`))
+	c.Assert(err, gc.ErrorMatches, `charms with deployment metadata only supported for "kubernetes"`)
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsFalse)
+}
+
+func (s *YAMLPositionSuite) TestBundleSyntaxError(c *gc.C) {
+	_, err := charm.ReadBundleData(strings.NewReader(`
+applications:
+  mysql:
+    charm: mysql
+---
+applications:
+  mysql: [
+`), charm.WithBundleSource("bundle.yaml"))
+	c.Assert(err, gc.ErrorMatches, `unmarshal document 1: yaml: line 7: did not find expected node content`)
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsTrue)
+	c.Assert(perr.Position(), gc.Equals, "bundle.yaml:7")
+}
+
+func (s *YAMLPositionSuite) TestBundleFieldInOverlayFile(c *gc.C) {
+	_, err := charm.ReadBundleData(strings.NewReader(`# a comment
+---
+applications:
+  mysql:
+    charm: mysql
+    num-units: 1
+`), charm.WithStrictApplications(), charm.WithBundleSource("overlay.yaml"))
+	c.Assert(err, gc.ErrorMatches, `application "mysql" field "num-units" not valid; did you mean "num_units"\?`)
+	var perr *charm.YAMLPositionError
+	c.Assert(errors.As(err, &perr), jc.IsTrue)
+	c.Assert(perr.Position(), gc.Equals, "overlay.yaml:6:5")
+}